@@ -1,6 +1,7 @@
 package block
 
 import (
+	"encoding/hex"
 	"fmt"
 
 	"github.com/0chain/gosdk/core/common"
@@ -51,15 +52,24 @@ type Block struct {
 	MagicBlockHash string `json:"magic_block_hash"`
 	PrevHash       string `json:"prev_hash"`
 
-	ClientStateHash Key                        `json:"state_hash"`
-	Txns            []*transaction.Transaction `json:"transactions,omitempty"`
+	StateChangesCount int                        `json:"state_changes_count"`
+	ClientStateHash   Key                        `json:"state_hash"`
+	Txns              []*transaction.Transaction `json:"transactions,omitempty"`
+
+	VerificationTickets []*VerificationTicket `json:"verification_tickets,omitempty"`
 
 	// muted
 
-	// VerificationTickets []*VerificationTicket `json:"verification_tickets,omitempty"`
 	// PrevBlockVerificationTickets []*VerificationTicket `json:"prev_verification_tickets,omitempty"`
 }
 
+// VerificationTicket is a miner's signature on a block hash. A block is
+// notarized once it carries tickets from at least T miners of its magic block.
+type VerificationTicket struct {
+	VerifierID string `json:"verifier_id"`
+	Signature  string `json:"signature"`
+}
+
 type ChainStats struct {
 	BlockSize            int     `json:"block_size"`
 	Count                int     `json:"count"`
@@ -86,3 +96,20 @@ type FeeStats struct {
 	MinFees  common.Balance `json:"min_fees"`
 	MeanFees common.Balance `json:"mean_fees"`
 }
+
+// ComputeHash recomputes the hash of the block the way miners do. It commits
+// to the previous block, the transaction and receipt roots and, once set, the
+// client state, so verification tickets on the hash cover all of them.
+func (b *Block) ComputeHash() string {
+	var merkleRoot, receiptRoot string
+	if b.Header != nil {
+		merkleRoot, receiptRoot = b.Header.MerkleTreeRoot, b.Header.ReceiptMerkleTreeRoot
+	}
+	data := fmt.Sprintf("%s:%s:%d:%d:%d:%d:%s:%s", b.MinerID, b.PrevHash,
+		b.CreationDate, b.Round, b.RoundRandomSeed, b.StateChangesCount,
+		merkleRoot, receiptRoot)
+	if len(b.ClientStateHash) > 0 {
+		data += ":" + hex.EncodeToString(b.ClientStateHash)
+	}
+	return encryption.Hash(data)
+}
@@ -0,0 +1,71 @@
+package block
+
+import (
+	"testing"
+
+	"github.com/0chain/gosdk/core/encryption"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockComputeHash(t *testing.T) {
+	b := &Block{
+		MinerID:           "miner",
+		PrevHash:          "prev",
+		CreationDate:      1680000000,
+		Round:             42,
+		RoundRandomSeed:   7,
+		StateChangesCount: 3,
+		Header: &Header{
+			MerkleTreeRoot:        "merkle",
+			ReceiptMerkleTreeRoot: "receipts",
+		},
+	}
+
+	t.Run("without client state", func(t *testing.T) {
+		// the layout of the confirmation headers checked by transaction.VerifyTransaction
+		require.Equal(t, encryption.Hash("miner:prev:1680000000:42:7:3:merkle:receipts"), b.ComputeHash())
+	})
+
+	t.Run("with client state", func(t *testing.T) {
+		state := *b
+		state.ClientStateHash = []byte{0xab, 0xcd}
+		require.Equal(t, encryption.Hash("miner:prev:1680000000:42:7:3:merkle:receipts:abcd"), state.ComputeHash())
+	})
+}
+
+func TestMagicBlockComputeHash(t *testing.T) {
+	mb := &MagicBlock{
+		PreviousMagicBlockHash: "prev",
+		MagicBlockNumber:       2,
+		StartingRound:          100,
+		Miners:                 &NodePool{Nodes: map[string]Node{"m2": {}, "m1": {}}},
+		Sharders:               &NodePool{Nodes: map[string]Node{"s1": {}}},
+		ShareOrSigns: &GroupSharesOrSigns{Shares: map[string]*ShareOrSigns{
+			"m1": {ID: "m1", ShareOrSigns: map[string]*DKGKeyShare{"m2": {ID: "m2", Share: "share"}}},
+		}},
+		Mpks: &Mpks{Mpks: map[string]*MPK{
+			"m2": {ID: "m2", Mpk: []string{"c", "d"}},
+			"m1": {ID: "m1", Mpk: []string{"a", "b"}},
+		}},
+		T: 2,
+		K: 2,
+		N: 2,
+	}
+
+	share := encryption.RawHash(`m1{"id":"m2","message":"","share":"share","sign":""}`)
+	data := "2prev100m1m2s1" + string(encryption.RawHash(share)) +
+		string(encryption.RawHash("ab")) + string(encryption.RawHash("cd")) + "22"
+	require.Equal(t, encryption.Hash([]byte(data)), mb.ComputeHash())
+
+	t.Run("ignores k", func(t *testing.T) {
+		k := *mb
+		k.K = 3
+		require.Equal(t, mb.ComputeHash(), k.ComputeHash())
+	})
+
+	t.Run("commits to the miners", func(t *testing.T) {
+		miners := *mb
+		miners.Miners = &NodePool{Nodes: map[string]Node{"m1": {}}}
+		require.NotEqual(t, mb.ComputeHash(), miners.ComputeHash())
+	})
+}
@@ -1,6 +1,16 @@
 package block
 
-import "time"
+import (
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/0chain/errors"
+	"github.com/0chain/gosdk/core/encryption"
+	"github.com/0chain/gosdk/core/zcncrypto"
+)
 
 type Node struct {
 	ID           string `yaml:"id" json:"id"`
@@ -69,3 +79,127 @@ type MagicBlock struct {
 	K                      int                 `json:"k"`
 	N                      int                 `json:"n"`
 }
+
+// GetMiner returns the miner with the given id from the magic block.
+func (mb *MagicBlock) GetMiner(id string) (*Node, bool) {
+	if mb.Miners == nil {
+		return nil, false
+	}
+	n, ok := mb.Miners.Nodes[id]
+	if !ok {
+		return nil, false
+	}
+	return &n, true
+}
+
+// GroupPublicKey returns the DKG group public key of the magic block. It is the
+// sum of the constant terms of the master public keys of all miners.
+func (mb *MagicBlock) GroupPublicKey() (zcncrypto.PublicKey, error) {
+	if mb.Mpks == nil || len(mb.Mpks.Mpks) == 0 {
+		return nil, errors.New("group_public_key", "magic block has no mpks")
+	}
+
+	var gpk zcncrypto.PublicKey
+	for id, mpk := range mb.Mpks.Mpks {
+		if mpk == nil || len(mpk.Mpk) == 0 {
+			return nil, errors.Newf("group_public_key", "empty mpk for miner %v", id)
+		}
+		pk := zcncrypto.BlsSignerInstance.NewPublicKey()
+		if err := pk.DeserializeHexStr(mpk.Mpk[0]); err != nil {
+			return nil, errors.Wrap(err, "invalid mpk of miner "+id)
+		}
+		if gpk == nil {
+			gpk = pk
+			continue
+		}
+		gpk.Add(pk)
+	}
+	return gpk, nil
+}
+
+// ComputeHash recomputes the hash of the magic block the way miners do: the
+// link to the previous magic block, the ids of the miners and sharders, the
+// hashes of the DKG shares and master public keys, and T and N.
+func (mb *MagicBlock) ComputeHash() string {
+	data := []byte(strconv.FormatInt(mb.MagicBlockNumber, 10))
+	data = append(data, mb.PreviousMagicBlockHash...)
+	data = append(data, strconv.FormatInt(mb.StartingRound, 10)...)
+	for _, pool := range []*NodePool{mb.Miners, mb.Sharders} {
+		if pool == nil {
+			continue
+		}
+		for _, id := range sortedKeys(pool.Nodes) {
+			data = append(data, id...)
+		}
+	}
+	if mb.ShareOrSigns != nil {
+		data = append(data, mb.ShareOrSigns.hash()...)
+	}
+	if mb.Mpks != nil {
+		for _, id := range sortedKeys(mb.Mpks.Mpks) {
+			data = append(data, mb.Mpks.Mpks[id].hash()...)
+		}
+	}
+	data = append(data, strconv.Itoa(mb.T)...)
+	data = append(data, strconv.Itoa(mb.N)...)
+	return encryption.Hash(data)
+}
+
+// hash hashes the shares of every miner ordered by miner id.
+func (gsos *GroupSharesOrSigns) hash() []byte {
+	var data []byte
+	for _, id := range sortedKeys(gsos.Shares) {
+		data = append(data, gsos.Shares[id].hash()...)
+	}
+	return encryption.RawHash(data)
+}
+
+// hash hashes the id of the miner and its json encoded shares ordered by recipient.
+func (sos *ShareOrSigns) hash() []byte {
+	if sos == nil {
+		return encryption.RawHash("")
+	}
+	data := sos.ID
+	for _, id := range sortedKeys(sos.ShareOrSigns) {
+		share, _ := json.Marshal(sos.ShareOrSigns[id])
+		data += string(share)
+	}
+	return encryption.RawHash(data)
+}
+
+// hash hashes the coefficients of the master public key.
+func (mpk *MPK) hash() []byte {
+	var data []byte
+	if mpk != nil {
+		for _, c := range mpk.Mpk {
+			data = append(data, c...)
+		}
+	}
+	return encryption.RawHash(data)
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// VerifyNodeIDs checks that the ids of the miners and sharders are the hashes
+// of their public keys.
+func (mb *MagicBlock) VerifyNodeIDs() error {
+	for _, pool := range []*NodePool{mb.Miners, mb.Sharders} {
+		if pool == nil {
+			continue
+		}
+		for id, n := range pool.Nodes {
+			key, err := hex.DecodeString(n.PublicKey)
+			if err != nil || n.ID != id || encryption.Hash(key) != id {
+				return errors.Newf("magic_block", "node %v does not match its public key", id)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+// Package keystore stores zcncrypto wallets encrypted with a passphrase, so
+// private keys and mnemonics are never kept in plaintext at rest. Seal
+// encrypts other secrets the same way.
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/0chain/errors"
+	"github.com/0chain/gosdk/core/common"
+	"github.com/0chain/gosdk/core/zcncrypto"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// Version of the keystore format
+	Version = 1
+
+	kdfScrypt    = "scrypt"
+	cipherAESGCM = "aes-256-gcm"
+
+	saltSize = 32
+	keyLen   = 32
+
+	// bounds of the kdf parameters read from a keystore, so a crafted file
+	// can't make Decrypt allocate or compute without limit
+	maxScryptN = 1 << 20
+	maxScryptR = 32
+	maxScryptP = 16
+)
+
+// KDFParams are the scrypt parameters used to derive the encryption key from
+// the passphrase. The defaults match zboxutil.ScryptEncrypt.
+type KDFParams struct {
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+	Salt string `json:"salt"`
+}
+
+// DefaultKDFParams returns the scrypt cost parameters for new entries.
+func DefaultKDFParams() KDFParams {
+	return KDFParams{N: 32768, R: 8, P: 1}
+}
+
+// validate checks the parameters are in the range accepted by Decrypt.
+func (p KDFParams) validate() error {
+	if p.N < 2 || p.N > maxScryptN || p.N&(p.N-1) != 0 {
+		return errors.Throw(ErrInvalidKDFParams, "n must be a power of two up to 2^20")
+	}
+	if p.R < 1 || p.R > maxScryptR {
+		return errors.Throw(ErrInvalidKDFParams, "r is out of range")
+	}
+	if p.P < 1 || p.P > maxScryptP {
+		return errors.Throw(ErrInvalidKDFParams, "p is out of range")
+	}
+	if len(p.Salt) != 2*saltSize {
+		return errors.Throw(ErrInvalidKDFParams, "invalid salt size")
+	}
+	return nil
+}
+
+// Sealed is data encrypted with AES-GCM and a key derived from a passphrase
+// with scrypt.
+type Sealed struct {
+	KDF        string    `json:"kdf"`
+	KDFParams  KDFParams `json:"kdf_params"`
+	Cipher     string    `json:"cipher"`
+	Nonce      string    `json:"nonce"`
+	Ciphertext string    `json:"ciphertext"`
+}
+
+// Seal encrypts data with a key derived from passphrase. additionalData is
+// authenticated but not encrypted, and is required to open the data.
+func Seal(data []byte, passphrase string, additionalData []byte) (*Sealed, error) {
+	if passphrase == "" {
+		return nil, ErrEmptyPassphrase
+	}
+
+	params := DefaultKDFParams()
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	params.Salt = hex.EncodeToString(salt)
+
+	aead, err := newAEAD(passphrase, params)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return &Sealed{
+		KDF:        kdfScrypt,
+		KDFParams:  params,
+		Cipher:     cipherAESGCM,
+		Nonce:      hex.EncodeToString(nonce),
+		Ciphertext: hex.EncodeToString(aead.Seal(nil, nonce, data, additionalData)),
+	}, nil
+}
+
+// Open decrypts the data with passphrase and the additional data it was sealed with.
+func (s *Sealed) Open(passphrase string, additionalData []byte) ([]byte, error) {
+	if s.KDF != kdfScrypt || s.Cipher != cipherAESGCM {
+		return nil, errors.New("keystore_decrypt", "unsupported kdf or cipher")
+	}
+	if err := s.KDFParams.validate(); err != nil {
+		return nil, err
+	}
+
+	nonce, err := hex.DecodeString(s.Nonce)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid nonce")
+	}
+	ciphertext, err := hex.DecodeString(s.Ciphertext)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid ciphertext")
+	}
+
+	aead, err := newAEAD(passphrase, s.KDFParams)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, errors.New("keystore_decrypt", "invalid nonce size")
+	}
+	data, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, ErrInvalidPassphrase
+	}
+	return data, nil
+}
+
+// EncryptedWallet is a wallet encrypted with a passphrase. Only the client id
+// and key are kept in plaintext so wallets can be listed without unlocking.
+type EncryptedWallet struct {
+	Version   int    `json:"version"`
+	ClientID  string `json:"client_id"`
+	ClientKey string `json:"client_key"`
+	Sealed
+	CreatedAt common.Timestamp `json:"created_at"`
+}
+
+// Encrypt encrypts w with a key derived from passphrase.
+func Encrypt(w *zcncrypto.Wallet, passphrase string) (*EncryptedWallet, error) {
+	if w == nil || w.ClientID == "" {
+		return nil, errors.New("keystore_encrypt", "invalid wallet")
+	}
+	if passphrase == "" {
+		return nil, ErrEmptyPassphrase
+	}
+
+	plaintext, err := json.Marshal(w)
+	if err != nil {
+		return nil, err
+	}
+
+	ew := &EncryptedWallet{
+		Version:   Version,
+		ClientID:  w.ClientID,
+		ClientKey: w.ClientKey,
+		CreatedAt: common.Now(),
+	}
+	sealed, err := Seal(plaintext, passphrase, ew.additionalData())
+	if err != nil {
+		return nil, err
+	}
+	ew.Sealed = *sealed
+	return ew, nil
+}
+
+// Decrypt decrypts the wallet with passphrase.
+func (ew *EncryptedWallet) Decrypt(passphrase string) (*zcncrypto.Wallet, error) {
+	plaintext, err := ew.Open(passphrase, ew.additionalData())
+	if err != nil {
+		return nil, err
+	}
+
+	w := &zcncrypto.Wallet{}
+	if err = json.Unmarshal(plaintext, w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// additionalData binds the ciphertext to the plaintext identity of the entry.
+func (ew *EncryptedWallet) additionalData() []byte {
+	return []byte(ew.ClientID + ":" + ew.ClientKey)
+}
+
+func newAEAD(passphrase string, params KDFParams) (cipher.AEAD, error) {
+	salt, err := hex.DecodeString(params.Salt)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid salt")
+	}
+	key, err := scrypt.Key([]byte(passphrase), salt, params.N, params.R, params.P, keyLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,179 @@
+package keystore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/0chain/gosdk/core/zcncrypto"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWallet(t *testing.T) *zcncrypto.Wallet {
+	w, err := zcncrypto.NewSignatureScheme("bls0chain").GenerateKeys()
+	require.NoError(t, err)
+	return w
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	w := newTestWallet(t)
+
+	ew, err := Encrypt(w, "passphrase")
+	require.NoError(t, err)
+	require.Equal(t, w.ClientID, ew.ClientID)
+	require.NotContains(t, ew.Ciphertext, w.Keys[0].PrivateKey)
+
+	got, err := ew.Decrypt("passphrase")
+	require.NoError(t, err)
+	require.Equal(t, w, got)
+
+	_, err = ew.Decrypt("wrong")
+	require.ErrorIs(t, err, ErrInvalidPassphrase)
+
+	ew.ClientID = "tampered"
+	_, err = ew.Decrypt("passphrase")
+	require.ErrorIs(t, err, ErrInvalidPassphrase)
+
+	_, err = Encrypt(w, "")
+	require.ErrorIs(t, err, ErrEmptyPassphrase)
+}
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wallets", "keystore.json")
+	s, err := Open(path)
+	require.NoError(t, err)
+
+	w1, w2 := newTestWallet(t), newTestWallet(t)
+	require.NoError(t, s.Save("main", w1, "one"))
+	require.NoError(t, s.Save("device", w2, "two"))
+	require.ErrorIs(t, s.Save("main", w2, "two"), ErrWalletExists)
+
+	// reopen to read from disk
+	s, err = Open(path)
+	require.NoError(t, err)
+
+	list, err := s.List()
+	require.NoError(t, err)
+	require.Equal(t, []WalletInfo{
+		{Name: "device", ClientID: w2.ClientID, ClientKey: w2.ClientKey},
+		{Name: "main", ClientID: w1.ClientID, ClientKey: w1.ClientKey},
+	}, list)
+
+	got, err := s.Load("main", "one")
+	require.NoError(t, err)
+	require.Equal(t, w1.Keys, got.Keys)
+
+	require.NoError(t, s.ChangePassphrase("main", "one", "three"))
+	_, err = s.Load("main", "one")
+	require.ErrorIs(t, err, ErrInvalidPassphrase)
+	got, err = s.Load("main", "three")
+	require.NoError(t, err)
+	require.Equal(t, w1.Mnemonic, got.Mnemonic)
+
+	require.ErrorIs(t, s.Rename("main", "device"), ErrWalletExists)
+	require.NoError(t, s.Rename("main", "retired"))
+	_, err = s.Load("main", "three")
+	require.ErrorIs(t, err, ErrWalletNotFound)
+
+	require.NoError(t, s.Delete("retired"))
+	require.ErrorIs(t, s.Delete("retired"), ErrWalletNotFound)
+}
+
+func TestKDFParamsBounds(t *testing.T) {
+	w := newTestWallet(t)
+	ew, err := Encrypt(w, "passphrase")
+	require.NoError(t, err)
+
+	for name, tamper := range map[string]func(p *KDFParams){
+		"huge n":      func(p *KDFParams) { p.N = 1 << 30 },
+		"n not power": func(p *KDFParams) { p.N = 30000 },
+		"huge r":      func(p *KDFParams) { p.R = 1 << 20 },
+		"huge p":      func(p *KDFParams) { p.P = 1 << 20 },
+		"zero r":      func(p *KDFParams) { p.R = 0 },
+		"short salt":  func(p *KDFParams) { p.Salt = "00" },
+	} {
+		t.Run(name, func(t *testing.T) {
+			tampered := *ew
+			tamper(&tampered.KDFParams)
+			_, err := tampered.Decrypt("passphrase")
+			require.ErrorIs(t, err, ErrInvalidKDFParams)
+		})
+	}
+}
+
+func TestStoreWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "keystore.json")
+	s, err := Open(path)
+	require.NoError(t, err)
+	require.NoError(t, s.Save("main", newTestWallet(t), "one"))
+
+	// a leftover of an interrupted write doesn't affect the store
+	require.NoError(t, os.WriteFile(path+".tmp", []byte("{trunc"), 0600))
+	require.NoError(t, s.Save("device", newTestWallet(t), "two"))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	info, err := entries[0].Info()
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	list, err := s.List()
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+}
+
+func TestStoreRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keystore.json")
+	s, err := Open(path)
+	require.NoError(t, err)
+
+	oldWallet, newWallet := newTestWallet(t), newTestWallet(t)
+	require.NoError(t, s.Save("main", oldWallet, "one"))
+
+	r := &Rotation{
+		OldClientID: oldWallet.ClientID,
+		NewClientID: newWallet.ClientID,
+		PendingName: "main.rotating",
+		RetiredName: "main.retired",
+		Txns:        map[string]string{},
+	}
+	require.NoError(t, s.BeginRotation("main", newWallet, "one", r))
+	require.ErrorIs(t, s.BeginRotation("main", newWallet, "one", r), ErrRotationExists)
+
+	// resume: the state and the pending wallet survive a restart
+	r.Txns["allocation"] = "hash"
+	require.NoError(t, s.UpdateRotation("main", r))
+	s, err = Open(path)
+	require.NoError(t, err)
+	got, err := s.Rotation("main")
+	require.NoError(t, err)
+	require.Equal(t, r, got)
+	pending, err := s.Load("main.rotating", "one")
+	require.NoError(t, err)
+	require.Equal(t, newWallet.ClientID, pending.ClientID)
+
+	// resume after the swap: the new wallet is under the name and the state is kept
+	require.NoError(t, s.SwapRotation("main"))
+	require.NoError(t, s.SwapRotation("main"))
+	s, err = Open(path)
+	require.NoError(t, err)
+	got, err = s.Rotation("main")
+	require.NoError(t, err)
+	require.True(t, got.Swapped)
+	current, err := s.Load("main", "one")
+	require.NoError(t, err)
+	require.Equal(t, newWallet.ClientID, current.ClientID)
+	retired, err := s.Load("main.retired", "one")
+	require.NoError(t, err)
+	require.Equal(t, oldWallet.ClientID, retired.ClientID)
+	_, err = s.Load("main.rotating", "one")
+	require.ErrorIs(t, err, ErrWalletNotFound)
+
+	require.NoError(t, s.FinishRotation("main"))
+	got, err = s.Rotation("main")
+	require.NoError(t, err)
+	require.Nil(t, got)
+	require.ErrorIs(t, s.SwapRotation("main"), ErrRotationNotFound)
+}
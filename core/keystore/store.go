@@ -0,0 +1,360 @@
+package keystore
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/0chain/errors"
+	"github.com/0chain/gosdk/core/sys"
+	"github.com/0chain/gosdk/core/zcncrypto"
+)
+
+// Store keeps multiple named encrypted wallets in a single file on sys.Files.
+type Store struct {
+	path string
+	mu   sync.Mutex
+}
+
+// WalletInfo describes a stored wallet without unlocking it.
+type WalletInfo struct {
+	Name      string `json:"name"`
+	ClientID  string `json:"client_id"`
+	ClientKey string `json:"client_key"`
+}
+
+// Rotation is the state of an unfinished key rotation of a stored wallet. It
+// is kept in the store so an interrupted rotation can be resumed.
+type Rotation struct {
+	OldClientID string `json:"old_client_id"`
+	NewClientID string `json:"new_client_id"`
+	// PendingName is the name the new wallet is kept under until the swap
+	PendingName string `json:"pending_name"`
+	// RetiredName is the name the old wallet is kept under after the swap
+	RetiredName string `json:"retired_name"`
+	// Swapped is set once the new wallet is stored under the rotated name
+	Swapped bool `json:"swapped"`
+	// Txns maps the completed steps of the rotation to their transactions
+	Txns map[string]string `json:"txns,omitempty"`
+}
+
+type storeFile struct {
+	Version   int                         `json:"version"`
+	Wallets   map[string]*EncryptedWallet `json:"wallets"`
+	Rotations map[string]*Rotation        `json:"rotations,omitempty"`
+}
+
+// Open returns the store kept in the file at path. The file is created on first save.
+func Open(path string) (*Store, error) {
+	if path == "" {
+		return nil, errors.New("keystore_open", "path is required")
+	}
+	s := &Store{path: path}
+	if _, err := s.read(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// List returns the stored wallets sorted by name.
+func (s *Store) List() ([]WalletInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]WalletInfo, 0, len(f.Wallets))
+	for name, ew := range f.Wallets {
+		list = append(list, WalletInfo{Name: name, ClientID: ew.ClientID, ClientKey: ew.ClientKey})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// Save encrypts w with passphrase and stores it under name.
+func (s *Store) Save(name string, w *zcncrypto.Wallet, passphrase string) error {
+	if name == "" {
+		return errors.New("keystore_save", "name is required")
+	}
+	ew, err := Encrypt(w, passphrase)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := f.Wallets[name]; ok {
+		return errors.Throw(ErrWalletExists, name)
+	}
+	f.Wallets[name] = ew
+	return s.write(f)
+}
+
+// Load decrypts the wallet stored under name.
+func (s *Store) Load(name, passphrase string) (*zcncrypto.Wallet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	ew, ok := f.Wallets[name]
+	if !ok {
+		return nil, errors.Throw(ErrWalletNotFound, name)
+	}
+	return ew.Decrypt(passphrase)
+}
+
+// ChangePassphrase re-encrypts the wallet stored under name with newPassphrase.
+func (s *Store) ChangePassphrase(name, oldPassphrase, newPassphrase string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := s.read()
+	if err != nil {
+		return err
+	}
+	ew, ok := f.Wallets[name]
+	if !ok {
+		return errors.Throw(ErrWalletNotFound, name)
+	}
+	w, err := ew.Decrypt(oldPassphrase)
+	if err != nil {
+		return err
+	}
+	if f.Wallets[name], err = Encrypt(w, newPassphrase); err != nil {
+		return err
+	}
+	return s.write(f)
+}
+
+// Rename moves the wallet stored under oldName to newName.
+func (s *Store) Rename(oldName, newName string) error {
+	if newName == "" {
+		return errors.New("keystore_rename", "name is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := s.read()
+	if err != nil {
+		return err
+	}
+	ew, ok := f.Wallets[oldName]
+	if !ok {
+		return errors.Throw(ErrWalletNotFound, oldName)
+	}
+	if _, ok := f.Wallets[newName]; ok {
+		return errors.Throw(ErrWalletExists, newName)
+	}
+	delete(f.Wallets, oldName)
+	f.Wallets[newName] = ew
+	return s.write(f)
+}
+
+// Delete removes the wallet stored under name.
+func (s *Store) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := f.Wallets[name]; !ok {
+		return errors.Throw(ErrWalletNotFound, name)
+	}
+	delete(f.Wallets, name)
+	return s.write(f)
+}
+
+// BeginRotation stores next under r.PendingName together with the rotation
+// state of the wallet stored under name.
+func (s *Store) BeginRotation(name string, next *zcncrypto.Wallet, passphrase string, r *Rotation) error {
+	if r == nil || r.PendingName == "" || r.RetiredName == "" {
+		return errors.New("keystore_rotation", "pending and retired names are required")
+	}
+	ew, err := Encrypt(next, passphrase)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := f.Wallets[name]; !ok {
+		return errors.Throw(ErrWalletNotFound, name)
+	}
+	if _, ok := f.Rotations[name]; ok {
+		return errors.Throw(ErrRotationExists, name)
+	}
+	for _, n := range []string{r.PendingName, r.RetiredName} {
+		if _, ok := f.Wallets[n]; ok {
+			return errors.Throw(ErrWalletExists, n)
+		}
+	}
+	f.Wallets[r.PendingName] = ew
+	f.Rotations[name] = r
+	return s.write(f)
+}
+
+// Rotation returns the state of the unfinished rotation of the wallet stored
+// under name, or nil if there is none.
+func (s *Store) Rotation(name string) (*Rotation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	return f.Rotations[name], nil
+}
+
+// UpdateRotation saves the progress of the rotation of the wallet stored under name.
+func (s *Store) UpdateRotation(name string, r *Rotation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := f.Rotations[name]; !ok {
+		return errors.Throw(ErrRotationNotFound, name)
+	}
+	f.Rotations[name] = r
+	return s.write(f)
+}
+
+// SwapRotation moves the wallet stored under name to the retired name of its
+// rotation and the new wallet from the pending name to name, in a single write.
+func (s *Store) SwapRotation(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := s.read()
+	if err != nil {
+		return err
+	}
+	r, ok := f.Rotations[name]
+	if !ok {
+		return errors.Throw(ErrRotationNotFound, name)
+	}
+	if r.Swapped {
+		return nil
+	}
+	old, ok := f.Wallets[name]
+	if !ok {
+		return errors.Throw(ErrWalletNotFound, name)
+	}
+	next, ok := f.Wallets[r.PendingName]
+	if !ok {
+		return errors.Throw(ErrWalletNotFound, r.PendingName)
+	}
+	if _, ok := f.Wallets[r.RetiredName]; ok {
+		return errors.Throw(ErrWalletExists, r.RetiredName)
+	}
+	delete(f.Wallets, r.PendingName)
+	f.Wallets[r.RetiredName] = old
+	f.Wallets[name] = next
+	r.Swapped = true
+	return s.write(f)
+}
+
+// FinishRotation removes the rotation state of the wallet stored under name.
+func (s *Store) FinishRotation(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := f.Rotations[name]; !ok {
+		return errors.Throw(ErrRotationNotFound, name)
+	}
+	delete(f.Rotations, name)
+	return s.write(f)
+}
+
+func (s *Store) read() (*storeFile, error) {
+	f := &storeFile{Version: Version, Wallets: make(map[string]*EncryptedWallet), Rotations: make(map[string]*Rotation)}
+
+	buf, err := sys.Files.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(buf, f); err != nil {
+		return nil, errors.Wrap(err, "invalid keystore file")
+	}
+	if f.Version > Version {
+		return nil, errors.New("keystore_read", "unsupported keystore version")
+	}
+	if f.Wallets == nil {
+		f.Wallets = make(map[string]*EncryptedWallet)
+	}
+	if f.Rotations == nil {
+		f.Rotations = make(map[string]*Rotation)
+	}
+	return f, nil
+}
+
+func (s *Store) write(f *storeFile) error {
+	buf, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(s.path); dir != "." {
+		if err = sys.Files.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+	}
+
+	// write a temporary file and rename it over the store, so an interrupted
+	// write never leaves a truncated store behind
+	tmp := s.path + ".tmp"
+	if err = writeSync(tmp, buf); err != nil {
+		_ = sys.Files.Remove(tmp)
+		return err
+	}
+	if err = sys.Files.Rename(tmp, s.path); err != nil {
+		_ = sys.Files.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+func writeSync(name string, buf []byte) error {
+	file, err := sys.Files.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(buf); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,28 @@
+package keystore
+
+import (
+	"errors"
+)
+
+var (
+	// ErrEmptyPassphrase passphrase is required to encrypt a wallet
+	ErrEmptyPassphrase = errors.New("[keystore] passphrase is empty")
+
+	// ErrInvalidPassphrase wallet can't be decrypted with the passphrase
+	ErrInvalidPassphrase = errors.New("[keystore] invalid passphrase")
+
+	// ErrWalletNotFound no wallet is stored under the name
+	ErrWalletNotFound = errors.New("[keystore] wallet not found")
+
+	// ErrWalletExists a wallet is already stored under the name
+	ErrWalletExists = errors.New("[keystore] wallet already exists")
+
+	// ErrInvalidKDFParams kdf parameters of an entry are out of the supported range
+	ErrInvalidKDFParams = errors.New("[keystore] invalid kdf parameters")
+
+	// ErrRotationNotFound the wallet has no unfinished rotation
+	ErrRotationNotFound = errors.New("[keystore] rotation not found")
+
+	// ErrRotationExists the wallet already has an unfinished rotation
+	ErrRotationExists = errors.New("[keystore] rotation already exists")
+)
@@ -0,0 +1,271 @@
+// Package lightclient verifies chain data against a trusted genesis magic block
+// instead of trusting a majority of sharders.
+package lightclient
+
+import (
+	"context"
+	"encoding/hex"
+	"sort"
+	"sync"
+
+	"github.com/0chain/errors"
+	"github.com/0chain/gosdk/core/block"
+	"github.com/0chain/gosdk/core/transaction"
+	"github.com/0chain/gosdk/core/zcncrypto"
+)
+
+const (
+	// minerSignatureScheme is the signature scheme miners use for verification tickets.
+	minerSignatureScheme = "bls0chain"
+
+	// minerSmartContractAddress is the address of the miner smart contract that stores the magic blocks.
+	minerSmartContractAddress = "6dba10422e368813802877a85039d3985d96760ed844092319743fb3a76712d9"
+)
+
+// Source provides untrusted chain data, usually fetched from sharders.
+type Source interface {
+	// GetMagicBlockByNumber returns ErrMagicBlockNotFound if the chain has no such magic block yet.
+	GetMagicBlockByNumber(ctx context.Context, number int64) (*block.MagicBlock, error)
+	// GetBlockByRound returns the finalized block of the round including its verification tickets.
+	GetBlockByRound(ctx context.Context, round int64) (*block.Block, error)
+}
+
+// LightClient follows the magic block chain from a trusted genesis and proves
+// blocks by checking miner verification tickets against the magic block that
+// was in effect for their round.
+type LightClient struct {
+	source Source
+
+	mu          sync.RWMutex
+	magicBlocks []*block.MagicBlock // ordered by number, the first one is trusted
+}
+
+// New creates a light client that trusts genesis and fetches everything else from source.
+func New(genesis *block.MagicBlock, source Source) (*LightClient, error) {
+	if genesis == nil || genesis.Hash == "" {
+		return nil, errors.Throw(ErrInvalidMagicBlock, "genesis is required")
+	}
+	if err := verifyMagicBlock(genesis); err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return nil, errors.New("light_client", "source is required")
+	}
+	return &LightClient{
+		source:      source,
+		magicBlocks: []*block.MagicBlock{genesis},
+	}, nil
+}
+
+// LatestMagicBlock returns the newest verified magic block.
+func (lc *LightClient) LatestMagicBlock() *block.MagicBlock {
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
+	return lc.magicBlocks[len(lc.magicBlocks)-1]
+}
+
+// Sync follows magic block transitions from the latest verified magic block
+// until the source has no newer one. A transition is accepted only if it links
+// to the previous magic block and is in the state of a block notarized under it.
+func (lc *LightClient) Sync(ctx context.Context) error {
+	for {
+		prev := lc.LatestMagicBlock()
+		next, err := lc.source.GetMagicBlockByNumber(ctx, prev.MagicBlockNumber+1)
+		if errors.Is(err, ErrMagicBlockNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err = lc.verifyTransition(ctx, prev, next); err != nil {
+			return err
+		}
+
+		lc.mu.Lock()
+		lc.magicBlocks = append(lc.magicBlocks, next)
+		lc.mu.Unlock()
+	}
+}
+
+// MagicBlockForRound returns the verified magic block in effect for round,
+// syncing newer magic blocks if round is past the latest known one.
+func (lc *LightClient) MagicBlockForRound(ctx context.Context, round int64) (*block.MagicBlock, error) {
+	if mb := lc.lookupMagicBlock(round); mb != nil && mb != lc.LatestMagicBlock() {
+		return mb, nil
+	}
+
+	if err := lc.Sync(ctx); err != nil {
+		return nil, err
+	}
+
+	if mb := lc.lookupMagicBlock(round); mb != nil {
+		return mb, nil
+	}
+	return nil, errors.Throw(ErrMagicBlockNotFound, "no magic block for round")
+}
+
+// VerifyBlock checks that b is notarized by the magic block in effect for its round.
+func (lc *LightClient) VerifyBlock(ctx context.Context, b *block.Block) error {
+	if b == nil {
+		return errors.Throw(ErrBlockMismatch, "empty block")
+	}
+	mb, err := lc.MagicBlockForRound(ctx, b.Round)
+	if err != nil {
+		return err
+	}
+	return verifyNotarization(mb, b)
+}
+
+// VerifyBlockHeader fetches the finalized block of the header's round and proves
+// it. It implements transaction.BlockVerifier.
+func (lc *LightClient) VerifyBlockHeader(ctx context.Context, header *transaction.RoundBlockHeader) error {
+	b, err := lc.source.GetBlockByRound(ctx, header.Round)
+	if err != nil {
+		return err
+	}
+	if b == nil || string(b.Hash) != header.Hash {
+		return errors.Throw(ErrBlockMismatch, "confirmed block "+header.Hash)
+	}
+	return lc.VerifyBlock(ctx, b)
+}
+
+// VerifyGroupSignature verifies a threshold signature on hash, aggregated from
+// the DKG shares of the miners in effect for round, against their group public key.
+func (lc *LightClient) VerifyGroupSignature(ctx context.Context, round int64, hash, signature string) error {
+	mb, err := lc.MagicBlockForRound(ctx, round)
+	if err != nil {
+		return err
+	}
+	gpk, err := mb.GroupPublicKey()
+	if err != nil {
+		return err
+	}
+
+	rawHash, err := hex.DecodeString(hash)
+	if err != nil {
+		return err
+	}
+	sig := zcncrypto.BlsSignerInstance.NewSignature()
+	if err = sig.DeserializeHexStr(signature); err != nil {
+		return errors.Wrap(err, "invalid group signature")
+	}
+	if !sig.Verify(gpk, string(rawHash)) {
+		return errors.Throw(ErrInvalidSignature, "group signature")
+	}
+	return nil
+}
+
+// VerifyTransaction verifies txnHash like transaction.VerifyTransaction, but
+// additionally proves the confirmation block instead of trusting sharders.
+func (lc *LightClient) VerifyTransaction(txnHash string, sharders []string) (*transaction.Transaction, error) {
+	ov := transaction.NewOptimisticVerifier(sharders)
+	ov.SetBlockVerifier(lc)
+	return ov.VerifyTransactionOptimistic(txnHash)
+}
+
+func (lc *LightClient) lookupMagicBlock(round int64) *block.MagicBlock {
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
+
+	i := sort.Search(len(lc.magicBlocks), func(i int) bool {
+		return lc.magicBlocks[i].StartingRound > round
+	})
+	if i == 0 {
+		return nil
+	}
+	return lc.magicBlocks[i-1]
+}
+
+func (lc *LightClient) verifyTransition(ctx context.Context, prev, next *block.MagicBlock) error {
+	if next == nil || next.Hash == "" {
+		return errors.Throw(ErrInvalidMagicBlock, "empty magic block")
+	}
+	if err := verifyMagicBlock(next); err != nil {
+		return err
+	}
+	if next.MagicBlockNumber != prev.MagicBlockNumber+1 {
+		return errors.Throw(ErrInvalidMagicBlock, "unexpected magic block number")
+	}
+	if next.PreviousMagicBlockHash != prev.Hash {
+		return errors.Throw(ErrInvalidMagicBlock, "previous hash does not match")
+	}
+	if next.StartingRound <= prev.StartingRound {
+		return errors.Throw(ErrInvalidMagicBlock, "starting round does not advance")
+	}
+
+	// the block before the starting round is still notarized by the previous
+	// miners, and its state hash commits to the new magic block
+	ms, ok := lc.source.(MagicBlockStateSource)
+	if !ok {
+		return errors.New("light_client", "source does not provide state proofs")
+	}
+	b, err := lc.source.GetBlockByRound(ctx, next.StartingRound-1)
+	if err != nil {
+		return err
+	}
+	if b == nil || b.Round != next.StartingRound-1 {
+		return errors.Throw(ErrBlockMismatch, "block before the starting round")
+	}
+	if err = verifyNotarization(prev, b); err != nil {
+		return err
+	}
+	proof, err := ms.GetMagicBlockStateProof(ctx, b.Round)
+	if err != nil {
+		return err
+	}
+	return verifyMagicBlockState(b, next, proof)
+}
+
+// verifyMagicBlock checks that the hash of mb covers its contents and that its
+// nodes are identified by their public keys.
+func verifyMagicBlock(mb *block.MagicBlock) error {
+	if mb.ComputeHash() != mb.Hash {
+		return errors.Throw(ErrInvalidMagicBlock, "hash does not match the magic block")
+	}
+	if err := mb.VerifyNodeIDs(); err != nil {
+		return errors.Throw(ErrInvalidMagicBlock, err.Error())
+	}
+	return nil
+}
+
+// verifyNotarization checks that b carries valid verification tickets from at
+// least T distinct miners of mb.
+func verifyNotarization(mb *block.MagicBlock, b *block.Block) error {
+	if b.Header != nil && b.Header.Hash != string(b.Hash) {
+		return errors.Throw(ErrBlockMismatch, "invalid block header")
+	}
+	if b.ComputeHash() != string(b.Hash) {
+		return errors.Throw(ErrBlockMismatch, "hash does not match the block header")
+	}
+
+	threshold := mb.T
+	if threshold <= 0 && mb.Miners != nil {
+		threshold = len(mb.Miners.Nodes)*2/3 + 1
+	}
+
+	verified := make(map[string]bool)
+	for _, vt := range b.VerificationTickets {
+		if vt == nil || verified[vt.VerifierID] {
+			continue
+		}
+		miner, ok := mb.GetMiner(vt.VerifierID)
+		if !ok {
+			continue
+		}
+
+		ss := zcncrypto.NewSignatureScheme(minerSignatureScheme)
+		if err := ss.SetPublicKey(miner.PublicKey); err != nil {
+			continue
+		}
+		if ok, err := ss.Verify(vt.Signature, string(b.Hash)); err != nil || !ok {
+			continue
+		}
+		verified[vt.VerifierID] = true
+	}
+
+	if len(verified) < threshold {
+		return errors.Throw(ErrNotNotarized, "not enough valid verification tickets")
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+package lightclient
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/0chain/gosdk/core/block"
+	"github.com/0chain/gosdk/core/common"
+	"github.com/0chain/gosdk/core/encryption"
+	"github.com/0chain/gosdk/core/zcncrypto"
+	"github.com/stretchr/testify/require"
+)
+
+type memorySource struct {
+	magicBlocks      map[int64]*block.MagicBlock
+	blocks           map[int64]*block.Block
+	magicBlockProofs map[int64]*StateProof
+}
+
+func (s *memorySource) GetMagicBlockByNumber(ctx context.Context, number int64) (*block.MagicBlock, error) {
+	mb, ok := s.magicBlocks[number]
+	if !ok {
+		return nil, ErrMagicBlockNotFound
+	}
+	return mb, nil
+}
+
+func (s *memorySource) GetBlockByRound(ctx context.Context, round int64) (*block.Block, error) {
+	b, ok := s.blocks[round]
+	if !ok {
+		return nil, fmt.Errorf("no block for round %d", round)
+	}
+	return b, nil
+}
+
+func (s *memorySource) GetMagicBlockStateProof(ctx context.Context, round int64) (*StateProof, error) {
+	proof, ok := s.magicBlockProofs[round]
+	if !ok {
+		return nil, fmt.Errorf("no magic block proof for round %d", round)
+	}
+	return proof, nil
+}
+
+type testMiner struct {
+	id     string
+	scheme *zcncrypto.HerumiScheme
+}
+
+func newMiners(t *testing.T, n int) []*testMiner {
+	miners := make([]*testMiner, n)
+	for i := range miners {
+		ss := zcncrypto.NewHerumiScheme()
+		w, err := ss.GenerateKeys()
+		require.NoError(t, err)
+		miners[i] = &testMiner{id: w.ClientID, scheme: ss}
+	}
+	return miners
+}
+
+func newMagicBlock(number, startingRound int64, prevHash string, miners []*testMiner) *block.MagicBlock {
+	mb := &block.MagicBlock{
+		PreviousMagicBlockHash: prevHash,
+		MagicBlockNumber:       number,
+		StartingRound:          startingRound,
+		Miners:                 &block.NodePool{Nodes: make(map[string]block.Node)},
+		Mpks:                   &block.Mpks{Mpks: make(map[string]*block.MPK)},
+		T:                      2,
+		N:                      len(miners),
+	}
+	for _, m := range miners {
+		mb.Miners.Nodes[m.id] = block.Node{ID: m.id, PublicKey: m.scheme.GetPublicKey()}
+		mb.Mpks.Mpks[m.id] = &block.MPK{ID: m.id, Mpk: []string{m.scheme.GetPublicKey()}}
+	}
+	mb.Hash = mb.ComputeHash()
+	return mb
+}
+
+func newBlock(t *testing.T, round int64, signers []*testMiner, fields ...func(b *block.Block)) *block.Block {
+	b := &block.Block{
+		Round:    round,
+		PrevHash: encryption.Hash(fmt.Sprintf("block:%d", round-1)),
+	}
+	for _, f := range fields {
+		f(b)
+	}
+	b.Hash = common.Key(b.ComputeHash())
+	signBlock(t, b, signers)
+	return b
+}
+
+// magicBlockState returns a state hash whose trie only stores mb under the
+// magic block key, and the proof of mb against it.
+func magicBlockState(t *testing.T, mb *block.MagicBlock) ([]byte, *StateProof) {
+	value, err := json.Marshal(mb)
+	require.NoError(t, err)
+	leaf := encodeStateNode(stateNodeLeaf, magicBlockKey, string(value))
+	stateHash, err := hex.DecodeString(encryption.Hash(leaf))
+	require.NoError(t, err)
+	return stateHash, &StateProof{Round: mb.StartingRound - 1, Nodes: []string{hex.EncodeToString(leaf)}}
+}
+
+func withState(stateHash []byte) func(b *block.Block) {
+	return func(b *block.Block) {
+		b.ClientStateHash = stateHash
+	}
+}
+
+func signBlock(t *testing.T, b *block.Block, signers []*testMiner) {
+	b.VerificationTickets = nil
+	for _, m := range signers {
+		sig, err := m.scheme.Sign(string(b.Hash))
+		require.NoError(t, err)
+		b.VerificationTickets = append(b.VerificationTickets, &block.VerificationTicket{
+			VerifierID: m.id,
+			Signature:  sig,
+		})
+	}
+}
+
+func TestLightClient(t *testing.T) {
+	oldMiners := newMiners(t, 3)
+	newMinersSet := newMiners(t, 3)
+
+	genesis := newMagicBlock(1, 0, "", oldMiners)
+	next := newMagicBlock(2, 100, genesis.Hash, newMinersSet)
+
+	stateHash, proof := magicBlockState(t, next)
+	announce := newBlock(t, 99, oldMiners[:2], withState(stateHash))
+
+	source := &memorySource{
+		magicBlocks: map[int64]*block.MagicBlock{2: next},
+		blocks: map[int64]*block.Block{
+			50:  newBlock(t, 50, oldMiners[:2]),
+			99:  announce,
+			150: newBlock(t, 150, newMinersSet),
+		},
+		magicBlockProofs: map[int64]*StateProof{99: proof},
+	}
+
+	ctx := context.Background()
+
+	t.Run("follows magic block transitions", func(t *testing.T) {
+		lc, err := New(genesis, source)
+		require.NoError(t, err)
+		require.NoError(t, lc.Sync(ctx))
+		require.Equal(t, next.Hash, lc.LatestMagicBlock().Hash)
+
+		mb, err := lc.MagicBlockForRound(ctx, 99)
+		require.NoError(t, err)
+		require.Equal(t, genesis.Hash, mb.Hash)
+	})
+
+	t.Run("verifies notarized blocks", func(t *testing.T) {
+		lc, err := New(genesis, source)
+		require.NoError(t, err)
+		require.NoError(t, lc.VerifyBlock(ctx, source.blocks[50]))
+		require.NoError(t, lc.VerifyBlock(ctx, source.blocks[150]))
+	})
+
+	t.Run("rejects blocks signed by the wrong miners", func(t *testing.T) {
+		lc, err := New(genesis, source)
+		require.NoError(t, err)
+		err = lc.VerifyBlock(ctx, newBlock(t, 60, newMinersSet))
+		require.ErrorIs(t, err, ErrNotNotarized)
+	})
+
+	t.Run("rejects blocks below threshold", func(t *testing.T) {
+		lc, err := New(genesis, source)
+		require.NoError(t, err)
+		err = lc.VerifyBlock(ctx, newBlock(t, 60, oldMiners[:1]))
+		require.ErrorIs(t, err, ErrNotNotarized)
+	})
+
+	t.Run("rejects unlinked magic blocks", func(t *testing.T) {
+		forged := newMagicBlock(2, 100, "forged", newMinersSet)
+		lc, err := New(genesis, &memorySource{
+			magicBlocks:      map[int64]*block.MagicBlock{2: forged},
+			blocks:           source.blocks,
+			magicBlockProofs: source.magicBlockProofs,
+		})
+		require.NoError(t, err)
+		require.ErrorIs(t, lc.Sync(ctx), ErrInvalidMagicBlock)
+	})
+
+	t.Run("rejects magic blocks not finalized by previous miners", func(t *testing.T) {
+		forgedAnnounce := newBlock(t, 99, newMinersSet, withState(stateHash))
+		lc, err := New(genesis, &memorySource{
+			magicBlocks:      source.magicBlocks,
+			blocks:           map[int64]*block.Block{99: forgedAnnounce},
+			magicBlockProofs: source.magicBlockProofs,
+		})
+		require.NoError(t, err)
+		require.ErrorIs(t, lc.Sync(ctx), ErrNotNotarized)
+	})
+
+	t.Run("rejects tampered magic blocks", func(t *testing.T) {
+		forgedMiners := newMiners(t, 3)
+		tampered := *next
+		tampered.Miners = &block.NodePool{Nodes: make(map[string]block.Node)}
+		for id, n := range next.Miners.Nodes {
+			tampered.Miners.Nodes[id] = n
+		}
+		// swap the key of a miner and keep the announced hash
+		id := newMinersSet[0].id
+		tampered.Miners.Nodes[id] = block.Node{ID: id, PublicKey: forgedMiners[0].scheme.GetPublicKey()}
+
+		lc, err := New(genesis, &memorySource{
+			magicBlocks:      map[int64]*block.MagicBlock{2: &tampered},
+			blocks:           source.blocks,
+			magicBlockProofs: source.magicBlockProofs,
+		})
+		require.NoError(t, err)
+		require.ErrorIs(t, lc.Sync(ctx), ErrInvalidMagicBlock)
+
+		// rehashing the forged magic block breaks the ids of its miners
+		tampered.Hash = tampered.ComputeHash()
+		lc, err = New(genesis, &memorySource{
+			magicBlocks:      map[int64]*block.MagicBlock{2: &tampered},
+			blocks:           source.blocks,
+			magicBlockProofs: source.magicBlockProofs,
+		})
+		require.NoError(t, err)
+		require.ErrorIs(t, lc.Sync(ctx), ErrInvalidMagicBlock)
+
+		// a consistent forged magic block is not in the state of the signed block
+		forged := newMagicBlock(2, 100, genesis.Hash, forgedMiners)
+		lc, err = New(genesis, &memorySource{
+			magicBlocks:      map[int64]*block.MagicBlock{2: forged},
+			blocks:           source.blocks,
+			magicBlockProofs: source.magicBlockProofs,
+		})
+		require.NoError(t, err)
+		require.ErrorIs(t, lc.Sync(ctx), ErrInvalidMagicBlock)
+	})
+
+	t.Run("rejects tampered blocks", func(t *testing.T) {
+		forged := newMagicBlock(2, 100, genesis.Hash, newMiners(t, 3))
+		forgedState, forgedProof := magicBlockState(t, forged)
+		tampered := *announce
+		tampered.ClientStateHash = forgedState
+		lc, err := New(genesis, &memorySource{
+			magicBlocks:      map[int64]*block.MagicBlock{2: forged},
+			blocks:           map[int64]*block.Block{99: &tampered},
+			magicBlockProofs: map[int64]*StateProof{99: forgedProof},
+		})
+		require.NoError(t, err)
+		require.ErrorIs(t, lc.Sync(ctx), ErrBlockMismatch)
+
+		b := *source.blocks[50]
+		b.ClientStateHash = []byte("forged state")
+		require.ErrorIs(t, lc.VerifyBlock(ctx, &b), ErrBlockMismatch)
+	})
+
+	t.Run("rejects forged magic block proofs", func(t *testing.T) {
+		forged := newMagicBlock(2, 100, genesis.Hash, newMiners(t, 3))
+		_, forgedProof := magicBlockState(t, forged)
+		lc, err := New(genesis, &memorySource{
+			magicBlocks:      map[int64]*block.MagicBlock{2: forged},
+			blocks:           source.blocks,
+			magicBlockProofs: map[int64]*StateProof{99: forgedProof},
+		})
+		require.NoError(t, err)
+		require.ErrorIs(t, lc.Sync(ctx), ErrInvalidProof)
+	})
+
+	t.Run("verifies group signatures", func(t *testing.T) {
+		lc, err := New(genesis, source)
+		require.NoError(t, err)
+
+		hash := encryption.Hash("round seed")
+		sig, err := oldMiners[0].scheme.Sign(hash)
+		require.NoError(t, err)
+		for _, m := range oldMiners[1:] {
+			sig, err = m.scheme.Add(sig, hash)
+			require.NoError(t, err)
+		}
+
+		require.NoError(t, lc.VerifyGroupSignature(ctx, 10, hash, sig))
+
+		partial, err := oldMiners[0].scheme.Sign(hash)
+		require.NoError(t, err)
+		require.ErrorIs(t, lc.VerifyGroupSignature(ctx, 10, hash, partial), ErrInvalidSignature)
+	})
+}
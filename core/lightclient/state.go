@@ -0,0 +1,171 @@
+package lightclient
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"github.com/0chain/errors"
+	"github.com/0chain/gosdk/core/block"
+	"github.com/0chain/gosdk/core/common"
+	"github.com/0chain/gosdk/core/encryption"
+)
+
+// node types of the client state trie
+const (
+	stateNodeLeaf      = 2
+	stateNodeFull      = 4
+	stateNodeExtension = 8
+)
+
+const stateNodeSeparator = ":"
+
+// magicBlockKey is the key of the latest magic block in the state of the miner smart contract.
+var magicBlockKey = minerSmartContractAddress + encryption.Hash("magic_block")
+
+// StateSource provides untrusted proofs of client states, usually fetched from sharders.
+type StateSource interface {
+	// GetClientStateProof returns the proof of the client state at the latest finalized round.
+	GetClientStateProof(ctx context.Context, clientID string) (*StateProof, error)
+}
+
+// MagicBlockStateSource provides untrusted proofs of the magic block stored in
+// the state of the miner smart contract, usually fetched from sharders.
+type MagicBlockStateSource interface {
+	// GetMagicBlockStateProof returns the proof of the magic block in the state of the block of round.
+	GetMagicBlockStateProof(ctx context.Context, round int64) (*StateProof, error)
+}
+
+// StateProof proves a client state against the state hash of the block of
+// Round. Nodes are the hex encoded trie nodes from the root to the leaf of
+// the client, keyed by the nibbles of the client id:
+//
+//	leaf:      0x02 path ":" value
+//	full:      0x04 child0 ":" ... ":" child15 ":" value
+//	extension: 0x08 path ":" child
+//
+// where children are the hex hashes of the child nodes.
+type StateProof struct {
+	Round int64    `json:"round"`
+	Nodes []string `json:"nodes"`
+}
+
+// ClientState is the proven state of a client.
+type ClientState struct {
+	ClientID string         `json:"client_id"`
+	Balance  common.Balance `json:"balance"`
+	Nonce    int64          `json:"nonce"`
+	Round    int64          `json:"round"`
+}
+
+// GetBalance fetches the proof of the client state from the source and verifies it.
+func (lc *LightClient) GetBalance(ctx context.Context, clientID string) (*ClientState, error) {
+	ss, ok := lc.source.(StateSource)
+	if !ok {
+		return nil, errors.New("light_client", "source does not provide state proofs")
+	}
+	proof, err := ss.GetClientStateProof(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	return lc.VerifyClientState(ctx, clientID, proof)
+}
+
+// VerifyClientState proves the state of the client against the state hash of
+// the notarized block of the proof's round.
+func (lc *LightClient) VerifyClientState(ctx context.Context, clientID string, proof *StateProof) (*ClientState, error) {
+	if proof == nil {
+		return nil, errors.Throw(ErrInvalidProof, "empty proof")
+	}
+	b, err := lc.source.GetBlockByRound(ctx, proof.Round)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil || b.Round != proof.Round {
+		return nil, errors.Throw(ErrBlockMismatch, "block of the proof round")
+	}
+	if err = lc.VerifyBlock(ctx, b); err != nil {
+		return nil, err
+	}
+
+	value, err := verifyStateProof(hex.EncodeToString(b.ClientStateHash), clientID, proof.Nodes)
+	if err != nil {
+		return nil, err
+	}
+	state := &ClientState{}
+	if err = json.Unmarshal(value, state); err != nil {
+		return nil, errors.Throw(ErrInvalidProof, "invalid client state")
+	}
+	if state.ClientID != clientID {
+		return nil, errors.Throw(ErrInvalidProof, "state of another client")
+	}
+	state.Round = proof.Round
+	return state, nil
+}
+
+// verifyMagicBlockState checks that the proof is taken against the state hash
+// of b and that the magic block it stores is mb.
+func verifyMagicBlockState(b *block.Block, mb *block.MagicBlock, proof *StateProof) error {
+	if proof == nil || proof.Round != b.Round {
+		return errors.Throw(ErrInvalidProof, "proof of another round")
+	}
+	value, err := verifyStateProof(hex.EncodeToString(b.ClientStateHash), magicBlockKey, proof.Nodes)
+	if err != nil {
+		return err
+	}
+	stored := &block.MagicBlock{}
+	if err = json.Unmarshal(value, stored); err != nil {
+		return errors.Throw(ErrInvalidProof, "invalid magic block")
+	}
+	if stored.ComputeHash() != mb.Hash {
+		return errors.Throw(ErrInvalidMagicBlock, "magic block is not finalized by the chain")
+	}
+	return nil
+}
+
+// verifyStateProof walks the nodes from the root and returns the value at key.
+func verifyStateProof(root, key string, nodes []string) ([]byte, error) {
+	expected, path := root, key
+	for _, n := range nodes {
+		raw, err := hex.DecodeString(n)
+		if err != nil || len(raw) == 0 {
+			return nil, errors.Throw(ErrInvalidProof, "invalid node")
+		}
+		if expected == "" || encryption.Hash(raw) != expected {
+			return nil, errors.Throw(ErrInvalidProof, "node hash does not match")
+		}
+
+		body := string(raw[1:])
+		switch raw[0] {
+		case stateNodeLeaf:
+			parts := strings.SplitN(body, stateNodeSeparator, 2)
+			if len(parts) != 2 || parts[0] != path {
+				return nil, errors.Throw(ErrInvalidProof, "key is not in the state")
+			}
+			return []byte(parts[1]), nil
+		case stateNodeFull:
+			parts := strings.SplitN(body, stateNodeSeparator, 17)
+			if len(parts) != 17 {
+				return nil, errors.Throw(ErrInvalidProof, "invalid full node")
+			}
+			if path == "" {
+				return []byte(parts[16]), nil
+			}
+			i := strings.IndexByte("0123456789abcdef", path[0])
+			if i < 0 {
+				return nil, errors.Throw(ErrInvalidProof, "invalid key")
+			}
+			expected, path = parts[i], path[1:]
+		case stateNodeExtension:
+			parts := strings.SplitN(body, stateNodeSeparator, 2)
+			if len(parts) != 2 || !strings.HasPrefix(path, parts[0]) {
+				return nil, errors.Throw(ErrInvalidProof, "key is not in the state")
+			}
+			expected, path = parts[1], path[len(parts[0]):]
+		default:
+			return nil, errors.Throw(ErrInvalidProof, "unknown node type")
+		}
+	}
+	return nil, errors.Throw(ErrInvalidProof, "proof ends before the key")
+}
@@ -0,0 +1,86 @@
+package lightclient
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/0chain/gosdk/core/block"
+	"github.com/0chain/gosdk/core/encryption"
+	"github.com/stretchr/testify/require"
+)
+
+func encodeStateNode(nodeType byte, fields ...string) []byte {
+	return append([]byte{nodeType}, strings.Join(fields, stateNodeSeparator)...)
+}
+
+type stateSource struct {
+	*memorySource
+	proofs map[string]*StateProof
+}
+
+func (s *stateSource) GetClientStateProof(ctx context.Context, clientID string) (*StateProof, error) {
+	return s.proofs[clientID], nil
+}
+
+func TestVerifyClientState(t *testing.T) {
+	miners := newMiners(t, 3)
+	genesis := newMagicBlock(1, 0, "", miners)
+
+	alice := encryption.Hash("alice")
+	bob := encryption.Hash("bob")
+	require.NotEqual(t, alice[0], bob[0])
+
+	leaf := func(clientID string, balance int64) []byte {
+		value, err := json.Marshal(map[string]interface{}{"client_id": clientID, "balance": balance, "nonce": 3})
+		require.NoError(t, err)
+		return encodeStateNode(stateNodeLeaf, clientID[1:], string(value))
+	}
+	aliceLeaf, bobLeaf := leaf(alice, 100), leaf(bob, 5)
+
+	children := make([]string, 17)
+	children[strings.IndexByte("0123456789abcdef", alice[0])] = encryption.Hash(aliceLeaf)
+	children[strings.IndexByte("0123456789abcdef", bob[0])] = encryption.Hash(bobLeaf)
+	root := encodeStateNode(stateNodeFull, children...)
+	stateHash, err := hex.DecodeString(encryption.Hash(root))
+	require.NoError(t, err)
+
+	b := newBlock(t, 10, miners, func(b *block.Block) { b.ClientStateHash = stateHash })
+	source := &stateSource{
+		memorySource: &memorySource{blocks: map[int64]*block.Block{10: b}},
+		proofs: map[string]*StateProof{
+			alice: {Round: 10, Nodes: []string{hex.EncodeToString(root), hex.EncodeToString(aliceLeaf)}},
+		},
+	}
+	lc, err := New(genesis, source)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	state, err := lc.GetBalance(ctx, alice)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), int64(state.Balance))
+	require.Equal(t, int64(3), state.Nonce)
+	require.Equal(t, int64(10), state.Round)
+
+	t.Run("tampered balance", func(t *testing.T) {
+		proof := &StateProof{Round: 10, Nodes: []string{hex.EncodeToString(root), hex.EncodeToString(leaf(alice, 1000))}}
+		_, err := lc.VerifyClientState(ctx, alice, proof)
+		require.ErrorIs(t, err, ErrInvalidProof)
+	})
+
+	t.Run("state of another client", func(t *testing.T) {
+		proof := &StateProof{Round: 10, Nodes: []string{hex.EncodeToString(root), hex.EncodeToString(bobLeaf)}}
+		_, err := lc.VerifyClientState(ctx, alice, proof)
+		require.ErrorIs(t, err, ErrInvalidProof)
+	})
+
+	t.Run("unnotarized state", func(t *testing.T) {
+		forged := newBlock(t, 10, newMiners(t, 3), func(b *block.Block) { b.ClientStateHash = stateHash })
+		lc, err := New(genesis, &memorySource{blocks: map[int64]*block.Block{10: forged}})
+		require.NoError(t, err)
+		_, err = lc.VerifyClientState(ctx, alice, source.proofs[alice])
+		require.ErrorIs(t, err, ErrNotNotarized)
+	})
+}
@@ -0,0 +1,25 @@
+package lightclient
+
+import (
+	"errors"
+)
+
+var (
+	// ErrMagicBlockNotFound the chain has no magic block with the requested number
+	ErrMagicBlockNotFound = errors.New("[lightclient] magic block not found")
+
+	// ErrInvalidMagicBlock magic block does not extend the trusted magic block chain
+	ErrInvalidMagicBlock = errors.New("[lightclient] invalid magic block")
+
+	// ErrNotNotarized block does not carry enough valid verification tickets
+	ErrNotNotarized = errors.New("[lightclient] block is not notarized")
+
+	// ErrBlockMismatch block returned by sharders does not match the expected one
+	ErrBlockMismatch = errors.New("[lightclient] block mismatch")
+
+	// ErrInvalidSignature signature does not verify against the expected public key
+	ErrInvalidSignature = errors.New("[lightclient] invalid signature")
+
+	// ErrInvalidProof state proof does not verify against the block state hash
+	ErrInvalidProof = errors.New("[lightclient] invalid state proof")
+)
@@ -0,0 +1,235 @@
+// Package multisig coordinates threshold signing for multisig wallets. The
+// coordinator collects signature shares from T of N signers over a pluggable
+// transport and interpolates the group signature, so the group private key is
+// never reconstructed.
+package multisig
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/0chain/errors"
+	"github.com/0chain/gosdk/core/common"
+	"github.com/0chain/gosdk/core/encryption"
+	"github.com/0chain/gosdk/core/transaction"
+	"github.com/0chain/gosdk/core/zcncrypto"
+)
+
+const (
+	// SignatureScheme is the only scheme supporting threshold signatures.
+	SignatureScheme = "bls0chain"
+
+	// DefaultSignTimeout bounds a Sign call without deadline.
+	DefaultSignTimeout = 2 * time.Minute
+)
+
+// SignRequest asks a signer to sign Hash on behalf of the group. Payload
+// carries the transaction being signed, so signers can review it; signers
+// recompute Hash from it and reject a mismatch. Blind requests without
+// payload are rejected.
+type SignRequest struct {
+	ID            string          `json:"id"`
+	GroupClientID string          `json:"group_client_id"`
+	Hash          string          `json:"hash"`
+	Payload       json.RawMessage `json:"payload,omitempty"`
+}
+
+// checkPayload recomputes the hash of the transaction in Payload, so an
+// approved payload can't be paired with the hash of something else.
+func (req *SignRequest) checkPayload() error {
+	if len(req.Payload) == 0 {
+		return ErrEmptyPayload
+	}
+	txn := &transaction.Transaction{}
+	if err := json.Unmarshal(req.Payload, txn); err != nil {
+		return errors.Throw(ErrPayloadMismatch, "invalid transaction payload")
+	}
+	if req.GroupClientID != "" && txn.ClientID != req.GroupClientID {
+		return errors.Throw(ErrPayloadMismatch, "transaction of another client")
+	}
+	txn.ComputeHashData()
+	if txn.Hash != req.Hash {
+		return errors.Throw(ErrPayloadMismatch, "hash does not match the transaction")
+	}
+	return nil
+}
+
+// SignatureShare is a signer's signature of the request hash with its key share.
+type SignatureShare struct {
+	SignerID  string `json:"signer_id"`
+	Signature string `json:"signature"`
+}
+
+// Transport delivers sign requests to signers.
+type Transport interface {
+	// RequestSignature asks the signer with the threshold id signerID to sign req.
+	RequestSignature(ctx context.Context, signerID string, req *SignRequest) (*SignatureShare, error)
+}
+
+// Group is the public part of a multisig wallet.
+type Group struct {
+	ClientID  string `json:"client_id"`
+	PublicKey string `json:"public_key"`
+	// Signers maps the threshold id of each key share to its public key
+	Signers map[string]string `json:"signers"`
+	T       int               `json:"threshold"`
+}
+
+// Coordinator collects signature shares and builds group signatures.
+type Coordinator struct {
+	group     Group
+	transport Transport
+}
+
+// NewCoordinator creates a coordinator for group reaching signers through transport.
+func NewCoordinator(group Group, transport Transport) (*Coordinator, error) {
+	if group.T < 1 || group.T > len(group.Signers) {
+		return nil, errors.New("multisig_coordinator", "threshold must be between 1 and the number of signers")
+	}
+	if group.PublicKey == "" {
+		return nil, errors.New("multisig_coordinator", "group public key is required")
+	}
+	if transport == nil {
+		return nil, errors.New("multisig_coordinator", "transport is required")
+	}
+	if group.ClientID == "" {
+		group.ClientID = clientID(group.PublicKey)
+	}
+	return &Coordinator{group: group, transport: transport}, nil
+}
+
+// Group returns the group the coordinator signs for.
+func (c *Coordinator) Group() Group {
+	return c.group
+}
+
+// SignRequest sends req to all signers and returns the group signature as soon
+// as T valid shares are collected. Invalid shares are ignored.
+func (c *Coordinator) SignRequest(ctx context.Context, req *SignRequest) (string, error) {
+	req.GroupClientID = c.group.ClientID
+	if err := req.checkPayload(); err != nil {
+		return "", err
+	}
+	if req.ID == "" {
+		req.ID = encryption.Hash(req.Hash + ":" + strconv.FormatInt(int64(common.Now()), 10))
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		share *SignatureShare
+		err   error
+	}
+	results := make(chan result, len(c.group.Signers))
+	var wg sync.WaitGroup
+	for signerID := range c.group.Signers {
+		wg.Add(1)
+		go func(signerID string) {
+			defer wg.Done()
+			share, err := c.transport.RequestSignature(ctx, signerID, req)
+			if err == nil && (share == nil || share.SignerID != signerID) {
+				err = errors.New("multisig_sign", "share of unexpected signer")
+			}
+			results <- result{share: share, err: err}
+		}(signerID)
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	shares := make(map[string]string, c.group.T)
+	var failures []string
+	for {
+		select {
+		case r, ok := <-results:
+			if !ok {
+				return "", errors.Throw(ErrNotEnoughShares, failures...)
+			}
+			if r.err != nil {
+				failures = append(failures, r.err.Error())
+				continue
+			}
+			if err := c.verifyShare(r.share, req.Hash); err != nil {
+				failures = append(failures, err.Error())
+				continue
+			}
+			shares[r.share.SignerID] = r.share.Signature
+			if len(shares) < c.group.T {
+				continue
+			}
+			return c.recover(shares, req.Hash)
+		case <-ctx.Done():
+			return "", errors.Throw(ErrNotEnoughShares, ctx.Err().Error())
+		}
+	}
+}
+
+// Sign implements signer.Signer. Signers only sign transactions they can
+// review, so signing a bare hash fails with ErrEmptyPayload; use
+// SignTransaction instead.
+func (c *Coordinator) Sign(hash string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultSignTimeout)
+	defer cancel()
+	return c.SignRequest(ctx, &SignRequest{Hash: hash})
+}
+
+// PublicKey implements signer.Signer.
+func (c *Coordinator) PublicKey() string {
+	return c.group.PublicKey
+}
+
+// Scheme implements signer.Signer.
+func (c *Coordinator) Scheme() string {
+	return SignatureScheme
+}
+
+// SignTransaction signs txn as the group. The transaction is sent to signers
+// as payload, so any transaction type, including smart contract calls, can be
+// reviewed and signed.
+func (c *Coordinator) SignTransaction(ctx context.Context, txn *transaction.Transaction) error {
+	txn.ClientID = c.group.ClientID
+	txn.PublicKey = c.group.PublicKey
+	txn.ComputeHashData()
+
+	payload, err := json.Marshal(txn)
+	if err != nil {
+		return err
+	}
+	txn.Signature, err = c.SignRequest(ctx, &SignRequest{ID: txn.Hash, Hash: txn.Hash, Payload: payload})
+	return err
+}
+
+func (c *Coordinator) verifyShare(share *SignatureShare, hash string) error {
+	pk, ok := c.group.Signers[share.SignerID]
+	if !ok {
+		return errors.Throw(ErrInvalidShare, "unknown signer "+share.SignerID)
+	}
+	if ok, err := verify(pk, share.Signature, hash); err != nil || !ok {
+		return errors.Throw(ErrInvalidShare, "signer "+share.SignerID)
+	}
+	return nil
+}
+
+func (c *Coordinator) recover(shares map[string]string, hash string) (string, error) {
+	sig, err := zcncrypto.RecoverThresholdSignature(shares)
+	if err != nil {
+		return "", err
+	}
+	if ok, err := verify(c.group.PublicKey, sig, hash); err != nil || !ok {
+		return "", errors.Throw(ErrInvalidShare, "group signature does not verify")
+	}
+	return sig, nil
+}
+
+func verify(publicKey, signature, hash string) (bool, error) {
+	ss := zcncrypto.NewSignatureScheme(SignatureScheme)
+	if err := ss.SetPublicKey(publicKey); err != nil {
+		return false, err
+	}
+	return ss.Verify(signature, hash)
+}
@@ -0,0 +1,164 @@
+package multisig
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/0chain/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/0chain/gosdk/core/encryption"
+	"github.com/0chain/gosdk/core/transaction"
+	"github.com/0chain/gosdk/core/zcncrypto"
+)
+
+func newGroup(t *testing.T, threshold, n int) (Group, []*Signer, zcncrypto.SignatureScheme) {
+	groupKey := zcncrypto.NewSignatureScheme(SignatureScheme)
+	_, err := groupKey.GenerateKeys()
+	require.NoError(t, err)
+
+	shares, err := zcncrypto.GenerateThresholdKeyShares(threshold, n, groupKey)
+	require.NoError(t, err)
+
+	signers := make([]*Signer, 0, n)
+	for _, share := range shares {
+		signers = append(signers, NewSigner(share, approveAll))
+	}
+	return GroupFromShares(groupKey.GetPublicKey(), threshold, shares), signers, groupKey
+}
+
+func approveAll(*SignRequest) error {
+	return nil
+}
+
+// newSignRequest builds a request to sign a transaction of the group.
+func newSignRequest(t *testing.T, group Group) *SignRequest {
+	txn := transaction.NewTransactionEntity(group.ClientID, "chain", group.PublicKey, 1)
+	txn.TransactionData = `{"name":"update_settings"}`
+	txn.ComputeHashData()
+	payload, err := json.Marshal(txn)
+	require.NoError(t, err)
+	return &SignRequest{Hash: txn.Hash, Payload: payload}
+}
+
+func TestCoordinator(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("threshold of signers", func(t *testing.T) {
+		group, signers, groupKey := newGroup(t, 2, 3)
+		// one signer is offline
+		c, err := NewCoordinator(group, NewLocalTransport(signers[0], signers[2]))
+		require.NoError(t, err)
+
+		req := newSignRequest(t, group)
+		sig, err := c.SignRequest(ctx, req)
+		require.NoError(t, err)
+
+		expected, err := groupKey.Sign(req.Hash)
+		require.NoError(t, err)
+		require.Equal(t, expected, sig)
+	})
+
+	t.Run("not enough signers", func(t *testing.T) {
+		group, signers, _ := newGroup(t, 3, 4)
+		rejected := NewSigner(signers[1].share, func(*SignRequest) error {
+			return errors.New("", "rejected")
+		})
+		c, err := NewCoordinator(group, NewLocalTransport(signers[0], rejected, signers[3]))
+		require.NoError(t, err)
+
+		_, err = c.SignRequest(ctx, newSignRequest(t, group))
+		require.ErrorIs(t, err, ErrNotEnoughShares)
+	})
+
+	t.Run("signer without approve func", func(t *testing.T) {
+		group, signers, _ := newGroup(t, 2, 2)
+		unapproved := NewSigner(signers[1].share, nil)
+		_, err := unapproved.Sign(ctx, newSignRequest(t, group))
+		require.ErrorIs(t, err, ErrNotApproved)
+
+		c, err := NewCoordinator(group, NewLocalTransport(signers[0], unapproved))
+		require.NoError(t, err)
+		_, err = c.SignRequest(ctx, newSignRequest(t, group))
+		require.ErrorIs(t, err, ErrNotEnoughShares)
+	})
+
+	t.Run("blind request", func(t *testing.T) {
+		group, signers, _ := newGroup(t, 2, 3)
+		c, err := NewCoordinator(group, NewLocalTransport(signers...))
+		require.NoError(t, err)
+
+		hash := encryption.Hash("multisig")
+		_, err = c.Sign(hash)
+		require.ErrorIs(t, err, ErrEmptyPayload)
+		_, err = signers[0].Sign(ctx, &SignRequest{Hash: hash})
+		require.ErrorIs(t, err, ErrEmptyPayload)
+	})
+
+	t.Run("invalid share is ignored", func(t *testing.T) {
+		group, signers, _ := newGroup(t, 2, 3)
+		other, _, _ := newGroup(t, 2, 3)
+		// signer 2 signs with a share of another group
+		group.Signers[signers[1].ID()] = other.Signers[signers[1].ID()]
+
+		c, err := NewCoordinator(group, NewLocalTransport(signers[0], signers[1]))
+		require.NoError(t, err)
+		_, err = c.SignRequest(ctx, newSignRequest(t, group))
+		require.ErrorIs(t, err, ErrNotEnoughShares)
+
+		c, err = NewCoordinator(group, NewLocalTransport(signers...))
+		require.NoError(t, err)
+		_, err = c.SignRequest(ctx, newSignRequest(t, group))
+		require.NoError(t, err)
+	})
+
+	t.Run("sign transaction", func(t *testing.T) {
+		group, signers, _ := newGroup(t, 2, 3)
+		c, err := NewCoordinator(group, NewLocalTransport(signers...))
+		require.NoError(t, err)
+
+		txn := transaction.NewTransactionEntity("", "chain", "", 1)
+		txn.TransactionType = transaction.TxnTypeSmartContract
+		txn.TransactionData = `{"name":"update_settings"}`
+		require.NoError(t, c.SignTransaction(ctx, txn))
+		require.Equal(t, group.ClientID, txn.ClientID)
+
+		ok, err := verify(group.PublicKey, txn.Signature, txn.Hash)
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("payload mismatch", func(t *testing.T) {
+		group, signers, _ := newGroup(t, 2, 3)
+		c, err := NewCoordinator(group, NewLocalTransport(signers...))
+		require.NoError(t, err)
+
+		reviewed := transaction.NewTransactionEntity(group.ClientID, "chain", group.PublicKey, 1)
+		reviewed.TransactionData = `{"name":"update_settings"}`
+		payload, err := json.Marshal(reviewed)
+		require.NoError(t, err)
+
+		// the signers review one transaction and are asked to sign another
+		signed := *reviewed
+		signed.Value = 1000
+		signed.ComputeHashData()
+
+		req := &SignRequest{Hash: signed.Hash, Payload: payload}
+		_, err = signers[0].Sign(ctx, req)
+		require.ErrorIs(t, err, ErrPayloadMismatch)
+		_, err = c.SignRequest(ctx, req)
+		require.ErrorIs(t, err, ErrPayloadMismatch)
+
+		reviewed.ComputeHashData()
+		_, err = c.SignRequest(ctx, &SignRequest{Hash: reviewed.Hash, Payload: payload})
+		require.NoError(t, err)
+	})
+
+	t.Run("invalid threshold", func(t *testing.T) {
+		group, signers, _ := newGroup(t, 2, 3)
+		group.T = 4
+		_, err := NewCoordinator(group, NewLocalTransport(signers...))
+		require.Error(t, err)
+	})
+}
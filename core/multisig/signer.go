@@ -0,0 +1,101 @@
+package multisig
+
+import (
+	"context"
+	"encoding/hex"
+
+	"github.com/0chain/errors"
+	"github.com/0chain/gosdk/core/encryption"
+	"github.com/0chain/gosdk/core/zcncrypto"
+)
+
+// ApproveFunc decides whether a signer agrees to sign a request.
+type ApproveFunc func(req *SignRequest) error
+
+// Signer holds one key share of a multisig wallet and answers sign requests.
+type Signer struct {
+	share   zcncrypto.SignatureScheme
+	approve ApproveFunc
+}
+
+// NewSigner creates a signer for a key share from zcncrypto.GenerateThresholdKeyShares.
+// approve is asked before every signature; a signer without approve signs nothing.
+func NewSigner(share zcncrypto.SignatureScheme, approve ApproveFunc) *Signer {
+	return &Signer{share: share, approve: approve}
+}
+
+// ID returns the threshold id of the key share.
+func (s *Signer) ID() string {
+	return s.share.GetID()
+}
+
+// PublicKey returns the public key of the key share.
+func (s *Signer) PublicKey() string {
+	return s.share.GetPublicKey()
+}
+
+// Sign signs the request hash with the key share once approved. The hash of
+// a request with payload must be the hash of the payload.
+func (s *Signer) Sign(ctx context.Context, req *SignRequest) (*SignatureShare, error) {
+	if err := req.checkPayload(); err != nil {
+		return nil, err
+	}
+	if s.approve == nil {
+		return nil, ErrNotApproved
+	}
+	if err := s.approve(req); err != nil {
+		return nil, errors.Wrap(err, "sign request rejected")
+	}
+	sig, err := s.share.Sign(req.Hash)
+	if err != nil {
+		return nil, err
+	}
+	return &SignatureShare{SignerID: s.ID(), Signature: sig}, nil
+}
+
+// LocalTransport delivers sign requests to in-process signers. It is meant for
+// tests and for signers living in the same process as the coordinator.
+type LocalTransport struct {
+	signers map[string]*Signer
+}
+
+// NewLocalTransport creates a transport reaching the given signers.
+func NewLocalTransport(signers ...*Signer) *LocalTransport {
+	t := &LocalTransport{signers: make(map[string]*Signer, len(signers))}
+	for _, s := range signers {
+		t.signers[s.ID()] = s
+	}
+	return t
+}
+
+// RequestSignature implements Transport.
+func (t *LocalTransport) RequestSignature(ctx context.Context, signerID string, req *SignRequest) (*SignatureShare, error) {
+	s, ok := t.signers[signerID]
+	if !ok {
+		return nil, errors.New("local_transport", "unknown signer "+signerID)
+	}
+	return s.Sign(ctx, req)
+}
+
+// GroupFromShares builds the public group description from the group public key
+// and the key shares, as created by zcncore.CreateMSWallet.
+func GroupFromShares(groupPublicKey string, t int, shares []zcncrypto.SignatureScheme) Group {
+	g := Group{
+		ClientID:  clientID(groupPublicKey),
+		PublicKey: groupPublicKey,
+		Signers:   make(map[string]string, len(shares)),
+		T:         t,
+	}
+	for _, s := range shares {
+		g.Signers[s.GetID()] = s.GetPublicKey()
+	}
+	return g
+}
+
+func clientID(publicKey string) string {
+	pk, err := hex.DecodeString(publicKey)
+	if err != nil {
+		return ""
+	}
+	return encryption.Hash(pk)
+}
@@ -0,0 +1,22 @@
+package multisig
+
+import (
+	"errors"
+)
+
+var (
+	// ErrNotEnoughShares fewer than T signers returned a valid signature share
+	ErrNotEnoughShares = errors.New("[multisig] not enough signature shares")
+
+	// ErrInvalidShare signature share does not verify against the signer's public key
+	ErrInvalidShare = errors.New("[multisig] invalid signature share")
+
+	// ErrPayloadMismatch request hash is not the hash of its payload
+	ErrPayloadMismatch = errors.New("[multisig] hash does not match the payload")
+
+	// ErrEmptyPayload request has no transaction for the signers to review
+	ErrEmptyPayload = errors.New("[multisig] sign request has no payload")
+
+	// ErrNotApproved signer has no ApproveFunc to approve the request
+	ErrNotApproved = errors.New("[multisig] sign request is not approved")
+)
@@ -0,0 +1,58 @@
+package signer
+
+import (
+	"github.com/0chain/errors"
+	"github.com/0chain/gosdk/core/zcncrypto"
+)
+
+// LocalSigner signs with private keys held in process. Split-key wallets are
+// supported by aggregating the signatures of all keys.
+type LocalSigner struct {
+	scheme    string
+	publicKey string
+	keys      []zcncrypto.KeyPair
+}
+
+// NewLocalSigner creates a signer from the keys of w.
+func NewLocalSigner(w *zcncrypto.Wallet, scheme string) (*LocalSigner, error) {
+	if w == nil || len(w.Keys) == 0 {
+		return nil, errors.New("local_signer", "wallet has no keys")
+	}
+	return &LocalSigner{
+		scheme:    scheme,
+		publicKey: w.ClientKey,
+		keys:      w.Keys,
+	}, nil
+}
+
+// Sign implements Signer.
+func (s *LocalSigner) Sign(hash string) (string, error) {
+	var sig string
+	for _, kv := range s.keys {
+		ss := zcncrypto.NewSignatureScheme(s.scheme)
+		err := ss.SetPrivateKey(kv.PrivateKey)
+		if err != nil {
+			return "", err
+		}
+
+		if len(sig) == 0 {
+			sig, err = ss.Sign(hash)
+		} else {
+			sig, err = ss.Add(sig, hash)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return sig, nil
+}
+
+// PublicKey implements Signer.
+func (s *LocalSigner) PublicKey() string {
+	return s.publicKey
+}
+
+// Scheme implements Signer.
+func (s *LocalSigner) Scheme() string {
+	return s.scheme
+}
@@ -0,0 +1,81 @@
+package signer
+
+import (
+	"sync"
+
+	"github.com/0chain/errors"
+)
+
+// Module is a PKCS#11-style token plugin. Private keys never leave the module;
+// it only exposes public keys and signing operations addressed by key label.
+type Module interface {
+	// Login opens a session on the token.
+	Login(pin string) error
+	// PublicKey returns the hex encoded public key stored under label.
+	PublicKey(label string) (string, error)
+	// Sign signs the hex encoded hash with the key stored under label.
+	Sign(label, hash string) (string, error)
+	// Scheme returns the signature scheme of the keys on the token.
+	Scheme() string
+}
+
+var (
+	modulesMu sync.RWMutex
+	modules   = make(map[string]Module)
+)
+
+// RegisterModule makes a token module available by name. It is meant to be
+// called from the init function of the package implementing the module.
+func RegisterModule(name string, m Module) {
+	modulesMu.Lock()
+	defer modulesMu.Unlock()
+
+	if m == nil {
+		panic("signer: RegisterModule module is nil")
+	}
+	if _, dup := modules[name]; dup {
+		panic("signer: RegisterModule called twice for module " + name)
+	}
+	modules[name] = m
+}
+
+// ModuleSigner signs with a key held by a registered token module.
+type ModuleSigner struct {
+	module    Module
+	label     string
+	publicKey string
+}
+
+// NewModuleSigner logs into the registered module and selects the key stored under label.
+func NewModuleSigner(name, label, pin string) (*ModuleSigner, error) {
+	modulesMu.RLock()
+	m, ok := modules[name]
+	modulesMu.RUnlock()
+	if !ok {
+		return nil, errors.New("module_signer", "unknown module "+name)
+	}
+
+	if err := m.Login(pin); err != nil {
+		return nil, errors.Wrap(err, "module login failed")
+	}
+	pk, err := m.PublicKey(label)
+	if err != nil {
+		return nil, err
+	}
+	return &ModuleSigner{module: m, label: label, publicKey: pk}, nil
+}
+
+// Sign implements Signer.
+func (s *ModuleSigner) Sign(hash string) (string, error) {
+	return s.module.Sign(s.label, hash)
+}
+
+// PublicKey implements Signer.
+func (s *ModuleSigner) PublicKey() string {
+	return s.publicKey
+}
+
+// Scheme implements Signer.
+func (s *ModuleSigner) Scheme() string {
+	return s.module.Scheme()
+}
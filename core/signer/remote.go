@@ -0,0 +1,153 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/0chain/errors"
+)
+
+const (
+	remotePublicKeyPath = "/v1/public_key"
+	remoteSignPath      = "/v1/sign"
+
+	// DefaultRemoteTimeout is the default timeout of a remote signing request.
+	DefaultRemoteTimeout = 30 * time.Second
+)
+
+// RemoteSigner signs by calling an HTTP signing service that holds the keys.
+//
+// The service exposes
+//
+//	GET  /v1/public_key -> {"public_key": "...", "scheme": "bls0chain"}
+//	POST /v1/sign {"hash": "..."} -> {"signature": "..."}
+//
+// Returned signatures are verified locally before they are used.
+type RemoteSigner struct {
+	baseURL   string
+	client    *http.Client
+	header    map[string]string
+	publicKey string
+	scheme    string
+}
+
+// RemoteOption configures a RemoteSigner.
+type RemoteOption func(*RemoteSigner)
+
+// WithHTTPClient sets the http client used to reach the signing service.
+func WithHTTPClient(c *http.Client) RemoteOption {
+	return func(s *RemoteSigner) {
+		s.client = c
+	}
+}
+
+// WithRemoteHeader sets a header, e.g. an access token, sent with every request.
+func WithRemoteHeader(key, value string) RemoteOption {
+	return func(s *RemoteSigner) {
+		s.header[key] = value
+	}
+}
+
+type remotePublicKeyResponse struct {
+	PublicKey string `json:"public_key"`
+	Scheme    string `json:"scheme"`
+}
+
+type remoteSignRequest struct {
+	Hash string `json:"hash"`
+}
+
+type remoteSignResponse struct {
+	Signature string `json:"signature"`
+}
+
+// NewRemoteSigner connects to the signing service at baseURL and fetches its public key.
+func NewRemoteSigner(ctx context.Context, baseURL string, opts ...RemoteOption) (*RemoteSigner, error) {
+	s := &RemoteSigner{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: DefaultRemoteTimeout},
+		header:  make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	var resp remotePublicKeyResponse
+	if err := s.do(ctx, http.MethodGet, remotePublicKeyPath, nil, &resp); err != nil {
+		return nil, err
+	}
+	if resp.PublicKey == "" || resp.Scheme == "" {
+		return nil, errors.New("remote_signer", "signing service returned no public key")
+	}
+	s.publicKey = resp.PublicKey
+	s.scheme = resp.Scheme
+	return s, nil
+}
+
+// Sign implements Signer.
+func (s *RemoteSigner) Sign(hash string) (string, error) {
+	var resp remoteSignResponse
+	if err := s.do(context.TODO(), http.MethodPost, remoteSignPath, &remoteSignRequest{Hash: hash}, &resp); err != nil {
+		return "", err
+	}
+
+	ok, err := Verify(s, resp.Signature, hash)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid signature from signing service")
+	}
+	if !ok {
+		return "", errors.New("remote_signer", "signing service returned a signature that does not verify")
+	}
+	return resp.Signature, nil
+}
+
+// PublicKey implements Signer.
+func (s *RemoteSigner) PublicKey() string {
+	return s.publicKey
+}
+
+// Scheme implements Signer.
+func (s *RemoteSigner) Scheme() string {
+	return s.scheme
+}
+
+func (s *RemoteSigner) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		buf, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(buf)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.header {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "signing service request failed")
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.New("remote_signer", fmt.Sprintf("%v: %s", resp.Status, respBody))
+	}
+	return json.Unmarshal(respBody, out)
+}
@@ -0,0 +1,40 @@
+// Package signer abstracts where 0chain keys live, so transactions, markers and
+// auth tickets can be signed by in-process keys, a remote signing service or a
+// hardware token without the SDK ever holding the private key.
+package signer
+
+import (
+	"encoding/hex"
+
+	"github.com/0chain/errors"
+	"github.com/0chain/gosdk/core/encryption"
+	"github.com/0chain/gosdk/core/zcncrypto"
+)
+
+// Signer signs hashes on behalf of a 0chain client.
+type Signer interface {
+	// Sign signs the hex encoded hash and returns the hex encoded signature.
+	Sign(hash string) (string, error)
+	// PublicKey returns the hex encoded public key the signatures verify against.
+	PublicKey() string
+	// Scheme returns the signature scheme, "bls0chain" or "ed25519".
+	Scheme() string
+}
+
+// ClientID returns the 0chain client id derived from the signer's public key.
+func ClientID(s Signer) (string, error) {
+	pk, err := hex.DecodeString(s.PublicKey())
+	if err != nil {
+		return "", errors.Wrap(err, "invalid public key")
+	}
+	return encryption.Hash(pk), nil
+}
+
+// Verify checks signature of hash against the signer's public key.
+func Verify(s Signer, signature, hash string) (bool, error) {
+	ss := zcncrypto.NewSignatureScheme(s.Scheme())
+	if err := ss.SetPublicKey(s.PublicKey()); err != nil {
+		return false, err
+	}
+	return ss.Verify(signature, hash)
+}
@@ -0,0 +1,123 @@
+package signer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/0chain/gosdk/core/encryption"
+	"github.com/0chain/gosdk/core/zcncrypto"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWallet(t *testing.T) *zcncrypto.Wallet {
+	w, err := zcncrypto.NewSignatureScheme("bls0chain").GenerateKeys()
+	require.NoError(t, err)
+	return w
+}
+
+func TestLocalSigner(t *testing.T) {
+	w := newTestWallet(t)
+	s, err := NewLocalSigner(w, "bls0chain")
+	require.NoError(t, err)
+
+	hash := encryption.Hash("data")
+	sig, err := s.Sign(hash)
+	require.NoError(t, err)
+
+	ok, err := Verify(s, sig, hash)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	clientID, err := ClientID(s)
+	require.NoError(t, err)
+	require.Equal(t, w.ClientID, clientID)
+}
+
+func TestRemoteSigner(t *testing.T) {
+	local, err := NewLocalSigner(newTestWallet(t), "bls0chain")
+	require.NoError(t, err)
+	forged, err := NewLocalSigner(newTestWallet(t), "bls0chain")
+	require.NoError(t, err)
+
+	var backend Signer = local
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "secret", r.Header.Get("X-Token"))
+		switch r.URL.Path {
+		case remotePublicKeyPath:
+			json.NewEncoder(w).Encode(remotePublicKeyResponse{PublicKey: local.PublicKey(), Scheme: local.Scheme()})
+		case remoteSignPath:
+			var req remoteSignRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			sig, err := backend.Sign(req.Hash)
+			require.NoError(t, err)
+			json.NewEncoder(w).Encode(remoteSignResponse{Signature: sig})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	s, err := NewRemoteSigner(context.Background(), srv.URL, WithRemoteHeader("X-Token", "secret"))
+	require.NoError(t, err)
+	require.Equal(t, local.PublicKey(), s.PublicKey())
+
+	hash := encryption.Hash("data")
+	sig, err := s.Sign(hash)
+	require.NoError(t, err)
+	ok, err := Verify(local, sig, hash)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	backend = forged
+	_, err = s.Sign(hash)
+	require.Error(t, err)
+}
+
+type memoryModule struct {
+	pin  string
+	keys map[string]*LocalSigner
+}
+
+func (m *memoryModule) Login(pin string) error {
+	if pin != m.pin {
+		return errors.New("invalid pin")
+	}
+	return nil
+}
+
+func (m *memoryModule) PublicKey(label string) (string, error) {
+	return m.keys[label].PublicKey(), nil
+}
+
+func (m *memoryModule) Sign(label, hash string) (string, error) {
+	return m.keys[label].Sign(hash)
+}
+
+func (m *memoryModule) Scheme() string {
+	return "bls0chain"
+}
+
+func TestModuleSigner(t *testing.T) {
+	local, err := NewLocalSigner(newTestWallet(t), "bls0chain")
+	require.NoError(t, err)
+	RegisterModule("memory", &memoryModule{pin: "1234", keys: map[string]*LocalSigner{"client": local}})
+
+	_, err = NewModuleSigner("memory", "client", "0000")
+	require.Error(t, err)
+	_, err = NewModuleSigner("unknown", "client", "1234")
+	require.Error(t, err)
+
+	s, err := NewModuleSigner("memory", "client", "1234")
+	require.NoError(t, err)
+
+	hash := encryption.Hash("data")
+	sig, err := s.Sign(hash)
+	require.NoError(t, err)
+	ok, err := Verify(s, sig, hash)
+	require.NoError(t, err)
+	require.True(t, ok)
+}
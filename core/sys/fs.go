@@ -32,6 +32,9 @@ type FS interface {
 	//MkdirAll creates a directory named path
 	MkdirAll(path string, perm os.FileMode) error
 
+	// Rename renames (moves) oldpath to newpath, replacing newpath if it exists.
+	Rename(oldpath, newpath string) error
+
 	// LoadProgress load progress
 	LoadProgress(progressID string) ([]byte, error)
 
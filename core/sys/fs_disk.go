@@ -47,6 +47,11 @@ func (dfs *DiskFS) MkdirAll(path string, perm os.FileMode) error {
 	return os.MkdirAll(path, perm)
 }
 
+// Rename renames (moves) oldpath to newpath, replacing newpath if it exists.
+func (dfs *DiskFS) Rename(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
 // Stat returns a FileInfo describing the named file.
 // If there is an error, it will be of type *PathError.
 func (dfs *DiskFS) Stat(name string) (fs.FileInfo, error) {
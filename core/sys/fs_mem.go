@@ -89,6 +89,18 @@ func (mfs *MemFS) MkdirAll(path string, perm os.FileMode) error {
 	return nil
 }
 
+// Rename renames (moves) oldpath to newpath, replacing newpath if it exists.
+func (mfs *MemFS) Rename(oldpath, newpath string) error {
+	file, ok := mfs.files[oldpath]
+	if !ok {
+		return os.ErrNotExist
+	}
+	file.Name = filepath.Base(newpath)
+	mfs.files[newpath] = file
+	delete(mfs.files, oldpath)
+	return nil
+}
+
 // Stat returns a FileInfo describing the named file.
 // If there is an error, it will be of type *PathError.
 func (mfs *MemFS) Stat(name string) (fs.FileInfo, error) {
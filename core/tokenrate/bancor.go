@@ -16,7 +16,11 @@ import (
 type bancorQuoteQuery struct {
 }
 
-func (qq *bancorQuoteQuery) getUSD(ctx context.Context, symbol string) (float64, error) {
+func (qq *bancorQuoteQuery) Name() string {
+	return "bancor"
+}
+
+func (qq *bancorQuoteQuery) Price(ctx context.Context, symbol, currency string) (float64, error) {
 
 	var result bancorResponse
 
@@ -65,7 +69,8 @@ func (qq *bancorQuoteQuery) getUSD(ctx context.Context, symbol string) (float64,
 		return 0, errs[0]
 	}
 
-	rate, ok := result.Data.Rate24hAgo["usd"]
+	currency = normalizeCurrency(currency)
+	rate, ok := result.Data.Rate24hAgo[currency]
 
 	if ok {
 
@@ -74,13 +79,13 @@ func (qq *bancorQuoteQuery) getUSD(ctx context.Context, symbol string) (float64,
 		}
 
 		//rate24ago is invalid, try get current rate
-		rate, ok = result.Data.Rate["usd"]
+		rate, ok = result.Data.Rate[currency]
 		if ok && rate.Value > 0 {
 			return rate.Value, nil
 		}
 	}
 
-	return 0, fmt.Errorf("bancor: %s price is not provided on bancor apis", symbol)
+	return 0, fmt.Errorf("bancor: %s price in %s is not provided on bancor apis", symbol, currency)
 }
 
 // {
@@ -6,7 +6,10 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/0chain/gosdk/core/resty"
 )
@@ -14,7 +17,11 @@ import (
 type coingeckoQuoteQuery struct {
 }
 
-func (qq *coingeckoQuoteQuery) getUSD(ctx context.Context, symbol string) (float64, error) {
+func (qq *coingeckoQuoteQuery) Name() string {
+	return "coingecko"
+}
+
+func (qq *coingeckoQuoteQuery) Price(ctx context.Context, symbol, currency string) (float64, error) {
 
 	var result coingeckoResponse
 
@@ -47,9 +54,10 @@ func (qq *coingeckoQuoteQuery) getUSD(ctx context.Context, symbol string) (float
 
 	var rate float64
 
-	h, ok := result.MarketData.High24h["usd"]
+	currency = normalizeCurrency(currency)
+	h, ok := result.MarketData.High24h[currency]
 	if ok {
-		l, ok := result.MarketData.Low24h["usd"]
+		l, ok := result.MarketData.Low24h[currency]
 		if ok {
 			rate = (h + l) / 2
 			if rate > 0 {
@@ -58,7 +66,7 @@ func (qq *coingeckoQuoteQuery) getUSD(ctx context.Context, symbol string) (float
 		}
 	}
 
-	rate, ok = result.MarketData.CurrentPrice["usd"]
+	rate, ok = result.MarketData.CurrentPrice[currency]
 
 	if ok {
 		if rate > 0 {
@@ -71,6 +79,62 @@ func (qq *coingeckoQuoteQuery) getUSD(ctx context.Context, symbol string) (float
 	return 0, fmt.Errorf("market API: %s price is not provided on internal https://zcnprices.zus.network/market api", symbol)
 }
 
+// HistoricalPrice quotes with the public CoinGecko history api, by the coin id of the symbol.
+func (qq *coingeckoQuoteQuery) HistoricalPrice(ctx context.Context, symbol, currency string, at time.Time) (float64, error) {
+
+	var result coingeckoResponse
+
+	id := coingeckoCoinID(symbol)
+	r := resty.New()
+	r.DoGet(ctx, "https://api.coingecko.com/api/v3/coins/"+id+"/history?localization=false&date="+at.UTC().Format("02-01-2006")).
+		Then(func(req *http.Request, resp *http.Response, respBody []byte, cf context.CancelFunc, err error) error {
+
+			if err != nil {
+				return err
+			}
+
+			if resp.StatusCode != http.StatusOK {
+				return errors.New("coingecko: " + strconv.Itoa(resp.StatusCode) + resp.Status)
+			}
+
+			err = json.Unmarshal(respBody, &result)
+			if err != nil {
+				return err
+			}
+			result.Raw = string(respBody)
+
+			return nil
+
+		})
+
+	errs := r.Wait()
+	if len(errs) > 0 {
+		return 0, errs[0]
+	}
+
+	rate, ok := result.MarketData.CurrentPrice[normalizeCurrency(currency)]
+	if ok && rate > 0 {
+		return rate, nil
+	}
+
+	return 0, fmt.Errorf("coingecko: %s price in %s is not provided at %s", symbol, currency, at.UTC().Format("2006-01-02"))
+}
+
+// coingeckoCoinID returns the CoinGecko id of the symbol, configurable with
+// the environment variable COINGECKO_ID_<SYMBOL>.
+func coingeckoCoinID(symbol string) string {
+	if id, ok := os.LookupEnv("COINGECKO_ID_" + strings.ToUpper(symbol)); ok {
+		return id
+	}
+	switch strings.ToLower(symbol) {
+	case "zcn":
+		return "0chain"
+	case "eth":
+		return "ethereum"
+	}
+	return strings.ToLower(symbol)
+}
+
 type coingeckoResponse struct {
 	MarketData coingeckoMarketData `json:"market_data"`
 	Raw        string              `json:"-"`
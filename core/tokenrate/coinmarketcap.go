@@ -20,7 +20,7 @@ type coinmarketcapQuoteQuery struct {
 // js call is unsupported for coinmarketcap api due to core issue
 // https://coinmarketcap.com/api/documentation/v1/#section/Quick-Start-Guide
 // Note: Making HTTP requests on the client side with Javascript is currently prohibited through CORS configuration. This is to protect your API Key which should not be visible to users of your application so your API Key is not stolen. Secure your API Key by routing calls through your own backend service.
-func createCoinmarketcapQuoteQuery() quoteQuery {
+func createCoinmarketcapQuoteQuery() Provider {
 
 	coinmarketcapAPIKEY, ok := os.LookupEnv("COINMARKETCAP_API_KEY")
 	if !ok {
@@ -32,7 +32,11 @@ func createCoinmarketcapQuoteQuery() quoteQuery {
 	}
 }
 
-func (qq *coinmarketcapQuoteQuery) getUSD(ctx context.Context, symbol string) (float64, error) {
+func (qq *coinmarketcapQuoteQuery) Name() string {
+	return "coinmarketcap"
+}
+
+func (qq *coinmarketcapQuoteQuery) Price(ctx context.Context, symbol, currency string) (float64, error) {
 
 	var result coinmarketcapResponse
 
@@ -41,8 +45,9 @@ func (qq *coinmarketcapQuoteQuery) getUSD(ctx context.Context, symbol string) (f
 	}))
 
 	s := strings.ToUpper(symbol)
+	c := strings.ToUpper(normalizeCurrency(currency))
 
-	r.DoGet(ctx, "https://pro-api.coinmarketcap.com/v2/cryptocurrency/quotes/latest?symbol="+s).
+	r.DoGet(ctx, "https://pro-api.coinmarketcap.com/v2/cryptocurrency/quotes/latest?symbol="+s+"&convert="+c).
 		Then(func(req *http.Request, resp *http.Response, respBody []byte, cf context.CancelFunc, err error) error {
 			if err != nil {
 				return err
@@ -73,7 +78,7 @@ func (qq *coinmarketcapQuoteQuery) getUSD(ctx context.Context, symbol string) (f
 		return 0, errors.New("coinmarketcap: " + symbol + " is not provided on coinmarketcap apis")
 	}
 
-	rate, ok := zcn[0].Quote["USD"]
+	rate, ok := zcn[0].Quote[c]
 	if ok {
 		if rate.Price > 0 {
 			return rate.Price, nil
@@ -82,7 +87,7 @@ func (qq *coinmarketcapQuoteQuery) getUSD(ctx context.Context, symbol string) (f
 		return 0, fmt.Errorf("coinmarketcap: invalid response %s", result.Raw)
 	}
 
-	return 0, errors.New("coinmarketcap: " + symbol + " to USD quote is not provided on coinmarketcap apis")
+	return 0, errors.New("coinmarketcap: " + symbol + " to " + c + " quote is not provided on coinmarketcap apis")
 }
 
 //	{
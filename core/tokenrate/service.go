@@ -0,0 +1,285 @@
+package tokenrate
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTTL is the time a price is served from the cache.
+	DefaultTTL = 5 * time.Minute
+	// DefaultMaxStale is the age up to which a cached price is served when no provider answers.
+	DefaultMaxStale = time.Hour
+	// DefaultMaxDeviation is the relative distance from the median above which a quote is rejected.
+	DefaultMaxDeviation = 0.1
+	// DefaultQueryTimeout bounds the queries of the providers.
+	DefaultQueryTimeout = 10 * time.Second
+)
+
+// Quote is an aggregated price.
+type Quote struct {
+	Symbol   string    `json:"symbol"`
+	Currency string    `json:"currency"`
+	Price    float64   `json:"price"`
+	Sources  []string  `json:"sources"` // Sources are the providers of the aggregated quotes
+	Time     time.Time `json:"time"`
+	Stale    bool      `json:"stale"` // Stale quotes are served from the cache after all providers failed
+}
+
+// Service aggregates the prices of providers into the median of their
+// quotes, rejecting outliers, and caches them.
+type Service struct {
+	Providers []Provider
+	// TTL of the cached prices (default = DefaultTTL)
+	TTL time.Duration
+	// MaxStale is the max age of cached prices served when providers fail (default = DefaultMaxStale), negative disables
+	MaxStale time.Duration
+	// MaxDeviation rejects quotes further from the median (default = DefaultMaxDeviation)
+	MaxDeviation float64
+	// MinQuotes is the min number of quotes to aggregate, outliers are rejected only if as many are kept (default = 1)
+	MinQuotes int
+	// QueryTimeout bounds the provider queries (default = DefaultQueryTimeout)
+	QueryTimeout time.Duration
+
+	mu         sync.Mutex
+	cache      map[string]*Quote
+	historical map[string]float64
+	now        func() time.Time
+}
+
+// NewService creates a price service of the providers.
+func NewService(providers ...Provider) *Service {
+	return &Service{Providers: providers}
+}
+
+// Price returns the aggregated price of the token in the currency.
+func (s *Service) Price(ctx context.Context, symbol, currency string) (float64, error) {
+	q, err := s.Quote(ctx, symbol, currency)
+	if err != nil {
+		return 0, err
+	}
+	return q.Price, nil
+}
+
+// Quote returns the aggregated quote of the token in the currency, from the
+// cache if younger than TTL.
+func (s *Service) Quote(ctx context.Context, symbol, currency string) (*Quote, error) {
+	currency = normalizeCurrency(currency)
+	key := strings.ToLower(symbol) + "/" + currency
+
+	s.mu.Lock()
+	cached := s.cache[key]
+	s.mu.Unlock()
+	now := s.timeNow()
+	if cached != nil && now.Sub(cached.Time) < s.ttl() {
+		return cached, nil
+	}
+
+	q, err := s.query(ctx, symbol, currency)
+	if err != nil {
+		if cached != nil && s.maxStale() >= 0 && now.Sub(cached.Time) < s.maxStale() {
+			stale := *cached
+			stale.Stale = true
+			return &stale, nil
+		}
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if s.cache == nil {
+		s.cache = make(map[string]*Quote)
+	}
+	s.cache[key] = q
+	s.mu.Unlock()
+
+	return q, nil
+}
+
+// HistoricalPrice returns the median price of the historical providers at the
+// day of at. The prices of past days are cached without expiry, the price of
+// the current day still changes and is not cached.
+func (s *Service) HistoricalPrice(ctx context.Context, symbol, currency string, at time.Time) (float64, error) {
+	currency = normalizeCurrency(currency)
+	day := at.UTC().Format("2006-01-02")
+	key := strings.ToLower(symbol) + "/" + currency + "/" + day
+
+	s.mu.Lock()
+	price, ok := s.historical[key]
+	s.mu.Unlock()
+	if ok {
+		return price, nil
+	}
+
+	var providers []Provider
+	for _, p := range s.Providers {
+		if _, ok := p.(HistoricalProvider); ok {
+			providers = append(providers, p)
+		}
+	}
+	prices, _, err := s.collect(ctx, providers, func(ctx context.Context, p Provider) (float64, error) {
+		return p.(HistoricalProvider).HistoricalPrice(ctx, symbol, currency, at)
+	})
+	if err != nil {
+		if err == ErrNoAvailableQuoteQuery {
+			return 0, ErrNoHistoricalQuote
+		}
+		return 0, err
+	}
+	price, _ = s.aggregate(prices, nil)
+
+	// dates compare in the same format
+	if day < s.timeNow().UTC().Format("2006-01-02") {
+		s.mu.Lock()
+		if s.historical == nil {
+			s.historical = make(map[string]float64)
+		}
+		s.historical[key] = price
+		s.mu.Unlock()
+	}
+
+	return price, nil
+}
+
+// Invalidate drops the cached prices.
+func (s *Service) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache = nil
+}
+
+func (s *Service) query(ctx context.Context, symbol, currency string) (*Quote, error) {
+	prices, sources, err := s.collect(ctx, s.Providers, func(ctx context.Context, p Provider) (float64, error) {
+		return p.Price(ctx, symbol, currency)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	price, sources := s.aggregate(prices, sources)
+	return &Quote{
+		Symbol:   symbol,
+		Currency: currency,
+		Price:    price,
+		Sources:  sources,
+		Time:     s.timeNow(),
+	}, nil
+}
+
+// collect queries the providers concurrently and returns the valid prices
+// with their providers.
+func (s *Service) collect(ctx context.Context, providers []Provider, get func(context.Context, Provider) (float64, error)) ([]float64, []string, error) {
+	timeout := s.QueryTimeout
+	if timeout <= 0 {
+		timeout = DefaultQueryTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		prices  []float64
+		sources []string
+		lastErr error
+	)
+	for _, p := range providers {
+		wg.Add(1)
+		go func(p Provider) {
+			defer wg.Done()
+			val, err := get(ctx, p)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				lastErr = err
+				return
+			}
+			if val > 0 && !math.IsInf(val, 0) && !math.IsNaN(val) {
+				prices = append(prices, val)
+				sources = append(sources, p.Name())
+			}
+		}(p)
+	}
+	wg.Wait()
+
+	if len(prices) < s.minQuotes() {
+		// All conversion APIs failed
+		if lastErr != nil && len(prices) == 0 {
+			return nil, nil, lastErr
+		}
+		return nil, nil, ErrNoAvailableQuoteQuery
+	}
+	return prices, sources, nil
+}
+
+// aggregate returns the median of the prices within MaxDeviation of the
+// median of all prices, and their sources. If fewer than MinQuotes prices
+// are kept, the quotes are too far apart to reject outliers and it falls
+// back to the median of all prices.
+func (s *Service) aggregate(prices []float64, sources []string) (float64, []string) {
+	m := median(prices)
+
+	maxDeviation := s.MaxDeviation
+	if maxDeviation <= 0 {
+		maxDeviation = DefaultMaxDeviation
+	}
+
+	var kept []float64
+	var keptSources []string
+	for i, p := range prices {
+		if math.Abs(p-m)/m <= maxDeviation {
+			kept = append(kept, p)
+			if sources != nil {
+				keptSources = append(keptSources, sources[i])
+			}
+		}
+	}
+	if len(kept) == 0 || len(kept) < s.minQuotes() {
+		kept = prices
+		keptSources = append([]string{}, sources...)
+	}
+	sort.Strings(keptSources)
+	return median(kept), keptSources
+}
+
+func (s *Service) minQuotes() int {
+	if s.MinQuotes < 1 {
+		return 1
+	}
+	return s.MinQuotes
+}
+
+func (s *Service) ttl() time.Duration {
+	if s.TTL > 0 {
+		return s.TTL
+	}
+	return DefaultTTL
+}
+
+func (s *Service) maxStale() time.Duration {
+	if s.MaxStale != 0 {
+		return s.MaxStale
+	}
+	return DefaultMaxStale
+}
+
+func (s *Service) timeNow() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
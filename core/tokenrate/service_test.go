@@ -0,0 +1,165 @@
+package tokenrate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProvider struct {
+	name   string
+	prices map[string]float64 // prices by currency
+	err    error
+	calls  int
+}
+
+func (p *fakeProvider) Name() string {
+	return p.name
+}
+
+func (p *fakeProvider) Price(_ context.Context, _, currency string) (float64, error) {
+	p.calls++
+	if p.err != nil {
+		return 0, p.err
+	}
+	return p.prices[currency], nil
+}
+
+type fakeHistoricalProvider struct {
+	fakeProvider
+}
+
+func (p *fakeHistoricalProvider) HistoricalPrice(_ context.Context, _, currency string, at time.Time) (float64, error) {
+	p.calls++
+	return p.prices[currency] / float64(at.Day()), nil
+}
+
+func TestService(t *testing.T) {
+	ctx := context.Background()
+	a := &fakeProvider{name: "a", prices: map[string]float64{"usd": 0.20, "eur": 0.18}}
+	b := &fakeProvider{name: "b", prices: map[string]float64{"usd": 0.21}}
+	c := &fakeProvider{name: "c", prices: map[string]float64{"usd": 0.22, "eur": 0.19}}
+	outlier := &fakeProvider{name: "outlier", prices: map[string]float64{"usd": 2.0}}
+	failing := &fakeProvider{name: "failing", err: errors.New("down")}
+
+	now := time.Date(2023, 5, 10, 12, 0, 0, 0, time.UTC)
+	s := NewService(a, b, c, outlier, failing)
+	s.now = func() time.Time { return now }
+
+	t.Run("median without outliers", func(t *testing.T) {
+		q, err := s.Quote(ctx, "ZCN", "USD")
+		require.NoError(t, err)
+		require.Equal(t, 0.21, q.Price)
+		require.Equal(t, []string{"a", "b", "c"}, q.Sources)
+		require.Equal(t, "usd", q.Currency)
+		require.False(t, q.Stale)
+	})
+
+	t.Run("currencies", func(t *testing.T) {
+		price, err := s.Price(ctx, "zcn", "eur")
+		require.NoError(t, err)
+		require.InDelta(t, 0.185, price, 1e-9)
+	})
+
+	t.Run("cache", func(t *testing.T) {
+		calls := a.calls
+		_, err := s.Price(ctx, "zcn", "usd")
+		require.NoError(t, err)
+		require.Equal(t, calls, a.calls)
+
+		now = now.Add(DefaultTTL)
+		_, err = s.Price(ctx, "zcn", "usd")
+		require.NoError(t, err)
+		require.Equal(t, calls+1, a.calls)
+	})
+
+	t.Run("stale fallback", func(t *testing.T) {
+		for _, p := range []*fakeProvider{a, b, c, outlier} {
+			p.err = errors.New("down")
+		}
+		now = now.Add(DefaultTTL)
+		q, err := s.Quote(ctx, "zcn", "usd")
+		require.NoError(t, err)
+		require.True(t, q.Stale)
+		require.Equal(t, 0.21, q.Price)
+
+		now = now.Add(DefaultMaxStale)
+		_, err = s.Quote(ctx, "zcn", "usd")
+		require.Error(t, err)
+	})
+
+	t.Run("min quotes", func(t *testing.T) {
+		s := NewService(&fakeProvider{name: "a", prices: map[string]float64{"usd": 1}}, failing)
+		s.MinQuotes = 2
+		_, err := s.Price(ctx, "zcn", "usd")
+		require.ErrorIs(t, err, ErrNoAvailableQuoteQuery)
+
+		// the outlier is kept when rejecting it leaves too few quotes
+		s = NewService(
+			&fakeProvider{name: "a", prices: map[string]float64{"usd": 1}},
+			&fakeProvider{name: "b", prices: map[string]float64{"usd": 1.02}},
+			&fakeProvider{name: "outlier", prices: map[string]float64{"usd": 2}},
+		)
+		s.MinQuotes = 3
+		q, err := s.Quote(ctx, "zcn", "usd")
+		require.NoError(t, err)
+		require.Equal(t, 1.02, q.Price)
+		require.Equal(t, []string{"a", "b", "outlier"}, q.Sources)
+	})
+
+	t.Run("no consensus", func(t *testing.T) {
+		s := NewService(
+			&fakeProvider{name: "a", prices: map[string]float64{"usd": 1}},
+			&fakeProvider{name: "b", prices: map[string]float64{"usd": 1.5}},
+		)
+		q, err := s.Quote(ctx, "zcn", "usd")
+		require.NoError(t, err)
+		require.Equal(t, 1.25, q.Price)
+		require.Equal(t, []string{"a", "b"}, q.Sources)
+	})
+}
+
+func TestServiceHistoricalPrice(t *testing.T) {
+	ctx := context.Background()
+	h := &fakeHistoricalProvider{fakeProvider{name: "h", prices: map[string]float64{"usd": 10}}}
+	s := NewService(h, &fakeProvider{name: "current", prices: map[string]float64{"usd": 1}})
+
+	at := time.Date(2023, 5, 5, 0, 0, 0, 0, time.UTC)
+	price, err := s.HistoricalPrice(ctx, "zcn", "usd", at)
+	require.NoError(t, err)
+	require.Equal(t, 2.0, price)
+
+	_, err = s.HistoricalPrice(ctx, "zcn", "usd", at.Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 1, h.calls)
+
+	t.Run("current day", func(t *testing.T) {
+		day := at.Add(48 * time.Hour)
+		now := day.Add(10 * time.Hour)
+		s.now = func() time.Time { return now }
+		_, err := s.HistoricalPrice(ctx, "zcn", "usd", day)
+		require.NoError(t, err)
+		_, err = s.HistoricalPrice(ctx, "zcn", "usd", day)
+		require.NoError(t, err)
+		require.Equal(t, 3, h.calls)
+
+		// cached once the day is over
+		now = now.Add(24 * time.Hour)
+		_, err = s.HistoricalPrice(ctx, "zcn", "usd", day)
+		require.NoError(t, err)
+		_, err = s.HistoricalPrice(ctx, "zcn", "usd", day)
+		require.NoError(t, err)
+		require.Equal(t, 4, h.calls)
+	})
+
+	_, err = NewService(&fakeProvider{name: "current"}).HistoricalPrice(ctx, "zcn", "usd", at)
+	require.ErrorIs(t, err, ErrNoHistoricalQuote)
+}
+
+func TestMedian(t *testing.T) {
+	require.Equal(t, 2.0, median([]float64{3, 1, 2}))
+	require.Equal(t, 2.5, median([]float64{4, 1, 2, 3}))
+}
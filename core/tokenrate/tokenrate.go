@@ -3,52 +3,85 @@ package tokenrate
 import (
 	"context"
 	"errors"
+	"strings"
+	"sync"
+	"time"
 )
 
 var ErrNoAvailableQuoteQuery = errors.New("token: no available quote query service")
-var quotes []quoteQuery
+
+// ErrNoHistoricalQuote no provider has the price at the given time
+var ErrNoHistoricalQuote = errors.New("token: no historical quote")
+
+// Provider quotes token prices in fiat currencies.
+type Provider interface {
+	// Name identifies the provider.
+	Name() string
+	// Price returns the price of one token in the currency, like "usd" or "eur".
+	Price(ctx context.Context, symbol, currency string) (float64, error)
+}
+
+// HistoricalProvider quotes token prices in the past.
+type HistoricalProvider interface {
+	Provider
+	// HistoricalPrice returns the price of one token in the currency at the day of at.
+	HistoricalPrice(ctx context.Context, symbol, currency string, at time.Time) (float64, error)
+}
+
+var (
+	defaultServiceMu sync.RWMutex
+	defaultService   *Service
+)
 
 func init() {
+	defaultService = NewService(DefaultProviders()...)
+}
 
-	//priority: uniswap > bancor > coingecko > coinmarketcap
-	quotes = []quoteQuery{
+// DefaultProviders returns the built-in providers.
+func DefaultProviders() []Provider {
+	return []Provider{
 		&coingeckoQuoteQuery{},
 		&bancorQuoteQuery{},
 		&uniswapQuoteQuery{},
 		createCoinmarketcapQuoteQuery(),
 		//more query services
 	}
-
 }
 
-func GetUSD(ctx context.Context, symbol string) (float64, error) {
-	var err error
-
-	ctx, cancel := context.WithCancel(ctx)
-	defer func() {
-		cancel()
-	}()
-
-	for _, q := range quotes {
-		val, err := q.getUSD(ctx, symbol)
+// Default returns the price service used by GetUSD, GetPrice and GetHistoricalPrice.
+func Default() *Service {
+	defaultServiceMu.RLock()
+	defer defaultServiceMu.RUnlock()
+	return defaultService
+}
 
-		if err != nil {
-			continue
-		}
+// SetDefault replaces the default price service, like to change its providers.
+func SetDefault(s *Service) {
+	defaultServiceMu.Lock()
+	defer defaultServiceMu.Unlock()
+	defaultService = s
+}
 
-		if val > 0 {
-			return val, nil
-		}
-	}
+// GetUSD returns the USD price of the token from the default service.
+func GetUSD(ctx context.Context, symbol string) (float64, error) {
+	return GetPrice(ctx, symbol, "usd")
+}
 
-	// All conversion APIs failed
-	if err != nil {
-		return 0, err
-	}
+// GetPrice returns the price of the token in the currency from the default service.
+func GetPrice(ctx context.Context, symbol, currency string) (float64, error) {
+	return Default().Price(ctx, symbol, currency)
+}
 
-	return 0, ErrNoAvailableQuoteQuery
+// GetHistoricalPrice returns the price of the token in the currency at the
+// day of at from the default service.
+func GetHistoricalPrice(ctx context.Context, symbol, currency string, at time.Time) (float64, error) {
+	return Default().HistoricalPrice(ctx, symbol, currency, at)
 }
 
-type quoteQuery interface {
-	getUSD(ctx context.Context, symbol string) (float64, error)
+func normalizeCurrency(currency string) string {
+	currency = strings.ToLower(strings.TrimSpace(currency))
+	if currency == "" {
+		return "usd"
+	}
+	return currency
 }
@@ -33,7 +33,15 @@ type Query struct {
 type uniswapQuoteQuery struct {
 }
 
-func (qq *uniswapQuoteQuery) getUSD(ctx context.Context, symbol string) (float64, error) {
+func (qq *uniswapQuoteQuery) Name() string {
+	return "uniswap"
+}
+
+// Price quotes USD with the USDC pair only.
+func (qq *uniswapQuoteQuery) Price(ctx context.Context, symbol, currency string) (float64, error) {
+	if normalizeCurrency(currency) != "usd" {
+		return 0, errors.New("uniswap: currency [" + currency + "] is unsupported")
+	}
 
 	hql := graphql.NewClient("https://api.thegraph.com/subgraphs/name/uniswap/uniswap-v2")
 
@@ -19,10 +19,17 @@ import (
 
 const retriesCount = 30
 
+// BlockVerifier proves that a block confirmed by sharders was finalized by the
+// chain, e.g. by checking miner signatures against a trusted magic block.
+type BlockVerifier interface {
+	VerifyBlockHeader(ctx context.Context, header *RoundBlockHeader) error
+}
+
 type OptimisticVerifier struct {
-	allSharders []string
-	sharders    []string
-	options     []resty.Option
+	allSharders   []string
+	sharders      []string
+	options       []resty.Option
+	blockVerifier BlockVerifier
 }
 
 func NewOptimisticVerifier(sharders []string) *OptimisticVerifier {
@@ -46,6 +53,12 @@ func NewOptimisticVerifier(sharders []string) *OptimisticVerifier {
 	}
 }
 
+// SetBlockVerifier makes the verifier prove the confirmation block with bv
+// instead of trusting the confirmation chain returned by sharders.
+func (v *OptimisticVerifier) SetBlockVerifier(bv BlockVerifier) {
+	v.blockVerifier = bv
+}
+
 func (v *OptimisticVerifier) VerifyTransactionOptimistic(txnHash string) (*Transaction, error) {
 	cfg, err := conf.GetClientConfig()
 	if err != nil {
@@ -159,6 +172,12 @@ L:
 		return nil, err
 	}
 
+	if v.blockVerifier != nil {
+		if err = v.blockVerifier.VerifyBlockHeader(context.TODO(), chain[0]); err != nil {
+			return nil, errors.Wrap(err, "confirmation block is not proven")
+		}
+	}
+
 	return txn, err
 }
 
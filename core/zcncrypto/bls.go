@@ -47,6 +47,8 @@ type PublicKey interface {
 	DeserializeHexStr(s string) error
 
 	Serialize() []byte
+
+	Add(rhs PublicKey)
 }
 
 type Signature interface {
@@ -56,6 +58,10 @@ type Signature interface {
 	Add(rhs Signature)
 
 	Verify(pk PublicKey, m string) bool
+
+	// Recover sets the signature to the one of the master key, interpolated
+	// from threshold signature shares made by the key shares with ids
+	Recover(sigs []Signature, ids []ID) error
 }
 
 type ID interface {
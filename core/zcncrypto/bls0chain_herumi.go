@@ -242,6 +242,10 @@ func (b0 *HerumiScheme) SetID(id string) error {
 func (b0 *HerumiScheme) GetID() string {
 	if b0.id == nil {
 		b0.id = BlsSignerInstance.NewID()
+		// restore the id of a key share loaded from json
+		if b0.Ids != "" {
+			_ = b0.id.SetHexString(b0.Ids)
+		}
 	}
 	return b0.id.GetHexString()
 }
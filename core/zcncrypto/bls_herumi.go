@@ -169,6 +169,12 @@ func (pk *herumiPublicKey) Serialize() []byte {
 	return pk.PublicKey.Serialize()
 }
 
+func (pk *herumiPublicKey) Add(rhs PublicKey) {
+	pk2, _ := rhs.(*herumiPublicKey)
+
+	pk.PublicKey.Add(pk2.PublicKey)
+}
+
 type herumiSignature struct {
 	*bls.Sign
 }
@@ -188,6 +194,29 @@ func (sg *herumiSignature) Add(rhs Signature) {
 	sg.Sign.Add(sg2.Sign)
 }
 
+func (sg *herumiSignature) Recover(sigs []Signature, ids []ID) error {
+	if len(sigs) != len(ids) {
+		return errors.New("signatures and ids count mismatch")
+	}
+
+	blsSigs := make([]bls.Sign, len(sigs))
+	blsIDs := make([]bls.ID, len(ids))
+	for i := range sigs {
+		sg2, ok := sigs[i].(*herumiSignature)
+		if !ok {
+			return errors.New("invalid herumi signature")
+		}
+		id, ok := ids[i].(*herumiID)
+		if !ok {
+			return errors.New("invalid herumi id")
+		}
+		blsSigs[i] = *sg2.Sign
+		blsIDs[i] = id.ID
+	}
+
+	return sg.Sign.Recover(blsSigs, blsIDs)
+}
+
 func (sg *herumiSignature) Verify(pk PublicKey, m string) bool {
 	pub, _ := pk.(*herumiPublicKey)
 
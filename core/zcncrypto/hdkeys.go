@@ -0,0 +1,205 @@
+package zcncrypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0chain/errors"
+	"github.com/0chain/gosdk/core/encryption"
+	"github.com/tyler-smith/go-bip39"
+	"golang.org/x/crypto/ed25519"
+)
+
+const (
+	// HardenedOffset is added to a child index to make it hardened. Only
+	// hardened derivation is supported, so every path element must be hardened.
+	HardenedOffset uint32 = 0x80000000
+
+	// MaxHardenedIndex is the largest account or index of a hardened path element.
+	MaxHardenedIndex = HardenedOffset - 1
+
+	// ZCNCoinType is the coin type of the default 0chain account path.
+	ZCNCoinType uint32 = 1337
+
+	// DefaultAccountGapLimit is how many consecutive unused accounts end the discovery in RestoreAccounts.
+	DefaultAccountGapLimit = 20
+
+	hdSeedPassword = "0chain-client-hd-key"
+
+	// hdEncryptionLabel separates the encryption seed of a node from its child keys
+	hdEncryptionLabel = "0chain encryption seed"
+)
+
+// hdMasterKeys are the HMAC keys of the master node, one per signature scheme
+// so the same mnemonic never yields related keys across schemes.
+var hdMasterKeys = map[string]string{
+	"bls0chain": "0chain bls0chain seed",
+	"ed25519":   "ed25519 seed",
+}
+
+// DerivationPath is a list of hardened child indexes, e.g. m/44'/1337'/0'/0'/0'.
+type DerivationPath []uint32
+
+// AccountPath returns the default path m/44'/1337'/account'/0'/index'.
+func AccountPath(account, index uint32) DerivationPath {
+	return DerivationPath{
+		44 + HardenedOffset,
+		ZCNCoinType + HardenedOffset,
+		account + HardenedOffset,
+		HardenedOffset,
+		index + HardenedOffset,
+	}
+}
+
+// ParseDerivationPath parses a path like "m/44'/1337'/0'/0'/1'". All elements must be hardened.
+func ParseDerivationPath(path string) (DerivationPath, error) {
+	parts := strings.Split(strings.TrimSpace(path), "/")
+	if len(parts) < 2 || parts[0] != "m" {
+		return nil, errors.New("parse_derivation_path", "path must start with m/")
+	}
+
+	var p DerivationPath
+	for _, part := range parts[1:] {
+		if !strings.HasSuffix(part, "'") && !strings.HasSuffix(part, "h") {
+			return nil, errors.New("parse_derivation_path", "only hardened derivation is supported: "+part)
+		}
+		i, err := strconv.ParseUint(part[:len(part)-1], 10, 31)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid path element "+part)
+		}
+		p = append(p, uint32(i)+HardenedOffset)
+	}
+	return p, nil
+}
+
+// String returns the path in m/44'/... notation.
+func (p DerivationPath) String() string {
+	var sb strings.Builder
+	sb.WriteString("m")
+	for _, i := range p {
+		fmt.Fprintf(&sb, "/%d'", i-HardenedOffset)
+	}
+	return sb.String()
+}
+
+// DerivedAccount is a wallet derived from a mnemonic and the path it was derived at.
+type DerivedAccount struct {
+	Path   string  `json:"path"`
+	Index  uint32  `json:"index"`
+	Wallet *Wallet `json:"wallet"`
+}
+
+// DeriveKeys derives the key pair at path from mnemonic for the signature
+// scheme. Child keys are derived SLIP-0010 style with HMAC-SHA512 and hardened
+// indexes only, so a leaked child key does not expose its siblings or the seed.
+// The wallet does not carry the mnemonic: it keeps its DerivationPath and an
+// EncryptionSeed derived from the child node, so the encryption keys of each
+// child differ and reveal nothing about the others.
+func DeriveKeys(scheme, mnemonic string, path DerivationPath) (*Wallet, error) {
+	masterKey, ok := hdMasterKeys[scheme]
+	if !ok {
+		return nil, errors.New("derive_keys", "unsupported signature scheme "+scheme)
+	}
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, hdSeedPassword)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid mnemonic")
+	}
+
+	key, chainCode := hdNode([]byte(masterKey), seed)
+	for _, i := range path {
+		if i < HardenedOffset {
+			return nil, errors.New("derive_keys", "only hardened derivation is supported")
+		}
+		data := make([]byte, 0, 37)
+		data = append(data, 0)
+		data = append(data, key...)
+		data = binary.BigEndian.AppendUint32(data, i)
+		key, chainCode = hdNode(chainCode, data)
+	}
+
+	w := &Wallet{Keys: make([]KeyPair, 1)}
+	switch scheme {
+	case "ed25519":
+		private := ed25519.NewKeyFromSeed(key)
+		public := private.Public().(ed25519.PublicKey)
+		w.Keys[0].PrivateKey = hex.EncodeToString(private)
+		w.Keys[0].PublicKey = hex.EncodeToString(public)
+		w.ClientID = encryption.Hash([]byte(public))
+	default:
+		if BlsSignerInstance == nil {
+			return nil, errors.New("derive_keys", "bls is not supported on this platform")
+		}
+		sk := BlsSignerInstance.NewSecretKey()
+		if err = sk.SetLittleEndian(key); err != nil {
+			return nil, err
+		}
+		pub := sk.GetPublicKey()
+		w.Keys[0].PrivateKey = sk.SerializeToHexStr()
+		w.Keys[0].PublicKey = pub.SerializeToHexStr()
+		w.ClientID = encryption.Hash(pub.Serialize())
+	}
+	w.ClientKey = w.Keys[0].PublicKey
+	encryptionSeed, _ := hdNode(chainCode, append([]byte(hdEncryptionLabel), key...))
+	w.EncryptionSeed = hex.EncodeToString(encryptionSeed)
+	w.DerivationPath = path.String()
+	w.Version = CryptoVersion
+	w.DateCreated = time.Now().Format(time.RFC3339)
+	return w, nil
+}
+
+// DeriveAccounts derives count consecutive wallets of account starting at index start.
+func DeriveAccounts(scheme, mnemonic string, account, start, count uint32) ([]*DerivedAccount, error) {
+	if account > MaxHardenedIndex || start > MaxHardenedIndex || count > MaxHardenedIndex-start+1 {
+		return nil, errors.New("derive_keys", "account or index out of the hardened range")
+	}
+	list := make([]*DerivedAccount, 0, count)
+	for i := start; i < start+count; i++ {
+		path := AccountPath(account, i)
+		w, err := DeriveKeys(scheme, mnemonic, path)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, &DerivedAccount{Path: path.String(), Index: i, Wallet: w})
+	}
+	return list, nil
+}
+
+// RestoreAccounts derives the wallets of account in order and returns every
+// wallet isUsed reports as used, stopping after gapLimit consecutive unused ones.
+func RestoreAccounts(scheme, mnemonic string, account uint32, gapLimit int, isUsed func(w *Wallet) (bool, error)) ([]*DerivedAccount, error) {
+	if gapLimit <= 0 {
+		gapLimit = DefaultAccountGapLimit
+	}
+
+	var used []*DerivedAccount
+	for i, gap := uint32(0), 0; gap < gapLimit && i <= MaxHardenedIndex; i++ {
+		list, err := DeriveAccounts(scheme, mnemonic, account, i, 1)
+		if err != nil {
+			return nil, err
+		}
+		ok, err := isUsed(list[0].Wallet)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			gap++
+			continue
+		}
+		gap = 0
+		used = append(used, list[0])
+	}
+	return used, nil
+}
+
+func hdNode(key, data []byte) ([]byte, []byte) {
+	mac := hmac.New(sha512.New, key)
+	mac.Write(data)
+	i := mac.Sum(nil)
+	return i[:32], i[32:]
+}
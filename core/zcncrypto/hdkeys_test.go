@@ -0,0 +1,108 @@
+package zcncrypto
+
+import (
+	"testing"
+
+	"github.com/0chain/gosdk/core/encryption"
+	"github.com/stretchr/testify/require"
+)
+
+const hdTestMnemonic = "glare mistake gun joke bid spare across diagram wrap cube swear cactus cave repeat you brave few best wild lion pitch pole original wasp"
+
+func TestDerivationPath(t *testing.T) {
+	p, err := ParseDerivationPath("m/44'/1337'/2'/0'/5'")
+	require.NoError(t, err)
+	require.Equal(t, AccountPath(2, 5), p)
+	require.Equal(t, "m/44'/1337'/2'/0'/5'", p.String())
+
+	_, err = ParseDerivationPath("m/44'/1337'/2/0'/5'")
+	require.Error(t, err)
+	_, err = ParseDerivationPath("44'/1337'")
+	require.Error(t, err)
+}
+
+func TestDeriveKeys(t *testing.T) {
+	for _, scheme := range []string{"bls0chain", "ed25519"} {
+		t.Run(scheme, func(t *testing.T) {
+			w1, err := DeriveKeys(scheme, hdTestMnemonic, AccountPath(0, 1))
+			require.NoError(t, err)
+			again, err := DeriveKeys(scheme, hdTestMnemonic, AccountPath(0, 1))
+			require.NoError(t, err)
+			require.Equal(t, w1.Keys, again.Keys)
+			require.Equal(t, w1.ClientID, again.ClientID)
+
+			w2, err := DeriveKeys(scheme, hdTestMnemonic, AccountPath(0, 2))
+			require.NoError(t, err)
+			require.NotEqual(t, w1.ClientID, w2.ClientID)
+
+			other, err := DeriveKeys(scheme, hdTestMnemonic, AccountPath(1, 1))
+			require.NoError(t, err)
+			require.NotEqual(t, w1.ClientID, other.ClientID)
+
+			// derived keys sign like any other wallet
+			hash := encryption.Hash("data")
+			sig, err := w1.Sign(hash, scheme)
+			require.NoError(t, err)
+			ss := NewSignatureScheme(scheme)
+			require.NoError(t, ss.SetPublicKey(w1.ClientKey))
+			ok, err := ss.Verify(sig, hash)
+			require.NoError(t, err)
+			require.True(t, ok)
+		})
+	}
+
+	_, err := DeriveKeys("bls0chain", "not a mnemonic", AccountPath(0, 0))
+	require.Error(t, err)
+}
+
+func TestRestoreAccounts(t *testing.T) {
+	derived, err := DeriveAccounts("bls0chain", hdTestMnemonic, 0, 0, 6)
+	require.NoError(t, err)
+
+	used := map[string]bool{
+		derived[0].Wallet.ClientID: true,
+		derived[3].Wallet.ClientID: true,
+	}
+	restored, err := RestoreAccounts("bls0chain", hdTestMnemonic, 0, 3, func(w *Wallet) (bool, error) {
+		return used[w.ClientID], nil
+	})
+	require.NoError(t, err)
+	require.Len(t, restored, 2)
+	require.Equal(t, uint32(0), restored[0].Index)
+	require.Equal(t, uint32(3), restored[1].Index)
+	require.Equal(t, derived[3].Wallet.Keys, restored[1].Wallet.Keys)
+}
+
+func TestDeriveAccountsRange(t *testing.T) {
+	_, err := DeriveAccounts("bls0chain", hdTestMnemonic, MaxHardenedIndex+1, 0, 1)
+	require.Error(t, err)
+	_, err = DeriveAccounts("bls0chain", hdTestMnemonic, 0, MaxHardenedIndex, 2)
+	require.Error(t, err)
+
+	derived, err := DeriveAccounts("bls0chain", hdTestMnemonic, MaxHardenedIndex, MaxHardenedIndex, 1)
+	require.NoError(t, err)
+	require.Equal(t, "m/44'/1337'/2147483647'/0'/2147483647'", derived[0].Path)
+}
+
+func TestDeriveKeysSecrets(t *testing.T) {
+	w1, err := DeriveKeys("bls0chain", hdTestMnemonic, AccountPath(0, 1))
+	require.NoError(t, err)
+	w2, err := DeriveKeys("bls0chain", hdTestMnemonic, AccountPath(0, 2))
+	require.NoError(t, err)
+
+	// children never carry the seed of their siblings
+	require.Empty(t, w1.Mnemonic)
+	require.Equal(t, "m/44'/1337'/0'/0'/1'", w1.DerivationPath)
+
+	buf, err := w1.Marshal()
+	require.NoError(t, err)
+	require.NotContains(t, buf, hdTestMnemonic)
+
+	require.Len(t, w1.EncryptionSeed, 64)
+	require.NotEqual(t, w1.EncryptionSeed, w2.EncryptionSeed)
+	require.NotEqual(t, w1.EncryptionSeed, w1.Keys[0].PrivateKey)
+
+	again, err := DeriveKeys("bls0chain", hdTestMnemonic, AccountPath(0, 1))
+	require.NoError(t, err)
+	require.Equal(t, w1.EncryptionSeed, again.EncryptionSeed)
+}
@@ -25,6 +25,12 @@ type Wallet struct {
 	Version     string    `json:"version"`
 	DateCreated string    `json:"date_created"`
 	Nonce       int64     `json:"nonce"`
+	// DerivationPath is the path of a wallet derived with DeriveKeys, which
+	// carries no mnemonic
+	DerivationPath string `json:"derivation_path,omitempty"`
+	// EncryptionSeed seeds the proxy re-encryption keys of a derived wallet in
+	// place of the mnemonic
+	EncryptionSeed string `json:"encryption_seed,omitempty"`
 }
 
 // SignatureScheme - an encryption scheme for signing and verifying messages
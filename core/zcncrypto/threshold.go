@@ -0,0 +1,38 @@
+package zcncrypto
+
+import (
+	"github.com/0chain/errors"
+)
+
+// RecoverThresholdSignature interpolates the group signature from signature
+// shares made with keys from GenerateThresholdKeyShares. The shares are keyed by
+// the hex id of the key share that made them; at least T of them are needed.
+func RecoverThresholdSignature(shares map[string]string) (string, error) {
+	if BlsSignerInstance == nil {
+		return "", errors.New("recover_threshold_signature", "bls is not supported on this platform")
+	}
+	if len(shares) == 0 {
+		return "", errors.New("recover_threshold_signature", "no signature shares")
+	}
+
+	sigs := make([]Signature, 0, len(shares))
+	ids := make([]ID, 0, len(shares))
+	for id, share := range shares {
+		blsID := BlsSignerInstance.NewID()
+		if err := blsID.SetHexString(id); err != nil {
+			return "", errors.Wrap(err, "invalid share id "+id)
+		}
+		sig := BlsSignerInstance.NewSignature()
+		if err := sig.DeserializeHexStr(share); err != nil {
+			return "", errors.Wrap(err, "invalid signature share of "+id)
+		}
+		ids = append(ids, blsID)
+		sigs = append(sigs, sig)
+	}
+
+	group := BlsSignerInstance.NewSignature()
+	if err := group.Recover(sigs, ids); err != nil {
+		return "", err
+	}
+	return group.SerializeToHexStr(), nil
+}
@@ -7,7 +7,6 @@ import (
 	"github.com/0chain/errors"
 	"github.com/0chain/gosdk/constants"
 	"github.com/0chain/gosdk/core/encryption"
-	"github.com/0chain/gosdk/core/sys"
 	"github.com/0chain/gosdk/zboxcore/client"
 )
 
@@ -41,7 +40,7 @@ func (c *Client) SignRequest(req *http.Request, allocation string) error {
 	req.Header.Set("X-App-Client-ID", c.ClientID)
 	req.Header.Set("X-App-Client-Key", c.ClientPublicKey)
 
-	sign, err := sys.Sign(encryption.Hash(allocation), client.GetClient().SignatureScheme, client.GetClientSysKeys())
+	sign, err := client.Sign(encryption.Hash(allocation))
 	if err != nil {
 		return err
 	}
@@ -174,6 +174,30 @@ func getNotProcessedZCNBurnTickets() string {
 	return string(result)
 }
 
+// Recovers burns on both chains which were never minted. With complete set the burns are minted,
+// otherwise they are only reported.
+func recoverBridgeBurns(complete bool, timeout int) string { //nolint
+	if bridge == nil {
+		return errors.New("recoverBridgeBurns", "bridge is not initialized").Error()
+	}
+
+	c, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	burns, err := bridge.RecoverBurns(c, complete)
+	if err != nil {
+		return errors.Wrap("recoverBridgeBurns", "failed to recover burns", err).Error()
+	}
+
+	var result []byte
+	result, err = json.Marshal(burns)
+	if err != nil {
+		return errors.Wrap("recoverBridgeBurns", "failed to marshal recovered burns", err).Error()
+	}
+
+	return string(result)
+}
+
 // estimateBurnWZCNGasAmount performs gas amount estimation for the given burn wzcn transaction.
 func estimateBurnWZCNGasAmount(from, to string, amountTokens int) string { // nolint:golint,unused
 	estimateBurnWZCNGasAmountResponse, err := bridge.EstimateBurnWZCNGasAmount(
@@ -159,6 +159,7 @@ func main() {
 				"hideLogs":               hideLogs,
 				"showLogs":               showLogs,
 				"getUSDRate":             getUSDRate,
+				"getRate":                getRate,
 				"isWalletID":             isWalletID,
 				"getVersion":             getVersion,
 				"getLookupHash":          getLookupHash,
@@ -239,6 +240,7 @@ func main() {
 				"getMintWZCNPayload":            getMintWZCNPayload,
 				"getNotProcessedWZCNBurnEvents": getNotProcessedWZCNBurnEvents,
 				"getNotProcessedZCNBurnTickets": getNotProcessedZCNBurnTickets,
+				"recoverBridgeBurns":            recoverBridgeBurns,
 				"estimateBurnWZCNGasAmount":     estimateBurnWZCNGasAmount,
 				"estimateMintWZCNGasAmount":     estimateMintWZCNGasAmount,
 				"estimateGasPrice":              estimateGasPrice,
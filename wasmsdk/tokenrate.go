@@ -9,3 +9,7 @@ import (
 func getUSDRate(symbol string) (float64, error) {
 	return tokenrate.GetUSD(context.TODO(), symbol)
 }
+
+func getRate(symbol, currency string) (float64, error) {
+	return tokenrate.GetPrice(context.TODO(), symbol, currency)
+}
@@ -1,9 +1,6 @@
 package main
 
 import (
-	"encoding/base64"
-	"encoding/json"
-	"errors"
 	"time"
 
 	"github.com/0chain/gosdk/zboxcore/marker"
@@ -54,17 +51,20 @@ func getAllocation(allocationID string) (*sdk.Allocation, error) {
 
 func getAllocationWith(authTicket string) (*sdk.Allocation, *marker.AuthTicket, error) {
 
-	sEnc, err := base64.StdEncoding.DecodeString(authTicket)
+	at, err := marker.ParseAuthTicket(authTicket)
 	if err != nil {
-		return nil, nil, errors.New("Error decoding the auth ticket." + err.Error())
+		return nil, nil, err
 	}
-	at := &marker.AuthTicket{}
-	err = json.Unmarshal(sEnc, at)
+	alloc, err := getAllocation(at.AllocationID)
 	if err != nil {
-		return nil, nil, errors.New("Error unmarshaling the auth ticket." + err.Error())
+		return nil, nil, err
 	}
-	alloc, err := getAllocation(at.AllocationID)
-	return alloc, at, err
+	if alloc.OwnerPublicKey != "" {
+		if err := at.VerifySignature(alloc.OwnerPublicKey); err != nil {
+			return nil, nil, err
+		}
+	}
+	return alloc, at, nil
 }
 
 func getFileMeta(allocationID, remotePath string) (*sdk.ConsolidatedFileMeta, error) {
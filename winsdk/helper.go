@@ -7,7 +7,6 @@ import (
 	"C"
 )
 import (
-	"encoding/base64"
 	"encoding/json"
 	"os"
 	"path/filepath"
@@ -59,15 +58,6 @@ func getLookupHash(allocationID, path string) string {
 
 func decodeAuthTicket(authTicket *C.char) (*marker.AuthTicket, string, error) {
 	at := C.GoString(authTicket)
-	buf, err := base64.StdEncoding.DecodeString(at)
-	if err != nil {
-		return nil, at, err
-	}
-	t := &marker.AuthTicket{}
-	err = json.Unmarshal(buf, t)
-	if err != nil {
-		return nil, at, err
-	}
-
+	t, err := marker.ParseAuthTicket(at)
 	return t, at, err
 }
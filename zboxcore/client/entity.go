@@ -3,6 +3,7 @@ package client
 import (
 	"encoding/json"
 
+	"github.com/0chain/gosdk/core/signer"
 	"github.com/0chain/gosdk/core/sys"
 	"github.com/0chain/gosdk/core/zcncrypto"
 )
@@ -28,9 +29,7 @@ func init() {
 
 	sys.Sign = signHash
 	// initialize SignFunc as default implementation
-	Sign = func(hash string) (string, error) {
-		return sys.Sign(hash, client.SignatureScheme, GetClientSysKeys())
-	}
+	Sign = signWithClientKeys
 
 	sys.Verify = VerifySignature
 	sys.VerifyWith = VerifySignatureWith
@@ -40,9 +39,29 @@ func init() {
 func PopulateClient(clientjson string, signatureScheme string) error {
 	err := json.Unmarshal([]byte(clientjson), &client)
 	client.SignatureScheme = signatureScheme
+	Sign = signWithClientKeys
 	return err
 }
 
+// SetSigner makes the client sign write markers, read markers, auth tickets
+// and transactions with s instead of in-process keys. The client id and key
+// are taken from the signer's public key.
+func SetSigner(s signer.Signer) error {
+	clientID, err := signer.ClientID(s)
+	if err != nil {
+		return err
+	}
+
+	client.Wallet = &zcncrypto.Wallet{
+		ClientID:  clientID,
+		ClientKey: s.PublicKey(),
+		Version:   zcncrypto.CryptoVersion,
+	}
+	client.SignatureScheme = s.Scheme()
+	Sign = s.Sign
+	return nil
+}
+
 func SetClientNonce(nonce int64) {
 	client.Nonce = nonce
 }
@@ -109,6 +128,10 @@ func GetClientSysKeys() []sys.KeyPair {
 	return keys
 }
 
+func signWithClientKeys(hash string) (string, error) {
+	return sys.Sign(hash, client.SignatureScheme, GetClientSysKeys())
+}
+
 func signHash(hash string, signatureScheme string, keys []sys.KeyPair) (string, error) {
 	retSignature := ""
 	for _, kv := range keys {
@@ -0,0 +1,113 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	// DataKeySize is the size of the AES-256 data keys of files.
+	DataKeySize = 32
+	// GCMOverhead is the size added to each encrypted chunk, the GCM tag.
+	GCMOverhead = 16
+)
+
+// Chunk contents, part of the nonces so that file and thumbnail chunks never share one.
+const (
+	ContentFile byte = iota
+	ContentThumbnail
+)
+
+var ErrInvalidDataKey = errors.New("encryption: invalid data key")
+
+// NewDataKey generates a random data key.
+func NewDataKey() ([]byte, error) {
+	key := make([]byte, DataKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// ChunkCipher encrypts the chunks of a file with AES-256-GCM. Every chunk of
+// every blobber is sealed independently with a nonce derived from its
+// position, so chunks can be decrypted in any order.
+type ChunkCipher struct {
+	aead cipher.AEAD
+}
+
+// NewChunkCipher creates the chunk cipher of the data key.
+func NewChunkCipher(dataKey []byte) (*ChunkCipher, error) {
+	if len(dataKey) != DataKeySize {
+		return nil, ErrInvalidDataKey
+	}
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &ChunkCipher{aead: aead}, nil
+}
+
+// chunkNonce is content(1) | blobber position(3) | chunk index(8)
+func chunkNonce(content byte, pos int, index int64) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint32(nonce[:4], uint32(pos))
+	nonce[0] = content
+	binary.BigEndian.PutUint64(nonce[4:], uint64(index))
+	return nonce
+}
+
+// Seal encrypts the chunk of the blobber at position pos. The result is
+// GCMOverhead bytes longer than data.
+func (c *ChunkCipher) Seal(content byte, pos int, index int64, data []byte) []byte {
+	return c.aead.Seal(nil, chunkNonce(content, pos, index), data, nil)
+}
+
+// Open decrypts and authenticates the chunk of the blobber at position pos.
+func (c *ChunkCipher) Open(content byte, pos int, index int64, data []byte) ([]byte, error) {
+	return c.aead.Open(nil, chunkNonce(content, pos, index), data, nil)
+}
+
+// SealWithKey encrypts the data with AES-256-GCM and a random nonce, which
+// prefixes the result.
+func SealWithKey(key, data []byte) ([]byte, error) {
+	aead, err := newKeyAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(data)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, data, nil), nil
+}
+
+// OpenWithKey decrypts the data of SealWithKey.
+func OpenWithKey(key, sealed []byte) ([]byte, error) {
+	aead, err := newKeyAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize()+aead.Overhead() {
+		return nil, ErrInvalidSealed
+	}
+	return aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+}
+
+func newKeyAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != DataKeySize {
+		return nil, ErrInvalidDataKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,68 @@
+package encryption
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunkCipher(t *testing.T) {
+	_, err := NewChunkCipher([]byte("short"))
+	require.ErrorIs(t, err, ErrInvalidDataKey)
+
+	key, err := NewDataKey()
+	require.NoError(t, err)
+	c, err := NewChunkCipher(key)
+	require.NoError(t, err)
+
+	data := []byte("chunk data")
+	sealed := c.Seal(ContentFile, 1, 7, data)
+	require.Len(t, sealed, len(data)+GCMOverhead)
+
+	opened, err := c.Open(ContentFile, 1, 7, sealed)
+	require.NoError(t, err)
+	require.Equal(t, data, opened)
+
+	t.Run("wrong position", func(t *testing.T) {
+		_, err := c.Open(ContentFile, 1, 8, sealed)
+		require.Error(t, err)
+		_, err = c.Open(ContentFile, 2, 7, sealed)
+		require.Error(t, err)
+		_, err = c.Open(ContentThumbnail, 1, 7, sealed)
+		require.Error(t, err)
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		tampered := append([]byte(nil), sealed...)
+		tampered[0] ^= 1
+		_, err := c.Open(ContentFile, 1, 7, tampered)
+		require.Error(t, err)
+	})
+
+	t.Run("other key", func(t *testing.T) {
+		other, err := NewDataKey()
+		require.NoError(t, err)
+		oc, err := NewChunkCipher(other)
+		require.NoError(t, err)
+		_, err = oc.Open(ContentFile, 1, 7, sealed)
+		require.Error(t, err)
+	})
+}
+
+func TestSealWithKey(t *testing.T) {
+	key, err := NewDataKey()
+	require.NoError(t, err)
+
+	sealed, err := SealWithKey(key, []byte("ticket"))
+	require.NoError(t, err)
+	opened, err := OpenWithKey(key, sealed)
+	require.NoError(t, err)
+	require.Equal(t, []byte("ticket"), opened)
+
+	other, err := NewDataKey()
+	require.NoError(t, err)
+	_, err = OpenWithKey(other, sealed)
+	require.Error(t, err)
+	_, err = OpenWithKey(key, sealed[:10])
+	require.ErrorIs(t, err, ErrInvalidSealed)
+}
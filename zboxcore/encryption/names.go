@@ -0,0 +1,138 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"io"
+	"path"
+	"strings"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	// NameKeySize is the size of the keys of name ciphers.
+	NameKeySize = 32
+	// MaxEncryptedNameSize is the longest encrypted name blobbers accept.
+	MaxEncryptedNameSize = 255
+
+	nameIVSize = 16
+)
+
+var (
+	ErrInvalidNameKey       = errors.New("encryption: invalid name key")
+	ErrInvalidEncryptedName = errors.New("encryption: invalid encrypted name")
+	ErrNameTooLong          = errors.New("encryption: encrypted name is too long")
+)
+
+// NameCipher encrypts the names of files and directories deterministically,
+// so that a path always has the same encrypted path and its lookup hash can
+// be computed on the client. It is a SIV construction: the IV is the HMAC of
+// the parent path and the name, and the name is encrypted with AES-CTR.
+// Equal names in different directories have different encrypted names, so
+// the descendants of renamed, moved or copied directories have to be
+// encrypted again for their new parents.
+type NameCipher struct {
+	block  cipher.Block
+	macKey []byte
+}
+
+// NewNameCipher creates the name cipher of the key.
+func NewNameCipher(key []byte) (*NameCipher, error) {
+	if len(key) != NameKeySize {
+		return nil, ErrInvalidNameKey
+	}
+	keys := make([]byte, 2*NameKeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, key, nil, []byte("name cipher")), keys); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(keys[:NameKeySize])
+	if err != nil {
+		return nil, err
+	}
+	return &NameCipher{block: block, macKey: keys[NameKeySize:]}, nil
+}
+
+func (c *NameCipher) iv(parent, name string) []byte {
+	mac := hmac.New(sha256.New, c.macKey)
+	mac.Write([]byte(parent))
+	mac.Write([]byte{0})
+	mac.Write([]byte(name))
+	return mac.Sum(nil)[:nameIVSize]
+}
+
+// EncryptName encrypts the name of the directory parent.
+func (c *NameCipher) EncryptName(parent, name string) (string, error) {
+	iv := c.iv(parent, name)
+	buf := make([]byte, nameIVSize+len(name))
+	copy(buf, iv)
+	cipher.NewCTR(c.block, iv).XORKeyStream(buf[nameIVSize:], []byte(name))
+
+	encrypted := base64.RawURLEncoding.EncodeToString(buf)
+	if len(encrypted) > MaxEncryptedNameSize {
+		return "", ErrNameTooLong
+	}
+	return encrypted, nil
+}
+
+// DecryptName decrypts and authenticates the encrypted name of the directory parent.
+func (c *NameCipher) DecryptName(parent, encrypted string) (string, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(encrypted)
+	if err != nil || len(buf) <= nameIVSize {
+		return "", ErrInvalidEncryptedName
+	}
+	iv := buf[:nameIVSize]
+	name := make([]byte, len(buf)-nameIVSize)
+	cipher.NewCTR(c.block, iv).XORKeyStream(name, buf[nameIVSize:])
+
+	if subtle.ConstantTimeCompare(iv, c.iv(parent, string(name))) != 1 {
+		return "", ErrInvalidEncryptedName
+	}
+	return string(name), nil
+}
+
+// EncryptPath encrypts every name of the absolute path p.
+func (c *NameCipher) EncryptPath(p string) (string, error) {
+	var parent, encrypted string
+	for _, name := range splitPath(p) {
+		encName, err := c.EncryptName(parent, name)
+		if err != nil {
+			return "", err
+		}
+		parent += "/" + name
+		encrypted += "/" + encName
+	}
+	if encrypted == "" {
+		return "/", nil
+	}
+	return encrypted, nil
+}
+
+// DecryptPath decrypts every name of the encrypted absolute path p.
+func (c *NameCipher) DecryptPath(p string) (string, error) {
+	var parent string
+	for _, encName := range splitPath(p) {
+		name, err := c.DecryptName(parent, encName)
+		if err != nil {
+			return "", err
+		}
+		parent += "/" + name
+	}
+	if parent == "" {
+		return "/", nil
+	}
+	return parent, nil
+}
+
+func splitPath(p string) []string {
+	p = strings.Trim(path.Clean("/"+p), "/")
+	if p == "" {
+		return nil
+	}
+	return strings.Split(p, "/")
+}
@@ -0,0 +1,84 @@
+package encryption
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNameCipher(t *testing.T) {
+	_, err := NewNameCipher([]byte("short"))
+	require.ErrorIs(t, err, ErrInvalidNameKey)
+
+	key := make([]byte, NameKeySize)
+	c, err := NewNameCipher(key)
+	require.NoError(t, err)
+
+	encrypted, err := c.EncryptPath("/docs/report.pdf")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(encrypted, "/"))
+	require.NotContains(t, encrypted, "docs")
+	require.NotContains(t, encrypted, "report")
+
+	again, err := c.EncryptPath("/docs/report.pdf")
+	require.NoError(t, err)
+	require.Equal(t, encrypted, again)
+
+	plain, err := c.DecryptPath(encrypted)
+	require.NoError(t, err)
+	require.Equal(t, "/docs/report.pdf", plain)
+
+	t.Run("root", func(t *testing.T) {
+		root, err := c.EncryptPath("/")
+		require.NoError(t, err)
+		require.Equal(t, "/", root)
+		root, err = c.DecryptPath("/")
+		require.NoError(t, err)
+		require.Equal(t, "/", root)
+	})
+
+	t.Run("same name in other directory", func(t *testing.T) {
+		a, err := c.EncryptName("/a", "file")
+		require.NoError(t, err)
+		b, err := c.EncryptName("/b", "file")
+		require.NoError(t, err)
+		require.NotEqual(t, a, b)
+	})
+
+	t.Run("wrong parent", func(t *testing.T) {
+		name, err := c.EncryptName("/a", "file")
+		require.NoError(t, err)
+		_, err = c.DecryptName("/b", name)
+		require.ErrorIs(t, err, ErrInvalidEncryptedName)
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		name, err := c.EncryptName("", "file")
+		require.NoError(t, err)
+		tampered := []byte(name)
+		if tampered[len(tampered)-1] == 'A' {
+			tampered[len(tampered)-1] = 'B'
+		} else {
+			tampered[len(tampered)-1] = 'A'
+		}
+		_, err = c.DecryptName("", string(tampered))
+		require.Error(t, err)
+		_, err = c.DecryptName("", "file")
+		require.ErrorIs(t, err, ErrInvalidEncryptedName)
+	})
+
+	t.Run("other key", func(t *testing.T) {
+		otherKey := make([]byte, NameKeySize)
+		otherKey[0] = 1
+		oc, err := NewNameCipher(otherKey)
+		require.NoError(t, err)
+		_, err = oc.DecryptPath(encrypted)
+		require.Error(t, err)
+	})
+
+	t.Run("too long", func(t *testing.T) {
+		_, err := c.EncryptName("", strings.Repeat("a", MaxEncryptedNameSize))
+		require.ErrorIs(t, err, ErrNameTooLong)
+	})
+}
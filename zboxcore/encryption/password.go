@@ -0,0 +1,54 @@
+package encryption
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/0chain/gosdk/core/keystore"
+	"golang.org/x/crypto/scrypt"
+)
+
+var (
+	ErrEmptyPassword     = errors.New("encryption: empty password")
+	ErrInvalidSealed     = errors.New("encryption: invalid password sealed data")
+	ErrIncorrectPassword = errors.New("encryption: incorrect password or corrupted data")
+)
+
+// DerivePasswordKey derives a key of size bytes from the password and the
+// salt with scrypt, with the cost parameters of the keystore.
+func DerivePasswordKey(password string, salt []byte, size int) ([]byte, error) {
+	if password == "" {
+		return nil, ErrEmptyPassword
+	}
+	params := keystore.DefaultKDFParams()
+	return scrypt.Key([]byte(password), salt, params.N, params.R, params.P, size)
+}
+
+// SealWithPassword encrypts the data like the keystore encrypts wallets, see
+// keystore.Seal. The result is JSON with the parameters to open it.
+func SealWithPassword(data []byte, password string) ([]byte, error) {
+	if password == "" {
+		return nil, ErrEmptyPassword
+	}
+	s, err := keystore.Seal(data, password, nil)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(s)
+}
+
+// OpenWithPassword decrypts the data of SealWithPassword.
+func OpenWithPassword(sealed []byte, password string) ([]byte, error) {
+	s := &keystore.Sealed{}
+	if err := json.Unmarshal(sealed, s); err != nil {
+		return nil, ErrInvalidSealed
+	}
+	data, err := s.Open(password, nil)
+	if errors.Is(err, keystore.ErrInvalidPassphrase) {
+		return nil, ErrIncorrectPassword
+	}
+	if err != nil {
+		return nil, ErrInvalidSealed
+	}
+	return data, nil
+}
@@ -0,0 +1,28 @@
+package encryption
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealWithPassword(t *testing.T) {
+	data := []byte("private key")
+
+	_, err := SealWithPassword(data, "")
+	require.ErrorIs(t, err, ErrEmptyPassword)
+
+	sealed, err := SealWithPassword(data, "correct horse")
+	require.NoError(t, err)
+	require.NotContains(t, string(sealed), "private key")
+
+	opened, err := OpenWithPassword(sealed, "correct horse")
+	require.NoError(t, err)
+	require.Equal(t, data, opened)
+
+	_, err = OpenWithPassword(sealed, "battery staple")
+	require.ErrorIs(t, err, ErrIncorrectPassword)
+
+	_, err = OpenWithPassword([]byte("{}"), "correct horse")
+	require.ErrorIs(t, err, ErrInvalidSealed)
+}
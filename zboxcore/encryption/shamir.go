@@ -0,0 +1,147 @@
+package encryption
+
+import (
+	"crypto/rand"
+	"errors"
+)
+
+// MaxSecretShares is the most shares a secret can be split into.
+const MaxSecretShares = 255
+
+var (
+	ErrInvalidThreshold = errors.New("encryption: threshold must be between 2 and the number of shares")
+	ErrInvalidShares    = errors.New("encryption: invalid secret shares")
+	ErrDuplicateShare   = errors.New("encryption: duplicate secret share")
+	ErrEmptySecret      = errors.New("encryption: empty secret")
+	ErrTooManyShares    = errors.New("encryption: too many secret shares")
+)
+
+// SplitSecret splits the secret with Shamir's secret sharing over GF(256)
+// into n shares, any threshold of which recover it with CombineShares.
+// Every share is one byte longer than the secret, its last byte is the x
+// coordinate of the share.
+func SplitSecret(secret []byte, n, threshold int) ([][]byte, error) {
+	if len(secret) == 0 {
+		return nil, ErrEmptySecret
+	}
+	if n > MaxSecretShares {
+		return nil, ErrTooManyShares
+	}
+	if threshold < 2 || threshold > n {
+		return nil, ErrInvalidThreshold
+	}
+
+	shares := make([][]byte, n)
+	for i := range shares {
+		shares[i] = make([]byte, len(secret)+1)
+		shares[i][len(secret)] = byte(i + 1)
+	}
+
+	// a random polynomial of degree threshold-1 per byte, the secret byte is its constant
+	coefficients := make([]byte, threshold)
+	for j, b := range secret {
+		if _, err := rand.Read(coefficients[1:]); err != nil {
+			return nil, err
+		}
+		coefficients[0] = b
+		for _, share := range shares {
+			share[j] = gfEval(coefficients, share[len(secret)])
+		}
+	}
+	return shares, nil
+}
+
+// CombineShares recovers the secret of at least threshold shares of SplitSecret.
+// Fewer shares give a wrong secret, which can't be detected here.
+func CombineShares(shares [][]byte) ([]byte, error) {
+	if len(shares) < 2 {
+		return nil, ErrInvalidShares
+	}
+	size := len(shares[0])
+	if size < 2 {
+		return nil, ErrInvalidShares
+	}
+
+	xs := make([]byte, len(shares))
+	seen := make(map[byte]bool, len(shares))
+	for i, share := range shares {
+		if len(share) != size {
+			return nil, ErrInvalidShares
+		}
+		x := share[size-1]
+		if x == 0 {
+			return nil, ErrInvalidShares
+		}
+		if seen[x] {
+			return nil, ErrDuplicateShare
+		}
+		seen[x] = true
+		xs[i] = x
+	}
+
+	// Lagrange interpolation at x = 0
+	secret := make([]byte, size-1)
+	ys := make([]byte, len(shares))
+	for j := range secret {
+		for i, share := range shares {
+			ys[i] = share[j]
+		}
+		secret[j] = gfInterpolate(xs, ys)
+	}
+	return secret, nil
+}
+
+func gfEval(coefficients []byte, x byte) byte {
+	var y byte
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		y = gfMul(y, x) ^ coefficients[i]
+	}
+	return y
+}
+
+func gfInterpolate(xs, ys []byte) byte {
+	var y byte
+	for i := range xs {
+		basis := byte(1)
+		for k := range xs {
+			if k == i {
+				continue
+			}
+			// x_k / (x_k - x_i), subtraction is xor
+			basis = gfMul(basis, gfDiv(xs[k], xs[k]^xs[i]))
+		}
+		y ^= gfMul(ys[i], basis)
+	}
+	return y
+}
+
+// gfMul multiplies in GF(256) with the AES polynomial.
+func gfMul(a, b byte) byte {
+	var p byte
+	for b > 0 {
+		if b&1 == 1 {
+			p ^= a
+		}
+		hi := a & 0x80
+		a <<= 1
+		if hi != 0 {
+			a ^= 0x1b
+		}
+		b >>= 1
+	}
+	return p
+}
+
+func gfInv(a byte) byte {
+	// a^254 is the inverse of a in GF(256)
+	r := a
+	for i := 0; i < 6; i++ {
+		r = gfMul(r, r)
+		r = gfMul(r, a)
+	}
+	return gfMul(r, r)
+}
+
+func gfDiv(a, b byte) byte {
+	return gfMul(a, gfInv(b))
+}
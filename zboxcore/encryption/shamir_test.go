@@ -0,0 +1,56 @@
+package encryption
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitSecret(t *testing.T) {
+	secret := []byte("encryption key ring")
+
+	shares, err := SplitSecret(secret, 5, 3)
+	require.NoError(t, err)
+	require.Len(t, shares, 5)
+	for _, share := range shares {
+		require.Len(t, share, len(secret)+1)
+	}
+
+	for _, subset := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}, {0, 1, 2, 3, 4}} {
+		var parts [][]byte
+		for _, i := range subset {
+			parts = append(parts, shares[i])
+		}
+		combined, err := CombineShares(parts)
+		require.NoError(t, err)
+		require.Equal(t, secret, combined)
+	}
+
+	t.Run("below threshold", func(t *testing.T) {
+		combined, err := CombineShares(shares[:2])
+		require.NoError(t, err)
+		require.NotEqual(t, secret, combined)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := SplitSecret(nil, 3, 2)
+		require.ErrorIs(t, err, ErrEmptySecret)
+		_, err = SplitSecret(secret, 3, 1)
+		require.ErrorIs(t, err, ErrInvalidThreshold)
+		_, err = SplitSecret(secret, 3, 4)
+		require.ErrorIs(t, err, ErrInvalidThreshold)
+		_, err = SplitSecret(secret, MaxSecretShares+1, 2)
+		require.ErrorIs(t, err, ErrTooManyShares)
+
+		_, err = CombineShares([][]byte{shares[0], shares[0]})
+		require.ErrorIs(t, err, ErrDuplicateShare)
+		_, err = CombineShares([][]byte{shares[0], shares[1][1:]})
+		require.ErrorIs(t, err, ErrInvalidShares)
+	})
+}
+
+func TestGF256(t *testing.T) {
+	for a := 1; a < 256; a++ {
+		require.Equal(t, byte(1), gfMul(byte(a), gfInv(byte(a))))
+	}
+}
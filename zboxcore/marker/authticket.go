@@ -1,12 +1,33 @@
 package marker
 
 import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"time"
 
+	"github.com/0chain/errors"
+	"github.com/0chain/gosdk/core/common"
 	"github.com/0chain/gosdk/core/encryption"
+	"github.com/0chain/gosdk/core/sys"
 	"github.com/0chain/gosdk/zboxcore/client"
 )
 
+// DefaultAuthTicketClockSkew is the tolerated difference between the clocks
+// of the owner and the holder of an auth ticket.
+const DefaultAuthTicketClockSkew = 5 * time.Minute
+
+var (
+	ErrAuthTicketDecode        = errors.New("auth_ticket_decode_error", "Error decoding the auth ticket")
+	ErrAuthTicketInvalid       = errors.New("auth_ticket_invalid", "Invalid auth ticket")
+	ErrAuthTicketSignature     = errors.New("auth_ticket_invalid_signature", "Auth ticket signature is not valid")
+	ErrAuthTicketExpired       = errors.New("auth_ticket_expired", "Auth ticket is expired")
+	ErrAuthTicketNotYetValid   = errors.New("auth_ticket_not_yet_valid", "Auth ticket timestamp is in the future")
+	ErrAuthTicketAllocation    = errors.New("auth_ticket_allocation_mismatch", "Auth ticket is not for the allocation")
+	ErrAuthTicketOwnerMismatch = errors.New("auth_ticket_owner_mismatch", "Public key is not the auth ticket owner's")
+)
+
 type AuthTicket struct {
 	ClientID        string `json:"client_id"`
 	OwnerID         string `json:"owner_id"`
@@ -19,7 +40,13 @@ type AuthTicket struct {
 	Timestamp       int64  `json:"timestamp"`
 	ReEncryptionKey string `json:"re_encryption_key,omitempty"`
 	Encrypted       bool   `json:"encrypted"`
-	Signature       string `json:"signature"`
+	// FileKey is the data key of an AES-GCM encrypted file re-encrypted to
+	// the referee. It isn't signed, only the referee can decrypt it.
+	FileKey string `json:"file_key,omitempty"`
+	// FileKeys are the FileKey of the AES-GCM encrypted files of a shared
+	// directory by lookup hash, of the files in it when it was shared.
+	FileKeys  map[string]string `json:"file_keys,omitempty"`
+	Signature string            `json:"signature"`
 }
 
 func (at *AuthTicket) GetHashData() string {
@@ -45,3 +72,137 @@ func (at *AuthTicket) Sign() error {
 	at.Signature, err = client.Sign(hash)
 	return err
 }
+
+// VerifySignature checks the ticket is signed by the owner of ownerPublicKey.
+func (at *AuthTicket) VerifySignature(ownerPublicKey string) error {
+	pk, err := hex.DecodeString(ownerPublicKey)
+	if err != nil {
+		return errors.New(ErrAuthTicketOwnerMismatch.Code, "Invalid owner public key. "+err.Error())
+	}
+	if encryption.Hash(pk) != at.OwnerID {
+		return ErrAuthTicketOwnerMismatch
+	}
+	if at.Signature == "" {
+		return errors.New(ErrAuthTicketSignature.Code, "Auth ticket is not signed")
+	}
+	ok, err := sys.VerifyWith(ownerPublicKey, at.Signature, encryption.Hash(at.GetHashData()))
+	if err != nil {
+		return errors.New(ErrAuthTicketSignature.Code, "Error during verifying signature. "+err.Error())
+	}
+	if !ok {
+		return ErrAuthTicketSignature
+	}
+	return nil
+}
+
+// AuthTicketOption configures the validation of auth tickets.
+type AuthTicketOption func(*authTicketOptions)
+
+type authTicketOptions struct {
+	allocationID   string
+	ownerPublicKey string
+	lookupKey      func(ownerID string) (string, error)
+	clockSkew      time.Duration
+}
+
+// WithAllocationID requires the ticket to be of the allocation.
+func WithAllocationID(allocationID string) AuthTicketOption {
+	return func(o *authTicketOptions) {
+		o.allocationID = allocationID
+	}
+}
+
+// WithOwnerPublicKey verifies the signature of the ticket against the public key of the owner.
+func WithOwnerPublicKey(publicKey string) AuthTicketOption {
+	return func(o *authTicketOptions) {
+		o.ownerPublicKey = publicKey
+	}
+}
+
+// WithOwnerPublicKeyLookup verifies the signature of the ticket against the
+// public key of the owner returned by lookup, like from the allocation.
+func WithOwnerPublicKeyLookup(lookup func(ownerID string) (string, error)) AuthTicketOption {
+	return func(o *authTicketOptions) {
+		o.lookupKey = lookup
+	}
+}
+
+// WithClockSkew sets the tolerated clock difference to the owner, DefaultAuthTicketClockSkew by default.
+func WithClockSkew(skew time.Duration) AuthTicketOption {
+	return func(o *authTicketOptions) {
+		o.clockSkew = skew
+	}
+}
+
+// DecodeAuthTicket decodes the base64 json of an auth ticket without validating it.
+func DecodeAuthTicket(authTicket string) (*AuthTicket, error) {
+	buf, err := base64.StdEncoding.DecodeString(authTicket)
+	if err != nil {
+		return nil, errors.New(ErrAuthTicketDecode.Code, "Error decoding the auth ticket."+err.Error())
+	}
+	at := &AuthTicket{}
+	if err := json.Unmarshal(buf, at); err != nil {
+		return nil, errors.New(ErrAuthTicketDecode.Code, "Error unmarshaling the auth ticket."+err.Error())
+	}
+	return at, nil
+}
+
+// ParseAuthTicket decodes and validates the base64 json of an auth ticket.
+// The errors have the codes of the ErrAuthTicket* errors.
+func ParseAuthTicket(authTicket string, opts ...AuthTicketOption) (*AuthTicket, error) {
+	at, err := DecodeAuthTicket(authTicket)
+	if err != nil {
+		return nil, err
+	}
+	if err := at.Validate(opts...); err != nil {
+		return nil, err
+	}
+	return at, nil
+}
+
+// Validate checks the structure, allocation and validity period of the
+// ticket, and its signature if the owner's public key is supplied or looked up.
+func (at *AuthTicket) Validate(opts ...AuthTicketOption) error {
+	o := &authTicketOptions{clockSkew: DefaultAuthTicketClockSkew}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	switch {
+	case at.AllocationID == "":
+		return errors.New(ErrAuthTicketInvalid.Code, "Auth ticket has no allocation")
+	case at.OwnerID == "":
+		return errors.New(ErrAuthTicketInvalid.Code, "Auth ticket has no owner")
+	case at.FilePathHash == "":
+		return errors.New(ErrAuthTicketInvalid.Code, "Auth ticket has no file path hash")
+	case at.RefType != "f" && at.RefType != "d":
+		return errors.New(ErrAuthTicketInvalid.Code, "Invalid auth ticket reference type "+at.RefType)
+	case at.Timestamp <= 0:
+		return errors.New(ErrAuthTicketInvalid.Code, "Auth ticket has no timestamp")
+	}
+
+	if o.allocationID != "" && o.allocationID != at.AllocationID {
+		return ErrAuthTicketAllocation
+	}
+
+	now := int64(common.Now())
+	skew := int64(o.clockSkew / time.Second)
+	if at.Timestamp > now+skew {
+		return ErrAuthTicketNotYetValid
+	}
+	if at.Expiration > 0 && at.Expiration < now-skew {
+		return ErrAuthTicketExpired
+	}
+
+	publicKey := o.ownerPublicKey
+	if publicKey == "" && o.lookupKey != nil {
+		var err error
+		if publicKey, err = o.lookupKey(at.OwnerID); err != nil {
+			return err
+		}
+	}
+	if publicKey != "" {
+		return at.VerifySignature(publicKey)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package marker
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/0chain/errors"
+	"github.com/0chain/gosdk/core/common"
+	"github.com/0chain/gosdk/core/zcncrypto"
+	"github.com/0chain/gosdk/zboxcore/client"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAuthTicket(t *testing.T) {
+	wallet, err := zcncrypto.NewSignatureScheme("bls0chain").GenerateKeys()
+	require.NoError(t, err)
+	walletJSON, err := json.Marshal(wallet)
+	require.NoError(t, err)
+	require.NoError(t, client.PopulateClient(string(walletJSON), "bls0chain"))
+
+	now := int64(common.Now())
+	newTicket := func(update func(at *AuthTicket)) string {
+		at := &AuthTicket{
+			OwnerID:      wallet.ClientID,
+			AllocationID: "alloc",
+			FilePathHash: "hash",
+			FileName:     "a.txt",
+			RefType:      "f",
+			Timestamp:    now,
+		}
+		if update != nil {
+			update(at)
+		}
+		require.NoError(t, at.Sign())
+		buf, err := json.Marshal(at)
+		require.NoError(t, err)
+		return base64.StdEncoding.EncodeToString(buf)
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		at, err := ParseAuthTicket(newTicket(nil), WithAllocationID("alloc"), WithOwnerPublicKey(wallet.ClientKey))
+		require.NoError(t, err)
+		require.Equal(t, "a.txt", at.FileName)
+
+		_, err = ParseAuthTicket(newTicket(nil), WithOwnerPublicKeyLookup(func(ownerID string) (string, error) {
+			require.Equal(t, wallet.ClientID, ownerID)
+			return wallet.ClientKey, nil
+		}))
+		require.NoError(t, err)
+	})
+
+	tests := []struct {
+		name   string
+		ticket string
+		opts   []AuthTicketOption
+		err    *errors.Error
+	}{
+		{"not base64", "not a ticket", nil, ErrAuthTicketDecode},
+		{"not json", base64.StdEncoding.EncodeToString([]byte("ticket")), nil, ErrAuthTicketDecode},
+		{"no path hash", newTicket(func(at *AuthTicket) { at.FilePathHash = "" }), nil, ErrAuthTicketInvalid},
+		{"ref type", newTicket(func(at *AuthTicket) { at.RefType = "x" }), nil, ErrAuthTicketInvalid},
+		{"allocation", newTicket(nil), []AuthTicketOption{WithAllocationID("other")}, ErrAuthTicketAllocation},
+		{"expired", newTicket(func(at *AuthTicket) { at.Expiration = now - 3600 }), nil, ErrAuthTicketExpired},
+		{"future", newTicket(func(at *AuthTicket) { at.Timestamp = now + 3600 }), nil, ErrAuthTicketNotYetValid},
+		{"skew", newTicket(func(at *AuthTicket) { at.Timestamp = now + 60 }), []AuthTicketOption{WithClockSkew(time.Second)}, ErrAuthTicketNotYetValid},
+		{"owner", newTicket(func(at *AuthTicket) { at.OwnerID = "other" }), []AuthTicketOption{WithOwnerPublicKey(wallet.ClientKey)}, ErrAuthTicketOwnerMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseAuthTicket(tt.ticket, tt.opts...)
+			require.Error(t, err)
+			require.True(t, errors.Is(err, tt.err), err)
+		})
+	}
+
+	t.Run("signature", func(t *testing.T) {
+		at, err := DecodeAuthTicket(newTicket(nil))
+		require.NoError(t, err)
+		at.FileName = "b.txt"
+		err = at.Validate(WithOwnerPublicKey(wallet.ClientKey))
+		require.True(t, errors.Is(err, ErrAuthTicketSignature), err)
+	})
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/0chain/gosdk/core/pathutil"
 	"github.com/0chain/gosdk/core/sys"
 	"github.com/0chain/gosdk/zboxcore/blockchain"
+	"github.com/0chain/gosdk/zboxcore/encryption"
 	"github.com/0chain/gosdk/zboxcore/fileref"
 	"github.com/0chain/gosdk/zboxcore/logger"
 	l "github.com/0chain/gosdk/zboxcore/logger"
@@ -109,11 +110,20 @@ type ConsolidatedFileMeta struct {
 	ActualFileSize  int64
 	ActualNumBlocks int64
 	EncryptedKey    string
+	// EncryptedKeyPoint is the wrapped data key of EncryptionModeGCM files
+	EncryptedKeyPoint string
 
 	ActualThumbnailSize int64
 	ActualThumbnailHash string
 
 	Collaborators []fileref.Collaborator
+	// RetentionLock is the active retention lock of the path, if any
+	RetentionLock *RetentionLock
+}
+
+// isEncrypted reports whether the file was encrypted on upload.
+func (m *ConsolidatedFileMeta) isEncrypted() bool {
+	return m.EncryptedKey != "" || isGCMKeyPoint(m.EncryptedKeyPoint)
 }
 
 type AllocationStats struct {
@@ -214,6 +224,7 @@ type Allocation struct {
 	initialized             bool
 	checkStatus             bool
 	readFree                bool
+	pathCipher              *encryption.NameCipher // encrypts paths if path encryption is enabled
 	// conseususes
 	consensusThreshold int
 	fullconsensus      int
@@ -237,6 +248,8 @@ type OperationRequest struct {
 	DownloadFile bool              // Required for upload repair operation
 	StreamUpload bool              // Required for streaming file when actualSize is not available
 	Opts         []ChunkedUploadOption
+
+	remotePaths bool // the paths are the paths blobbers have
 }
 
 func GetReadPriceRange() (PriceRange, error) {
@@ -417,6 +430,14 @@ func (a *Allocation) RepairFile(file sys.File, remotepath string, statusCallback
 			WithEncryptedPoint(ref.EncryptedKeyPoint),
 			WithChunkNumber(100),
 		}
+	} else if isGCMKeyPoint(ref.EncryptedKeyPoint) {
+		opts = []ChunkedUploadOption{
+			WithMask(mask),
+			WithEncryptionMode(EncryptionModeGCM),
+			WithStatusCallback(statusCallback),
+			WithEncryptedPoint(ref.EncryptedKeyPoint),
+			WithChunkNumber(100),
+		}
 	} else {
 		opts = []ChunkedUploadOption{
 			WithMask(mask),
@@ -657,9 +678,28 @@ func (a *Allocation) StartChunkedUpload(workdir, localPath string,
 		RemotePath: remotePath,
 	}
 
+	op := OperationRequest{
+		OperationType: constants.FileOperationInsert,
+		RemotePath:    remotePath,
+		FileMeta:      fileMeta,
+		FileReader:    fileReader,
+		IsUpdate:      isUpdate,
+		IsRepair:      isRepair,
+	}
+	if err := a.checkPolicyOperations([]OperationRequest{op}); err != nil {
+		return err
+	}
+	if err := a.checkPathEncryption([]OperationRequest{op}); err != nil {
+		return err
+	}
+	if err := a.encryptOperationPaths(&op); err != nil {
+		return err
+	}
+	fileMeta = op.FileMeta
+
 	options := []ChunkedUploadOption{
 		WithEncrypt(encryption),
-		WithStatusCallback(status),
+		WithStatusCallback(a.plainStatusCallback(status)),
 	}
 	options = append(options, uploadOpts...)
 
@@ -804,7 +844,11 @@ func (a *Allocation) RepairRequired(remotepath string) (zboxutil.Uint128, zboxut
 	listReq.fullconsensus = a.fullconsensus
 	listReq.consensusThresh = a.DataShards
 	listReq.ctx = a.ctx
-	listReq.remotefilepath = remotepath
+	remotePath, err := a.remotePath(remotepath)
+	if err != nil {
+		return zboxutil.Uint128{}, zboxutil.Uint128{}, false, nil, err
+	}
+	listReq.remotefilepath = remotePath
 	found, deleteMask, fileRef, _ := listReq.getFileConsensusFromBlobbers()
 	if fileRef == nil {
 		var repairErr error
@@ -826,6 +870,15 @@ func (a *Allocation) DoMultiOperation(operations []OperationRequest, opts ...Mul
 	if !a.isInitialized() {
 		return notInitialized
 	}
+	if err := a.checkPolicyOperations(operations); err != nil {
+		return err
+	}
+	if err := a.checkCollaboratorOperations(operations); err != nil {
+		return err
+	}
+	if err := a.checkPathEncryption(operations); err != nil {
+		return err
+	}
 	connectionID := zboxutil.NewConnectionId()
 	var mo MultiOperation
 	for i := 0; i < len(operations); {
@@ -844,6 +897,7 @@ func (a *Allocation) DoMultiOperation(operations []OperationRequest, opts ...Mul
 			opt(&mo)
 		}
 		previousPaths := make(map[string]bool)
+		var moved []*movedPath
 		connectionErrors := make([]error, len(mo.allocationObj.Blobbers))
 
 		var wg sync.WaitGroup
@@ -889,6 +943,13 @@ func (a *Allocation) DoMultiOperation(operations []OperationRequest, opts ...Mul
 				op.FileMeta.RemotePath = strings.TrimSpace(op.FileMeta.RemotePath)
 				op.FileMeta.RemoteName = strings.TrimSpace(op.FileMeta.RemoteName)
 			}
+			move, err := a.movedPathOf(&op)
+			if err != nil {
+				return err
+			}
+			if err := a.encryptOperationPaths(&op); err != nil {
+				return err
+			}
 			remotePath := op.RemotePath
 			parentPaths := GenerateParentPaths(remotePath)
 
@@ -900,7 +961,6 @@ func (a *Allocation) DoMultiOperation(operations []OperationRequest, opts ...Mul
 
 			var (
 				operation       Operationer
-				err             error
 				newConnectionID string
 			)
 
@@ -957,6 +1017,9 @@ func (a *Allocation) DoMultiOperation(operations []OperationRequest, opts ...Mul
 			}
 
 			mo.operations = append(mo.operations, operation)
+			if move != nil {
+				moved = append(moved, move)
+			}
 		}
 
 		if len(mo.operations) > 0 {
@@ -967,6 +1030,12 @@ func (a *Allocation) DoMultiOperation(operations []OperationRequest, opts ...Mul
 
 			mo.operations = nil
 		}
+		// names are encrypted with their directory, so moved paths get the names of the new one
+		for _, m := range moved {
+			if err := a.reencryptMoved(m); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
@@ -1108,7 +1177,7 @@ func (a *Allocation) generateDownloadRequest(
 	downloadReq.fileHandler = fileHandler
 	downloadReq.localFilePath = localFilePath
 	downloadReq.remotefilepath = remotePath
-	downloadReq.statusCallback = status
+	downloadReq.statusCallback = a.plainStatusCallback(status)
 	downloadReq.downloadMask = zboxutil.NewUint128(1).Lsh(uint64(len(a.Blobbers))).Sub64(1)
 	downloadReq.blobbers = a.Blobbers
 	downloadReq.datashards = a.DataShards
@@ -1150,6 +1219,10 @@ func (a *Allocation) addAndGenerateDownloadRequest(
 	localFilePath string,
 	downloadReqOpts ...DownloadRequestOption,
 ) error {
+	remotePath, err := a.remotePath(remotePath)
+	if err != nil {
+		return err
+	}
 	downloadReq, err := a.generateDownloadRequest(
 		fileHandler, remotePath, contentMode, startBlock, endBlock,
 		numBlocks, verifyDownload, status, "", localFilePath)
@@ -1312,18 +1385,21 @@ func (a *Allocation) prepareAndOpenLocalFile(localPath string, remotePath string
 	return f, localFilePath, toKeep, nil
 }
 
+// parseAuthTicket decodes the auth ticket and validates it is a valid
+// ticket of the allocation signed by its owner.
+func (a *Allocation) parseAuthTicket(authTicket string) (*marker.AuthTicket, error) {
+	return marker.ParseAuthTicket(authTicket,
+		marker.WithAllocationID(a.ID),
+		marker.WithOwnerPublicKey(a.OwnerPublicKey))
+}
+
 func (a *Allocation) ListDirFromAuthTicket(authTicket string, lookupHash string, opts ...ListRequestOptions) (*ListResult, error) {
 	if !a.isInitialized() {
 		return nil, notInitialized
 	}
-	sEnc, err := base64.StdEncoding.DecodeString(authTicket)
+	at, err := a.parseAuthTicket(authTicket)
 	if err != nil {
-		return nil, errors.New("auth_ticket_decode_error", "Error decoding the auth ticket."+err.Error())
-	}
-	at := &marker.AuthTicket{}
-	err = json.Unmarshal(sEnc, at)
-	if err != nil {
-		return nil, errors.New("auth_ticket_decode_error", "Error unmarshaling the auth ticket."+err.Error())
+		return nil, err
 	}
 	if len(at.FilePathHash) == 0 || len(lookupHash) == 0 {
 		return nil, errors.New("invalid_path", "Invalid path for the list")
@@ -1365,6 +1441,20 @@ func (a *Allocation) ListDir(path string, opts ...ListRequestOptions) (*ListResu
 	if !isabs {
 		return nil, errors.New("invalid_path", "Path should be valid and absolute")
 	}
+	remotePath, err := a.remotePath(path)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := a.listDir(remotePath, opts...)
+	if err != nil {
+		return nil, err
+	}
+	a.decryptListResult(ref)
+	return ref, nil
+}
+
+// listDir lists the path blobbers have for the remote path.
+func (a *Allocation) listDir(remotePath string, opts ...ListRequestOptions) (*ListResult, error) {
 	listReq := &ListRequest{Consensus: Consensus{RWMutex: &sync.RWMutex{}}}
 	listReq.allocationID = a.ID
 	listReq.allocationTx = a.Tx
@@ -1372,7 +1462,7 @@ func (a *Allocation) ListDir(path string, opts ...ListRequestOptions) (*ListResu
 	listReq.fullconsensus = a.fullconsensus
 	listReq.consensusThresh = a.DataShards
 	listReq.ctx = a.ctx
-	listReq.remotefilepath = path
+	listReq.remotefilepath = remotePath
 	for _, opt := range opts {
 		opt(listReq)
 	}
@@ -1447,6 +1537,11 @@ func (a *Allocation) DownloadFromBlobber(blobberID, localPath, remotePath string
 	if err != nil {
 		return err
 	}
+	remotePath, err = a.remotePath(remotePath)
+	if err != nil {
+		f.Close() //nolint: errcheck
+		return err
+	}
 	downloadReq, err := a.generateDownloadRequest(f, remotePath, DOWNLOAD_CONTENT_FULL, 1, 0, numBlockDownloads, verifyDownload,
 		status, zboxutil.NewConnectionId(), localFilePath)
 	if err != nil {
@@ -1488,14 +1583,9 @@ func (a *Allocation) GetRefsWithAuthTicket(authToken, offsetPath, updatedDate, o
 	if authToken == "" {
 		return nil, errors.New("empty_auth_token", "auth token cannot be empty")
 	}
-	sEnc, err := base64.StdEncoding.DecodeString(authToken)
+	authTicket, err := a.parseAuthTicket(authToken)
 	if err != nil {
-		return nil, errors.New("auth_ticket_decode_error", "Error decoding the auth ticket."+err.Error())
-	}
-
-	authTicket := new(marker.AuthTicket)
-	if err := json.Unmarshal(sEnc, authTicket); err != nil {
-		return nil, errors.New("json_unmarshall_error", err.Error())
+		return nil, err
 	}
 
 	at, _ := json.Marshal(authTicket)
@@ -1508,7 +1598,16 @@ func (a *Allocation) GetRefs(path, offsetPath, updatedDate, offsetDate, fileType
 		return nil, errors.New("invalid_path", fmt.Sprintf("Absolute path required. Path provided: %v", path))
 	}
 
-	return a.getRefs(path, "", "", offsetPath, updatedDate, offsetDate, fileType, refType, level, pageLimit)
+	path, err := a.remotePath(path)
+	if err != nil {
+		return nil, err
+	}
+	res, err := a.getRefs(path, "", "", offsetPath, updatedDate, offsetDate, fileType, refType, level, pageLimit)
+	if err != nil {
+		return nil, err
+	}
+	a.decryptRefs(res.Refs)
+	return res, nil
 }
 
 func (a *Allocation) GetRefsFromLookupHash(pathHash, offsetPath, updatedDate, offsetDate, fileType, refType string, level, pageLimit int) (*ObjectTreeResult, error) {
@@ -1516,8 +1615,12 @@ func (a *Allocation) GetRefsFromLookupHash(pathHash, offsetPath, updatedDate, of
 		return nil, errors.New("invalid_lookup_hash", "lookup hash cannot be empty")
 	}
 
-	return a.getRefs("", pathHash, "", offsetPath, updatedDate, offsetDate, fileType, refType, level, pageLimit)
-
+	res, err := a.getRefs("", pathHash, "", offsetPath, updatedDate, offsetDate, fileType, refType, level, pageLimit)
+	if err != nil {
+		return nil, err
+	}
+	a.decryptRefs(res.Refs)
+	return res, nil
 }
 
 func (a *Allocation) GetRecentlyAddedRefs(page int, fromDate int64, pageLimit int) (*RecentlyAddedRefResult, error) {
@@ -1547,7 +1650,12 @@ func (a *Allocation) GetRecentlyAddedRefs(page int, fromDate int64, pageLimit in
 			consensusThresh: a.consensusThreshold,
 		},
 	}
-	return req.GetRecentlyAddedRefs()
+	res, err := req.GetRecentlyAddedRefs()
+	if err != nil {
+		return nil, err
+	}
+	a.decryptRefs(res.Refs)
+	return res, nil
 }
 
 func (a *Allocation) GetFileMeta(path string) (*ConsolidatedFileMeta, error) {
@@ -1555,6 +1663,11 @@ func (a *Allocation) GetFileMeta(path string) (*ConsolidatedFileMeta, error) {
 		return nil, notInitialized
 	}
 
+	remotePath, err := a.remotePath(path)
+	if err != nil {
+		return nil, err
+	}
+
 	result := &ConsolidatedFileMeta{}
 	listReq := &ListRequest{Consensus: Consensus{RWMutex: &sync.RWMutex{}}}
 	listReq.allocationID = a.ID
@@ -1563,7 +1676,7 @@ func (a *Allocation) GetFileMeta(path string) (*ConsolidatedFileMeta, error) {
 	listReq.fullconsensus = a.fullconsensus
 	listReq.consensusThresh = a.consensusThreshold
 	listReq.ctx = a.ctx
-	listReq.remotefilepath = path
+	listReq.remotefilepath = remotePath
 	_, _, ref, _ := listReq.getFileConsensusFromBlobbers()
 	if ref != nil {
 		result.Type = ref.Type
@@ -1572,9 +1685,14 @@ func (a *Allocation) GetFileMeta(path string) (*ConsolidatedFileMeta, error) {
 		result.LookupHash = ref.LookupHash
 		result.MimeType = ref.MimeType
 		result.Path = ref.Path
+		if a.pathCipher != nil && ref.Path != "/" {
+			result.Path = a.plainPath(ref.Path)
+			_, result.Name = pathutil.Split(result.Path)
+		}
 		result.Size = ref.Size
 		result.NumBlocks = ref.NumBlocks
 		result.EncryptedKey = ref.EncryptedKey
+		result.EncryptedKeyPoint = ref.EncryptedKeyPoint
 		result.Collaborators = ref.Collaborators
 		result.ActualFileSize = ref.ActualFileSize
 		result.ActualThumbnailHash = ref.ActualThumbnailHash
@@ -1582,6 +1700,10 @@ func (a *Allocation) GetFileMeta(path string) (*ConsolidatedFileMeta, error) {
 		if result.ActualFileSize > 0 {
 			result.ActualNumBlocks = (ref.ActualFileSize + CHUNK_SIZE - 1) / CHUNK_SIZE
 		}
+		result.RetentionLock, err = a.retentionLockOf(path)
+		if err != nil {
+			return nil, err
+		}
 		return result, nil
 	}
 	return nil, errors.New("file_meta_error", "Error getting the file meta data from blobbers")
@@ -1601,14 +1723,9 @@ func (a *Allocation) GetFileMetaFromAuthTicket(authTicket string, lookupHash str
 	}
 
 	result := &ConsolidatedFileMeta{}
-	sEnc, err := base64.StdEncoding.DecodeString(authTicket)
-	if err != nil {
-		return nil, errors.New("auth_ticket_decode_error", "Error decoding the auth ticket."+err.Error())
-	}
-	at := &marker.AuthTicket{}
-	err = json.Unmarshal(sEnc, at)
+	at, err := a.parseAuthTicket(authTicket)
 	if err != nil {
-		return nil, errors.New("auth_ticket_decode_error", "Error unmarshaling the auth ticket."+err.Error())
+		return nil, err
 	}
 	if len(at.FilePathHash) == 0 || len(lookupHash) == 0 {
 		return nil, errors.New("invalid_path", "Invalid path for the list")
@@ -1663,7 +1780,11 @@ func (a *Allocation) GetFileStats(path string) (map[string]*FileStats, error) {
 	listReq.fullconsensus = a.fullconsensus
 	listReq.consensusThresh = a.consensusThreshold
 	listReq.ctx = a.ctx
-	listReq.remotefilepath = path
+	remotePath, err := a.remotePath(path)
+	if err != nil {
+		return nil, err
+	}
+	listReq.remotefilepath = remotePath
 	ref := listReq.getFileStatsFromBlobbers()
 	if ref != nil {
 		return ref, nil
@@ -1672,9 +1793,18 @@ func (a *Allocation) GetFileStats(path string) (map[string]*FileStats, error) {
 }
 
 func (a *Allocation) DeleteFile(path string) error {
+	op := OperationRequest{OperationType: constants.FileOperationDelete, RemotePath: path}
+	if err := a.checkPolicyOperations([]OperationRequest{op}); err != nil {
+		return err
+	}
+	if err := a.checkPathEncryption([]OperationRequest{op}); err != nil {
+		return err
+	}
 	return a.deleteFile(path, a.consensusThreshold, a.fullconsensus, zboxutil.NewUint128(1).Lsh(uint64(len(a.Blobbers))).Sub64(1))
 }
 
+// deleteFile deletes the path on the blobbers of the mask. Repairs call it
+// directly, without the checks of the allocation policy.
 func (a *Allocation) deleteFile(path string, threshConsensus, fullConsensus int, mask zboxutil.Uint128) error {
 	if !a.isInitialized() {
 		return notInitialized
@@ -1700,13 +1830,16 @@ func (a *Allocation) deleteFile(path string, threshConsensus, fullConsensus int,
 	req.allocationTx = a.Tx
 	req.consensus.Init(threshConsensus, fullConsensus)
 	req.ctx, req.ctxCncl = context.WithCancel(a.ctx)
-	req.remotefilepath = path
+	remotePath, err := a.remotePath(path)
+	if err != nil {
+		return err
+	}
+	req.remotefilepath = remotePath
 	req.connectionID = zboxutil.NewConnectionId()
 	req.deleteMask = mask
 	req.maskMu = &sync.Mutex{}
 	req.timestamp = int64(common.Now())
-	err := req.ProcessDelete()
-	return err
+	return req.ProcessDelete()
 }
 
 func (a *Allocation) createDir(remotePath string, threshConsensus, fullConsensus int, mask zboxutil.Uint128) error {
@@ -1722,7 +1855,10 @@ func (a *Allocation) createDir(remotePath string, threshConsensus, fullConsensus
 		return errors.New("invalid_path", "Path is not absolute")
 	}
 
-	remotePath = zboxutil.RemoteClean(remotePath)
+	remotePath, err := a.remotePath(zboxutil.RemoteClean(remotePath))
+	if err != nil {
+		return err
+	}
 	timestamp := int64(common.Now())
 	req := DirRequest{
 		allocationObj: a,
@@ -1743,8 +1879,7 @@ func (a *Allocation) createDir(remotePath string, threshConsensus, fullConsensus
 	}
 	req.ctx, req.ctxCncl = context.WithCancel(a.ctx)
 
-	err := req.ProcessDir(a)
-	return err
+	return req.ProcessDir(a)
 }
 
 func (a *Allocation) GetAuthTicketForShare(
@@ -1755,18 +1890,46 @@ func (a *Allocation) GetAuthTicketForShare(
 }
 
 func (a *Allocation) RevokeShare(path string, refereeClientID string) error {
-	success := make(chan int, len(a.Blobbers))
-	notFound := make(chan int, len(a.Blobbers))
+	failed, notFound, err := a.revokeShareOnBlobbers(a.Blobbers, path, refereeClientID)
+	if err != nil {
+		return err
+	}
+	if notFound == len(a.Blobbers) {
+		return errors.New("", "share not found")
+	}
+	if err := a.checkShareConsensus(failed); err != nil {
+		return err
+	}
+
+	// the blobbers which failed are retried by ReconcileShares
+	getShareRegistry(a.ID).update(path, refereeClientID, func(s *ShareInfo) {
+		s.Revoked = true
+		s.PendingBlobbers = failed
+		s.UploadedTicket = ""
+	})
+	return nil
+}
+
+// revokeShareOnBlobbers revokes the share on the blobbers and returns the ids
+// of the blobbers which failed and the number which had no such share.
+func (a *Allocation) revokeShareOnBlobbers(blobbers []*blockchain.StorageNode, path, refereeClientID string) ([]string, int, error) {
+	remotePath, err := a.remotePath(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	success := make(chan string, len(blobbers))
+	notFound := make(chan int, len(blobbers))
 	wg := &sync.WaitGroup{}
-	for idx := range a.Blobbers {
-		baseUrl := a.Blobbers[idx].Baseurl
+	for idx := range blobbers {
+		baseUrl := blobbers[idx].Baseurl
+		blobberID := blobbers[idx].ID
 		query := &url.Values{}
-		query.Add("path", path)
+		query.Add("path", remotePath)
 		query.Add("refereeClientID", refereeClientID)
 
 		httpreq, err := zboxutil.NewRevokeShareRequest(baseUrl, a.ID, a.Tx, query)
 		if err != nil {
-			return err
+			return nil, 0, err
 		}
 
 		wg.Add(1)
@@ -1793,24 +1956,34 @@ func (a *Allocation) RevokeShare(path string, refereeClientID string) error {
 				if err != nil {
 					return err
 				}
-				if data["status"].(float64) == http.StatusNotFound {
+				if status, ok := data["status"].(float64); ok && status == http.StatusNotFound {
 					notFound <- 1
 				}
 				return nil
 			})
 			if err == nil {
-				success <- 1
+				success <- blobberID
 			}
 		}()
 	}
 	wg.Wait()
-	if len(success) == len(a.Blobbers) {
-		if len(notFound) == len(a.Blobbers) {
-			return errors.New("", "share not found")
+	close(success)
+	return failedBlobbers(blobbers, success), len(notFound), nil
+}
+
+// failedBlobbers returns the ids of the blobbers missing from succeeded.
+func failedBlobbers(blobbers []*blockchain.StorageNode, succeeded <-chan string) []string {
+	ok := make(map[string]bool, len(blobbers))
+	for id := range succeeded {
+		ok[id] = true
+	}
+	var failed []string
+	for _, b := range blobbers {
+		if !ok[b.ID] {
+			failed = append(failed, b.ID)
 		}
-		return nil
 	}
-	return errors.New("", "consensus not reached")
+	return failed
 }
 
 var ErrInvalidPrivateShare = errors.New("invalid_private_share", "private sharing is only available for encrypted file")
@@ -1839,25 +2012,20 @@ func (a *Allocation) GetAuthTicket(path, filename string,
 		}
 
 		// private sharing is only available for encrypted file
-		if fileMeta.EncryptedKey == "" {
+		if !fileMeta.isEncrypted() {
 			return "", ErrInvalidPrivateShare
 		}
 	}
 
-	shareReq := &ShareRequest{
-		expirationSeconds: expiration,
-		allocationID:      a.ID,
-		allocationTx:      a.Tx,
-		blobbers:          a.Blobbers,
-		ctx:               a.ctx,
-		remotefilepath:    path,
-		remotefilename:    filename,
+	shareReq, err := a.newShareRequest(path, filename, referenceType, expiration)
+	if err != nil {
+		return "", err
 	}
-
-	if referenceType == fileref.DIRECTORY {
-		shareReq.refType = fileref.DIRECTORY
-	} else {
-		shareReq.refType = fileref.FILE
+	if shareReq.refType == fileref.DIRECTORY && refereeEncryptionPublicKey != "" {
+		// files uploaded later need the directory shared again
+		if shareReq.gcmKeyPoints, err = a.gcmKeyPoints(shareReq.remotefilepath); err != nil {
+			return "", err
+		}
 	}
 
 	aTicket, err := shareReq.getAuthTicket(refereeClientID, refereeEncryptionPublicKey)
@@ -1870,10 +2038,29 @@ func (a *Allocation) GetAuthTicket(path, filename string,
 		return "", err
 	}
 
-	if err := a.UploadAuthTicketToBlobber(string(atBytes), refereeEncryptionPublicKey, availableAfter); err != nil {
+	failed, err := a.uploadAuthTicketToBlobbers(a.Blobbers, string(atBytes), refereeEncryptionPublicKey, availableAfter)
+	if err != nil {
+		return "", err
+	}
+	if err := a.checkShareConsensus(failed); err != nil {
 		return "", err
 	}
 
+	share := &ShareInfo{
+		AllocationID:               a.ID,
+		Path:                       path,
+		FileName:                   shareReq.remotefilename,
+		RefType:                    shareReq.refType,
+		RefereeClientID:            refereeClientID,
+		RefereeEncryptionPublicKey: refereeEncryptionPublicKey,
+		CreatedAt:                  aTicket.Timestamp,
+		Expiration:                 aTicket.Expiration,
+		PendingBlobbers:            failed,
+	}
+	if availableAfter != nil {
+		share.AvailableAfter = availableAfter.Unix()
+	}
+
 	aTicket.ReEncryptionKey = ""
 	if err := aTicket.Sign(); err != nil {
 		return "", err
@@ -1883,35 +2070,119 @@ func (a *Allocation) GetAuthTicket(path, filename string,
 	if err != nil {
 		return "", err
 	}
+	if len(failed) > 0 {
+		// the blobbers which failed are retried by ReconcileShares
+		share.UploadedTicket = string(atBytes)
+	}
+
+	share.AuthTicket = base64.StdEncoding.EncodeToString(atBytes)
+	getShareRegistry(a.ID).put(share)
+
+	return share.AuthTicket, nil
+}
+
+// newShareRequest creates the request of the auth tickets of the clean
+// absolute path, which expire in expiration seconds, 0 never.
+func (a *Allocation) newShareRequest(path, filename, referenceType string, expiration int64) (*ShareRequest, error) {
+	remotePath, err := a.remotePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if a.pathCipher != nil {
+		// blobbers get the ticket, so it has the encrypted name
+		_, filename = pathutil.Split(remotePath)
+	}
+
+	shareReq := &ShareRequest{
+		expirationSeconds: expiration,
+		allocationID:      a.ID,
+		allocationTx:      a.Tx,
+		blobbers:          a.Blobbers,
+		ctx:               a.ctx,
+		remotefilepath:    remotePath,
+		remotefilename:    filename,
+	}
 
-	return base64.StdEncoding.EncodeToString(atBytes), nil
+	if referenceType == fileref.DIRECTORY {
+		shareReq.refType = fileref.DIRECTORY
+	} else {
+		shareReq.refType = fileref.FILE
+	}
+	return shareReq, nil
+}
+
+// gcmKeyPoints returns the encrypted key points of the EncryptionModeGCM
+// files in the remote directory, by lookup hash.
+func (a *Allocation) gcmKeyPoints(remoteDir string) (map[string]string, error) {
+	points := make(map[string]string)
+	var offsetPath string
+	for {
+		res, err := a.getRefs(remoteDir, "", "", offsetPath, "", "", "", fileref.FILE, 0, rotationPageLimit)
+		if err != nil {
+			return nil, err
+		}
+		for _, ref := range res.Refs {
+			if isGCMKeyPoint(ref.EncryptedKeyPoint) {
+				points[fileref.GetReferenceLookup(a.ID, ref.Path)] = ref.EncryptedKeyPoint
+			}
+		}
+		if len(res.Refs) < rotationPageLimit || res.OffsetPath == "" || res.OffsetPath == offsetPath {
+			return points, nil
+		}
+		offsetPath = res.OffsetPath
+	}
 }
 
 func (a *Allocation) UploadAuthTicketToBlobber(authTicket string, clientEncPubKey string, availableAfter *time.Time) error {
-	success := make(chan int, len(a.Blobbers))
+	failed, err := a.uploadAuthTicketToBlobbers(a.Blobbers, authTicket, clientEncPubKey, availableAfter)
+	if err != nil {
+		return err
+	}
+	return a.checkShareConsensus(failed)
+}
+
+// checkShareConsensus checks enough blobbers of the allocation applied a share change.
+func (a *Allocation) checkShareConsensus(failed []string) error {
+	consensus := Consensus{
+		RWMutex:         &sync.RWMutex{},
+		consensus:       len(a.Blobbers) - len(failed),
+		consensusThresh: a.DataShards,
+		fullconsensus:   a.fullconsensus,
+	}
+	if !consensus.isConsensusOk() {
+		return errors.New("", "consensus not reached")
+	}
+	return nil
+}
+
+// uploadAuthTicketToBlobbers uploads the auth ticket to the blobbers and
+// returns the ids of the blobbers which failed.
+func (a *Allocation) uploadAuthTicketToBlobbers(blobbers []*blockchain.StorageNode, authTicket string, clientEncPubKey string, availableAfter *time.Time) ([]string, error) {
+	success := make(chan string, len(blobbers))
 	wg := &sync.WaitGroup{}
-	for idx := range a.Blobbers {
-		url := a.Blobbers[idx].Baseurl
+	for idx := range blobbers {
+		url := blobbers[idx].Baseurl
+		blobberID := blobbers[idx].ID
 		body := new(bytes.Buffer)
 		formWriter := multipart.NewWriter(body)
 		if err := formWriter.WriteField("encryption_public_key", clientEncPubKey); err != nil {
-			return err
+			return nil, err
 		}
 		if err := formWriter.WriteField("auth_ticket", authTicket); err != nil {
-			return err
+			return nil, err
 		}
 		if availableAfter != nil {
 			if err := formWriter.WriteField("available_after", strconv.FormatInt(availableAfter.Unix(), 10)); err != nil {
-				return err
+				return nil, err
 			}
 		}
 
 		if err := formWriter.Close(); err != nil {
-			return err
+			return nil, err
 		}
 		httpreq, err := zboxutil.NewShareRequest(url, a.ID, a.Tx, body)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		httpreq.Header.Set("Content-Type", formWriter.FormDataContentType())
 
@@ -1937,24 +2208,19 @@ func (a *Allocation) UploadAuthTicketToBlobber(authTicket string, clientEncPubKe
 				return nil
 			})
 			if err == nil {
-				success <- 1
+				success <- blobberID
 			}
 		}()
 	}
 	wg.Wait()
-	consensus := Consensus{
-		RWMutex:         &sync.RWMutex{},
-		consensus:       len(success),
-		consensusThresh: a.DataShards,
-		fullconsensus:   a.fullconsensus,
-	}
-	if !consensus.isConsensusOk() {
-		return errors.New("", "consensus not reached")
-	}
-	return nil
+	close(success)
+	return failedBlobbers(blobbers, success), nil
 }
 
 func (a *Allocation) CancelDownload(remotepath string) error {
+	if p, err := a.remotePath(remotepath); err == nil {
+		remotepath = p
+	}
 	if downloadReq, ok := a.downloadProgressMap[remotepath]; ok {
 		downloadReq.isDownloadCanceled = true
 		downloadReq.ctxCncl()
@@ -1984,7 +2250,7 @@ func (a *Allocation) DownloadFromReader(
 
 	sd := r.(*StreamDownload)
 
-	fileName := filepath.Base(sd.remotefilepath)
+	fileName := filepath.Base(a.plainPath(sd.remotefilepath))
 	var localFPath string
 	if contentMode == DOWNLOAD_CONTENT_THUMB {
 		localFPath = filepath.Join(localPath, fileName, ".thumb")
@@ -2048,7 +2314,10 @@ func (a *Allocation) GetAllocationFileReader(
 	case authTicket != "":
 		res, err = a.GetRefsWithAuthTicket(authTicket, "", "", "", "", "regular", 0, 1)
 	case remotePath != "":
-		res, err = a.GetRefs(remotePath, "", "", "", "", "regular", 0, 1)
+		// the reader downloads the path blobbers have
+		if remotePath, err = a.remotePath(remotePath); err == nil {
+			res, err = a.getRefs(remotePath, "", "", "", "", "", "", "regular", 0, 1)
+		}
 	case lookupHash != "":
 		res, err = a.GetRefsFromLookupHash(lookupHash, "", "", "", "", "regular", 0, 1) //
 	default:
@@ -2203,14 +2472,9 @@ func (a *Allocation) downloadFromAuthTicket(fileHandler sys.File, authTicket str
 	remoteFilename string, contentMode string, verifyDownload bool,
 	status StatusCallback, isFinal bool, localFilePath string, downlaodReqOpts ...DownloadRequestOption) error {
 
-	sEnc, err := base64.StdEncoding.DecodeString(authTicket)
-	if err != nil {
-		return errors.New("auth_ticket_decode_error", "Error decoding the auth ticket."+err.Error())
-	}
-	at := &marker.AuthTicket{}
-	err = json.Unmarshal(sEnc, at)
+	at, err := a.parseAuthTicket(authTicket)
 	if err != nil {
-		return errors.New("auth_ticket_decode_error", "Error unmarshaling the auth ticket."+err.Error())
+		return err
 	}
 
 	if len(a.Blobbers) == 0 {
@@ -2324,6 +2588,9 @@ func (a *Allocation) RepairAlloc(statusCB StatusCallback) (err error) {
 }
 
 func (a *Allocation) CancelUpload(remotePath string) error {
+	if p, err := a.remotePath(remotePath); err == nil {
+		remotePath = p
+	}
 	cancelLock.Lock()
 	cancelFunc, ok := CancelOpCtx[remotePath]
 	cancelLock.Unlock()
@@ -2336,6 +2603,9 @@ func (a *Allocation) CancelUpload(remotePath string) error {
 }
 
 func (a *Allocation) PauseUpload(remotePath string) error {
+	if p, err := a.remotePath(remotePath); err == nil {
+		remotePath = p
+	}
 	cancelLock.Lock()
 	cancelFunc, ok := CancelOpCtx[remotePath]
 	cancelLock.Unlock()
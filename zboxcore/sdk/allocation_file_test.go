@@ -424,6 +424,7 @@ func TestAllocation_EncryptAndUpdateFile(t *testing.T) {
 	zboxutil.Client = &mockClient
 	mockFastClient := mocks.FastClient{}
 	zboxutil.FastHttpClient = &mockFastClient
+	setupMockClientMnemonic(t)
 
 	const mockLocalPath = "1.txt"
 
@@ -490,6 +491,7 @@ func TestAllocation_EncryptAndUploadFile(t *testing.T) {
 	zboxutil.Client = &mockClient
 	mockFastClient := mocks.FastClient{}
 	zboxutil.FastHttpClient = &mockFastClient
+	setupMockClientMnemonic(t)
 
 	const (
 		mockLocalPath = "1.txt"
@@ -531,6 +533,7 @@ func TestAllocation_EncryptAndUpdateFileWithThumbnail(t *testing.T) {
 	zboxutil.Client = &mockClient
 	mockFastClient := mocks.FastClient{}
 	zboxutil.FastHttpClient = &mockFastClient
+	setupMockClientMnemonic(t)
 
 	const (
 		mockLocalPath     = "1.txt"
@@ -607,6 +610,7 @@ func TestAllocation_EncryptAndUploadFileWithThumbnail(t *testing.T) {
 	zboxutil.Client = &mockClient
 	mockFastClient := mocks.FastClient{}
 	zboxutil.FastHttpClient = &mockFastClient
+	setupMockClientMnemonic(t)
 
 	const (
 		mockLocalPath     = "1.txt"
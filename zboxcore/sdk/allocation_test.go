@@ -42,6 +42,7 @@ const (
 	mockAllocationTxId = "mock transaction id"
 	mockClientId       = "mock client id"
 	mockClientKey      = "mock client key"
+	mockClientMnemonic = "travel twenty hen negative fresh sentence hen flat swift embody increase juice eternal satisfy want vessel matter honey video begin dutch trigger romance assault"
 	mockBlobberId      = "mock blobber id"
 	mockBlobberUrl     = "mockBlobberUrl"
 	mockLookupHash     = "mock lookup hash"
@@ -1392,6 +1393,7 @@ func TestAllocation_GetAuthTicket(t *testing.T) {
 			client.Wallet = &zcncrypto.Wallet{
 				ClientID:  mockClientId,
 				ClientKey: mockClientKey,
+				Mnemonic:  mockClientMnemonic,
 			}
 
 			require := require.New(t)
@@ -2331,6 +2333,14 @@ func TestAllocation_CancelRepair(t *testing.T) {
 	}
 }
 
+// setupMockClientMnemonic gives the client a mnemonic to derive its encryption keys from.
+func setupMockClientMnemonic(t *testing.T) {
+	client := zclient.GetClient()
+	mnemonic := client.Mnemonic
+	client.Mnemonic = mockClientMnemonic
+	t.Cleanup(func() { client.Mnemonic = mnemonic })
+}
+
 func setupMockAllocation(t *testing.T, a *Allocation) {
 	a.downloadChan = make(chan *DownloadRequest, 10)
 	a.repairChan = make(chan *RepairRequest, 1)
@@ -1,10 +1,6 @@
 package sdk
 
 import (
-	"encoding/base64"
-	"encoding/json"
-
-	"github.com/0chain/errors"
 	"github.com/0chain/gosdk/zboxcore/fileref"
 	"github.com/0chain/gosdk/zboxcore/marker"
 )
@@ -20,53 +16,34 @@ func InitAuthTicket(authTicket string) *AuthTicket {
 }
 
 func (at *AuthTicket) IsDir() (bool, error) {
-	sEnc, err := base64.StdEncoding.DecodeString(at.b64Ticket)
-	if err != nil {
-		return false, errors.New("auth_ticket_decode_error", "Error decoding the auth ticket."+err.Error())
-	}
-	authTicket := &marker.AuthTicket{}
-	err = json.Unmarshal(sEnc, authTicket)
+	authTicket, err := marker.DecodeAuthTicket(at.b64Ticket)
 	if err != nil {
-		return false, errors.New("auth_ticket_decode_error", "Error unmarshaling the auth ticket."+err.Error())
+		return false, err
 	}
 	return authTicket.RefType == fileref.DIRECTORY, nil
 }
 
 func (at *AuthTicket) GetFileName() (string, error) {
-	sEnc, err := base64.StdEncoding.DecodeString(at.b64Ticket)
+	authTicket, err := marker.DecodeAuthTicket(at.b64Ticket)
 	if err != nil {
-		return "", errors.New("auth_ticket_decode_error", "Error decoding the auth ticket."+err.Error())
-	}
-	authTicket := &marker.AuthTicket{}
-	err = json.Unmarshal(sEnc, authTicket)
-	if err != nil {
-		return "", errors.New("auth_ticket_decode_error", "Error unmarshaling the auth ticket."+err.Error())
+		return "", err
 	}
 	return authTicket.FileName, nil
 }
 
 func (at *AuthTicket) GetLookupHash() (string, error) {
-	sEnc, err := base64.StdEncoding.DecodeString(at.b64Ticket)
-	if err != nil {
-		return "", errors.New("auth_ticket_decode_error", "Error decoding the auth ticket."+err.Error())
-	}
-	authTicket := &marker.AuthTicket{}
-	err = json.Unmarshal(sEnc, authTicket)
+	authTicket, err := marker.DecodeAuthTicket(at.b64Ticket)
 	if err != nil {
-		return "", errors.New("auth_ticket_decode_error", "Error unmarshaling the auth ticket."+err.Error())
+		return "", err
 	}
 	return authTicket.FilePathHash, nil
 }
 
 func (at *AuthTicket) Unmarshall() (*marker.AuthTicket, error) {
-	sEnc, err := base64.StdEncoding.DecodeString(at.b64Ticket)
-	if err != nil {
-		return nil, errors.New("auth_ticket_decode_error", "Error decoding the auth ticket."+err.Error())
-	}
-	authTicket := &marker.AuthTicket{}
-	err = json.Unmarshal(sEnc, authTicket)
-	if err != nil {
-		return nil, errors.New("auth_ticket_decode_error", "Error unmarshaling the auth ticket."+err.Error())
-	}
-	return authTicket, nil
+	return marker.DecodeAuthTicket(at.b64Ticket)
+}
+
+// Parse decodes and validates the auth ticket, see marker.ParseAuthTicket.
+func (at *AuthTicket) Parse(opts ...marker.AuthTicketOption) (*marker.AuthTicket, error) {
+	return marker.ParseAuthTicket(at.b64Ticket, opts...)
 }
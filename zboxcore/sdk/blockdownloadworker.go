@@ -62,6 +62,7 @@ type downloadBlock struct {
 	LatestRM    *marker.ReadMarker `json:"latest_rm"`
 	idx         int
 	maskIdx     int
+	blockNum    int64
 	err         error
 	timeTaken   int64
 }
@@ -215,6 +216,7 @@ func (req *BlockDownloadRequest) downloadBlobberBlock(hostClient *fasthttp.HostC
 
 			rspData.idx = req.blobberIdx
 			rspData.maskIdx = req.maskIdx
+			rspData.blockNum = req.blockNum
 			rspData.timeTaken = timeTaken
 			rspData.Success = true
 
@@ -230,13 +230,13 @@ func CreateChunkedUpload(
 	}
 
 	su.loadProgress()
-	su.shardSize = getShardSize(su.fileMeta.ActualSize, su.allocationObj.DataShards, su.encryptOnUpload)
+	su.shardSize = getShardSizeWithOverhead(su.fileMeta.ActualSize, su.allocationObj.DataShards, su.encryptionOverhead())
 	su.fileHasher = CreateHasher(su.shardSize)
 
 	// encrypt option has been changed. upload it from scratch
 	// chunkSize has been changed. upload it from scratch
 	// actual size has been changed. upload it from scratch
-	if su.progress.ChunkSize != su.chunkSize || su.progress.EncryptOnUpload != su.encryptOnUpload || su.progress.ActualSize != su.fileMeta.ActualSize || su.progress.ChunkNumber != su.chunkNumber || su.progress.ConnectionID == "" {
+	if su.progress.ChunkSize != su.chunkSize || su.progress.EncryptOnUpload != su.encryptOnUpload || su.progress.EncryptionMode != su.encryptionMode || su.progress.ActualSize != su.fileMeta.ActualSize || su.progress.ChunkNumber != su.chunkNumber || su.progress.ConnectionID == "" {
 		su.progress.ChunkSize = 0 // reset chunk size
 	}
 
@@ -251,7 +251,15 @@ func CreateChunkedUpload(
 		return nil, err
 	}
 
-	if su.encryptOnUpload {
+	if su.encryptOnUpload && su.encryptionMode == EncryptionModeGCM {
+		if su.chunkSize <= encryption.GCMOverhead {
+			return nil, ErrInvalidChunkSize
+		}
+		su.chunkCipher, err = su.createChunkCipher()
+		if err != nil {
+			return nil, thrown.New("upload_failed", "Failed to create chunk cipher: "+err.Error())
+		}
+	} else if su.encryptOnUpload {
 		su.fileEncscheme = su.createEncscheme()
 		if su.fileEncscheme == nil {
 			return nil, thrown.New("upload_failed", "Failed to create encryption scheme")
@@ -290,7 +298,7 @@ func CreateChunkedUpload(
 			},
 		}
 	}
-	cReader, err := createChunkReader(su.fileReader, fileMeta.ActualSize, int64(su.chunkSize), su.allocationObj.DataShards, su.encryptOnUpload, su.uploadMask, su.fileErasureEncoder, su.fileEncscheme, su.fileHasher, su.chunkNumber)
+	cReader, err := createChunkReader(su.fileReader, fileMeta.ActualSize, int64(su.chunkSize), su.allocationObj.DataShards, su.encryptOnUpload, su.uploadMask, su.fileErasureEncoder, su.fileEncscheme, su.chunkCipher, su.fileHasher, su.chunkNumber)
 
 	if err != nil {
 		return nil, err
@@ -391,6 +399,7 @@ func (su *ChunkedUpload) createUploadProgress(connectionId string) {
 			ChunkIndex:        -1,
 			ChunkSize:         su.chunkSize,
 			EncryptOnUpload:   su.encryptOnUpload,
+			EncryptionMode:    su.encryptionMode,
 			EncryptedKeyPoint: su.encryptedKeyPoint,
 			ActualSize:        su.fileMeta.ActualSize,
 			ChunkNumber:       su.chunkNumber,
@@ -420,10 +429,13 @@ func (su *ChunkedUpload) createEncscheme() encryption.EncryptionScheme {
 			return nil
 		}
 	} else {
-		privateKey, err := encscheme.Initialize(client.GetClient().Mnemonic)
+		privateKey, err := clientEncryptionPrivateKey()
 		if err != nil {
 			return nil
 		}
+		if err := encscheme.InitializeWithPrivateKey(privateKey); err != nil {
+			return nil
+		}
 
 		su.progress.EncryptPrivateKey = hex.EncodeToString(privateKey)
 	}
@@ -440,6 +452,57 @@ func (su *ChunkedUpload) createEncscheme() encryption.EncryptionScheme {
 	return encscheme
 }
 
+// createChunkCipher creates the cipher of the file's data key, which is
+// wrapped with the client's keys and saved as the encrypted key point.
+func (su *ChunkedUpload) createChunkCipher() (*encryption.ChunkCipher, error) {
+	owner, err := clientEncryptionScheme()
+	if err != nil {
+		return nil, err
+	}
+
+	var dataKey []byte
+	switch point := su.progress.EncryptedKeyPoint; {
+	case point == "":
+		dataKey, err = encryption.NewDataKey()
+		if err != nil {
+			return nil, err
+		}
+		fileKey, err := wrapDataKey(owner, dataKey)
+		if err != nil {
+			return nil, err
+		}
+		su.progress.EncryptedKeyPoint, err = fileKey.encode()
+		if err != nil {
+			return nil, err
+		}
+	case isGCMKeyPoint(point):
+		// resumed upload or repair, keep the data key of the file
+		lookupHash := fileref.GetReferenceLookup(su.allocationObj.ID, su.fileMeta.RemotePath)
+		dataKey, _, _, err = ownerDataKey(lookupHash, point)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errInvalidFileKey
+	}
+
+	// blobbers must not re-encrypt the chunks of shared files
+	su.encryptedKey = ""
+	return encryption.NewChunkCipher(dataKey)
+}
+
+// encryptionOverhead returns the bytes encryption adds to a chunk.
+func (su *ChunkedUpload) encryptionOverhead() int64 {
+	switch {
+	case !su.encryptOnUpload:
+		return 0
+	case su.encryptionMode == EncryptionModeGCM:
+		return encryption.GCMOverhead
+	default:
+		return EncryptedDataPaddingSize + EncryptionHeaderSize
+	}
+}
+
 func (su *ChunkedUpload) process() error {
 	if su.statusCallback != nil {
 		su.statusCallback.Started(su.allocationObj.ID, su.fileMeta.RemotePath, su.opCode, int(su.fileMeta.ActualSize)+int(su.fileMeta.ActualThumbnailSize))
@@ -475,7 +538,7 @@ func (su *ChunkedUpload) process() error {
 			}
 			if su.fileMeta.ActualSize == 0 {
 				su.fileMeta.ActualSize = su.progress.ReadLength
-				su.shardSize = getShardSize(su.fileMeta.ActualSize, su.allocationObj.DataShards, su.encryptOnUpload)
+				su.shardSize = getShardSizeWithOverhead(su.fileMeta.ActualSize, su.allocationObj.DataShards, su.encryptionOverhead())
 			} else if su.fileMeta.ActualSize != su.progress.ReadLength && su.thumbnailBytes == nil {
 				if su.statusCallback != nil {
 					su.statusCallback.Error(su.allocationObj.ID, su.fileMeta.RemotePath, su.opCode, thrown.New("upload_failed", "Upload failed. Uploaded size does not match with actual size: "+fmt.Sprintf("%d != %d", su.fileMeta.ActualSize, su.progress.ReadLength)))
@@ -785,22 +848,23 @@ func (su *ChunkedUpload) processCommit() error {
 
 // getShardSize will return the size of data of a file each blobber is getting.
 func getShardSize(dataSize int64, dataShards int, isEncrypted bool) int64 {
-	chunkSize := int64(DefaultChunkSize)
 	if isEncrypted {
-		chunkSize -= (EncryptedDataPaddingSize + EncryptionHeaderSize)
+		return getShardSizeWithOverhead(dataSize, dataShards, EncryptedDataPaddingSize+EncryptionHeaderSize)
 	}
+	return getShardSizeWithOverhead(dataSize, dataShards, 0)
+}
+
+// getShardSizeWithOverhead will return the size of data of a file each blobber is getting
+// when encryption adds overhead bytes to every chunk.
+func getShardSizeWithOverhead(dataSize int64, dataShards int, overhead int64) int64 {
+	chunkSize := int64(DefaultChunkSize) - overhead
 
 	totalChunkSize := chunkSize * int64(dataShards)
 
 	n := dataSize / totalChunkSize
 	r := dataSize % totalChunkSize
 
-	var remainderShards int64
-	if isEncrypted {
-		remainderShards = (r+int64(dataShards)-1)/int64(dataShards) + EncryptedDataPaddingSize + EncryptionHeaderSize
-	} else {
-		remainderShards = (r + int64(dataShards) - 1) / int64(dataShards)
-	}
+	remainderShards := (r+int64(dataShards)-1)/int64(dataShards) + overhead
 	return n*DefaultChunkSize + remainderShards
 }
 
@@ -59,6 +59,8 @@ type chunkedUploadChunkReader struct {
 	erasureEncoder reedsolomon.Encoder
 	// encscheme encryption scheme
 	encscheme encryption.EncryptionScheme
+	// chunkCipher encrypts chunks instead of encscheme in EncryptionModeGCM
+	chunkCipher *encryption.ChunkCipher
 	// hasher to calculate actual file hash, validation root and fixed merkle root
 	hasher         Hasher
 	hasherDataChan chan []byte
@@ -68,7 +70,7 @@ type chunkedUploadChunkReader struct {
 }
 
 // createChunkReader create ChunkReader instance
-func createChunkReader(fileReader io.Reader, size, chunkSize int64, dataShards int, encryptOnUpload bool, uploadMask zboxutil.Uint128, erasureEncoder reedsolomon.Encoder, encscheme encryption.EncryptionScheme, hasher Hasher, chunkNumber int) (ChunkedUploadChunkReader, error) {
+func createChunkReader(fileReader io.Reader, size, chunkSize int64, dataShards int, encryptOnUpload bool, uploadMask zboxutil.Uint128, erasureEncoder reedsolomon.Encoder, encscheme encryption.EncryptionScheme, chunkCipher *encryption.ChunkCipher, hasher Hasher, chunkNumber int) (ChunkedUploadChunkReader, error) {
 
 	if chunkSize <= 0 {
 		return nil, errors.Throw(constants.ErrInvalidParameter, "chunkSize: "+strconv.FormatInt(chunkSize, 10))
@@ -96,6 +98,7 @@ func createChunkReader(fileReader io.Reader, size, chunkSize int64, dataShards i
 		uploadMask:      uploadMask,
 		erasureEncoder:  erasureEncoder,
 		encscheme:       encscheme,
+		chunkCipher:     chunkCipher,
 		hasher:          hasher,
 		hasherDataChan:  make(chan []byte, 3*chunkNumber),
 		hasherWG:        sync.WaitGroup{},
@@ -104,6 +107,9 @@ func createChunkReader(fileReader io.Reader, size, chunkSize int64, dataShards i
 	if r.encryptOnUpload {
 		//additional 16 bytes to save encrypted data
 		r.chunkHeaderSize = EncryptedDataPaddingSize + EncryptionHeaderSize
+		if r.chunkCipher != nil {
+			r.chunkHeaderSize = encryption.GCMOverhead
+		}
 		r.chunkDataSize = chunkSize - r.chunkHeaderSize
 	} else {
 		r.chunkDataSize = chunkSize
@@ -213,6 +219,10 @@ func (r *chunkedUploadChunkReader) Next() (*ChunkData, error) {
 	if r.encryptOnUpload {
 		for i := r.uploadMask; !i.Equals64(0); i = i.And(zboxutil.NewUint128(1).Lsh(pos).Not()) {
 			pos = uint64(i.TrailingZeros())
+			if r.chunkCipher != nil {
+				fragments[pos] = r.chunkCipher.Seal(encryption.ContentFile, int(pos), int64(chunk.Index), fragments[pos])
+				continue
+			}
 			encMsg, err := r.encscheme.Encrypt(fragments[pos])
 			if err != nil {
 				return nil, err
@@ -253,6 +263,11 @@ func (r *chunkedUploadChunkReader) Read(buf []byte) ([][]byte, error) {
 	if r.encryptOnUpload {
 		for i := r.uploadMask; !i.Equals64(0); i = i.And(zboxutil.NewUint128(1).Lsh(pos).Not()) {
 			pos = uint64(i.TrailingZeros())
+			if r.chunkCipher != nil {
+				// thumbnails are a single chunk
+				fragments[pos] = r.chunkCipher.Seal(encryption.ContentThumbnail, int(pos), 0, fragments[pos])
+				continue
+			}
 			encMsg, err := r.encscheme.Encrypt(fragments[pos])
 			if err != nil {
 				return nil, err
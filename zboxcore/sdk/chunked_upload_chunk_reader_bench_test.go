@@ -67,7 +67,7 @@ func BenchmarkChunkedUploadChunkReader(b *testing.B) {
 					bytes.NewReader(buf), int64(bm.Size),
 					int64(bm.ChunkSize), bm.DataShards,
 					bm.EncryptOnUpload, uploadMask,
-					erasureEncoder, encscheme,
+					erasureEncoder, encscheme, nil,
 					CreateHasher(getShardSize(bm.Size, bm.DataShards, bm.EncryptOnUpload)), 100,
 				)
 				if err != nil {
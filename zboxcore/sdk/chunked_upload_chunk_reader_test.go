@@ -5,6 +5,7 @@ import (
 	"math"
 	"testing"
 
+	"github.com/0chain/gosdk/zboxcore/blockchain"
 	"github.com/0chain/gosdk/zboxcore/encryption"
 	"github.com/0chain/gosdk/zboxcore/zboxutil"
 	"github.com/klauspost/reedsolomon"
@@ -82,7 +83,7 @@ func TestReadChunks(t *testing.T) {
 				bytes.NewReader(buf), int64(test.Size),
 				int64(test.ChunkSize), test.DataShards,
 				test.EncryptOnUpload, uploadMask,
-				erasureEncoder, encscheme,
+				erasureEncoder, encscheme, nil,
 				CreateHasher(getShardSize(test.Size, test.DataShards, test.EncryptOnUpload)), 100,
 			)
 			require.Nil(err)
@@ -128,3 +129,87 @@ func TestReadChunks(t *testing.T) {
 		})
 	}
 }
+
+func TestChunkCipherRoundTrip(t *testing.T) {
+	const dataShards, parityShards = 2, 1
+	blobbers := dataShards + parityShards
+	size := int64(KB*64*2*3 + KB)
+
+	dataKey, err := encryption.NewDataKey()
+	require.NoError(t, err)
+	chunkCipher, err := encryption.NewChunkCipher(dataKey)
+	require.NoError(t, err)
+	erasureEncoder, err := reedsolomon.New(dataShards, parityShards)
+	require.NoError(t, err)
+
+	buf := generateRandomBytes(size)
+	reader, err := createChunkReader(bytes.NewReader(buf), size, BlockSize, dataShards, true,
+		zboxutil.NewUint128(1).Lsh(uint64(blobbers)).Sub64(1), erasureEncoder, nil, chunkCipher,
+		CreateHasher(getShardSize(size, dataShards, true)), 100)
+	require.NoError(t, err)
+
+	// the fragments of every blobber, one per chunk
+	fragments := make([][][]byte, blobbers)
+	var chunks int
+	for {
+		chunk, err := reader.Next()
+		require.NoError(t, err)
+		if chunk.ReadSize > 0 {
+			require.Equal(t, chunks, chunk.Index)
+			for pos := range fragments {
+				fragments[pos] = append(fragments[pos], chunk.Fragments[pos])
+			}
+			chunks++
+		}
+		if chunk.IsFinal {
+			break
+		}
+	}
+	reader.Close()
+	require.Equal(t, 4, chunks)
+
+	req := &DownloadRequest{
+		chunkCipher: chunkCipher,
+		blobbers:    make([]*blockchain.StorageNode, blobbers),
+		ecEncoder:   erasureEncoder,
+	}
+	for i := range req.blobbers {
+		req.blobbers[i] = &blockchain.StorageNode{ID: "blobber", Baseurl: "http://blobber"}
+	}
+
+	// download the blocks from the second one, as a range download does
+	startBlock := 1
+	shards := make([][][]byte, chunks-startBlock)
+	for i := range shards {
+		shards[i] = make([][]byte, blobbers)
+	}
+	for pos := range fragments {
+		result := &downloadBlock{idx: pos, blockNum: int64(startBlock), BlockChunks: fragments[pos][startBlock:]}
+		require.NoError(t, req.fillShards(shards, result))
+	}
+
+	var data []byte
+	for _, blockShards := range shards {
+		require.NoError(t, req.decodeEC(blockShards))
+		for _, shard := range blockShards[:dataShards] {
+			data = append(data, shard...)
+		}
+	}
+	offset := startBlock * (BlockSize - encryption.GCMOverhead) * dataShards
+	require.Equal(t, buf[offset:], data[:len(buf)-offset])
+
+	t.Run("nonce", func(t *testing.T) {
+		// a fragment of another blobber position
+		result := &downloadBlock{idx: 1, blockNum: 0, BlockChunks: fragments[0][:1]}
+		require.Error(t, req.fillShards(shards, result))
+
+		// a fragment of another chunk index
+		result = &downloadBlock{idx: 0, blockNum: 1, BlockChunks: fragments[0][:1]}
+		require.Error(t, req.fillShards(shards, result))
+
+		// a thumbnail fragment is sealed with the thumbnail content
+		req.contentMode = DOWNLOAD_CONTENT_THUMB
+		result = &downloadBlock{idx: 0, blockNum: 0, BlockChunks: fragments[0][:1]}
+		require.Error(t, req.fillShards(shards, result))
+	})
+}
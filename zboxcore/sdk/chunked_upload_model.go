@@ -52,6 +52,10 @@ type ChunkedUpload struct {
 
 	// encryptOnUpload encrypt data on upload or not.
 	encryptOnUpload bool
+	// encryptionMode how data is encrypted on upload
+	encryptionMode EncryptionMode
+	// chunkCipher encrypts chunks in EncryptionModeGCM
+	chunkCipher *encryption.ChunkCipher
 	// webStreaming whether data has to be encoded.
 	webStreaming bool
 	// chunkSize how much bytes a chunk has. 64KB is default value.
@@ -174,9 +178,10 @@ type UploadProgress struct {
 	ActualSize  int64 `json:"actual_size,omitempty"`
 	ChunkNumber int   `json:"chunk_number,omitempty"`
 	// EncryptOnUpload encrypt data on upload or not
-	EncryptOnUpload   bool   `json:"is_encrypted,omitempty"`
-	EncryptPrivateKey string `json:"-"`
-	EncryptedKeyPoint string `json:"encrypted_key_point,omitempty"`
+	EncryptOnUpload   bool           `json:"is_encrypted,omitempty"`
+	EncryptionMode    EncryptionMode `json:"encryption_mode,omitempty"`
+	EncryptPrivateKey string         `json:"-"`
+	EncryptedKeyPoint string         `json:"encrypted_key_point,omitempty"`
 
 	// ConnectionID chunked upload connection_id
 	ConnectionID string `json:"connection_id,omitempty"`
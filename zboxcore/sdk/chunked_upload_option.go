@@ -61,6 +61,14 @@ func WithEncrypt(on bool) ChunkedUploadOption {
 	}
 }
 
+// WithEncryptionMode turn on encrypt on upload with the encryption mode.
+func WithEncryptionMode(mode EncryptionMode) ChunkedUploadOption {
+	return func(su *ChunkedUpload) {
+		su.encryptOnUpload = true
+		su.encryptionMode = mode
+	}
+}
+
 // WithStatusCallback register StatusCallback instance
 func WithStatusCallback(callback StatusCallback) ChunkedUploadOption {
 	return func(su *ChunkedUpload) {
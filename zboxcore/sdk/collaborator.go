@@ -0,0 +1,434 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"sync"
+
+	"github.com/0chain/errors"
+
+	"github.com/0chain/gosdk/constants"
+	"github.com/0chain/gosdk/zboxcore/blockchain"
+	"github.com/0chain/gosdk/zboxcore/client"
+	"github.com/0chain/gosdk/zboxcore/fileref"
+	l "github.com/0chain/gosdk/zboxcore/logger"
+	"github.com/0chain/gosdk/zboxcore/zboxutil"
+)
+
+// CollaboratorRole is the access of a collaborator to a path.
+type CollaboratorRole string
+
+const (
+	// CollaboratorRead reads the path with an auth ticket shared to the
+	// collaborator, so it is available for encrypted files and directories.
+	// The grant is recorded with the shares of the allocation, marked apart
+	// from the other private shares.
+	CollaboratorRead CollaboratorRole = "read"
+	// CollaboratorWrite is a collaborator of the path on the blobbers. The
+	// collaborator's writes are committed like the owner's, with write
+	// markers signed by the collaborator, so they need blobbers accepting
+	// the write markers of the collaborators of the paths, see
+	// SetCollaboratorWrites.
+	CollaboratorWrite CollaboratorRole = "write"
+)
+
+var (
+	ErrInvalidCollaboratorRole    = errors.New("invalid_collaborator_role", "collaborator role must be read or write")
+	ErrCollaboratorNotOwner       = errors.New("collaborator_not_permitted", "only the owner can change the collaborators of the allocation")
+	ErrCollaboratorNotFound       = errors.New("collaborator_not_found", "client is not a read collaborator of the path")
+	ErrCollaboratorWritesDisabled = errors.New("collaborator_writes_disabled", "write collaborators need blobbers accepting their write markers, see SetCollaboratorWrites")
+)
+
+var collaboratorWrites = false
+
+// SetCollaboratorWrites enables write collaborators: adding them and the
+// writes of clients other than the owner. Only enable it with blobbers which
+// accept the write markers of the collaborators of the paths, others reject
+// the commits of collaborators. Disabled, both fail with
+// ErrCollaboratorWritesDisabled.
+func SetCollaboratorWrites(enabled bool) {
+	collaboratorWrites = enabled
+}
+
+// CollaboratorInfo is a collaborator of a path.
+type CollaboratorInfo struct {
+	Path     string           `json:"path"`
+	ClientID string           `json:"client_id"`
+	Role     CollaboratorRole `json:"role"`
+	// AuthTicket is the ticket to read the path with, for read collaborators
+	AuthTicket string `json:"auth_ticket,omitempty"`
+}
+
+// AddCollaborator gives the client access to the path. Read collaborators
+// get a private share of the path: encPublicKey is the collaborator's
+// encryption public key and the returned auth ticket is given to the
+// collaborator. Write collaborators are added to the blobbers, if enabled
+// with SetCollaboratorWrites.
+func (a *Allocation) AddCollaborator(filePath, clientID string, role CollaboratorRole, encPublicKey string) (string, error) {
+	if err := a.checkCollaboratorChange(filePath, clientID); err != nil {
+		return "", err
+	}
+	filePath = zboxutil.RemoteClean(filePath)
+
+	switch role {
+	case CollaboratorRead:
+		fileMeta, err := a.GetFileMeta(filePath)
+		if err != nil {
+			return "", err
+		}
+		refType := fileref.FILE
+		if fileMeta.Type == fileref.DIRECTORY {
+			refType = fileref.DIRECTORY
+		}
+		authTicket, err := a.GetAuthTicket(filePath, path.Base(filePath), refType, clientID, encPublicKey, 0, nil)
+		if err != nil {
+			return "", err
+		}
+		getShareRegistry(a.ID).update(filePath, clientID, func(s *ShareInfo) {
+			s.Collaborator = true
+		})
+		return authTicket, nil
+	case CollaboratorWrite:
+		if !collaboratorWrites {
+			return "", ErrCollaboratorWritesDisabled
+		}
+		remotePath, err := a.remotePath(filePath)
+		if err != nil {
+			return "", err
+		}
+		failed, err := a.addCollaboratorToBlobbers(remotePath, clientID)
+		if err != nil {
+			return "", err
+		}
+		return "", a.checkShareConsensus(failed)
+	default:
+		return "", ErrInvalidCollaboratorRole
+	}
+}
+
+// RemoveCollaborator removes the role of the client on the path.
+func (a *Allocation) RemoveCollaborator(filePath, clientID string, role CollaboratorRole) error {
+	if err := a.checkCollaboratorChange(filePath, clientID); err != nil {
+		return err
+	}
+	filePath = zboxutil.RemoteClean(filePath)
+
+	switch role {
+	case CollaboratorRead:
+		if s := a.GetShare(filePath, clientID); s == nil || !s.Collaborator || !s.Active() {
+			return ErrCollaboratorNotFound
+		}
+		return a.RevokeShare(filePath, clientID)
+	case CollaboratorWrite:
+		remotePath, err := a.remotePath(filePath)
+		if err != nil {
+			return err
+		}
+		failed, err := a.removeCollaboratorFromBlobbers(remotePath, clientID)
+		if err != nil {
+			return err
+		}
+		return a.checkShareConsensus(failed)
+	default:
+		return ErrInvalidCollaboratorRole
+	}
+}
+
+// GetCollaborators returns the collaborators of the path: the write
+// collaborators agreed on by the blobbers, and the active read grants of the
+// path. Other private shares of the path aren't collaborators.
+func (a *Allocation) GetCollaborators(filePath string) ([]*CollaboratorInfo, error) {
+	if !a.isInitialized() {
+		return nil, notInitialized
+	}
+	filePath = zboxutil.RemoteClean(filePath)
+	if !zboxutil.IsRemoteAbs(filePath) {
+		return nil, errors.New("invalid_path", "Path should be valid and absolute")
+	}
+	remotePath, err := a.remotePath(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	writers, err := a.getCollaboratorsFromBlobbers(remotePath)
+	if err != nil {
+		return nil, err
+	}
+	var collaborators []*CollaboratorInfo
+	for _, clientID := range writers {
+		collaborators = append(collaborators, &CollaboratorInfo{Path: filePath, ClientID: clientID, Role: CollaboratorWrite})
+	}
+	for _, s := range a.ListShares(&ShareFilter{PathPrefix: filePath}) {
+		if s.Path != filePath || !s.Collaborator {
+			continue
+		}
+		collaborators = append(collaborators, &CollaboratorInfo{
+			Path:       filePath,
+			ClientID:   s.RefereeClientID,
+			Role:       CollaboratorRead,
+			AuthTicket: s.AuthTicket,
+		})
+	}
+	return collaborators, nil
+}
+
+func (a *Allocation) checkCollaboratorChange(filePath, clientID string) error {
+	if !a.isInitialized() {
+		return notInitialized
+	}
+	if client.GetClientID() != a.Owner {
+		return ErrCollaboratorNotOwner
+	}
+	if clientID == "" || clientID == a.Owner {
+		return errors.New("invalid_collaborator", "collaborator must be a client other than the owner")
+	}
+	if filePath == "" || !zboxutil.IsRemoteAbs(filePath) {
+		return errors.New("invalid_path", "Path should be valid and absolute")
+	}
+	return nil
+}
+
+// addCollaboratorToBlobbers adds the collaborator of the remote path and
+// returns the ids of the blobbers which failed.
+func (a *Allocation) addCollaboratorToBlobbers(remotePath, clientID string) ([]string, error) {
+	return a.changeCollaboratorOnBlobbers(func(b *blockchain.StorageNode) (*http.Request, error) {
+		body := new(bytes.Buffer)
+		formWriter := multipart.NewWriter(body)
+		if err := formWriter.WriteField("path", remotePath); err != nil {
+			return nil, err
+		}
+		if err := formWriter.WriteField("collab_id", clientID); err != nil {
+			return nil, err
+		}
+		if err := formWriter.Close(); err != nil {
+			return nil, err
+		}
+		httpreq, err := zboxutil.NewCollaboratorRequest(b.Baseurl, a.ID, a.Tx, body)
+		if err != nil {
+			return nil, err
+		}
+		httpreq.Header.Add("Content-Type", formWriter.FormDataContentType())
+		return httpreq, nil
+	})
+}
+
+// removeCollaboratorFromBlobbers removes the collaborator of the remote path
+// and returns the ids of the blobbers which failed.
+func (a *Allocation) removeCollaboratorFromBlobbers(remotePath, clientID string) ([]string, error) {
+	return a.changeCollaboratorOnBlobbers(func(b *blockchain.StorageNode) (*http.Request, error) {
+		query := &url.Values{}
+		query.Add("path", remotePath)
+		query.Add("collab_id", clientID)
+		return zboxutil.DeleteCollaboratorRequest(b.Baseurl, a.ID, a.Tx, query)
+	})
+}
+
+func (a *Allocation) changeCollaboratorOnBlobbers(newRequest func(b *blockchain.StorageNode) (*http.Request, error)) ([]string, error) {
+	success := make(chan string, len(a.Blobbers))
+	wg := &sync.WaitGroup{}
+	for _, blobber := range a.Blobbers {
+		httpreq, err := newRequest(blobber)
+		if err != nil {
+			return nil, err
+		}
+
+		wg.Add(1)
+		go func(blobber *blockchain.StorageNode) {
+			defer wg.Done()
+			err := zboxutil.HttpDo(a.ctx, a.ctxCancelF, httpreq, func(resp *http.Response, err error) error {
+				if err != nil {
+					l.Logger.Error("Collaborator : ", err)
+					return err
+				}
+				defer resp.Body.Close()
+				if resp.StatusCode != http.StatusOK {
+					respbody, _ := ioutil.ReadAll(resp.Body)
+					l.Logger.Error(blobber.Baseurl, " Collaborator error response: ", resp.StatusCode, string(respbody))
+					return fmt.Errorf(string(respbody))
+				}
+				return nil
+			})
+			if err == nil {
+				success <- blobber.ID
+			}
+		}(blobber)
+	}
+	wg.Wait()
+	close(success)
+	return failedBlobbers(a.Blobbers, success), nil
+}
+
+// getCollaboratorsFromBlobbers returns the client ids of the collaborators
+// of the remote path returned by the consensus of the blobbers.
+func (a *Allocation) getCollaboratorsFromBlobbers(remotePath string) ([]string, error) {
+	var (
+		mu        sync.Mutex
+		responses int
+		counts    = make(map[string]int)
+	)
+	wg := &sync.WaitGroup{}
+	for _, blobber := range a.Blobbers {
+		query := &url.Values{}
+		query.Add("path", remotePath)
+		httpreq, err := zboxutil.GetCollaboratorsRequest(blobber.Baseurl, a.ID, a.Tx, query)
+		if err != nil {
+			return nil, err
+		}
+
+		wg.Add(1)
+		go func(blobber *blockchain.StorageNode) {
+			defer wg.Done()
+			_ = zboxutil.HttpDo(a.ctx, a.ctxCancelF, httpreq, func(resp *http.Response, err error) error {
+				if err != nil {
+					l.Logger.Error("Get collaborators : ", err)
+					return err
+				}
+				defer resp.Body.Close()
+				respbody, err := ioutil.ReadAll(resp.Body)
+				if err != nil {
+					return err
+				}
+				if resp.StatusCode != http.StatusOK {
+					l.Logger.Error(blobber.Baseurl, " Get collaborators error response: ", resp.StatusCode, string(respbody))
+					return fmt.Errorf(string(respbody))
+				}
+				var collaborators []fileref.Collaborator
+				if err := json.Unmarshal(respbody, &collaborators); err != nil {
+					return err
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+				responses++
+				seen := make(map[string]bool, len(collaborators))
+				for _, c := range collaborators {
+					if !seen[c.ClientID] {
+						seen[c.ClientID] = true
+						counts[c.ClientID]++
+					}
+				}
+				return nil
+			})
+		}(blobber)
+	}
+	wg.Wait()
+
+	if responses < a.consensusThreshold {
+		return nil, errors.New("consensus_not_met",
+			fmt.Sprintf("Get collaborators: required consensus %d got %d", a.consensusThreshold, responses))
+	}
+	var clientIDs []string
+	for clientID, count := range counts {
+		if count >= a.consensusThreshold {
+			clientIDs = append(clientIDs, clientID)
+		}
+	}
+	sort.Strings(clientIDs)
+	return clientIDs, nil
+}
+
+// checkCollaboratorOperations returns ErrPathNotPermitted if the client isn't
+// the owner and not a write collaborator of the paths of the operations, and
+// ErrCollaboratorWritesDisabled for clients other than the owner if write
+// collaborators aren't enabled.
+func (a *Allocation) checkCollaboratorOperations(operations []OperationRequest) error {
+	if client.GetClientID() == a.Owner {
+		return nil
+	}
+	checker := newCollaboratorChecker(a)
+	for _, op := range operations {
+		// repairs restore what the blobbers already agreed on
+		if op.IsRepair {
+			continue
+		}
+		if !collaboratorWrites {
+			return ErrCollaboratorWritesDisabled
+		}
+		if err := a.encryptOperationPaths(&op); err != nil {
+			return err
+		}
+		if err := checker.check(&op); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collaboratorChecker checks the client is a write collaborator of the paths
+// of the operations of clients other than the owner.
+type collaboratorChecker struct {
+	a         *Allocation
+	clientID  string
+	permitted map[string]bool // by remote path
+	lookup    func(remotePath string) ([]string, error)
+}
+
+func newCollaboratorChecker(a *Allocation) *collaboratorChecker {
+	return &collaboratorChecker{
+		a:         a,
+		clientID:  client.GetClientID(),
+		permitted: make(map[string]bool),
+		lookup:    a.getCollaboratorsFromBlobbers,
+	}
+}
+
+// isPermitted reports whether the client is a collaborator of the remote path or a parent of it.
+func (c *collaboratorChecker) isPermitted(remotePath string) (bool, error) {
+	if c.clientID == c.a.Owner {
+		return true, nil
+	}
+	if !zboxutil.IsRemoteAbs(remotePath) {
+		return false, nil
+	}
+	for p := zboxutil.RemoteClean(remotePath); ; p = path.Dir(p) {
+		permitted, ok := c.permitted[p]
+		if !ok {
+			collaborators, err := c.lookup(p)
+			if err != nil {
+				return false, err
+			}
+			i := sort.SearchStrings(collaborators, c.clientID)
+			permitted = i < len(collaborators) && collaborators[i] == c.clientID
+			c.permitted[p] = permitted
+		}
+		if permitted {
+			return true, nil
+		}
+		if p == "/" {
+			return false, nil
+		}
+	}
+}
+
+// check returns ErrPathNotPermitted if the client can't write the paths of
+// the operation. The source of copies must be a path of the client too, so
+// clients can't copy files they can't read into the paths they write.
+func (c *collaboratorChecker) check(op *OperationRequest) error {
+	paths := []string{op.RemotePath}
+	switch op.OperationType {
+	case constants.FileOperationInsert, constants.FileOperationUpdate:
+		paths = []string{op.FileMeta.RemotePath}
+	case constants.FileOperationCopy, constants.FileOperationMove:
+		paths = append(paths, op.DestPath)
+	}
+
+	for _, p := range paths {
+		permitted, err := c.isPermitted(p)
+		if err != nil {
+			return err
+		}
+		if !permitted {
+			return errors.New(ErrPathNotPermitted.Code, fmt.Sprintf("client %s is not a write collaborator of %s", c.clientID, c.a.plainPath(p)))
+		}
+	}
+	return nil
+}
+
+var ErrPathNotPermitted = errors.New("path_not_permitted", "client is not permitted to write the path")
@@ -0,0 +1,194 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/0chain/errors"
+	"github.com/0chain/gosdk/constants"
+	"github.com/0chain/gosdk/core/zcncrypto"
+	"github.com/0chain/gosdk/zboxcore/blockchain"
+	zclient "github.com/0chain/gosdk/zboxcore/client"
+	"github.com/0chain/gosdk/zboxcore/fileref"
+	"github.com/0chain/gosdk/zboxcore/mocks"
+	"github.com/0chain/gosdk/zboxcore/zboxutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollaboratorChecker(t *testing.T) {
+	collaborators := map[string][]string{
+		"/shared":      {"alice", "bob"},
+		"/shared/docs": {"carol"},
+	}
+	newChecker := func(clientID string) (*collaboratorChecker, map[string]int) {
+		lookups := make(map[string]int)
+		return &collaboratorChecker{
+			a:         &Allocation{Owner: "owner"},
+			clientID:  clientID,
+			permitted: make(map[string]bool),
+			lookup: func(remotePath string) ([]string, error) {
+				lookups[remotePath]++
+				return collaborators[remotePath], nil
+			},
+		}, lookups
+	}
+
+	tests := []struct {
+		name      string
+		clientID  string
+		op        OperationRequest
+		permitted bool
+	}{
+		{"owner", "owner", OperationRequest{OperationType: constants.FileOperationDelete, RemotePath: "/private/a.txt"}, true},
+		{"collaborator", "alice", OperationRequest{OperationType: constants.FileOperationDelete, RemotePath: "/shared/a.txt"}, true},
+		{"parent collaborator", "alice", OperationRequest{OperationType: constants.FileOperationCreateDir, RemotePath: "/shared/docs/new"}, true},
+		{"child collaborator", "carol", OperationRequest{OperationType: constants.FileOperationDelete, RemotePath: "/shared/a.txt"}, false},
+		{"other path", "alice", OperationRequest{OperationType: constants.FileOperationDelete, RemotePath: "/private/a.txt"}, false},
+		{"upload", "bob", OperationRequest{OperationType: constants.FileOperationInsert, FileMeta: FileMeta{RemotePath: "/shared/b.txt"}}, true},
+		{"copy to shared", "bob", OperationRequest{OperationType: constants.FileOperationCopy, RemotePath: "/private/a.txt", DestPath: "/shared"}, false},
+		{"copy in shared", "bob", OperationRequest{OperationType: constants.FileOperationCopy, RemotePath: "/shared/a.txt", DestPath: "/shared/docs"}, true},
+		{"move from private", "bob", OperationRequest{OperationType: constants.FileOperationMove, RemotePath: "/private/a.txt", DestPath: "/shared"}, false},
+		{"invalid path", "bob", OperationRequest{OperationType: constants.FileOperationDelete, RemotePath: "shared/a.txt"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newChecker(tt.clientID)
+			err := c.check(&tt.op)
+			if tt.permitted {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.True(t, errors.Is(err, ErrPathNotPermitted))
+		})
+	}
+
+	t.Run("cached", func(t *testing.T) {
+		c, lookups := newChecker("carol")
+		for i := 0; i < 3; i++ {
+			require.NoError(t, c.check(&OperationRequest{OperationType: constants.FileOperationDelete, RemotePath: "/shared/docs/a.txt"}))
+		}
+		require.Equal(t, map[string]int{"/shared/docs/a.txt": 1, "/shared/docs": 1}, lookups)
+	})
+}
+
+// collaboratorBlobber is a mocked blobber keeping the collaborators of the
+// paths.
+type collaboratorBlobber struct {
+	mu            sync.Mutex
+	collaborators map[string]map[string]bool
+}
+
+func (b *collaboratorBlobber) do(req *http.Request) (*http.Response, error) {
+	respond := func(body interface{}) (*http.Response, error) {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader(buf))}, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case strings.HasPrefix(req.URL.Path, zboxutil.COLLABORATOR_ENDPOINT):
+		switch req.Method {
+		case http.MethodPost:
+			if err := req.ParseMultipartForm(1 << 20); err != nil {
+				return nil, err
+			}
+			remotePath := req.FormValue("path")
+			if b.collaborators[remotePath] == nil {
+				b.collaborators[remotePath] = make(map[string]bool)
+			}
+			b.collaborators[remotePath][req.FormValue("collab_id")] = true
+		case http.MethodDelete:
+			delete(b.collaborators[req.URL.Query().Get("path")], req.URL.Query().Get("collab_id"))
+		default:
+			collaborators := []fileref.Collaborator{}
+			for clientID := range b.collaborators[req.URL.Query().Get("path")] {
+				collaborators = append(collaborators, fileref.Collaborator{ClientID: clientID})
+			}
+			return respond(collaborators)
+		}
+		return respond(map[string]string{})
+	case strings.HasPrefix(req.URL.Path, zboxutil.FILE_META_ENDPOINT):
+		return respond(&fileref.FileRef{
+			Ref:          fileref.Ref{Type: fileref.FILE, Name: "a.txt", Path: "/docs/a.txt"},
+			EncryptedKey: "encrypted key",
+		})
+	default:
+		return respond(map[string]string{})
+	}
+}
+
+func TestCollaborators(t *testing.T) {
+	defer func(c zboxutil.HttpClient) { zboxutil.Client = c }(zboxutil.Client)
+	blobber := &collaboratorBlobber{collaborators: make(map[string]map[string]bool)}
+	mockClient := &mocks.HttpClient{}
+	mockClient.On("Do", mock.Anything).Return(blobber.do)
+	zboxutil.Client = mockClient
+
+	client := zclient.GetClient()
+	client.Wallet = &zcncrypto.Wallet{
+		ClientID:  mockClientId,
+		ClientKey: mockClientKey,
+	}
+	setupMockClientMnemonic(t)
+
+	a := &Allocation{ID: "collaborators", Tx: "collaborators", Owner: mockClientId, DataShards: 2, ParityShards: 1}
+	for i := 0; i < 3; i++ {
+		a.Blobbers = append(a.Blobbers, &blockchain.StorageNode{
+			ID:      fmt.Sprintf("blobber%d", i),
+			Baseurl: fmt.Sprintf("http://blobber%d", i),
+		})
+	}
+	setupMockAllocation(t, a)
+	defer a.ctxCancelF()
+
+	readerKey, err := newTestEncryptionScheme(t, "reader").GetPublicKey()
+	require.NoError(t, err)
+	otherKey, err := newTestEncryptionScheme(t, "other").GetPublicKey()
+	require.NoError(t, err)
+
+	_, err = a.AddCollaborator("/docs", "writer", CollaboratorWrite, "")
+	require.True(t, errors.Is(err, ErrCollaboratorWritesDisabled))
+
+	SetCollaboratorWrites(true)
+	defer SetCollaboratorWrites(false)
+	_, err = a.AddCollaborator("/docs", "writer", CollaboratorWrite, "")
+	require.NoError(t, err)
+	ticket, err := a.AddCollaborator("/docs/a.txt", "reader", CollaboratorRead, readerKey)
+	require.NoError(t, err)
+	require.NotEmpty(t, ticket)
+
+	// a private share of the path isn't a read grant
+	_, err = a.GetAuthTicket("/docs/a.txt", "a.txt", fileref.FILE, "other", otherKey, 0, nil)
+	require.NoError(t, err)
+	require.True(t, errors.Is(a.RemoveCollaborator("/docs/a.txt", "other", CollaboratorRead), ErrCollaboratorNotFound))
+
+	collaborators, err := a.GetCollaborators("/docs")
+	require.NoError(t, err)
+	require.Equal(t, []*CollaboratorInfo{{Path: "/docs", ClientID: "writer", Role: CollaboratorWrite}}, collaborators)
+	collaborators, err = a.GetCollaborators("/docs/a.txt")
+	require.NoError(t, err)
+	require.Equal(t, []*CollaboratorInfo{{Path: "/docs/a.txt", ClientID: "reader", Role: CollaboratorRead, AuthTicket: ticket}}, collaborators)
+
+	require.NoError(t, a.RemoveCollaborator("/docs", "writer", CollaboratorWrite))
+	require.NoError(t, a.RemoveCollaborator("/docs/a.txt", "reader", CollaboratorRead))
+	require.True(t, errors.Is(a.RemoveCollaborator("/docs/a.txt", "reader", CollaboratorRead), ErrCollaboratorNotFound))
+
+	collaborators, err = a.GetCollaborators("/docs")
+	require.NoError(t, err)
+	require.Empty(t, collaborators)
+	collaborators, err = a.GetCollaborators("/docs/a.txt")
+	require.NoError(t, err)
+	require.Empty(t, collaborators)
+}
@@ -55,6 +55,21 @@ func WithWorkDir(workdir string) DownloadRequestOption {
 	}
 }
 
+// WithGroupKey decrypts files shared with a group with the group key.
+func WithGroupKey(key *GroupKey) DownloadRequestOption {
+	return func(dr *DownloadRequest) {
+		dr.groupKey = key
+	}
+}
+
+// WithDecryptionKey decrypts files shared to the encryption public key of
+// the base64 private key, e.g. the key of an unlocked public link.
+func WithDecryptionKey(privateKey string) DownloadRequestOption {
+	return func(dr *DownloadRequest) {
+		dr.decryptionKey = privateKey
+	}
+}
+
 func WithFileCallback(cb func()) DownloadRequestOption {
 	return func(dr *DownloadRequest) {
 		dr.fileCallback = cb
@@ -93,6 +108,8 @@ type DownloadRequest struct {
 	ecEncoder          reedsolomon.Encoder
 	maskMu             *sync.Mutex
 	encScheme          encryption.EncryptionScheme
+	gcmFileKey         string // encrypted key point of EncryptionModeGCM files
+	chunkCipher        *encryption.ChunkCipher
 	shouldVerify       bool
 	blocksPerShard     int64
 	connectionID       string
@@ -104,6 +121,8 @@ type DownloadRequest struct {
 	offset             int64
 	bufferMap          map[int]zboxutil.DownloadBuffer
 	downloadStorer     DownloadProgressStorer
+	groupKey           *GroupKey
+	decryptionKey      string
 	workdir            string
 	downloadQueue      downloadQueue // Always initialize this queue with max time taken
 }
@@ -345,6 +364,11 @@ func (req *DownloadRequest) fillShards(shards [][][]byte, result *downloadBlock)
 			if err != nil {
 				return err
 			}
+		} else if req.chunkCipher != nil {
+			data, err = req.openChunk(result, i)
+			if err != nil {
+				return err
+			}
 		} else {
 			data = result.BlockChunks[i]
 		}
@@ -411,6 +435,23 @@ func (req *DownloadRequest) getDecryptedDataForAuthTicket(result *downloadBlock,
 	return decrypted, nil
 }
 
+// openChunk will decrypt and authenticate a block of an EncryptionModeGCM file.
+func (req *DownloadRequest) openChunk(result *downloadBlock, blockNum int) ([]byte, error) {
+	content, index := encryption.ContentFile, result.blockNum+int64(blockNum)
+	if req.contentMode == DOWNLOAD_CONTENT_THUMB {
+		content, index = encryption.ContentThumbnail, 0
+	}
+	data, err := req.chunkCipher.Open(content, result.idx, index, result.BlockChunks[blockNum])
+	if err != nil {
+		logger.Logger.Error("Block decryption failed", req.blobbers[result.idx].Baseurl, err)
+		return nil, errors.New(
+			"decryption_error",
+			fmt.Sprintf("Decryption error %s while decrypting data from %s blobber",
+				err.Error(), req.blobbers[result.idx].Baseurl))
+	}
+	return data, nil
+}
+
 // processDownload will setup download parameters and downloads data with given
 // start block, end block and number of blocks to download in single request.
 // This will also write data to the file handler and will verify content by calculating content hash.
@@ -461,12 +502,14 @@ func (req *DownloadRequest) processDownload() {
 	elapsedInitEC := time.Since(now)
 	if req.encryptedKey != "" {
 		err = req.initEncryption()
-		if err != nil {
-			req.errorCB(
-				fmt.Errorf("Error while initializing encryption"), remotePathCB,
-			)
-			return
-		}
+	} else if req.gcmFileKey != "" {
+		err = req.initChunkCipher()
+	}
+	if err != nil {
+		req.errorCB(
+			fmt.Errorf("Error while initializing encryption"), remotePathCB,
+		)
+		return
 	}
 	elapsedInitEncryption := time.Since(now) - elapsedInitEC
 
@@ -946,31 +989,104 @@ func (req *DownloadRequest) initEC() error {
 	return nil
 }
 
-// initEncryption will initialize encScheme with client's keys
+// initEncryption will initialize encScheme with client's keys, or the group
+// key for files shared with a group
 func (req *DownloadRequest) initEncryption() (err error) {
-	req.encScheme = encryption.NewEncryptionScheme()
-	mnemonic := client.GetClient().Mnemonic
-	if mnemonic != "" {
-		_, err = req.encScheme.Initialize(client.GetClient().Mnemonic)
-		if err != nil {
-			return err
+	req.encScheme, err = req.decryptionScheme()
+	if err != nil {
+		return err
+	}
+
+	err = req.encScheme.InitForDecryption("filetype:audio", req.encryptedKey)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// initChunkCipher will initialize chunkCipher with the data key of an
+// EncryptionModeGCM file. Referees open the data key of the auth ticket.
+func (req *DownloadRequest) initChunkCipher() error {
+	var (
+		dataKey []byte
+		err     error
+	)
+	lookupHash := req.remotefilepathhash
+	if lookupHash == "" {
+		lookupHash = fileref.GetReferenceLookup(req.allocationID, req.remotefilepath)
+	}
+	if req.authTicket != nil {
+		fileKey := req.authTicket.FileKey
+		if fileKey == "" {
+			// a file of a shared directory
+			fileKey = req.authTicket.FileKeys[lookupHash]
 		}
-	} else {
-		key, err := hex.DecodeString(client.GetClientPrivateKey())
-		if err != nil {
-			return err
+		if fileKey == "" {
+			return errors.New("invalid_file_key", "auth ticket has no data key of the file")
 		}
-		err = req.encScheme.InitializeWithPrivateKey(key)
+		var encScheme encryption.EncryptionScheme
+		encScheme, err = req.decryptionScheme()
 		if err != nil {
 			return err
 		}
+		dataKey, err = openSharedDataKey(encScheme, fileKey)
+	} else {
+		dataKey, _, _, err = ownerDataKey(lookupHash, req.gcmFileKey)
 	}
-
-	err = req.encScheme.InitForDecryption("filetype:audio", req.encryptedKey)
 	if err != nil {
 		return err
 	}
-	return nil
+
+	req.chunkCipher, err = encryption.NewChunkCipher(dataKey)
+	return err
+}
+
+// decryptionScheme returns the scheme of the group key or of the decryption
+// key if set, or the client's keys
+func (req *DownloadRequest) decryptionScheme() (encryption.EncryptionScheme, error) {
+	if req.groupKey != nil {
+		return req.groupKey.encryptionScheme()
+	}
+	if req.decryptionKey != "" {
+		return encryptionSchemeOfKey(req.decryptionKey)
+	}
+	return clientEncryptionScheme()
+}
+
+// ErrNoEncryptionKeys the client has neither a mnemonic nor a private key to
+// derive encryption keys from, e.g. when it signs through an external signer.
+var ErrNoEncryptionKeys = errors.New("no_encryption_keys", "client has no keys to derive the encryption keys from")
+
+// clientEncryptionScheme returns the encryption scheme of the client's keys
+func clientEncryptionScheme() (encryption.EncryptionScheme, error) {
+	if r := getClientKeyRing(); r != nil {
+		return r.scheme()
+	}
+
+	encScheme := encryption.NewEncryptionScheme()
+	mnemonic := client.GetClient().Mnemonic
+	if seed := client.GetClient().EncryptionSeed; seed != "" {
+		// derived wallets carry a seed of their own instead of the mnemonic
+		mnemonic = seed
+	}
+	if mnemonic == "" && client.GetClientPrivateKey() == "" {
+		return nil, ErrNoEncryptionKeys
+	}
+	if mnemonic != "" {
+		if _, err := encScheme.Initialize(mnemonic); err != nil {
+			return nil, err
+		}
+		return encScheme, nil
+	}
+
+	key, err := hex.DecodeString(client.GetClientPrivateKey())
+	if err != nil {
+		return nil, err
+	}
+	if err := encScheme.InitializeWithPrivateKey(key); err != nil {
+		return nil, err
+	}
+	return encScheme, nil
 }
 
 func (req *DownloadRequest) errorCB(err error, remotePathCB string) {
@@ -1011,12 +1127,17 @@ func (req *DownloadRequest) calculateShardsParams(
 	}
 	req.size = size
 	req.encryptedKey = fRef.EncryptedKey
+	if isGCMKeyPoint(fRef.EncryptedKeyPoint) {
+		req.gcmFileKey = fRef.EncryptedKeyPoint
+	}
 	req.chunkSize = int(fRef.ChunkSize)
 
 	effectivePerShardSize := (size + int64(req.datashards) - 1) / int64(req.datashards)
 	effectiveBlockSize := fRef.ChunkSize
 	if fRef.EncryptedKey != "" {
 		effectiveBlockSize -= EncryptionHeaderSize + EncryptedDataPaddingSize
+	} else if req.gcmFileKey != "" {
+		effectiveBlockSize -= encryption.GCMOverhead
 	}
 
 	req.effectiveBlockSize = int(effectiveBlockSize)
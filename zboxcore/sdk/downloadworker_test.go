@@ -6,6 +6,8 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/0chain/gosdk/core/zcncrypto"
+	zclient "github.com/0chain/gosdk/zboxcore/client"
 	"github.com/0chain/gosdk/zboxcore/zboxutil"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/sha3"
@@ -177,6 +179,29 @@ func TestFillShards(t *testing.T) {
 	}
 }
 
+func TestClientEncryptionScheme(t *testing.T) {
+	client := zclient.GetClient()
+	wallet := client.Wallet
+	t.Cleanup(func() { client.Wallet = wallet })
+
+	t.Run("external signer", func(t *testing.T) {
+		client.Wallet = &zcncrypto.Wallet{ClientID: mockClientId, ClientKey: mockClientKey}
+		_, err := clientEncryptionScheme()
+		require.ErrorIs(t, err, ErrNoEncryptionKeys)
+	})
+
+	t.Run("mnemonic", func(t *testing.T) {
+		client.Wallet = &zcncrypto.Wallet{ClientID: mockClientId, Mnemonic: mockClientMnemonic}
+		encScheme, err := clientEncryptionScheme()
+		require.NoError(t, err)
+		publicKey, err := encScheme.GetPublicKey()
+		require.NoError(t, err)
+		expected, err := newTestEncryptionScheme(t, mockClientMnemonic).GetPublicKey()
+		require.NoError(t, err)
+		require.Equal(t, expected, publicKey)
+	})
+}
+
 func getDummyData(size int) ([]byte, error) {
 	b := make([]byte, size)
 	_, err := rand.Read(b) //nolint
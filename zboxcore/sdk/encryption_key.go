@@ -0,0 +1,575 @@
+package sdk
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	"github.com/0chain/errors"
+
+	"github.com/0chain/gosdk/zboxcore/client"
+	"github.com/0chain/gosdk/zboxcore/encryption"
+	"github.com/0chain/gosdk/zboxcore/fileref"
+)
+
+const (
+	recoveryKitVersion = 1
+	keyShareVersion    = 1
+	rotationPageLimit  = 100
+	storeKeySize       = 32
+)
+
+var ErrInvalidKeyRing = errors.New("invalid_key_ring", "invalid encryption key ring")
+
+// EncryptionKeyRing holds the client's encryption key apart from the wallet,
+// so it can be backed up, recovered and rotated on its own. Without a key
+// ring set with SetEncryptionKeyRing the key is derived from the wallet.
+//
+// The key ring must be backed up again after every rotation, with
+// ExportRecoveryKit or Escrow.
+type EncryptionKeyRing struct {
+	// PrivateKey is the base64 private key of the proxy re-encryption scheme
+	PrivateKey string `json:"private_key"`
+	// PreviousKeys are the keys replaced by rotations, newest first, while
+	// files are bound to them
+	PreviousKeys []string `json:"previous_keys,omitempty"`
+	// PathKey is the base64 key the paths of allocations are encrypted with,
+	// the first key of the ring. It is set by the first rotation.
+	PathKey string `json:"path_key,omitempty"`
+	// FileKeys are the data keys of EncryptionModeGCM files re-wrapped with
+	// PrivateKey by rotations, by lookup hash of the files. Blobbers keep
+	// the key points of the uploads, the key ring is the only copy of them.
+	FileKeys map[string]*RewrappedFileKey `json:"file_keys,omitempty"`
+	// StoreKey is the base64 key sealing the keys the SDK stores locally,
+	// like the keys of share groups. Rotations keep it.
+	StoreKey string `json:"store_key,omitempty"`
+}
+
+// RewrappedFileKey is the data key of a file re-wrapped by a rotation.
+type RewrappedFileKey struct {
+	// Point is the encrypted key point of the file on blobbers
+	Point string `json:"point"`
+	// Key is the data key wrapped with the current key of the key ring
+	Key string `json:"key"`
+}
+
+var (
+	keyRingMutex  sync.RWMutex
+	clientKeyRing *EncryptionKeyRing
+)
+
+// NewEncryptionKeyRing creates a key ring with a new random key.
+func NewEncryptionKeyRing() (*EncryptionKeyRing, error) {
+	privateKey, err := newEncryptionPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	storeKey := make([]byte, storeKeySize)
+	if _, err := rand.Read(storeKey); err != nil {
+		return nil, err
+	}
+	return &EncryptionKeyRing{PrivateKey: privateKey, StoreKey: base64.StdEncoding.EncodeToString(storeKey)}, nil
+}
+
+func newEncryptionPrivateKey() (string, error) {
+	seed := make([]byte, 32)
+	if _, err := rand.Read(seed); err != nil {
+		return "", err
+	}
+	encScheme := encryption.NewEncryptionScheme()
+	if _, err := encScheme.Initialize(hex.EncodeToString(seed)); err != nil {
+		return "", err
+	}
+	return encScheme.GetPrivateKey()
+}
+
+// SetEncryptionKeyRing sets the key ring of the client's encryption key. A
+// nil key ring goes back to the key of the wallet.
+func SetEncryptionKeyRing(r *EncryptionKeyRing) error {
+	if r != nil {
+		if _, err := r.scheme(); err != nil {
+			return err
+		}
+	}
+	keyRingMutex.Lock()
+	clientKeyRing = r.clone()
+	keyRingMutex.Unlock()
+	return nil
+}
+
+// GetEncryptionKeyRing returns the key ring of the client's encryption key.
+// Without a key ring set, it returns one with the key of the wallet, to back
+// it up before rotating to a key apart from the wallet.
+func GetEncryptionKeyRing() (*EncryptionKeyRing, error) {
+	if r := getClientKeyRing(); r != nil {
+		return r, nil
+	}
+	encScheme, err := clientEncryptionScheme()
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := encScheme.GetPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	return &EncryptionKeyRing{PrivateKey: privateKey}, nil
+}
+
+func getClientKeyRing() *EncryptionKeyRing {
+	keyRingMutex.RLock()
+	defer keyRingMutex.RUnlock()
+	return clientKeyRing.clone()
+}
+
+func (r *EncryptionKeyRing) clone() *EncryptionKeyRing {
+	if r == nil {
+		return nil
+	}
+	c := &EncryptionKeyRing{
+		PrivateKey:   r.PrivateKey,
+		PreviousKeys: append([]string(nil), r.PreviousKeys...),
+		PathKey:      r.PathKey,
+		StoreKey:     r.StoreKey,
+	}
+	if len(r.FileKeys) > 0 {
+		c.FileKeys = make(map[string]*RewrappedFileKey, len(r.FileKeys))
+		for k, v := range r.FileKeys {
+			fk := *v
+			c.FileKeys[k] = &fk
+		}
+	}
+	return c
+}
+
+func encryptionSchemeOfKey(privateKey string) (encryption.EncryptionScheme, error) {
+	key, err := base64.StdEncoding.DecodeString(privateKey)
+	if err != nil {
+		return nil, errors.New("invalid_key_ring", err.Error())
+	}
+	encScheme := encryption.NewEncryptionScheme()
+	if err := encScheme.InitializeWithPrivateKey(key); err != nil {
+		return nil, errors.New("invalid_key_ring", err.Error())
+	}
+	return encScheme, nil
+}
+
+// clientEncryptionPrivateKey returns the private key of the client's encryption scheme.
+func clientEncryptionPrivateKey() ([]byte, error) {
+	encScheme, err := clientEncryptionScheme()
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := encScheme.GetPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(privateKey)
+}
+
+func (r *EncryptionKeyRing) scheme() (encryption.EncryptionScheme, error) {
+	if r.PrivateKey == "" {
+		return nil, ErrInvalidKeyRing
+	}
+	return encryptionSchemeOfKey(r.PrivateKey)
+}
+
+// pathKey returns the key the paths of allocations are encrypted with.
+func (r *EncryptionKeyRing) pathKey() string {
+	if r.PathKey != "" {
+		return r.PathKey
+	}
+	if n := len(r.PreviousKeys); n > 0 {
+		return r.PreviousKeys[n-1]
+	}
+	return r.PrivateKey
+}
+
+// storeKey returns the key sealing the keys stored by the SDK. Key rings
+// without a store key, like the ring of the wallet key, derive it from their
+// first key, which rotations keep too.
+func (r *EncryptionKeyRing) storeKey() ([]byte, error) {
+	if r.StoreKey == "" {
+		key := sha256.Sum256([]byte("0chain store key\x00" + r.pathKey()))
+		return key[:], nil
+	}
+	key, err := base64.StdEncoding.DecodeString(r.StoreKey)
+	if err != nil || len(key) != storeKeySize {
+		return nil, ErrInvalidKeyRing
+	}
+	return key, nil
+}
+
+// PublicKey returns the encryption public key of the current key.
+func (r *EncryptionKeyRing) PublicKey() (string, error) {
+	encScheme, err := r.scheme()
+	if err != nil {
+		return "", err
+	}
+	return encScheme.GetPublicKey()
+}
+
+// ownerDataKey returns the data key of an EncryptionModeGCM file of the
+// client with the encrypted key point, with the file key and the scheme it
+// is wrapped with. The key re-wrapped by a rotation comes first, then the
+// point is opened with the current and the previous keys.
+func ownerDataKey(lookupHash, point string) ([]byte, *gcmFileKey, encryption.EncryptionScheme, error) {
+	owner, err := clientEncryptionScheme()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	r := getClientKeyRing()
+
+	if r != nil {
+		if fk := r.FileKeys[lookupHash]; fk != nil && fk.Point == point {
+			point = fk.Key
+		}
+	}
+	fileKey, err := decodeGCMFileKey(point)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	dataKey, err := fileKey.unwrap(owner)
+	if err == nil || r == nil {
+		return dataKey, fileKey, owner, err
+	}
+
+	for _, previousKey := range r.PreviousKeys {
+		previous, perr := encryptionSchemeOfKey(previousKey)
+		if perr != nil {
+			continue
+		}
+		if dataKey, perr := fileKey.unwrap(previous); perr == nil {
+			return dataKey, fileKey, previous, nil
+		}
+	}
+	return nil, nil, nil, err
+}
+
+// ExportRecoveryKit encrypts the key ring with the password. The kit is
+// restored with ImportRecoveryKit.
+func (r *EncryptionKeyRing) ExportRecoveryKit(password string) (string, error) {
+	publicKey, err := r.PublicKey()
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := encryption.SealWithPassword(data, password)
+	if err != nil {
+		return "", errors.New("recovery_kit_error", err.Error())
+	}
+	kit, err := json.Marshal(&recoveryKit{
+		Version:   recoveryKitVersion,
+		PublicKey: publicKey,
+		KeyRing:   sealed,
+	})
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(kit), nil
+}
+
+type recoveryKit struct {
+	Version int `json:"version"`
+	// PublicKey identifies the key of the kit without the password
+	PublicKey string          `json:"public_key"`
+	KeyRing   json.RawMessage `json:"key_ring"`
+}
+
+// ImportRecoveryKit decrypts the key ring of a recovery kit with the password.
+func ImportRecoveryKit(kit, password string) (*EncryptionKeyRing, error) {
+	buf, err := base64.StdEncoding.DecodeString(kit)
+	if err != nil {
+		return nil, errors.New("invalid_recovery_kit", err.Error())
+	}
+	k := &recoveryKit{}
+	if err := json.Unmarshal(buf, k); err != nil {
+		return nil, errors.New("invalid_recovery_kit", err.Error())
+	}
+	if k.Version != recoveryKitVersion {
+		return nil, errors.New("invalid_recovery_kit", "unsupported recovery kit version")
+	}
+	data, err := encryption.OpenWithPassword(k.KeyRing, password)
+	if err != nil {
+		return nil, errors.New("invalid_recovery_kit", err.Error())
+	}
+	return decodeKeyRing(data, k.PublicKey)
+}
+
+func decodeKeyRing(data []byte, publicKey string) (*EncryptionKeyRing, error) {
+	r := &EncryptionKeyRing{}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, errors.New("invalid_key_ring", err.Error())
+	}
+	pub, err := r.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	if publicKey != "" && pub != publicKey {
+		return nil, errors.New("invalid_key_ring", "public key of the key ring doesn't match")
+	}
+	return r, nil
+}
+
+type keyShareEnvelope struct {
+	Version   int    `json:"version"`
+	Threshold int    `json:"threshold"`
+	PublicKey string `json:"public_key"` // public key of the escrowed key ring
+	Share     []byte `json:"share"`      // share re-encrypted to the trustee
+}
+
+// Escrow splits the key ring with Shamir's secret sharing between trustees,
+// contacts or devices, so that any threshold of them can recover it while
+// fewer learn nothing about it. The trustees are encryption public keys by
+// ID, the envelope of a share of each trustee is returned by ID.
+//
+// Trustees open their envelope with OpenKeyShare and give the share back to
+// the owner, who recovers the key ring with RecoverEncryptionKeyRing.
+func (r *EncryptionKeyRing) Escrow(trustees map[string]string, threshold int) (map[string]string, error) {
+	owner, err := r.scheme()
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := owner.GetPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	shares, err := encryption.SplitSecret(data, len(trustees), threshold)
+	if err != nil {
+		return nil, errors.New("key_escrow_error", err.Error())
+	}
+
+	envelopes := make(map[string]string, len(trustees))
+	i := 0
+	for id, trusteePublicKey := range trustees {
+		buf, err := sealToPublicKey(owner, shares[i], trusteePublicKey)
+		if err != nil {
+			return nil, errors.New("key_escrow_error", err.Error())
+		}
+		i++
+
+		envelope, err := json.Marshal(&keyShareEnvelope{
+			Version:   keyShareVersion,
+			Threshold: threshold,
+			PublicKey: publicKey,
+			Share:     buf,
+		})
+		if err != nil {
+			return nil, err
+		}
+		envelopes[id] = base64.StdEncoding.EncodeToString(envelope)
+	}
+	return envelopes, nil
+}
+
+// OpenKeyShare decrypts the envelope of a key share with the client's keys.
+// The share is given back to the owner to recover the key ring.
+func OpenKeyShare(envelope string) (string, error) {
+	encScheme, err := clientEncryptionScheme()
+	if err != nil {
+		return "", err
+	}
+	return openKeyShare(encScheme, envelope)
+}
+
+func openKeyShare(trustee encryption.EncryptionScheme, envelope string) (string, error) {
+	buf, err := base64.StdEncoding.DecodeString(envelope)
+	if err != nil {
+		return "", errors.New("invalid_key_share", err.Error())
+	}
+	env := &keyShareEnvelope{}
+	if err := json.Unmarshal(buf, env); err != nil {
+		return "", errors.New("invalid_key_share", err.Error())
+	}
+	if env.Version != keyShareVersion {
+		return "", errors.New("invalid_key_share", "unsupported key share version")
+	}
+	reEncMsg, err := unmarshalReEncryptedMessage(env.Share)
+	if err != nil {
+		return "", errors.New("invalid_key_share", err.Error())
+	}
+	share, err := trustee.ReDecrypt(reEncMsg)
+	if err != nil {
+		return "", errors.New("invalid_key_share", err.Error())
+	}
+
+	opened, err := json.Marshal(&keyShareEnvelope{
+		Version:   keyShareVersion,
+		Threshold: env.Threshold,
+		PublicKey: env.PublicKey,
+		Share:     share,
+	})
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(opened), nil
+}
+
+// RecoverEncryptionKeyRing combines the key shares opened by trustees into
+// the escrowed key ring.
+func RecoverEncryptionKeyRing(shares []string) (*EncryptionKeyRing, error) {
+	var (
+		publicKey string
+		threshold int
+		secrets   = make([][]byte, 0, len(shares))
+	)
+	for _, share := range shares {
+		buf, err := base64.StdEncoding.DecodeString(share)
+		if err != nil {
+			return nil, errors.New("invalid_key_share", err.Error())
+		}
+		env := &keyShareEnvelope{}
+		if err := json.Unmarshal(buf, env); err != nil {
+			return nil, errors.New("invalid_key_share", err.Error())
+		}
+		if publicKey == "" {
+			publicKey, threshold = env.PublicKey, env.Threshold
+		} else if env.PublicKey != publicKey {
+			return nil, errors.New("invalid_key_share", "key shares are of different key rings")
+		}
+		secrets = append(secrets, env.Share)
+	}
+	if len(secrets) < threshold || len(secrets) < 2 {
+		return nil, errors.New("not_enough_key_shares", "not enough key shares to recover the key ring")
+	}
+
+	data, err := encryption.CombineShares(secrets)
+	if err != nil {
+		return nil, errors.New("invalid_key_share", err.Error())
+	}
+	return decodeKeyRing(data, publicKey)
+}
+
+// KeyRotationResult is the result of RotateEncryptionKey.
+type KeyRotationResult struct {
+	// KeyRing is the new key ring of the client, to back up
+	KeyRing *EncryptionKeyRing
+	// Rewrapped are the paths of the files whose data keys were re-wrapped, by allocation ID
+	Rewrapped map[string][]string
+	// Pending are the paths of the encrypted files bound to a previous key,
+	// by allocation ID. They must be re-uploaded, until then they are
+	// downloaded with a key ring of the previous key.
+	Pending map[string][]string
+}
+
+// RotateEncryptionKey replaces the client's encryption key with a new one
+// and re-wraps the data keys of the EncryptionModeGCM files of the
+// allocations with it, without uploading the files again. Blobbers only
+// take key points with uploads, so the re-wrapped keys are kept in the key
+// ring only: losing the new key ring loses the files, back it up with
+// ExportRecoveryKit or Escrow before dropping the previous one.
+//
+// Files encrypted with proxy re-encryption only are bound to the key and are
+// reported as pending. The previous keys are kept in the key ring while
+// files are pending, so every allocation of the client with encrypted files
+// must be given: once none of their files is pending the previous keys are
+// dropped. Without allocations the previous keys are kept.
+//
+// The new key ring is set as the client's, new uploads and shares use the new
+// key. Shares created before keep working.
+func RotateEncryptionKey(allocations ...*Allocation) (*KeyRotationResult, error) {
+	current, err := GetEncryptionKeyRing()
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := newEncryptionPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	next := &EncryptionKeyRing{
+		PrivateKey:   privateKey,
+		PreviousKeys: append([]string{current.PrivateKey}, current.PreviousKeys...),
+		PathKey:      current.pathKey(),
+		FileKeys:     make(map[string]*RewrappedFileKey),
+		StoreKey:     current.StoreKey,
+	}
+	nextScheme, err := next.scheme()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &KeyRotationResult{
+		KeyRing:   next,
+		Rewrapped: make(map[string][]string),
+		Pending:   make(map[string][]string),
+	}
+	for _, a := range allocations {
+		if err := a.rewrapFileKeys(nextScheme, next, result); err != nil {
+			return nil, err
+		}
+	}
+
+	// keys re-wrapped by previous rotations of files of other allocations
+	for lookupHash, fk := range current.FileKeys {
+		if _, ok := next.FileKeys[lookupHash]; ok {
+			continue
+		}
+		if err := rewrapFileKey(nextScheme, next, lookupHash, fk.Point); err != nil {
+			return nil, err
+		}
+	}
+	if len(allocations) > 0 && len(result.Pending) == 0 {
+		next.PreviousKeys = nil
+	}
+
+	if err := SetEncryptionKeyRing(next); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (a *Allocation) rewrapFileKeys(nextScheme encryption.EncryptionScheme, next *EncryptionKeyRing, result *KeyRotationResult) error {
+	if client.GetClientID() != a.Owner {
+		return errors.New("key_rotation_not_permitted", "only the owner can rotate the keys of the allocation "+a.ID)
+	}
+
+	var offsetPath string
+	for {
+		res, err := a.getRefs("/", "", "", offsetPath, "", "", "", fileref.FILE, 0, rotationPageLimit)
+		if err != nil {
+			return err
+		}
+		for _, ref := range res.Refs {
+			switch {
+			case isGCMKeyPoint(ref.EncryptedKeyPoint):
+				lookupHash := fileref.GetReferenceLookup(a.ID, ref.Path)
+				if err := rewrapFileKey(nextScheme, next, lookupHash, ref.EncryptedKeyPoint); err != nil {
+					return errors.New("key_rotation_error", ref.Path+": "+err.Error())
+				}
+				result.Rewrapped[a.ID] = append(result.Rewrapped[a.ID], a.plainPath(ref.Path))
+			case ref.EncryptedKey != "":
+				result.Pending[a.ID] = append(result.Pending[a.ID], a.plainPath(ref.Path))
+			}
+		}
+		if len(res.Refs) < rotationPageLimit || res.OffsetPath == "" || res.OffsetPath == offsetPath {
+			return nil
+		}
+		offsetPath = res.OffsetPath
+	}
+}
+
+func rewrapFileKey(nextScheme encryption.EncryptionScheme, next *EncryptionKeyRing, lookupHash, point string) error {
+	dataKey, _, _, err := ownerDataKey(lookupHash, point)
+	if err != nil {
+		return err
+	}
+	fileKey, err := wrapDataKey(nextScheme, dataKey)
+	if err != nil {
+		return err
+	}
+	key, err := fileKey.encode()
+	if err != nil {
+		return err
+	}
+	next.FileKeys[lookupHash] = &RewrappedFileKey{Point: point, Key: key}
+	return nil
+}
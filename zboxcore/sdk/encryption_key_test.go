@@ -0,0 +1,165 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/0chain/gosdk/zboxcore/encryption"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptionKeyRing(t *testing.T) {
+	r, err := NewEncryptionKeyRing()
+	require.NoError(t, err)
+	publicKey, err := r.PublicKey()
+	require.NoError(t, err)
+
+	t.Run("recovery kit", func(t *testing.T) {
+		kit, err := r.ExportRecoveryKit("password")
+		require.NoError(t, err)
+
+		imported, err := ImportRecoveryKit(kit, "password")
+		require.NoError(t, err)
+		require.Equal(t, r, imported)
+
+		_, err = ImportRecoveryKit(kit, "wrong password")
+		require.Error(t, err)
+	})
+
+	t.Run("escrow", func(t *testing.T) {
+		trustees := map[string]encryption.EncryptionScheme{
+			"alice": newTestEncryptionScheme(t, "alice"),
+			"bob":   newTestEncryptionScheme(t, "bob"),
+			"carol": newTestEncryptionScheme(t, "carol"),
+		}
+		publicKeys := make(map[string]string)
+		for id, trustee := range trustees {
+			publicKeys[id], err = trustee.GetPublicKey()
+			require.NoError(t, err)
+		}
+
+		envelopes, err := r.Escrow(publicKeys, 2)
+		require.NoError(t, err)
+		require.Len(t, envelopes, 3)
+
+		alice, err := openKeyShare(trustees["alice"], envelopes["alice"])
+		require.NoError(t, err)
+		carol, err := openKeyShare(trustees["carol"], envelopes["carol"])
+		require.NoError(t, err)
+
+		_, err = openKeyShare(trustees["bob"], envelopes["alice"])
+		require.Error(t, err)
+
+		recovered, err := RecoverEncryptionKeyRing([]string{alice, carol})
+		require.NoError(t, err)
+		recoveredPublicKey, err := recovered.PublicKey()
+		require.NoError(t, err)
+		require.Equal(t, publicKey, recoveredPublicKey)
+
+		_, err = RecoverEncryptionKeyRing([]string{alice})
+		require.Error(t, err)
+	})
+}
+
+func TestOwnerDataKey(t *testing.T) {
+	defer SetEncryptionKeyRing(nil) //nolint:errcheck
+
+	previous, err := NewEncryptionKeyRing()
+	require.NoError(t, err)
+	require.NoError(t, SetEncryptionKeyRing(previous))
+
+	dataKey, err := encryption.NewDataKey()
+	require.NoError(t, err)
+	previousScheme, err := previous.scheme()
+	require.NoError(t, err)
+	fileKey, err := wrapDataKey(previousScheme, dataKey)
+	require.NoError(t, err)
+	point, err := fileKey.encode()
+	require.NoError(t, err)
+
+	key, _, _, err := ownerDataKey("file", point)
+	require.NoError(t, err)
+	require.Equal(t, dataKey, key)
+
+	// rotate without allocations, the file is opened with the previous key
+	result, err := RotateEncryptionKey()
+	require.NoError(t, err)
+	require.Equal(t, []string{previous.PrivateKey}, result.KeyRing.PreviousKeys)
+
+	key, _, owner, err := ownerDataKey("file", point)
+	require.NoError(t, err)
+	require.Equal(t, dataKey, key)
+	previousPublicKey, err := previous.PublicKey()
+	require.NoError(t, err)
+	ownerPublicKey, err := owner.GetPublicKey()
+	require.NoError(t, err)
+	require.Equal(t, previousPublicKey, ownerPublicKey)
+
+	t.Run("rewrapped", func(t *testing.T) {
+		next := getClientKeyRing()
+		nextScheme, err := next.scheme()
+		require.NoError(t, err)
+		next.FileKeys = make(map[string]*RewrappedFileKey)
+		require.NoError(t, rewrapFileKey(nextScheme, next, "file", point))
+		// the ring alone opens the file
+		next.PreviousKeys = nil
+		require.NoError(t, SetEncryptionKeyRing(next))
+
+		key, _, _, err := ownerDataKey("file", point)
+		require.NoError(t, err)
+		require.Equal(t, dataKey, key)
+
+		_, _, _, err = ownerDataKey("other file", point)
+		require.Error(t, err)
+	})
+}
+
+func TestEncryptionKeyRingPathKey(t *testing.T) {
+	first, err := NewEncryptionKeyRing()
+	require.NoError(t, err)
+	require.Equal(t, first.PrivateKey, first.pathKey())
+
+	// rings of previous rotations keep the first key last
+	rotated := &EncryptionKeyRing{PrivateKey: "next", PreviousKeys: []string{"previous", first.PrivateKey}}
+	require.Equal(t, first.PrivateKey, rotated.pathKey())
+
+	// the path key outlives dropped previous keys
+	rotated = &EncryptionKeyRing{PrivateKey: "next", PathKey: first.PrivateKey}
+	require.Equal(t, first.PrivateKey, rotated.pathKey())
+	require.Equal(t, first.PrivateKey, rotated.clone().PathKey)
+}
+
+func TestEncryptionKeyRingStoreKey(t *testing.T) {
+	ring, err := NewEncryptionKeyRing()
+	require.NoError(t, err)
+	require.NoError(t, SetEncryptionKeyRing(ring))
+	defer SetEncryptionKeyRing(nil) //nolint:errcheck
+
+	key, err := ring.storeKey()
+	require.NoError(t, err)
+	require.Len(t, key, storeKeySize)
+
+	stored, err := shareGroupStoreKey()
+	require.NoError(t, err)
+	require.Equal(t, key, stored)
+
+	// rotations keep the store key
+	result, err := RotateEncryptionKey()
+	require.NoError(t, err)
+	require.NotEqual(t, ring.PrivateKey, result.KeyRing.PrivateKey)
+	rotatedKey, err := result.KeyRing.storeKey()
+	require.NoError(t, err)
+	require.Equal(t, key, rotatedKey)
+
+	// rings without a store key derive it from the path key
+	legacy := &EncryptionKeyRing{PrivateKey: "next", PathKey: ring.PrivateKey}
+	legacyKey, err := legacy.storeKey()
+	require.NoError(t, err)
+	require.Len(t, legacyKey, storeKeySize)
+	legacy.PrivateKey = "rotated"
+	rotatedKey, err = legacy.storeKey()
+	require.NoError(t, err)
+	require.Equal(t, legacyKey, rotatedKey)
+
+	_, err = (&EncryptionKeyRing{StoreKey: "short"}).storeKey()
+	require.ErrorIs(t, err, ErrInvalidKeyRing)
+}
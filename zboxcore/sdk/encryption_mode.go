@@ -0,0 +1,181 @@
+package sdk
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/0chain/errors"
+	"github.com/0chain/gosdk/zboxcore/encryption"
+	"go.dedis.ch/kyber/v3/group/edwards25519"
+)
+
+// EncryptionMode is how files encrypted on upload are encrypted.
+type EncryptionMode int
+
+const (
+	// EncryptionModePRE encrypts every chunk with proxy re-encryption, and
+	// blobbers re-encrypt the chunks of shared files to the referee. It is the default.
+	EncryptionModePRE EncryptionMode = iota
+	// EncryptionModeGCM encrypts every chunk with AES-256-GCM and a random
+	// data key of the file, which is wrapped with proxy re-encryption. It adds
+	// 16 bytes to a chunk instead of 272 and chunks can be decrypted in any
+	// order. Referees get the data key re-encrypted to them in the auth
+	// ticket, for a directory the keys of the files in it when it is shared.
+	// The data keys are never rotated, so these files can't be shared with
+	// share groups, see ErrGCMGroupShare.
+	EncryptionModeGCM
+)
+
+// gcmKeyPointPrefix prefixes the encrypted key point of EncryptionModeGCM files.
+const gcmKeyPointPrefix = "gcm1:"
+
+var errInvalidFileKey = errors.New("invalid_file_key", "invalid data key of the file")
+
+// gcmFileKey is the data key of an EncryptionModeGCM file encrypted with
+// the owner's keys. It is saved as the encrypted key point of the file,
+// and the encrypted key is left empty so blobbers don't re-encrypt chunks.
+type gcmFileKey struct {
+	Point           string `json:"point"`
+	EncryptedData   []byte `json:"data"`
+	MessageChecksum string `json:"message_checksum"`
+	OverallChecksum string `json:"overall_checksum"`
+}
+
+func isGCMKeyPoint(point string) bool {
+	return strings.HasPrefix(point, gcmKeyPointPrefix)
+}
+
+// wrapDataKey encrypts the data key with the owner's scheme.
+func wrapDataKey(owner encryption.EncryptionScheme, dataKey []byte) (*gcmFileKey, error) {
+	owner.InitForEncryption("filetype:audio")
+	encMsg, err := owner.Encrypt(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &gcmFileKey{
+		Point:           owner.GetEncryptedKeyPoint(),
+		EncryptedData:   encMsg.EncryptedData,
+		MessageChecksum: encMsg.MessageChecksum,
+		OverallChecksum: encMsg.OverallChecksum,
+	}, nil
+}
+
+func decodeGCMFileKey(point string) (*gcmFileKey, error) {
+	if !isGCMKeyPoint(point) {
+		return nil, errInvalidFileKey
+	}
+	buf, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(point, gcmKeyPointPrefix))
+	if err != nil {
+		return nil, errors.New("invalid_file_key", err.Error())
+	}
+	k := &gcmFileKey{}
+	if err := json.Unmarshal(buf, k); err != nil {
+		return nil, errors.New("invalid_file_key", err.Error())
+	}
+	return k, nil
+}
+
+func (k *gcmFileKey) encode() (string, error) {
+	buf, err := json.Marshal(k)
+	if err != nil {
+		return "", err
+	}
+	return gcmKeyPointPrefix + base64.StdEncoding.EncodeToString(buf), nil
+}
+
+func (k *gcmFileKey) encryptedMessage(owner encryption.EncryptionScheme) (*encryption.EncryptedMessage, error) {
+	if err := owner.InitForEncryptionWithPoint("filetype:audio", k.Point); err != nil {
+		return nil, errors.New("invalid_file_key", err.Error())
+	}
+	return &encryption.EncryptedMessage{
+		EncryptedKey:    owner.GetEncryptedKey(),
+		EncryptedData:   k.EncryptedData,
+		MessageChecksum: k.MessageChecksum,
+		OverallChecksum: k.OverallChecksum,
+	}, nil
+}
+
+// unwrap decrypts the data key with the owner's scheme.
+func (k *gcmFileKey) unwrap(owner encryption.EncryptionScheme) ([]byte, error) {
+	encMsg, err := k.encryptedMessage(owner)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := owner.Decrypt(encMsg)
+	if err != nil {
+		return nil, errors.New("invalid_file_key", err.Error())
+	}
+	return dataKey, nil
+}
+
+// reEncrypt re-encrypts the data key to the encryption public key of a
+// referee, to be opened with openSharedDataKey.
+func (k *gcmFileKey) reEncrypt(owner encryption.EncryptionScheme, encPublicKey string) (string, error) {
+	encMsg, err := k.encryptedMessage(owner)
+	if err != nil {
+		return "", err
+	}
+	reKey, err := owner.GetReGenKey(encPublicKey, "filetype:audio")
+	if err != nil {
+		return "", err
+	}
+	reEncMsg, err := owner.ReEncrypt(encMsg, reKey, encPublicKey)
+	if err != nil {
+		return "", err
+	}
+	buf, err := reEncMsg.Marshal()
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf), nil
+}
+
+// openSharedDataKey decrypts the file key of an auth ticket with the referee's scheme.
+func openSharedDataKey(referee encryption.EncryptionScheme, fileKey string) ([]byte, error) {
+	buf, err := base64.StdEncoding.DecodeString(fileKey)
+	if err != nil {
+		return nil, errors.New("invalid_file_key", err.Error())
+	}
+	reEncMsg, err := unmarshalReEncryptedMessage(buf)
+	if err != nil {
+		return nil, errors.New("invalid_file_key", err.Error())
+	}
+	dataKey, err := referee.ReDecrypt(reEncMsg)
+	if err != nil {
+		return nil, errors.New("invalid_file_key", err.Error())
+	}
+	return dataKey, nil
+}
+
+// sealToPublicKey encrypts the data with the owner's scheme and re-encrypts
+// it to the encryption public key, to be opened with ReDecrypt of its scheme.
+func sealToPublicKey(owner encryption.EncryptionScheme, data []byte, encPublicKey string) ([]byte, error) {
+	owner.InitForEncryption("filetype:audio")
+	encMsg, err := owner.Encrypt(data)
+	if err != nil {
+		return nil, err
+	}
+	reKey, err := owner.GetReGenKey(encPublicKey, "filetype:audio")
+	if err != nil {
+		return nil, err
+	}
+	reEncMsg, err := owner.ReEncrypt(encMsg, reKey, encPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return reEncMsg.Marshal()
+}
+
+func unmarshalReEncryptedMessage(buf []byte) (*encryption.ReEncryptedMessage, error) {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	reEncMsg := &encryption.ReEncryptedMessage{
+		D1: suite.Point(),
+		D4: suite.Point(),
+		D5: suite.Point(),
+	}
+	if err := reEncMsg.Unmarshal(buf); err != nil {
+		return nil, err
+	}
+	return reEncMsg, nil
+}
@@ -0,0 +1,84 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/0chain/gosdk/zboxcore/encryption"
+	"github.com/0chain/gosdk/zboxcore/fileref"
+	"github.com/0chain/gosdk/zboxcore/marker"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGCMFileKey(t *testing.T) {
+	dataKey, err := encryption.NewDataKey()
+	require.NoError(t, err)
+
+	fileKey, err := wrapDataKey(newTestEncryptionScheme(t, "owner"), dataKey)
+	require.NoError(t, err)
+	point, err := fileKey.encode()
+	require.NoError(t, err)
+	require.True(t, isGCMKeyPoint(point))
+
+	decoded, err := decodeGCMFileKey(point)
+	require.NoError(t, err)
+	require.Equal(t, fileKey, decoded)
+
+	t.Run("unwrap", func(t *testing.T) {
+		key, err := decoded.unwrap(newTestEncryptionScheme(t, "owner"))
+		require.NoError(t, err)
+		require.Equal(t, dataKey, key)
+
+		_, err = decoded.unwrap(newTestEncryptionScheme(t, "other"))
+		require.Error(t, err)
+	})
+
+	t.Run("share", func(t *testing.T) {
+		referee := newTestEncryptionScheme(t, "referee")
+		refereePublicKey, err := referee.GetPublicKey()
+		require.NoError(t, err)
+
+		shared, err := decoded.reEncrypt(newTestEncryptionScheme(t, "owner"), refereePublicKey)
+		require.NoError(t, err)
+
+		key, err := openSharedDataKey(referee, shared)
+		require.NoError(t, err)
+		require.Equal(t, dataKey, key)
+
+		_, err = openSharedDataKey(newTestEncryptionScheme(t, "other"), shared)
+		require.Error(t, err)
+
+		// a file of a shared directory
+		refereeKey, err := referee.GetPrivateKey()
+		require.NoError(t, err)
+		req := &DownloadRequest{
+			allocationID:   "alloc",
+			remotefilepath: "/dir/a.txt",
+			decryptionKey:  refereeKey,
+			authTicket: &marker.AuthTicket{
+				FileKeys: map[string]string{fileref.GetReferenceLookup("alloc", "/dir/a.txt"): shared},
+			},
+		}
+		require.NoError(t, req.initChunkCipher())
+		require.NotNil(t, req.chunkCipher)
+
+		req.remotefilepath = "/dir/b.txt"
+		require.Error(t, req.initChunkCipher())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := decodeGCMFileKey("pre point")
+		require.Error(t, err)
+		_, err = decodeGCMFileKey(gcmKeyPointPrefix + "!")
+		require.Error(t, err)
+	})
+}
+
+func TestGetShardSizeWithOverhead(t *testing.T) {
+	for _, size := range []int64{0, 1, DefaultChunkSize, 10*DefaultChunkSize + 3} {
+		require.Equal(t, getShardSize(size, 2, false), getShardSizeWithOverhead(size, 2, 0))
+		require.Equal(t, getShardSize(size, 2, true), getShardSizeWithOverhead(size, 2, EncryptedDataPaddingSize+EncryptionHeaderSize))
+	}
+	// two full chunks and a last chunk of one byte per shard
+	size := 2*2*(DefaultChunkSize-encryption.GCMOverhead) + 2
+	require.Equal(t, int64(2*DefaultChunkSize+1+encryption.GCMOverhead), getShardSizeWithOverhead(int64(size), 2, encryption.GCMOverhead))
+}
@@ -0,0 +1,472 @@
+package sdk
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/0chain/errors"
+	"github.com/0chain/gosdk/constants"
+	"github.com/0chain/gosdk/core/sys"
+	"github.com/0chain/gosdk/zboxcore/client"
+	"github.com/0chain/gosdk/zboxcore/encryption"
+	"github.com/0chain/gosdk/zboxcore/fileref"
+	"github.com/0chain/gosdk/zboxcore/zboxutil"
+)
+
+// PathEncryptionMarkerPath is the file marking the allocations with encrypted
+// paths. It keeps its plain name and holds the check value of the path key.
+const PathEncryptionMarkerPath = "/.path_encryption"
+
+var (
+	ErrPathEncryptionRequired = errors.New("path_encryption_required", "the paths of the allocation are encrypted, path encryption should be enabled")
+	ErrPathKeyMismatch        = errors.New("path_key_mismatch", "the paths of the allocation are encrypted with another key")
+)
+
+// EnablePathEncryption encrypts the names of the files and directories of
+// the allocation, so blobbers only see opaque names. Names are encrypted
+// deterministically with a key derived from the owner's encryption key and
+// the allocation, so the same path always has the same encrypted path and
+// lookup hash.
+//
+// Paths given to and returned by the allocation, status callbacks included,
+// stay plain: they are encrypted before requests to blobbers and decrypted
+// in listings. Names which can't be decrypted, e.g. of files uploaded before
+// path encryption was enabled, are returned as they are. Names are encrypted
+// with the path of their directory, so renaming, moving or copying a path
+// renames its descendants to their names in the new directory.
+//
+// The first call writes PathEncryptionMarkerPath to the allocation. Loaded
+// allocations with the marker fail with ErrPathKeyMismatch if the key isn't
+// the key of the marker, and their writes fail with ErrPathEncryptionRequired
+// until path encryption is enabled. The key is derived from the first
+// encryption key of the client, kept as the PathKey of the key ring, so
+// rotations of the key with RotateEncryptionKey don't change the encrypted
+// paths.
+func (a *Allocation) EnablePathEncryption() error {
+	if client.GetClientID() != a.Owner {
+		return errors.New("path_encryption_not_permitted", "only the owner can encrypt the paths of the allocation")
+	}
+
+	r, err := GetEncryptionKeyRing()
+	if err != nil {
+		return err
+	}
+	secret, err := base64.StdEncoding.DecodeString(r.pathKey())
+	if err != nil {
+		return err
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("path encryption:" + a.ID))
+	key := mac.Sum(nil)
+	cipher, err := encryption.NewNameCipher(key)
+	if err != nil {
+		return err
+	}
+	mac = hmac.New(sha256.New, key)
+	mac.Write([]byte("path encryption check"))
+	check := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	encrypted, err := loadPathEncryption(a)
+	if err != nil {
+		return err
+	}
+	if encrypted {
+		marker, err := a.downloadPathEncryptionMarker()
+		if err != nil {
+			return err
+		}
+		if !hmac.Equal([]byte(strings.TrimSpace(string(marker))), []byte(check)) {
+			return ErrPathKeyMismatch
+		}
+		a.pathCipher = cipher
+		return nil
+	}
+
+	a.pathCipher = cipher
+	if err := a.writePathEncryptionMarker(check); err != nil {
+		a.pathCipher = nil
+		return err
+	}
+	setPathEncrypted(a.ID, true)
+	return nil
+}
+
+var (
+	pathEncrypted   = make(map[string]bool)
+	pathEncryptedMu sync.Mutex
+)
+
+func setPathEncrypted(allocationID string, encrypted bool) {
+	pathEncryptedMu.Lock()
+	defer pathEncryptedMu.Unlock()
+	pathEncrypted[allocationID] = encrypted
+}
+
+// loadPathEncryption reports whether the allocation has encrypted paths.
+var loadPathEncryption = (*Allocation).hasPathEncryptionMarker
+
+// hasPathEncryptionMarker reports whether the allocation has the path
+// encryption marker, looking it up once per process.
+func (a *Allocation) hasPathEncryptionMarker() (bool, error) {
+	pathEncryptedMu.Lock()
+	encrypted, ok := pathEncrypted[a.ID]
+	pathEncryptedMu.Unlock()
+	if ok {
+		return encrypted, nil
+	}
+
+	root, err := a.listDir("/")
+	if err != nil {
+		return false, err
+	}
+	for _, child := range root.Children {
+		if child.Path == PathEncryptionMarkerPath {
+			encrypted = true
+			break
+		}
+	}
+	setPathEncrypted(a.ID, encrypted)
+	return encrypted, nil
+}
+
+func (a *Allocation) writePathEncryptionMarker(check string) error {
+	op := OperationRequest{
+		OperationType: constants.FileOperationInsert,
+		RemotePath:    PathEncryptionMarkerPath,
+		Workdir:       defaultWorkdir(),
+		FileReader:    strings.NewReader(check),
+		FileMeta: FileMeta{
+			MimeType:   "text/plain",
+			ActualSize: int64(len(check)),
+			RemoteName: path.Base(PathEncryptionMarkerPath),
+			RemotePath: PathEncryptionMarkerPath,
+		},
+	}
+	return a.DoMultiOperation([]OperationRequest{op})
+}
+
+func (a *Allocation) downloadPathEncryptionMarker() ([]byte, error) {
+	f := &sys.MemFile{}
+	cb := &markerStatusCB{done: make(chan error, 1)}
+	if err := a.DownloadFileToFileHandler(f, PathEncryptionMarkerPath, false, cb, true); err != nil {
+		return nil, err
+	}
+	select {
+	case err := <-cb.done:
+		if err != nil {
+			return nil, err
+		}
+	case <-a.ctx.Done():
+		return nil, a.ctx.Err()
+	}
+	return f.Buffer, nil
+}
+
+// checkPathEncryption returns ErrPathEncryptionRequired for the operations
+// on an allocation with encrypted paths if path encryption isn't enabled, so
+// plain names aren't written next to the encrypted ones.
+func (a *Allocation) checkPathEncryption(operations []OperationRequest) error {
+	if a.pathCipher != nil {
+		return nil
+	}
+	for _, op := range operations {
+		// repairs restore what the blobbers already have
+		if op.IsRepair {
+			continue
+		}
+		encrypted, err := loadPathEncryption(a)
+		if err != nil {
+			return err
+		}
+		if encrypted {
+			return ErrPathEncryptionRequired
+		}
+		return nil
+	}
+	return nil
+}
+
+// IsPathEncrypted reports whether the paths of the allocation are encrypted.
+func (a *Allocation) IsPathEncrypted() bool {
+	return a.pathCipher != nil
+}
+
+// GetLookupHash returns the lookup hash blobbers have for the path.
+func (a *Allocation) GetLookupHash(path string) (string, error) {
+	remotePath, err := a.remotePath(zboxutil.RemoteClean(path))
+	if err != nil {
+		return "", err
+	}
+	return fileref.GetReferenceLookup(a.ID, remotePath), nil
+}
+
+// remotePath returns the path blobbers have for the path p. Relative paths
+// are left as they are for the requests to reject them, and the marker of
+// path encryption keeps its plain path.
+func (a *Allocation) remotePath(p string) (string, error) {
+	if a.pathCipher == nil || !zboxutil.IsRemoteAbs(p) || p == PathEncryptionMarkerPath {
+		return p, nil
+	}
+	remotePath, err := a.pathCipher.EncryptPath(p)
+	if err != nil {
+		return "", errors.New("invalid_path", err.Error())
+	}
+	return remotePath, nil
+}
+
+// remoteName returns the name blobbers have for the name in the directory parent.
+func (a *Allocation) remoteName(parent, name string) (string, error) {
+	if a.pathCipher == nil || name == "" {
+		return name, nil
+	}
+	remoteName, err := a.pathCipher.EncryptName(nameParent(zboxutil.RemoteClean(parent)), name)
+	if err != nil {
+		return "", errors.New("invalid_name", err.Error())
+	}
+	return remoteName, nil
+}
+
+// plainPath returns the path of the path p of blobbers, or p if it isn't encrypted.
+func (a *Allocation) plainPath(p string) string {
+	if a.pathCipher == nil || p == "" {
+		return p
+	}
+	plain, err := a.pathCipher.DecryptPath(p)
+	if err != nil {
+		return p
+	}
+	return plain
+}
+
+// decryptListResult decrypts the paths and names of the listing.
+func (a *Allocation) decryptListResult(r *ListResult) {
+	if a.pathCipher == nil || r == nil {
+		return
+	}
+	if r.Path != "" && r.Path != "/" {
+		r.Path = a.plainPath(r.Path)
+		r.Name = path.Base(r.Path)
+	}
+	for _, child := range r.Children {
+		a.decryptListResult(child)
+	}
+}
+
+// decryptRefs decrypts the paths and names of the refs.
+func (a *Allocation) decryptRefs(refs []ORef) {
+	if a.pathCipher == nil {
+		return
+	}
+	for i := range refs {
+		ref := &refs[i]
+		if ref.Path == "" || ref.Path == "/" {
+			continue
+		}
+		ref.Path = a.plainPath(ref.Path)
+		ref.Name = path.Base(ref.Path)
+		ref.ParentPath = path.Dir(ref.Path)
+	}
+}
+
+// encryptOperationPaths replaces the paths of the operation with the paths blobbers have.
+func (a *Allocation) encryptOperationPaths(op *OperationRequest) (err error) {
+	if a.pathCipher == nil || op.remotePaths {
+		return nil
+	}
+	if op.OperationType == constants.FileOperationRename {
+		if op.DestName, err = a.remoteName(path.Dir(zboxutil.RemoteClean(op.RemotePath)), op.DestName); err != nil {
+			return err
+		}
+	}
+	if op.RemotePath, err = a.remotePath(op.RemotePath); err != nil {
+		return err
+	}
+	if op.DestPath, err = a.remotePath(op.DestPath); err != nil {
+		return err
+	}
+	if op.FileMeta.RemotePath != "" {
+		if op.FileMeta.RemotePath, err = a.remotePath(op.FileMeta.RemotePath); err != nil {
+			return err
+		}
+		op.FileMeta.RemoteName = path.Base(op.FileMeta.RemotePath)
+	}
+	if op.OperationType == constants.FileOperationInsert || op.OperationType == constants.FileOperationUpdate {
+		op.Opts = append(op.Opts[:len(op.Opts):len(op.Opts)], withPlainStatusPaths(a))
+	}
+	return nil
+}
+
+// movedPath is a path renamed, moved or copied by an operation.
+type movedPath struct {
+	from, to   string // plain paths
+	remotePath string // the path blobbers have after the operation
+}
+
+// movedPathOf returns the path the operation renames, moves or copies, nil
+// for other operations or without path encryption.
+func (a *Allocation) movedPathOf(op *OperationRequest) (*movedPath, error) {
+	if a.pathCipher == nil || op.remotePaths {
+		return nil, nil
+	}
+	m := &movedPath{from: zboxutil.RemoteClean(op.RemotePath)}
+	switch op.OperationType {
+	case constants.FileOperationRename:
+		m.to = path.Join(path.Dir(m.from), op.DestName)
+		remotePath, err := a.remotePath(m.to)
+		if err != nil {
+			return nil, err
+		}
+		m.remotePath = remotePath
+	case constants.FileOperationCopy, constants.FileOperationMove:
+		destPath := zboxutil.RemoteClean(op.DestPath)
+		m.to = path.Join(destPath, path.Base(m.from))
+		remoteDest, err := a.remotePath(destPath)
+		if err != nil {
+			return nil, err
+		}
+		remoteFrom, err := a.remotePath(m.from)
+		if err != nil {
+			return nil, err
+		}
+		// blobbers keep the name encrypted for the source directory
+		m.remotePath = path.Join(remoteDest, path.Base(remoteFrom))
+	default:
+		return nil, nil
+	}
+	return m, nil
+}
+
+// reencryptMoved renames the moved path and its descendants to their names
+// encrypted for their new directories, a level of the tree at a time.
+func (a *Allocation) reencryptMoved(m *movedPath) error {
+	level := []movedPath{*m}
+	for len(level) > 0 {
+		var (
+			ops  []OperationRequest
+			next []movedPath
+		)
+		for _, n := range level {
+			remotePath, err := a.remotePath(n.to)
+			if err != nil {
+				return err
+			}
+			if n.remotePath != remotePath {
+				ops = append(ops, OperationRequest{
+					OperationType: constants.FileOperationRename,
+					RemotePath:    n.remotePath,
+					DestName:      path.Base(remotePath),
+					remotePaths:   true,
+				})
+			}
+			if n.from == n.to {
+				continue
+			}
+
+			ref, err := a.listDir(n.remotePath)
+			if err != nil {
+				return err
+			}
+			if ref.Type != fileref.DIRECTORY {
+				continue
+			}
+			for _, child := range ref.Children {
+				encName := path.Base(child.Path)
+				name, err := a.pathCipher.DecryptName(nameParent(n.from), encName)
+				if err != nil {
+					// names written before path encryption was enabled stay plain
+					continue
+				}
+				next = append(next, movedPath{
+					from:       path.Join(n.from, name),
+					to:         path.Join(n.to, name),
+					remotePath: path.Join(remotePath, encName),
+				})
+			}
+		}
+		if err := a.DoMultiOperation(ops); err != nil {
+			return err
+		}
+		level = next
+	}
+	return nil
+}
+
+// nameParent returns the parent the names of the directory dir are encrypted with.
+func nameParent(dir string) string {
+	if dir == "/" {
+		return ""
+	}
+	return dir
+}
+
+// plainPathStatusCB passes the plain paths of the allocation to the status callback.
+type plainPathStatusCB struct {
+	a  *Allocation
+	cb StatusCallback
+}
+
+// plainStatusCallback returns the status callback getting the plain paths.
+func (a *Allocation) plainStatusCallback(cb StatusCallback) StatusCallback {
+	if a.pathCipher == nil || cb == nil {
+		return cb
+	}
+	if _, ok := cb.(*plainPathStatusCB); ok {
+		return cb
+	}
+	return &plainPathStatusCB{a: a, cb: cb}
+}
+
+// withPlainStatusPaths passes the plain paths to the status callback of the upload.
+func withPlainStatusPaths(a *Allocation) ChunkedUploadOption {
+	return func(su *ChunkedUpload) {
+		su.statusCallback = a.plainStatusCallback(su.statusCallback)
+	}
+}
+
+func (cb *plainPathStatusCB) Started(allocationId, filePath string, op int, totalBytes int) {
+	cb.cb.Started(allocationId, cb.a.plainPath(filePath), op, totalBytes)
+}
+
+func (cb *plainPathStatusCB) InProgress(allocationId, filePath string, op int, completedBytes int, data []byte) {
+	cb.cb.InProgress(allocationId, cb.a.plainPath(filePath), op, completedBytes, data)
+}
+
+func (cb *plainPathStatusCB) Error(allocationID string, filePath string, op int, err error) {
+	cb.cb.Error(allocationID, cb.a.plainPath(filePath), op, err)
+}
+
+func (cb *plainPathStatusCB) Completed(allocationId, filePath string, filename string, mimetype string, size int, op int) {
+	if plain := cb.a.plainPath(filePath); plain != filePath {
+		filePath, filename = plain, path.Base(plain)
+	}
+	cb.cb.Completed(allocationId, filePath, filename, mimetype, size, op)
+}
+
+func (cb *plainPathStatusCB) RepairCompleted(filesRepaired int) {
+	cb.cb.RepairCompleted(filesRepaired)
+}
+
+// markerStatusCB reports the end of the download of the path encryption marker.
+type markerStatusCB struct {
+	done chan error
+}
+
+func (cb *markerStatusCB) Started(allocationId, filePath string, op int, totalBytes int) {}
+
+func (cb *markerStatusCB) InProgress(allocationId, filePath string, op int, completedBytes int, data []byte) {
+}
+
+func (cb *markerStatusCB) Error(allocationID string, filePath string, op int, err error) {
+	cb.done <- err
+}
+
+func (cb *markerStatusCB) Completed(allocationId, filePath string, filename string, mimetype string, size int, op int) {
+	cb.done <- nil
+}
+
+func (cb *markerStatusCB) RepairCompleted(filesRepaired int) {}
@@ -0,0 +1,152 @@
+package sdk
+
+import (
+	"errors"
+	"path"
+	"testing"
+
+	"github.com/0chain/gosdk/constants"
+	"github.com/0chain/gosdk/zboxcore/encryption"
+	"github.com/0chain/gosdk/zboxcore/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathEncryption(t *testing.T) {
+	a := &Allocation{ID: "allocation"}
+
+	remotePath, err := a.remotePath("/docs/report.pdf")
+	require.NoError(t, err)
+	require.Equal(t, "/docs/report.pdf", remotePath)
+
+	cipher, err := encryption.NewNameCipher(make([]byte, encryption.NameKeySize))
+	require.NoError(t, err)
+	a.pathCipher = cipher
+	require.True(t, a.IsPathEncrypted())
+
+	remotePath, err = a.remotePath("/docs/report.pdf")
+	require.NoError(t, err)
+	require.NotEqual(t, "/docs/report.pdf", remotePath)
+	require.Equal(t, "/docs/report.pdf", a.plainPath(remotePath))
+	require.Equal(t, "/plain", a.plainPath("/plain"))
+
+	t.Run("lookup hash", func(t *testing.T) {
+		hash, err := a.GetLookupHash("/docs/report.pdf/")
+		require.NoError(t, err)
+		expected, err := a.GetLookupHash("/docs/report.pdf")
+		require.NoError(t, err)
+		require.Equal(t, expected, hash)
+	})
+
+	t.Run("operations", func(t *testing.T) {
+		op := OperationRequest{
+			OperationType: constants.FileOperationRename,
+			RemotePath:    "/docs/report.pdf",
+			DestName:      "old.pdf",
+		}
+		require.NoError(t, a.encryptOperationPaths(&op))
+		require.Equal(t, remotePath, op.RemotePath)
+
+		renamed, err := a.remotePath("/docs/old.pdf")
+		require.NoError(t, err)
+		require.Equal(t, "/"+op.DestName, renamed[len(renamed)-len(op.DestName)-1:])
+
+		upload := OperationRequest{
+			OperationType: constants.FileOperationInsert,
+			FileMeta:      FileMeta{RemotePath: "/docs/report.pdf", RemoteName: "report.pdf"},
+		}
+		require.NoError(t, a.encryptOperationPaths(&upload))
+		require.Equal(t, remotePath, upload.FileMeta.RemotePath)
+		require.Equal(t, "/"+upload.FileMeta.RemoteName, remotePath[len(remotePath)-len(upload.FileMeta.RemoteName)-1:])
+	})
+
+	t.Run("moved paths", func(t *testing.T) {
+		move, err := a.movedPathOf(&OperationRequest{
+			OperationType: constants.FileOperationRename,
+			RemotePath:    "/docs",
+			DestName:      "papers",
+		})
+		require.NoError(t, err)
+		papers, err := a.remotePath("/papers")
+		require.NoError(t, err)
+		require.Equal(t, &movedPath{from: "/docs", to: "/papers", remotePath: papers}, move)
+
+		// the children of the renamed directory are encrypted for /docs
+		child := papers + "/" + path.Base(remotePath)
+		require.Equal(t, child, a.plainPath(child))
+
+		// moved paths keep the name encrypted for the source directory
+		move, err = a.movedPathOf(&OperationRequest{
+			OperationType: constants.FileOperationMove,
+			RemotePath:    "/docs/report.pdf",
+			DestPath:      "/papers",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "/papers/report.pdf", move.to)
+		require.Equal(t, child, move.remotePath)
+		moved, err := a.remotePath("/papers/report.pdf")
+		require.NoError(t, err)
+		require.NotEqual(t, moved, move.remotePath)
+
+		move, err = a.movedPathOf(&OperationRequest{OperationType: constants.FileOperationDelete, RemotePath: "/docs"})
+		require.NoError(t, err)
+		require.Nil(t, move)
+	})
+
+	t.Run("marker", func(t *testing.T) {
+		marker, err := a.remotePath(PathEncryptionMarkerPath)
+		require.NoError(t, err)
+		require.Equal(t, PathEncryptionMarkerPath, marker)
+
+		load := loadPathEncryption
+		defer func() { loadPathEncryption = load }()
+		loadPathEncryption = func(a *Allocation) (bool, error) {
+			return true, nil
+		}
+		upload := []OperationRequest{{OperationType: constants.FileOperationInsert, FileMeta: FileMeta{RemotePath: "/a.txt"}}}
+		require.NoError(t, a.checkPathEncryption(upload))
+
+		plain := &Allocation{ID: "allocation"}
+		require.ErrorIs(t, plain.checkPathEncryption(upload), ErrPathEncryptionRequired)
+		upload[0].IsRepair = true
+		require.NoError(t, plain.checkPathEncryption(upload))
+	})
+
+	t.Run("status callback", func(t *testing.T) {
+		cb := &mocks.StatusCallback{}
+		cb.On("Started", "allocation", "/docs/report.pdf", 0, 10).Return()
+		cb.On("Completed", "allocation", "/docs/report.pdf", "report.pdf", "application/pdf", 10, 0).Return()
+		cb.On("Error", "allocation", "/plain", 0, mock.Anything).Return()
+
+		status := a.plainStatusCallback(cb)
+		require.Same(t, status, a.plainStatusCallback(status))
+		status.Started("allocation", remotePath, 0, 10)
+		status.Completed("allocation", remotePath, path.Base(remotePath), "application/pdf", 10, 0)
+		status.Error("allocation", "/plain", 0, errors.New("failed"))
+		cb.AssertExpectations(t)
+
+		require.Equal(t, StatusCallback(cb), (&Allocation{}).plainStatusCallback(cb))
+	})
+
+	t.Run("listing", func(t *testing.T) {
+		dir, err := a.remotePath("/docs")
+		require.NoError(t, err)
+		result := &ListResult{
+			Path:     dir,
+			Name:     "docs",
+			Children: []*ListResult{{Path: remotePath}},
+		}
+		a.decryptListResult(result)
+		require.Equal(t, "/docs", result.Path)
+		require.Equal(t, "docs", result.Name)
+		require.Equal(t, "/docs/report.pdf", result.Children[0].Path)
+		require.Equal(t, "report.pdf", result.Children[0].Name)
+
+		refs := []ORef{{}}
+		refs[0].Path = remotePath
+		a.decryptRefs(refs)
+		require.Equal(t, "/docs/report.pdf", refs[0].Path)
+		require.Equal(t, "report.pdf", refs[0].Name)
+		require.Equal(t, "/docs", refs[0].ParentPath)
+	})
+}
@@ -0,0 +1,443 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0chain/errors"
+
+	"github.com/0chain/gosdk/constants"
+	"github.com/0chain/gosdk/core/common"
+	"github.com/0chain/gosdk/core/encryption"
+	"github.com/0chain/gosdk/core/sys"
+	"github.com/0chain/gosdk/zboxcore/client"
+	"github.com/0chain/gosdk/zboxcore/zboxutil"
+)
+
+// PolicyPath is the path of the policy document of an allocation.
+const PolicyPath = "/.policy.json"
+
+const (
+	// PolicyImmutable allows no operation on the matched paths
+	PolicyImmutable = uint16(0)
+	// PolicyAppendOnly only allows new files and directories on the matched paths
+	PolicyAppendOnly = CanUploadMask
+)
+
+var (
+	ErrPolicyDenied           = errors.New("policy_denied", "operation is not allowed by the allocation policy")
+	ErrInvalidPolicy          = errors.New("invalid_policy", "invalid allocation policy")
+	ErrInvalidPolicySignature = errors.New("invalid_policy_signature", "allocation policy is not signed by the owner")
+)
+
+// PathPolicy allows the file operations of FileOptions on the paths matching
+// Pattern. Patterns are absolute with the syntax of path.Match, and a
+// trailing "/**" matches the directory and everything below it.
+type PathPolicy struct {
+	Pattern     string `json:"pattern"`
+	FileOptions uint16 `json:"file_options"`
+}
+
+// AllocationPolicy restricts the allocation-wide FileOptions per path.
+// Operations must be allowed by every policy matching their paths.
+type AllocationPolicy struct {
+	// Version increases on every update of the policy
+	Version int64         `json:"version"`
+	Paths   []*PathPolicy `json:"paths"`
+	// Locks are the retention locks of the allocation
+	Locks []*RetentionLock `json:"locks,omitempty"`
+	// Signature of the policy by the owner
+	Signature string `json:"signature,omitempty"`
+}
+
+// Validate checks the patterns of the policy.
+func (p *AllocationPolicy) Validate() error {
+	for _, pp := range p.Paths {
+		if pp == nil || !zboxutil.IsRemoteAbs(pp.Pattern) {
+			return errors.New(ErrInvalidPolicy.Code, "pattern should be valid and absolute")
+		}
+		pattern := strings.TrimSuffix(pp.Pattern, "/**")
+		if strings.Contains(pattern, "**") {
+			return errors.New(ErrInvalidPolicy.Code, "** is only allowed at the end of a pattern: "+pp.Pattern)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return errors.New(ErrInvalidPolicy.Code, pp.Pattern+": "+err.Error())
+		}
+	}
+	for _, lock := range p.Locks {
+		if err := lock.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *AllocationPolicy) hash() (string, error) {
+	unsigned := *p
+	unsigned.Signature = ""
+	buf, err := json.Marshal(&unsigned)
+	if err != nil {
+		return "", err
+	}
+	return encryption.Hash(buf), nil
+}
+
+func (p *AllocationPolicy) sign() error {
+	hash, err := p.hash()
+	if err != nil {
+		return err
+	}
+	p.Signature, err = client.Sign(hash)
+	return err
+}
+
+func (p *AllocationPolicy) verify(ownerPublicKey string) error {
+	hash, err := p.hash()
+	if err != nil {
+		return err
+	}
+	ok, err := client.VerifySignatureWith(ownerPublicKey, p.Signature, hash)
+	if err != nil || !ok {
+		return ErrInvalidPolicySignature
+	}
+	return nil
+}
+
+// allows reports whether the policy allows the operation of mask on the
+// path, and on everything below it if subtree.
+func (p *AllocationPolicy) allows(remotePath string, mask uint16, subtree bool) bool {
+	for _, pp := range p.Paths {
+		if pp.FileOptions&mask != 0 {
+			continue
+		}
+		if matchPolicyPattern(pp.Pattern, remotePath) {
+			return false
+		}
+		if subtree {
+			if root := policyPatternRoot(pp.Pattern); root == remotePath || isPathBelow(root, remotePath) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func matchPolicyPattern(pattern, p string) bool {
+	if dir := strings.TrimSuffix(pattern, "/**"); dir != pattern {
+		return dir == "" || p == dir || isPathBelow(p, dir)
+	}
+	ok, _ := path.Match(pattern, p)
+	return ok
+}
+
+// policyPatternRoot returns the directory of the pattern before any wildcard.
+func policyPatternRoot(pattern string) string {
+	if i := strings.IndexAny(pattern, "*?[\\"); i >= 0 {
+		return path.Dir(pattern[:i+1])
+	}
+	return pattern
+}
+
+// isPathBelow reports whether p is below dir.
+func isPathBelow(p, dir string) bool {
+	if dir == "/" {
+		return p != "/"
+	}
+	return strings.HasPrefix(p, dir+"/")
+}
+
+type policyCheck struct {
+	path    string
+	mask    uint16
+	subtree bool
+}
+
+func policyChecks(op *OperationRequest) []policyCheck {
+	remotePath := zboxutil.RemoteClean(op.RemotePath)
+	switch op.OperationType {
+	case constants.FileOperationInsert, constants.FileOperationUpdate:
+		if op.FileMeta.RemotePath != "" {
+			remotePath = zboxutil.RemoteClean(op.FileMeta.RemotePath)
+		}
+		if op.OperationType == constants.FileOperationUpdate || op.IsUpdate {
+			return []policyCheck{{path: remotePath, mask: CanUpdateMask}}
+		}
+		return []policyCheck{{path: remotePath, mask: CanUploadMask}}
+	case constants.FileOperationCreateDir:
+		return []policyCheck{{path: remotePath, mask: CanUploadMask}}
+	case constants.FileOperationDelete:
+		return []policyCheck{{path: remotePath, mask: CanDeleteMask, subtree: true}}
+	case constants.FileOperationRename:
+		return []policyCheck{
+			{path: remotePath, mask: CanRenameMask, subtree: true},
+			{path: path.Join(path.Dir(remotePath), op.DestName), mask: CanUploadMask},
+		}
+	case constants.FileOperationCopy:
+		return []policyCheck{
+			{path: remotePath, mask: CanCopyMask},
+			{path: path.Join(zboxutil.RemoteClean(op.DestPath), path.Base(remotePath)), mask: CanUploadMask},
+		}
+	case constants.FileOperationMove:
+		return []policyCheck{
+			{path: remotePath, mask: CanMoveMask, subtree: true},
+			{path: path.Join(zboxutil.RemoteClean(op.DestPath), path.Base(remotePath)), mask: CanUploadMask},
+		}
+	}
+	return nil
+}
+
+// check returns ErrPolicyDenied if the policy doesn't allow the operation,
+// ErrRetentionLocked if it changes locked paths or the compliance locks of
+// the policy document.
+func (p *AllocationPolicy) check(op *OperationRequest) error {
+	now := common.Now()
+	if writesPolicy(op) {
+		if err := p.checkPolicyWrite(op, now); err != nil {
+			return err
+		}
+	}
+	for _, c := range policyChecks(op) {
+		if lock := p.lockOf(c, now); lock != nil {
+			return errors.New(ErrRetentionLocked.Code, fmt.Sprintf("%s of %s is not allowed by the %s retention lock of %s until %s",
+				op.OperationType, c.path, lock.Mode, lock.Path, time.Unix(int64(lock.RetainUntil), 0).UTC().Format(time.RFC3339)))
+		}
+		// only the owner writes the policy document, whatever the path policies
+		if c.path == PolicyPath {
+			continue
+		}
+		if !p.allows(c.path, c.mask, c.subtree) {
+			return errors.New(ErrPolicyDenied.Code, fmt.Sprintf("%s of %s is not allowed by the allocation policy", op.OperationType, c.path))
+		}
+	}
+	return nil
+}
+
+// touchesPolicyPath reports whether the operation changes the policy document.
+func touchesPolicyPath(op *OperationRequest) bool {
+	for _, c := range policyChecks(op) {
+		if c.path == PolicyPath || (c.subtree && c.path == "/") {
+			return true
+		}
+	}
+	return false
+}
+
+type cachedPolicy struct {
+	exists bool   // whether the allocation has a policy document
+	hash   string // of the policy document
+	policy *AllocationPolicy
+}
+
+var (
+	policies   = make(map[string]*cachedPolicy)
+	policiesMu sync.Mutex
+)
+
+func getCachedPolicy(allocationID string) *cachedPolicy {
+	policiesMu.Lock()
+	defer policiesMu.Unlock()
+	return policies[allocationID]
+}
+
+func setCachedPolicy(allocationID string, c *cachedPolicy) {
+	policiesMu.Lock()
+	defer policiesMu.Unlock()
+	policies[allocationID] = c
+}
+
+// GetPolicy returns the policy of the allocation, loading it on first use.
+// It is empty if the allocation has none.
+func (a *Allocation) GetPolicy() (*AllocationPolicy, error) {
+	if c := getCachedPolicy(a.ID); c != nil {
+		return c.policy, nil
+	}
+	return loadPolicy(a)
+}
+
+// LoadPolicy reads the policy document of the allocation from the blobbers
+// and verifies it is signed by the owner.
+func (a *Allocation) LoadPolicy() (*AllocationPolicy, error) {
+	if !a.isInitialized() {
+		return nil, notInitialized
+	}
+	root, err := a.ListDir("/")
+	if err != nil {
+		return nil, err
+	}
+	var doc *ListResult
+	for _, child := range root.Children {
+		if child.Path == PolicyPath {
+			doc = child
+			break
+		}
+	}
+	if doc == nil {
+		c := &cachedPolicy{policy: &AllocationPolicy{}}
+		setCachedPolicy(a.ID, c)
+		return c.policy, nil
+	}
+	if c := getCachedPolicy(a.ID); c != nil && c.exists && doc.Hash != "" && c.hash == doc.Hash {
+		return c.policy, nil
+	}
+
+	buf, err := a.downloadPolicy()
+	if err != nil {
+		return nil, err
+	}
+	policy := &AllocationPolicy{}
+	if err := json.Unmarshal(buf, policy); err != nil {
+		return nil, errors.New(ErrInvalidPolicy.Code, err.Error())
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	if err := policy.verify(a.OwnerPublicKey); err != nil {
+		return nil, err
+	}
+	setCachedPolicy(a.ID, &cachedPolicy{exists: true, hash: doc.Hash, policy: policy})
+	return policy, nil
+}
+
+// SetPolicy signs the path policies and writes them to the allocation,
+// keeping the retention locks of the allocation. Only the owner can set the
+// policy.
+func (a *Allocation) SetPolicy(policy *AllocationPolicy) error {
+	if !a.isInitialized() {
+		return notInitialized
+	}
+	if client.GetClientID() != a.Owner {
+		return errors.New("policy_not_permitted", "only the owner can set the allocation policy")
+	}
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	current, err := a.LoadPolicy()
+	if err != nil {
+		return err
+	}
+
+	next := *policy
+	next.Locks = current.Locks
+	return a.savePolicy(current, &next)
+}
+
+// savePolicy signs the next version of the current policy and writes it to the allocation.
+func (a *Allocation) savePolicy(current, next *AllocationPolicy) error {
+	exists := getCachedPolicy(a.ID).exists
+
+	next.Version = current.Version + 1
+	if err := next.sign(); err != nil {
+		return err
+	}
+	buf, err := json.Marshal(next)
+	if err != nil {
+		return err
+	}
+
+	op := OperationRequest{
+		OperationType: constants.FileOperationInsert,
+		RemotePath:    PolicyPath,
+		Workdir:       defaultWorkdir(),
+		FileReader:    bytes.NewReader(buf),
+		FileMeta: FileMeta{
+			MimeType:   "application/json",
+			ActualSize: int64(len(buf)),
+			RemoteName: path.Base(PolicyPath),
+			RemotePath: PolicyPath,
+		},
+	}
+	if exists {
+		op.OperationType = constants.FileOperationUpdate
+	}
+	if err := a.DoMultiOperation([]OperationRequest{op}); err != nil {
+		return err
+	}
+	// the hash of the new document is unknown until the next load
+	setCachedPolicy(a.ID, &cachedPolicy{exists: true, policy: next})
+	return nil
+}
+
+func (a *Allocation) downloadPolicy() ([]byte, error) {
+	f := &sys.MemFile{}
+	cb := &policyStatusCB{done: make(chan error, 1)}
+	if err := a.DownloadFileToFileHandler(f, PolicyPath, false, cb, true); err != nil {
+		return nil, err
+	}
+	select {
+	case err := <-cb.done:
+		if err != nil {
+			return nil, err
+		}
+	case <-a.ctx.Done():
+		return nil, a.ctx.Err()
+	}
+	return f.Buffer, nil
+}
+
+// loadPolicy loads the policy of the allocation, revalidating the cached
+// policy by the hash of the document.
+var loadPolicy = (*Allocation).LoadPolicy
+
+// checkPolicyOperations returns ErrPolicyDenied if the policy doesn't allow
+// the operations, ErrRetentionLocked if they change locked paths. The policy
+// is loaded if none is cached, and revalidated for the operations of other
+// clients and the operations changing existing paths. Only the owner can
+// change the policy document.
+func (a *Allocation) checkPolicyOperations(operations []OperationRequest) error {
+	var policy *AllocationPolicy
+	isOwner := client.GetClientID() == a.Owner
+	cached := getCachedPolicy(a.ID)
+	if cached != nil && isOwner {
+		policy = cached.policy
+	}
+
+	loaded := false
+	for i := range operations {
+		op := &operations[i]
+		if op.IsRepair {
+			continue
+		}
+		if !loaded && (cached == nil || !isOwner || changesExisting(op)) {
+			var err error
+			if policy, err = loadPolicy(a); err != nil {
+				return err
+			}
+			loaded = true
+		}
+		if !isOwner && touchesPolicyPath(op) {
+			return errors.New(ErrPolicyDenied.Code, "only the owner can change "+PolicyPath)
+		}
+		if policy == nil {
+			continue
+		}
+		if err := policy.check(op); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// policyStatusCB reports the end of the download of the policy document.
+type policyStatusCB struct {
+	done chan error
+}
+
+func (cb *policyStatusCB) Started(allocationId, filePath string, op int, totalBytes int) {}
+
+func (cb *policyStatusCB) InProgress(allocationId, filePath string, op int, completedBytes int, data []byte) {
+}
+
+func (cb *policyStatusCB) Error(allocationID string, filePath string, op int, err error) {
+	cb.done <- err
+}
+
+func (cb *policyStatusCB) Completed(allocationId, filePath string, filename string, mimetype string, size int, op int) {
+	cb.done <- nil
+}
+
+func (cb *policyStatusCB) RepairCompleted(filesRepaired int) {}
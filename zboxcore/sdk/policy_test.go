@@ -0,0 +1,456 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/0chain/errors"
+	"github.com/0chain/gosdk/constants"
+	"github.com/0chain/gosdk/core/encryption"
+	"github.com/0chain/gosdk/core/zcncrypto"
+	"github.com/0chain/gosdk/zboxcore/blockchain"
+	"github.com/0chain/gosdk/zboxcore/client"
+	"github.com/0chain/gosdk/zboxcore/fileref"
+	"github.com/0chain/gosdk/zboxcore/mocks"
+	"github.com/0chain/gosdk/zboxcore/zboxutil"
+	"github.com/hitenjain14/fasthttp"
+	"github.com/hitenjain14/fasthttp/fasthttputil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllocationPolicy(t *testing.T) {
+	policy := &AllocationPolicy{Paths: []*PathPolicy{
+		{Pattern: "/archive/**", FileOptions: PolicyImmutable},
+		{Pattern: "/inbox", FileOptions: PolicyAppendOnly},
+		{Pattern: "/inbox/*", FileOptions: PolicyAppendOnly},
+		{Pattern: "/reports/*.pdf", FileOptions: PolicyAppendOnly | CanUpdateMask},
+	}}
+	require.NoError(t, policy.Validate())
+
+	tests := []struct {
+		name    string
+		op      OperationRequest
+		allowed bool
+	}{
+		{"upload to archive", OperationRequest{OperationType: constants.FileOperationInsert, FileMeta: FileMeta{RemotePath: "/archive/a.txt"}}, false},
+		{"delete in archive", OperationRequest{OperationType: constants.FileOperationDelete, RemotePath: "/archive/2023/a.txt"}, false},
+		{"delete archive", OperationRequest{OperationType: constants.FileOperationDelete, RemotePath: "/archive"}, false},
+		{"delete parent of archive", OperationRequest{OperationType: constants.FileOperationDelete, RemotePath: "/"}, false},
+		{"move archive", OperationRequest{OperationType: constants.FileOperationMove, RemotePath: "/archive", DestPath: "/old"}, false},
+		{"copy from archive", OperationRequest{OperationType: constants.FileOperationCopy, RemotePath: "/archive/a.txt", DestPath: "/"}, false},
+		{"upload to archived name", OperationRequest{OperationType: constants.FileOperationInsert, FileMeta: FileMeta{RemotePath: "/archived.txt"}}, true},
+		{"upload to inbox", OperationRequest{OperationType: constants.FileOperationInsert, FileMeta: FileMeta{RemotePath: "/inbox/a.txt"}}, true},
+		{"create dir in inbox", OperationRequest{OperationType: constants.FileOperationCreateDir, RemotePath: "/inbox/new"}, true},
+		{"update in inbox", OperationRequest{OperationType: constants.FileOperationUpdate, FileMeta: FileMeta{RemotePath: "/inbox/a.txt"}}, false},
+		{"delete in inbox", OperationRequest{OperationType: constants.FileOperationDelete, RemotePath: "/inbox/a.txt"}, false},
+		{"rename in inbox", OperationRequest{OperationType: constants.FileOperationRename, RemotePath: "/inbox/a.txt", DestName: "b.txt"}, false},
+		{"move to inbox", OperationRequest{OperationType: constants.FileOperationMove, RemotePath: "/a.txt", DestPath: "/inbox"}, true},
+		{"delete below inbox", OperationRequest{OperationType: constants.FileOperationDelete, RemotePath: "/inbox/new/a.txt"}, true},
+		{"update report", OperationRequest{OperationType: constants.FileOperationUpdate, FileMeta: FileMeta{RemotePath: "/reports/q1.pdf"}}, true},
+		{"delete report", OperationRequest{OperationType: constants.FileOperationDelete, RemotePath: "/reports/q1.pdf"}, false},
+		{"delete reports", OperationRequest{OperationType: constants.FileOperationDelete, RemotePath: "/reports"}, false},
+		{"delete other report", OperationRequest{OperationType: constants.FileOperationDelete, RemotePath: "/reports/q1.txt"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.check(&tt.op)
+			if tt.allowed {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.True(t, errors.Is(err, ErrPolicyDenied))
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, pattern := range []string{"archive/**", "/archive/**/a.txt", "/archive/[a"} {
+			p := &AllocationPolicy{Paths: []*PathPolicy{{Pattern: pattern}}}
+			require.Error(t, p.Validate(), pattern)
+		}
+	})
+}
+
+// stubPolicy replaces the policy loaded from the blobbers for the test and
+// returns the count of loads.
+func stubPolicy(t *testing.T, policy *AllocationPolicy) *int {
+	loads := new(int)
+	load := loadPolicy
+	loadPolicy = func(a *Allocation) (*AllocationPolicy, error) {
+		*loads++
+		setCachedPolicy(a.ID, &cachedPolicy{exists: true, policy: policy})
+		return policy, nil
+	}
+	t.Cleanup(func() { loadPolicy = load })
+	return loads
+}
+
+func TestCheckPolicyOperations(t *testing.T) {
+	a := &Allocation{ID: "policy allocation", Owner: "owner"}
+	stubPolicy(t, &AllocationPolicy{Paths: []*PathPolicy{
+		{Pattern: "/**", FileOptions: PolicyImmutable},
+	}})
+
+	update := OperationRequest{OperationType: constants.FileOperationUpdate, FileMeta: FileMeta{RemotePath: PolicyPath}}
+	upload := OperationRequest{OperationType: constants.FileOperationInsert, FileMeta: FileMeta{RemotePath: "/a.txt"}}
+	repair := OperationRequest{OperationType: constants.FileOperationInsert, IsRepair: true, FileMeta: FileMeta{RemotePath: "/a.txt"}}
+
+	// the client is not the owner
+	err := a.checkPolicyOperations([]OperationRequest{update})
+	require.True(t, errors.Is(err, ErrPolicyDenied))
+	err = a.checkPolicyOperations([]OperationRequest{upload})
+	require.True(t, errors.Is(err, ErrPolicyDenied))
+	require.NoError(t, a.checkPolicyOperations([]OperationRequest{repair}))
+
+	a.Owner = client.GetClientID()
+	require.NoError(t, a.checkPolicyOperations([]OperationRequest{update}))
+	err = a.checkPolicyOperations([]OperationRequest{upload})
+	require.True(t, errors.Is(err, ErrPolicyDenied))
+}
+
+func TestCheckPolicyOperationsLoadsUncached(t *testing.T) {
+	a := &Allocation{ID: "uncached policy allocation", Owner: client.GetClientID()}
+	upload := OperationRequest{OperationType: constants.FileOperationInsert, FileMeta: FileMeta{RemotePath: "/a.txt"}}
+
+	// a new process of the owner has no cached policy
+	loads := stubPolicy(t, &AllocationPolicy{Paths: []*PathPolicy{{Pattern: "/**", FileOptions: PolicyImmutable}}})
+	err := a.checkPolicyOperations([]OperationRequest{upload})
+	require.True(t, errors.Is(err, ErrPolicyDenied))
+	require.Equal(t, 1, *loads)
+}
+
+func TestCheckPolicyOperationsRevalidates(t *testing.T) {
+	a := &Allocation{ID: "revalidated policy allocation", Owner: "owner"}
+	upload := OperationRequest{OperationType: constants.FileOperationInsert, FileMeta: FileMeta{RemotePath: "/a.txt"}}
+	update := OperationRequest{OperationType: constants.FileOperationInsert, IsUpdate: true, FileMeta: FileMeta{RemotePath: "/a.txt"}}
+	remove := OperationRequest{OperationType: constants.FileOperationDelete, RemotePath: "/a.txt"}
+
+	loads := stubPolicy(t, &AllocationPolicy{})
+	require.NoError(t, a.checkPolicyOperations([]OperationRequest{upload}))
+
+	// the owner made the allocation append only since
+	stubPolicy(t, &AllocationPolicy{Paths: []*PathPolicy{{Pattern: "/**", FileOptions: PolicyAppendOnly}}})
+	require.NoError(t, a.checkPolicyOperations([]OperationRequest{upload}))
+	err := a.checkPolicyOperations([]OperationRequest{update})
+	require.True(t, errors.Is(err, ErrPolicyDenied))
+	err = a.checkPolicyOperations([]OperationRequest{remove})
+	require.True(t, errors.Is(err, ErrPolicyDenied))
+	require.Equal(t, 1, *loads)
+}
+
+// policyBlobbers are mocked blobbers keeping the files committed by the
+// client, enough to write and read back the policy document.
+type policyBlobbers struct {
+	mu      sync.Mutex
+	files   map[string]map[string]*fileref.FileRef // by blobber and path
+	data    map[string]map[string][]byte           // by blobber and path
+	pending map[string]map[string]*fileref.FileRef // by blobber and connection
+}
+
+func newPolicyBlobbers() *policyBlobbers {
+	return &policyBlobbers{
+		files:   make(map[string]map[string]*fileref.FileRef),
+		data:    make(map[string]map[string][]byte),
+		pending: make(map[string]map[string]*fileref.FileRef),
+	}
+}
+
+func (b *policyBlobbers) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	blobber, endpoint := parts[0], "/"+parts[1]
+	if b.files[blobber] == nil {
+		b.files[blobber] = make(map[string]*fileref.FileRef)
+		b.data[blobber] = make(map[string][]byte)
+		b.pending[blobber] = make(map[string]*fileref.FileRef)
+	}
+	files, data := b.files[blobber], b.data[blobber]
+
+	respond := func(body interface{}) {
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(body) //nolint: errcheck
+	}
+	switch {
+	case strings.HasPrefix(endpoint, zboxutil.UPLOAD_ENDPOINT):
+		var meta UploadFormData
+		if err := r.ParseMultipartForm(1 << 24); err != nil || json.Unmarshal([]byte(r.FormValue("uploadMeta")), &meta) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f, _, err := r.FormFile("uploadFile")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		shard, _ := ioutil.ReadAll(f)
+		ref := b.pending[blobber][meta.ConnectionID]
+		if ref == nil {
+			data[meta.Path] = nil
+		}
+		data[meta.Path] = append(data[meta.Path], shard...)
+		if meta.IsFinal {
+			ref = &fileref.FileRef{
+				Ref: fileref.Ref{
+					Type:       fileref.FILE,
+					Name:       meta.Filename,
+					Path:       meta.Path,
+					Size:       int64(len(data[meta.Path])),
+					ActualSize: meta.ActualSize,
+					ChunkSize:  meta.ChunkSize,
+					LookupHash: fileref.GetReferenceLookup(r.Header.Get(zboxutil.ALLOCATION_ID_HEADER), meta.Path),
+				},
+				ValidationRoot:          meta.ValidationRoot,
+				ValidationRootSignature: meta.ValidationRootSignature,
+				FixedMerkleRoot:         meta.FixedMerkleRoot,
+				ActualFileSize:          meta.ActualSize,
+				ActualFileHash:          meta.ActualHash,
+				ActualFileHashSignature: meta.ActualFileHashSignature,
+				MimeType:                meta.MimeType,
+			}
+			ref.FileMetaHash = encryption.Hash(ref.Path + ref.ActualFileHash)
+		}
+		b.pending[blobber][meta.ConnectionID] = ref
+		respond(&UploadResult{Filename: meta.Filename})
+	case strings.HasPrefix(endpoint, zboxutil.COMMIT_ENDPOINT):
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if ref := b.pending[blobber][r.FormValue("connection_id")]; ref != nil {
+			files[ref.Path] = ref
+		}
+		delete(b.pending[blobber], r.FormValue("connection_id"))
+		respond(map[string]string{})
+	case strings.HasPrefix(endpoint, zboxutil.LIST_ENDPOINT), strings.HasPrefix(endpoint, zboxutil.REFERENCE_ENDPOINT):
+		root := map[string]interface{}{"type": fileref.DIRECTORY, "name": "/", "path": "/"}
+		var (
+			list   []*fileref.ReferencePath
+			hashes []string
+		)
+		for _, ref := range files {
+			buf, _ := json.Marshal(ref)
+			entity := make(map[string]interface{})
+			json.Unmarshal(buf, &entity) //nolint: errcheck
+			list = append(list, &fileref.ReferencePath{Meta: entity})
+			hashes = append(hashes, ref.FileMetaHash)
+		}
+		sort.Strings(hashes)
+		root["file_meta_hash"] = encryption.Hash(strings.Join(hashes, ":"))
+		if strings.HasPrefix(endpoint, zboxutil.REFERENCE_ENDPOINT) {
+			respond(&ReferencePathResult{ReferencePath: &fileref.ReferencePath{Meta: root, List: list}})
+			return
+		}
+		result := &fileref.ListResult{Meta: root}
+		for _, rp := range list {
+			result.Entities = append(result.Entities, rp.Meta)
+		}
+		respond(result)
+	case strings.HasPrefix(endpoint, zboxutil.FILE_META_ENDPOINT):
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		for _, ref := range files {
+			if ref.LookupHash == r.FormValue("path_hash") {
+				respond(ref)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusBadRequest)
+	case strings.HasPrefix(endpoint, zboxutil.DOWNLOAD_ENDPOINT):
+		blockNum, _ := strconv.ParseInt(r.Header.Get("X-Block-Num"), 10, 64)
+		numBlocks, _ := strconv.ParseInt(r.Header.Get("X-Num-Blocks"), 10, 64)
+		for _, ref := range files {
+			if ref.LookupHash == r.Header.Get("X-Path-Hash") {
+				shard := data[ref.Path]
+				start, end := blockNum*ref.ChunkSize, (blockNum+numBlocks)*ref.ChunkSize
+				if end > int64(len(shard)) {
+					end = int64(len(shard))
+				}
+				w.Write(shard[start:end]) //nolint: errcheck
+				return
+			}
+		}
+		w.WriteHeader(http.StatusBadRequest)
+	case strings.HasPrefix(endpoint, zboxutil.WM_LOCK_ENDPOINT) && r.Method == http.MethodPost:
+		respond(&WMLockResult{Status: WMLockStatusOK})
+	case strings.HasPrefix(endpoint, zboxutil.LATEST_WRITE_MARKER_ENDPOINT):
+		respond(map[string]interface{}{"latest_write_marker": nil, "prev_write_marker": nil})
+	default:
+		respond(map[string]string{})
+	}
+}
+
+// setupPolicyBlobbers makes the allocation use mocked policy blobbers, the
+// client a new owner of the allocation, and returns the blobbers and the
+// owner's wallet.
+func setupPolicyBlobbers(t *testing.T, a *Allocation) (*policyBlobbers, *zcncrypto.Wallet) {
+	blobbers := newPolicyBlobbers()
+	ln := fasthttputil.NewInmemoryListener()
+	go fasthttp.Serve(ln, func(ctx *fasthttp.RequestCtx) { //nolint: errcheck
+		req := httptest.NewRequest(string(ctx.Method()), string(ctx.RequestURI()), bytes.NewReader(ctx.PostBody()))
+		ctx.Request.Header.VisitAll(func(key, value []byte) {
+			req.Header.Set(string(key), string(value))
+		})
+		rec := httptest.NewRecorder()
+		blobbers.ServeHTTP(rec, req)
+		ctx.SetStatusCode(rec.Code)
+		ctx.SetBody(rec.Body.Bytes())
+	})
+	dial := func(addr string) (net.Conn, error) { return ln.Dial() }
+
+	mockClient := &mocks.HttpClient{}
+	mockClient.On("Do", mock.Anything).Return(func(req *http.Request) (*http.Response, error) {
+		rec := httptest.NewRecorder()
+		blobbers.ServeHTTP(rec, req)
+		return rec.Result(), nil
+	})
+	httpClient, fastClient := zboxutil.Client, zboxutil.FastHttpClient
+	zboxutil.Client = mockClient
+	zboxutil.FastHttpClient = &fasthttp.Client{Dial: dial}
+
+	wallet, err := zcncrypto.NewSignatureScheme("bls0chain").GenerateKeys()
+	require.NoError(t, err)
+	c := client.GetClient()
+	clientWallet, scheme := c.Wallet, c.SignatureScheme
+	c.Wallet, c.SignatureScheme = wallet, "bls0chain"
+	workdir := Workdir
+	Workdir = t.TempDir()
+
+	a.Owner, a.OwnerPublicKey = wallet.ClientID, wallet.ClientKey
+	for i := 0; i < 3; i++ {
+		id := fmt.Sprintf("%s blobber %d", a.ID, i)
+		zboxutil.HostClientMap[id] = &fasthttp.HostClient{Addr: "blobbers", Dial: dial}
+		a.Blobbers = append(a.Blobbers, &blockchain.StorageNode{ID: id, Baseurl: fmt.Sprintf("http://blobbers/blobber%d", i)})
+	}
+	a.DataShards, a.ParityShards, a.Size = 2, 1, GB
+	a.FileOptions = uint16(63)
+	a.InitAllocation()
+	sdkInitialized = true
+
+	load := loadPolicy
+	loadPolicy = (*Allocation).LoadPolicy
+	t.Cleanup(func() {
+		loadPolicy = load
+		a.ctxCancelF()
+		for _, b := range a.Blobbers {
+			delete(zboxutil.HostClientMap, b.ID)
+		}
+		Workdir = workdir
+		c.Wallet, c.SignatureScheme = clientWallet, scheme
+		zboxutil.Client, zboxutil.FastHttpClient = httpClient, fastClient
+		ln.Close()
+	})
+	return blobbers, wallet
+}
+
+// writePolicyDocument writes the policy document as is, without signing it.
+func writePolicyDocument(t *testing.T, a *Allocation, policy *AllocationPolicy) {
+	buf, err := json.Marshal(policy)
+	require.NoError(t, err)
+	require.NoError(t, a.DoMultiOperation([]OperationRequest{{
+		OperationType: constants.FileOperationUpdate,
+		RemotePath:    PolicyPath,
+		Workdir:       Workdir,
+		FileReader:    bytes.NewReader(buf),
+		FileMeta: FileMeta{
+			MimeType:   "application/json",
+			ActualSize: int64(len(buf)),
+			RemoteName: path.Base(PolicyPath),
+			RemotePath: PolicyPath,
+		},
+	}}))
+}
+
+func TestPolicyDocument(t *testing.T) {
+	a := &Allocation{ID: "policy document allocation", Tx: "policy document allocation"}
+	blobbers, owner := setupPolicyBlobbers(t, a)
+	paths := []*PathPolicy{{Pattern: "/archive/**", FileOptions: PolicyImmutable}}
+
+	policy, err := a.LoadPolicy()
+	require.NoError(t, err)
+	require.Equal(t, &AllocationPolicy{}, policy)
+
+	require.NoError(t, a.SetPolicy(&AllocationPolicy{Paths: paths}))
+	for _, files := range blobbers.files {
+		require.Contains(t, files, PolicyPath)
+	}
+
+	// a new process loads the signed policy from the blobbers
+	setCachedPolicy(a.ID, nil)
+	policy, err = a.GetPolicy()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), policy.Version)
+	require.Equal(t, paths, policy.Paths)
+	require.NoError(t, policy.verify(owner.ClientKey))
+
+	err = a.DoMultiOperation([]OperationRequest{{OperationType: constants.FileOperationDelete, RemotePath: "/archive/a.txt"}})
+	require.True(t, errors.Is(err, ErrPolicyDenied))
+
+	paths = append(paths, &PathPolicy{Pattern: "/inbox/**", FileOptions: PolicyAppendOnly})
+	require.NoError(t, a.SetPolicy(&AllocationPolicy{Paths: paths}))
+	setCachedPolicy(a.ID, nil)
+	policy, err = a.LoadPolicy()
+	require.NoError(t, err)
+	require.Equal(t, int64(2), policy.Version)
+	require.Equal(t, paths, policy.Paths)
+
+}
+
+func TestPolicyDocumentSignature(t *testing.T) {
+	other, err := zcncrypto.NewSignatureScheme("bls0chain").GenerateKeys()
+	require.NoError(t, err)
+	signer := zcncrypto.NewSignatureScheme("bls0chain")
+	require.NoError(t, signer.SetPrivateKey(other.Keys[0].PrivateKey))
+
+	tests := []struct {
+		name   string
+		change func(t *testing.T, p *AllocationPolicy)
+	}{
+		{"tampered", func(t *testing.T, p *AllocationPolicy) {
+			p.Paths = nil
+		}},
+		{"not signed by the owner", func(t *testing.T, p *AllocationPolicy) {
+			p.Paths = nil
+			hash, err := p.hash()
+			require.NoError(t, err)
+			p.Signature, err = signer.Sign(hash)
+			require.NoError(t, err)
+			require.NoError(t, p.verify(other.ClientKey))
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &Allocation{ID: "policy signature allocation " + tt.name, Tx: "policy signature allocation " + tt.name}
+			_, owner := setupPolicyBlobbers(t, a)
+			require.NoError(t, a.SetPolicy(&AllocationPolicy{Paths: []*PathPolicy{{Pattern: "/archive/**", FileOptions: PolicyImmutable}}}))
+			policy, err := a.LoadPolicy()
+			require.NoError(t, err)
+
+			changed := *policy
+			tt.change(t, &changed)
+			require.True(t, errors.Is(changed.verify(owner.ClientKey), ErrInvalidPolicySignature))
+			writePolicyDocument(t, a, &changed)
+
+			_, err = a.LoadPolicy()
+			require.True(t, errors.Is(err, ErrInvalidPolicySignature))
+			// nor can the allocation be written with the invalid policy
+			err = a.DoMultiOperation([]OperationRequest{{OperationType: constants.FileOperationDelete, RemotePath: "/archive/a.txt"}})
+			require.True(t, errors.Is(err, ErrInvalidPolicySignature))
+		})
+	}
+}
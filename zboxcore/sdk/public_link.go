@@ -0,0 +1,431 @@
+package sdk
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+
+	"github.com/0chain/errors"
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/0chain/gosdk/core/common"
+	"github.com/0chain/gosdk/zboxcore/encryption"
+	"github.com/0chain/gosdk/zboxcore/fileref"
+	"github.com/0chain/gosdk/zboxcore/zboxutil"
+)
+
+// flags of public links
+const (
+	publicLinkPassword byte = 1 << iota
+	publicLinkTicket
+)
+
+const (
+	publicLinkVersion    = 1
+	publicLinkIDSize     = 16
+	publicLinkSecretSize = 32
+
+	// DefaultPublicLinkTicketLifetime is the lifetime in seconds of the auth
+	// tickets of redeemed public links.
+	DefaultPublicLinkTicketLifetime = 3600
+)
+
+var (
+	ErrInvalidPublicLink   = errors.New("invalid_public_link", "invalid public link")
+	ErrPublicLinkNotFound  = errors.New("public_link_not_found", "public link not found")
+	ErrPublicLinkExhausted = errors.New("public_link_exhausted", "public link reached its maximum redeems")
+	ErrPublicLinkExpired   = errors.New("public_link_expired", "public link is expired or revoked")
+	ErrPublicLinkPassword  = errors.New("public_link_password", "incorrect password of the public link")
+	// ErrPublicLinkGroupShare is returned for links of paths shared with a share group
+	ErrPublicLinkGroupShare = errors.New("public_link_group_share", "path is shared with a share group")
+)
+
+// PublicLinkOptions are the options of a public link.
+type PublicLinkOptions struct {
+	// Password protects the link. It never leaves the client: the auth ticket
+	// is encrypted with a key derived from it, and encrypted content is
+	// re-encrypted to a key derived from it.
+	Password string
+	// ExpirationSeconds is the lifetime of the link, 0 never expires
+	ExpirationSeconds int64
+	// MaxDownloads caps the redeems of the link with RedeemPublicLink of
+	// the issuer, not the downloads: an auth ticket of a redeem downloads
+	// any number of times until it expires after TicketLifetime. 0 is
+	// unlimited. Links with a cap have no auth ticket, holders redeem it
+	// for every download.
+	MaxDownloads int
+	// TicketLifetime is the lifetime in seconds of the auth tickets of
+	// redeems, DefaultPublicLinkTicketLifetime if 0
+	TicketLifetime int64
+}
+
+// PublicLinkInfo is the public link of a share, kept by the issuer.
+type PublicLinkInfo struct {
+	ID                string `json:"id"`
+	PasswordProtected bool   `json:"password_protected"`
+	MaxDownloads      int    `json:"max_downloads,omitempty"`
+	Downloads         int    `json:"downloads"` // redeems of the link
+	TicketLifetime    int64  `json:"ticket_lifetime,omitempty"`
+	// TicketKey encrypts the auth tickets of redeems
+	TicketKey []byte `json:"ticket_key,omitempty"`
+}
+
+// PublicLink is a decoded public link. A link is a compact URL-safe string of
+// the link ID, the allocation, the salt of the password or the secret of the
+// link, and the encrypted auth ticket, compressed.
+type PublicLink struct {
+	ID           string
+	AllocationID string
+
+	flags  byte
+	secret []byte // salt of the password, or secret of links without one
+	ticket []byte
+}
+
+// PasswordProtected reports whether the link needs a password.
+func (l *PublicLink) PasswordProtected() bool {
+	return l.flags&publicLinkPassword != 0
+}
+
+// Redeemable reports whether the auth ticket must be redeemed with the issuer.
+func (l *PublicLink) Redeemable() bool {
+	return l.flags&publicLinkTicket == 0
+}
+
+// Encode returns the URL-safe string of the link.
+func (l *PublicLink) Encode() (string, error) {
+	id, err := hex.DecodeString(l.ID)
+	if err != nil || len(id) != publicLinkIDSize {
+		return "", ErrInvalidPublicLink
+	}
+	allocationID, err := hex.DecodeString(l.AllocationID)
+	if err != nil || len(allocationID) != sha256.Size {
+		return "", ErrInvalidPublicLink
+	}
+
+	buf := make([]byte, 0, 2+len(id)+len(allocationID)+len(l.secret)+len(l.ticket))
+	buf = append(buf, publicLinkVersion, l.flags)
+	buf = append(buf, id...)
+	buf = append(buf, allocationID...)
+	buf = append(buf, l.secret...)
+	buf = append(buf, l.ticket...)
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// ParsePublicLink decodes a public link.
+func ParsePublicLink(link string) (*PublicLink, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(link)
+	if err != nil {
+		return nil, ErrInvalidPublicLink
+	}
+	const size = 2 + publicLinkIDSize + sha256.Size + publicLinkSecretSize
+	if len(buf) < size || buf[0] != publicLinkVersion {
+		return nil, ErrInvalidPublicLink
+	}
+
+	l := &PublicLink{flags: buf[1]}
+	buf = buf[2:]
+	l.ID, buf = hex.EncodeToString(buf[:publicLinkIDSize]), buf[publicLinkIDSize:]
+	l.AllocationID, buf = hex.EncodeToString(buf[:sha256.Size]), buf[sha256.Size:]
+	l.secret, buf = buf[:publicLinkSecretSize], buf[publicLinkSecretSize:]
+	if l.Redeemable() != (len(buf) == 0) {
+		return nil, ErrInvalidPublicLink
+	}
+	l.ticket = buf
+	return l, nil
+}
+
+// publicLinkKeys are the keys of a link derived from its secret.
+type publicLinkKeys struct {
+	ticketKey     []byte
+	decryptionKey string // base64 private key encrypted content is re-encrypted to
+}
+
+func derivePublicLinkKeys(password string, secret []byte) (*publicLinkKeys, error) {
+	if password != "" {
+		var err error
+		if secret, err = encryption.DerivePasswordKey(password, secret, publicLinkSecretSize); err != nil {
+			return nil, err
+		}
+	}
+
+	keys := make([]byte, 2*publicLinkSecretSize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, nil, []byte("public link")), keys); err != nil {
+		return nil, err
+	}
+	encScheme := encryption.NewEncryptionScheme()
+	if _, err := encScheme.Initialize(hex.EncodeToString(keys[publicLinkSecretSize:])); err != nil {
+		return nil, err
+	}
+	decryptionKey, err := encScheme.GetPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	return &publicLinkKeys{ticketKey: keys[:publicLinkSecretSize], decryptionKey: decryptionKey}, nil
+}
+
+func (k *publicLinkKeys) publicKey() (string, error) {
+	encScheme, err := encryptionSchemeOfKey(k.decryptionKey)
+	if err != nil {
+		return "", err
+	}
+	return encScheme.GetPublicKey()
+}
+
+// sealTicket compresses and encrypts the base64 auth ticket.
+func (k *publicLinkKeys) sealTicket(authTicket string) ([]byte, error) {
+	at, err := base64.StdEncoding.DecodeString(authTicket)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(at); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return encryption.SealWithKey(k.ticketKey, buf.Bytes())
+}
+
+func (k *publicLinkKeys) openTicket(sealed []byte) (string, error) {
+	compressed, err := encryption.OpenWithKey(k.ticketKey, sealed)
+	if err != nil {
+		return "", ErrPublicLinkPassword
+	}
+	at, err := ioutil.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	if err != nil {
+		return "", errors.New("invalid_public_link", err.Error())
+	}
+	return base64.StdEncoding.EncodeToString(at), nil
+}
+
+// CreatePublicLink shares the file or directory publicly and returns a link
+// to it. The share is kept in the share registry with the link, and revoked
+// with RevokeShare of the path and no referee. A path has one public share,
+// a new link replaces the previous one. Paths with an active share of a share
+// group fail with ErrPublicLinkGroupShare.
+func (a *Allocation) CreatePublicLink(path, filename, referenceType string, opts PublicLinkOptions) (string, error) {
+	if !a.isInitialized() {
+		return "", notInitialized
+	}
+	path = zboxutil.RemoteClean(path)
+	if path == "" || !zboxutil.IsRemoteAbs(path) {
+		return "", errors.New("invalid_path", "Path should be valid and absolute")
+	}
+	if opts.ExpirationSeconds < 0 || opts.MaxDownloads < 0 || opts.TicketLifetime < 0 {
+		return "", errors.New("invalid_public_link", "negative public link option")
+	}
+	if s := a.GetShare(path, ""); s != nil && s.GroupID != "" && s.Active() {
+		return "", ErrPublicLinkGroupShare
+	}
+
+	l := &PublicLink{AllocationID: a.ID, secret: make([]byte, publicLinkSecretSize)}
+	id := make([]byte, publicLinkIDSize)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	l.ID = hex.EncodeToString(id)
+	if _, err := rand.Read(l.secret); err != nil {
+		return "", err
+	}
+	if opts.Password != "" {
+		l.flags |= publicLinkPassword
+	}
+	keys, err := derivePublicLinkKeys(opts.Password, l.secret)
+	if err != nil {
+		return "", err
+	}
+
+	// encrypted content is re-encrypted to the key of the link
+	var encPublicKey string
+	encrypted := referenceType == fileref.DIRECTORY
+	if !encrypted {
+		fileMeta, err := a.GetFileMeta(path)
+		if err != nil {
+			return "", err
+		}
+		encrypted = fileMeta.isEncrypted()
+	}
+	if encrypted {
+		if encPublicKey, err = keys.publicKey(); err != nil {
+			return "", err
+		}
+	}
+
+	authTicket, err := a.GetAuthTicket(path, filename, referenceType, "", encPublicKey, opts.ExpirationSeconds, nil)
+	if err != nil {
+		return "", err
+	}
+
+	info := &PublicLinkInfo{
+		ID:                l.ID,
+		PasswordProtected: opts.Password != "",
+		MaxDownloads:      opts.MaxDownloads,
+	}
+	if opts.MaxDownloads > 0 {
+		info.TicketLifetime = opts.TicketLifetime
+		info.TicketKey = keys.ticketKey
+	} else {
+		l.flags |= publicLinkTicket
+		if l.ticket, err = keys.sealTicket(authTicket); err != nil {
+			return "", err
+		}
+	}
+	getShareRegistry(a.ID).update(path, "", func(s *ShareInfo) {
+		s.Link = info
+	})
+
+	return l.Encode()
+}
+
+// findPublicLink returns the share of the public link.
+func (a *Allocation) findPublicLink(linkID string) *ShareInfo {
+	for _, s := range a.ListShares(&ShareFilter{IncludeInactive: true}) {
+		if s.Link != nil && s.Link.ID == linkID {
+			return s
+		}
+	}
+	return nil
+}
+
+// RedeemPublicLink is called by the issuer of a public link with a maximum
+// of downloads for a download of it. It counts the redeem and returns an
+// auth ticket which expires after the ticket lifetime of the link, encrypted
+// for the link. The holder of the link opens it with
+// UnlockedPublicLink.AuthTicket.
+func (a *Allocation) RedeemPublicLink(linkID string) (string, error) {
+	s := a.findPublicLink(linkID)
+	if s == nil {
+		return "", ErrPublicLinkNotFound
+	}
+	if !s.Active() {
+		return "", ErrPublicLinkExpired
+	}
+	if s.Link.MaxDownloads == 0 {
+		return "", errors.New("invalid_public_link", "public link has no maximum of downloads")
+	}
+
+	lifetime := s.Link.TicketLifetime
+	if lifetime == 0 {
+		lifetime = DefaultPublicLinkTicketLifetime
+	}
+	if s.Expiration > 0 {
+		remaining := s.Expiration - int64(common.Now())
+		if remaining <= 0 {
+			return "", ErrPublicLinkExpired
+		}
+		if remaining < lifetime {
+			lifetime = remaining
+		}
+	}
+
+	// the download is counted first, so concurrent redeems can't exceed the maximum
+	registry := getShareRegistry(a.ID)
+	var counted bool
+	registry.update(s.Path, "", func(s *ShareInfo) {
+		if s.Link != nil && s.Link.ID == linkID && s.Link.Downloads < s.Link.MaxDownloads {
+			s.Link.Downloads++
+			counted = true
+		}
+	})
+	if !counted {
+		return "", ErrPublicLinkExhausted
+	}
+
+	ticket, err := a.publicLinkTicket(s, lifetime)
+	if err != nil {
+		registry.update(s.Path, "", func(s *ShareInfo) {
+			if s.Link != nil && s.Link.ID == linkID {
+				s.Link.Downloads--
+			}
+		})
+		return "", err
+	}
+	return ticket, nil
+}
+
+// publicLinkTicket issues an auth ticket of the share of the link, sealed for the link.
+func (a *Allocation) publicLinkTicket(s *ShareInfo, lifetime int64) (string, error) {
+	shareReq, err := a.newShareRequest(s.Path, s.FileName, s.RefType, lifetime)
+	if err != nil {
+		return "", err
+	}
+	at, err := shareReq.getAuthTicket("", s.RefereeEncryptionPublicKey)
+	if err != nil {
+		return "", err
+	}
+	// the re-encryption key of the share is already on the blobbers
+	at.ReEncryptionKey = ""
+	if err := at.Sign(); err != nil {
+		return "", err
+	}
+	atBytes, err := json.Marshal(at)
+	if err != nil {
+		return "", err
+	}
+
+	keys := &publicLinkKeys{ticketKey: s.Link.TicketKey}
+	sealed, err := keys.sealTicket(base64.StdEncoding.EncodeToString(atBytes))
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// UnlockedPublicLink is a public link opened with its password.
+type UnlockedPublicLink struct {
+	*PublicLink
+	keys *publicLinkKeys
+}
+
+// Unlock derives the keys of the link from the password, empty for links
+// without one. For links with an auth ticket the password is checked.
+func (l *PublicLink) Unlock(password string) (*UnlockedPublicLink, error) {
+	if l.PasswordProtected() == (password == "") {
+		return nil, ErrPublicLinkPassword
+	}
+	keys, err := derivePublicLinkKeys(password, l.secret)
+	if err != nil {
+		return nil, err
+	}
+	u := &UnlockedPublicLink{PublicLink: l, keys: keys}
+	if !l.Redeemable() {
+		if _, err := u.AuthTicket(""); err != nil {
+			return nil, err
+		}
+	}
+	return u, nil
+}
+
+// AuthTicket returns the base64 auth ticket of the link, or of the redeemed
+// ticket of RedeemPublicLink for redeemable links.
+func (u *UnlockedPublicLink) AuthTicket(redeemed string) (string, error) {
+	sealed := u.ticket
+	if u.Redeemable() {
+		if redeemed == "" {
+			return "", errors.New("invalid_public_link", "public link must be redeemed with the issuer")
+		}
+		var err error
+		if sealed, err = base64.RawURLEncoding.DecodeString(redeemed); err != nil {
+			return "", errors.New("invalid_public_link", err.Error())
+		}
+	}
+	return u.keys.openTicket(sealed)
+}
+
+// DecryptionKey returns the key to download the encrypted content of the
+// link with, see WithDecryptionKey.
+func (u *UnlockedPublicLink) DecryptionKey() string {
+	return u.keys.decryptionKey
+}
@@ -0,0 +1,149 @@
+package sdk
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/0chain/gosdk/zboxcore/fileref"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPublicLink(t *testing.T, password string, authTicket string) *PublicLink {
+	l := &PublicLink{
+		ID:           strings.Repeat("ab", publicLinkIDSize),
+		AllocationID: strings.Repeat("cd", 32),
+		secret:       make([]byte, publicLinkSecretSize),
+	}
+	if password != "" {
+		l.flags |= publicLinkPassword
+	}
+	if authTicket != "" {
+		keys, err := derivePublicLinkKeys(password, l.secret)
+		require.NoError(t, err)
+		l.flags |= publicLinkTicket
+		l.ticket, err = keys.sealTicket(authTicket)
+		require.NoError(t, err)
+	}
+	return l
+}
+
+func TestPublicLink(t *testing.T) {
+	authTicket := base64.StdEncoding.EncodeToString([]byte(`{"allocation_id":"` + strings.Repeat("cd", 32) + `","file_path_hash":"` + strings.Repeat("ef", 32) + `"}`))
+
+	t.Run("encoding", func(t *testing.T) {
+		l := newTestPublicLink(t, "password", authTicket)
+		link, err := l.Encode()
+		require.NoError(t, err)
+		require.NotContains(t, link, "+")
+		require.NotContains(t, link, "/")
+		require.Less(t, len(link), len(authTicket)+2*len(l.ID)+2*len(l.AllocationID))
+
+		parsed, err := ParsePublicLink(link)
+		require.NoError(t, err)
+		require.Equal(t, l, parsed)
+		require.True(t, parsed.PasswordProtected())
+		require.False(t, parsed.Redeemable())
+
+		_, err = ParsePublicLink(link[:20])
+		require.Error(t, err)
+	})
+
+	t.Run("password", func(t *testing.T) {
+		l := newTestPublicLink(t, "password", authTicket)
+
+		_, err := l.Unlock("wrong")
+		require.Equal(t, ErrPublicLinkPassword, err)
+		_, err = l.Unlock("")
+		require.Equal(t, ErrPublicLinkPassword, err)
+
+		u, err := l.Unlock("password")
+		require.NoError(t, err)
+		at, err := u.AuthTicket("")
+		require.NoError(t, err)
+		require.Equal(t, authTicket, at)
+
+		require.NotEmpty(t, u.DecryptionKey())
+	})
+
+	t.Run("without password", func(t *testing.T) {
+		l := newTestPublicLink(t, "", authTicket)
+		_, err := l.Unlock("password")
+		require.Equal(t, ErrPublicLinkPassword, err)
+
+		u, err := l.Unlock("")
+		require.NoError(t, err)
+		at, err := u.AuthTicket("")
+		require.NoError(t, err)
+		require.Equal(t, authTicket, at)
+	})
+
+	t.Run("redeemed", func(t *testing.T) {
+		l := newTestPublicLink(t, "password", "")
+		require.True(t, l.Redeemable())
+
+		keys, err := derivePublicLinkKeys("password", l.secret)
+		require.NoError(t, err)
+		sealed, err := keys.sealTicket(authTicket)
+		require.NoError(t, err)
+
+		u, err := l.Unlock("password")
+		require.NoError(t, err)
+		_, err = u.AuthTicket("")
+		require.Error(t, err)
+		at, err := u.AuthTicket(base64.RawURLEncoding.EncodeToString(sealed))
+		require.NoError(t, err)
+		require.Equal(t, authTicket, at)
+	})
+}
+
+func TestRedeemPublicLink(t *testing.T) {
+	a := &Allocation{ID: "public link allocation"}
+	getShareRegistry(a.ID).put(&ShareInfo{
+		AllocationID: a.ID,
+		Path:         "/a.txt",
+		Link:         &PublicLinkInfo{ID: "exhausted", MaxDownloads: 2, Downloads: 2},
+	})
+	getShareRegistry(a.ID).put(&ShareInfo{
+		AllocationID: a.ID,
+		Path:         "/b.txt",
+		Revoked:      true,
+		Link:         &PublicLinkInfo{ID: "revoked", MaxDownloads: 2},
+	})
+
+	_, err := a.RedeemPublicLink("unknown")
+	require.Equal(t, ErrPublicLinkNotFound, err)
+
+	_, err = a.RedeemPublicLink("exhausted")
+	require.Equal(t, ErrPublicLinkExhausted, err)
+	require.Equal(t, 2, a.GetShare("/a.txt", "").Link.Downloads)
+
+	_, err = a.RedeemPublicLink("revoked")
+	require.Equal(t, ErrPublicLinkExpired, err)
+}
+
+func TestPublicLinkGroupShareConflict(t *testing.T) {
+	defer func(initialized bool) { sdkInitialized = initialized }(sdkInitialized)
+	sdkInitialized = true
+	a := &Allocation{ID: "public link group allocation", initialized: true}
+	getShareRegistry(a.ID).put(&ShareInfo{
+		AllocationID: a.ID,
+		Path:         "/group",
+		RefType:      fileref.DIRECTORY,
+		GroupID:      "group",
+	})
+	getShareRegistry(a.ID).put(&ShareInfo{
+		AllocationID: a.ID,
+		Path:         "/linked",
+		RefType:      fileref.DIRECTORY,
+		Link:         &PublicLinkInfo{ID: "link"},
+	})
+
+	_, err := a.CreatePublicLink("/group", "group", fileref.DIRECTORY, PublicLinkOptions{})
+	require.Equal(t, ErrPublicLinkGroupShare, err)
+
+	g := &ShareGroup{ID: "group"}
+	err = a.shareWithGroup(g, "/linked", "linked", fileref.DIRECTORY, 0)
+	require.Equal(t, ErrGroupSharePublicLink, err)
+	require.Equal(t, "link", a.GetShare("/linked", "").Link.ID)
+}
@@ -2,15 +2,13 @@ package sdk
 
 import (
 	"context"
-	"encoding/base64"
-	"encoding/json"
 	"fmt"
 	"io"
 	"math"
 	"sync"
 
 	"github.com/0chain/errors"
-	"github.com/0chain/gosdk/zboxcore/marker"
+	"github.com/0chain/gosdk/zboxcore/encryption"
 	"github.com/0chain/gosdk/zboxcore/zboxutil"
 )
 
@@ -175,6 +173,7 @@ func GetDStorageFileReader(alloc *Allocation, ref *ORef, sdo *StreamDownloadOpti
 			datashards:        alloc.DataShards,
 			parityshards:      alloc.ParityShards,
 			remotefilepath:    ref.Path,
+			contentMode:       sdo.ContentMode,
 			numBlocks:         int64(sdo.BlocksPerMarker),
 			validationRootMap: make(map[string]*blobberFile),
 			shouldVerify:      sdo.VerifyDownload,
@@ -200,16 +199,10 @@ func GetDStorageFileReader(alloc *Allocation, ref *ORef, sdo *StreamDownloadOpti
 	}
 
 	if sdo.AuthTicket != "" {
-		sEnc, err := base64.StdEncoding.DecodeString(sdo.AuthTicket)
+		at, err := alloc.parseAuthTicket(sdo.AuthTicket)
 		if err != nil {
-			return nil, errors.New("auth_ticket_decode_error", "Error decoding the auth ticket."+err.Error())
-		}
-		at := &marker.AuthTicket{}
-		err = json.Unmarshal(sEnc, at)
-		if err != nil {
-			return nil, errors.New("auth_ticket_decode_error", "Error unmarshaling the auth ticket."+err.Error())
+			return nil, err
 		}
-
 		sd.authTicket = at
 	}
 
@@ -227,6 +220,14 @@ func GetDStorageFileReader(alloc *Allocation, ref *ORef, sdo *StreamDownloadOpti
 		if err != nil {
 			return nil, err
 		}
+	} else if isGCMKeyPoint(ref.EncryptedKeyPoint) {
+		// any block can be decrypted on its own, so seeking needs no extra reads
+		sd.effectiveBlockSize = BlockSize - encryption.GCMOverhead
+		sd.gcmFileKey = ref.EncryptedKeyPoint
+		err = sd.initChunkCipher()
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return sd, err
@@ -0,0 +1,35 @@
+package sdk
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/0chain/errors"
+	"github.com/0chain/gosdk/core/common"
+	"github.com/0chain/gosdk/zboxcore/marker"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetDStorageFileReaderAuthTicket(t *testing.T) {
+	alloc := &Allocation{ID: "alloc"}
+	ref := &ORef{}
+
+	newTicket := func(allocationID string) string {
+		buf, err := json.Marshal(&marker.AuthTicket{
+			OwnerID:      "owner",
+			AllocationID: allocationID,
+			FilePathHash: "hash",
+			RefType:      "f",
+			Timestamp:    int64(common.Now()),
+		})
+		require.NoError(t, err)
+		return base64.StdEncoding.EncodeToString(buf)
+	}
+
+	_, err := GetDStorageFileReader(alloc, ref, &StreamDownloadOption{AuthTicket: "not a ticket"})
+	require.True(t, errors.Is(err, marker.ErrAuthTicketDecode), err)
+
+	_, err = GetDStorageFileReader(alloc, ref, &StreamDownloadOption{AuthTicket: newTicket("other")})
+	require.True(t, errors.Is(err, marker.ErrAuthTicketAllocation), err)
+}
@@ -0,0 +1,299 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"sort"
+	"time"
+
+	"github.com/0chain/errors"
+
+	"github.com/0chain/gosdk/constants"
+	"github.com/0chain/gosdk/core/common"
+	"github.com/0chain/gosdk/zboxcore/client"
+	"github.com/0chain/gosdk/zboxcore/zboxutil"
+)
+
+// RetentionMode is the mode of a retention lock.
+type RetentionMode string
+
+const (
+	// RetentionGovernance locks can be shortened or removed by the owner.
+	RetentionGovernance RetentionMode = "governance"
+	// RetentionCompliance locks can only be extended until released, by
+	// clients using this SDK, see RetentionLock.
+	RetentionCompliance RetentionMode = "compliance"
+)
+
+// retentionMask are the operations changing existing paths
+const retentionMask = CanDeleteMask | CanUpdateMask | CanMoveMask | CanRenameMask
+
+var ErrRetentionLocked = errors.New("retention_locked", "path is locked by a retention lock")
+
+// RetentionLock keeps the file or directory at Path, and everything below
+// it, from being deleted, updated, moved or renamed until RetainUntil.
+// Locks are kept in the policy document and checked by the SDK before
+// operations; the blobbers don't verify them, so they are advisory for
+// clients writing to the blobbers without this SDK.
+type RetentionLock struct {
+	Path string `json:"path"`
+	// LookupHash and Type of the ref of the path when locked
+	LookupHash  string           `json:"lookup_hash"`
+	Type        string           `json:"type"`
+	Mode        RetentionMode    `json:"mode"`
+	RetainUntil common.Timestamp `json:"retain_until"`
+	CreatedAt   common.Timestamp `json:"created_at"`
+}
+
+// Active reports whether the lock is not released at now.
+func (l *RetentionLock) Active(now common.Timestamp) bool {
+	return now < l.RetainUntil
+}
+
+func (l *RetentionLock) validate() error {
+	if l == nil || !zboxutil.IsRemoteAbs(l.Path) {
+		return errors.New(ErrInvalidPolicy.Code, "retention lock path should be valid and absolute")
+	}
+	if l.Mode != RetentionGovernance && l.Mode != RetentionCompliance {
+		return errors.New(ErrInvalidPolicy.Code, "retention mode must be governance or compliance")
+	}
+	return nil
+}
+
+// lockOf returns the active lock forbidding the check at now, nil if none.
+func (p *AllocationPolicy) lockOf(c policyCheck, now common.Timestamp) *RetentionLock {
+	if c.mask&retentionMask == 0 {
+		return nil
+	}
+	for _, lock := range p.Locks {
+		if !lock.Active(now) {
+			continue
+		}
+		if c.path == lock.Path || isPathBelow(c.path, lock.Path) {
+			return lock
+		}
+		if c.subtree && isPathBelow(lock.Path, c.path) {
+			return lock
+		}
+		// the locks are kept in the policy document, its updates are
+		// checked by checkPolicyWrite
+		if c.path == PolicyPath && c.mask != CanUpdateMask {
+			return lock
+		}
+	}
+	return nil
+}
+
+// writesPolicy reports whether the operation writes the policy document.
+func writesPolicy(op *OperationRequest) bool {
+	switch op.OperationType {
+	case constants.FileOperationInsert, constants.FileOperationUpdate:
+		remotePath := op.RemotePath
+		if op.FileMeta.RemotePath != "" {
+			remotePath = op.FileMeta.RemotePath
+		}
+		return zboxutil.RemoteClean(remotePath) == PolicyPath
+	}
+	return false
+}
+
+// checkPolicyWrite returns ErrRetentionLocked if the policy document written
+// by the operation removes, shortens or weakens an active compliance lock.
+func (p *AllocationPolicy) checkPolicyWrite(op *OperationRequest, now common.Timestamp) error {
+	var compliance []*RetentionLock
+	for _, lock := range p.Locks {
+		if lock.Active(now) && lock.Mode == RetentionCompliance {
+			compliance = append(compliance, lock)
+		}
+	}
+	if len(compliance) == 0 {
+		return nil
+	}
+
+	buf, err := readOperationFile(op)
+	if err != nil {
+		return errors.New(ErrRetentionLocked.Code, "policy document can't be checked for the compliance retention locks: "+err.Error())
+	}
+	next := &AllocationPolicy{}
+	if err := json.Unmarshal(buf, next); err != nil {
+		return errors.New(ErrRetentionLocked.Code, "policy document can't be checked for the compliance retention locks: "+err.Error())
+	}
+	for _, lock := range compliance {
+		var replaced *RetentionLock
+		for _, l := range next.Locks {
+			if l != nil && l.Path == lock.Path {
+				replaced = l
+				break
+			}
+		}
+		if err := checkLockReplace(lock, replaced, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readOperationFile reads the file of the upload and rewinds it for the upload.
+func readOperationFile(op *OperationRequest) ([]byte, error) {
+	if op.FileReader == nil {
+		return nil, errors.New("invalid_operation", "operation has no file")
+	}
+	buf, err := ioutil.ReadAll(op.FileReader)
+	if err != nil {
+		return nil, err
+	}
+	if seeker, ok := op.FileReader.(io.Seeker); ok {
+		_, err = seeker.Seek(0, io.SeekStart)
+		return buf, err
+	}
+	op.FileReader = bytes.NewReader(buf)
+	return buf, nil
+}
+
+// retentionLockOf returns the active lock of the path, nil if none. The
+// policy is loaded on first use.
+func (a *Allocation) retentionLockOf(remotePath string) (*RetentionLock, error) {
+	policy, err := a.GetPolicy()
+	if err != nil {
+		return nil, err
+	}
+	lock := policy.lockOf(policyCheck{path: remotePath, mask: retentionMask}, common.Now())
+	if lock == nil {
+		return nil, nil
+	}
+	l := *lock
+	return &l, nil
+}
+
+// changesExisting reports whether the operation changes existing paths.
+func changesExisting(op *OperationRequest) bool {
+	for _, c := range policyChecks(op) {
+		if c.mask&retentionMask != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// SetRetentionLock locks the file or directory at the path until
+// retainUntil. The active lock of the path is replaced, compliance locks
+// can only be extended. Only the owner can lock paths.
+func (a *Allocation) SetRetentionLock(filePath string, mode RetentionMode, retainUntil time.Time) (*RetentionLock, error) {
+	if !a.isInitialized() {
+		return nil, notInitialized
+	}
+	if client.GetClientID() != a.Owner {
+		return nil, errors.New("policy_not_permitted", "only the owner can lock paths")
+	}
+	filePath = zboxutil.RemoteClean(filePath)
+	now := common.Now()
+	lock := &RetentionLock{
+		Path:        filePath,
+		Mode:        mode,
+		RetainUntil: common.Timestamp(retainUntil.Unix()),
+		CreatedAt:   now,
+	}
+	if err := lock.validate(); err != nil {
+		return nil, err
+	}
+	if !lock.Active(now) {
+		return nil, errors.New("invalid_retention", "retention date should be in the future")
+	}
+
+	fileMeta, err := a.GetFileMeta(filePath)
+	if err != nil {
+		return nil, err
+	}
+	lock.LookupHash = fileMeta.LookupHash
+	lock.Type = fileMeta.Type
+
+	current, err := a.LoadPolicy()
+	if err != nil {
+		return nil, err
+	}
+	next := *current
+	next.Locks = nil
+	for _, l := range current.Locks {
+		if l.Path != filePath {
+			next.Locks = append(next.Locks, l)
+			continue
+		}
+		if err := checkLockReplace(l, lock, now); err != nil {
+			return nil, err
+		}
+	}
+	next.Locks = append(next.Locks, lock)
+	if err := a.savePolicy(current, &next); err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
+// checkLockReplace returns ErrRetentionLocked if the active compliance lock
+// would be shortened or weakened by next.
+func checkLockReplace(lock, next *RetentionLock, now common.Timestamp) error {
+	if !lock.Active(now) || lock.Mode != RetentionCompliance {
+		return nil
+	}
+	if next == nil || next.Mode != RetentionCompliance || next.RetainUntil < lock.RetainUntil {
+		return errors.New(ErrRetentionLocked.Code, "compliance retention lock of "+lock.Path+" can only be extended")
+	}
+	return nil
+}
+
+// RemoveRetentionLock removes the lock of the path. Active compliance locks
+// can't be removed.
+func (a *Allocation) RemoveRetentionLock(filePath string) error {
+	if !a.isInitialized() {
+		return notInitialized
+	}
+	if client.GetClientID() != a.Owner {
+		return errors.New("policy_not_permitted", "only the owner can unlock paths")
+	}
+	filePath = zboxutil.RemoteClean(filePath)
+	current, err := a.LoadPolicy()
+	if err != nil {
+		return err
+	}
+
+	next := *current
+	next.Locks = nil
+	found := false
+	for _, l := range current.Locks {
+		if l.Path != filePath {
+			next.Locks = append(next.Locks, l)
+			continue
+		}
+		if err := checkLockReplace(l, nil, common.Now()); err != nil {
+			return err
+		}
+		found = true
+	}
+	if !found {
+		return errors.New("retention_lock_not_found", "no retention lock of "+filePath)
+	}
+	return a.savePolicy(current, &next)
+}
+
+// GetRetentionLocks returns the retention locks of the allocation by
+// release date, the active ones unless includeReleased.
+func (a *Allocation) GetRetentionLocks(includeReleased bool) ([]*RetentionLock, error) {
+	policy, err := a.LoadPolicy()
+	if err != nil {
+		return nil, err
+	}
+	now := common.Now()
+	var locks []*RetentionLock
+	for _, l := range policy.Locks {
+		if includeReleased || l.Active(now) {
+			lock := *l
+			locks = append(locks, &lock)
+		}
+	}
+	sort.SliceStable(locks, func(i, j int) bool {
+		return locks[i].RetainUntil < locks[j].RetainUntil
+	})
+	return locks, nil
+}
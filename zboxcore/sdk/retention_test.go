@@ -0,0 +1,124 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/0chain/errors"
+	"github.com/0chain/gosdk/constants"
+	"github.com/0chain/gosdk/core/common"
+	"github.com/0chain/gosdk/zboxcore/client"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetentionLocks(t *testing.T) {
+	now := common.Now()
+	policy := &AllocationPolicy{Locks: []*RetentionLock{
+		{Path: "/records", Mode: RetentionCompliance, RetainUntil: now + 3600},
+		{Path: "/drafts/a.txt", Mode: RetentionGovernance, RetainUntil: now + 3600},
+		{Path: "/released", Mode: RetentionCompliance, RetainUntil: now - 1},
+	}}
+	require.NoError(t, policy.Validate())
+
+	policyUpdate := func(locks ...*RetentionLock) OperationRequest {
+		buf, err := json.Marshal(&AllocationPolicy{Locks: locks})
+		require.NoError(t, err)
+		return OperationRequest{
+			OperationType: constants.FileOperationUpdate,
+			FileMeta:      FileMeta{RemotePath: PolicyPath},
+			FileReader:    bytes.NewReader(buf),
+		}
+	}
+	extended := *policy.Locks[0]
+	extended.RetainUntil += 3600
+	shortened := *policy.Locks[0]
+	shortened.RetainUntil -= 60
+
+	tests := []struct {
+		name    string
+		op      OperationRequest
+		allowed bool
+	}{
+		{"delete locked dir", OperationRequest{OperationType: constants.FileOperationDelete, RemotePath: "/records"}, false},
+		{"delete in locked dir", OperationRequest{OperationType: constants.FileOperationDelete, RemotePath: "/records/2023/a.txt"}, false},
+		{"delete parent", OperationRequest{OperationType: constants.FileOperationDelete, RemotePath: "/"}, false},
+		{"update locked file", OperationRequest{OperationType: constants.FileOperationUpdate, FileMeta: FileMeta{RemotePath: "/drafts/a.txt"}}, false},
+		{"rename locked file", OperationRequest{OperationType: constants.FileOperationRename, RemotePath: "/drafts/a.txt", DestName: "b.txt"}, false},
+		{"move parent", OperationRequest{OperationType: constants.FileOperationMove, RemotePath: "/drafts", DestPath: "/old"}, false},
+		{"delete policy", OperationRequest{OperationType: constants.FileOperationDelete, RemotePath: PolicyPath}, false},
+		{"update policy", OperationRequest{OperationType: constants.FileOperationUpdate, FileMeta: FileMeta{RemotePath: PolicyPath}}, false},
+		{"remove compliance lock", policyUpdate(policy.Locks[1]), false},
+		{"shorten compliance lock", policyUpdate(&shortened), false},
+		{"extend compliance lock", policyUpdate(&extended), true},
+		{"remove governance lock", policyUpdate(policy.Locks[0]), true},
+		{"upload to locked dir", OperationRequest{OperationType: constants.FileOperationInsert, FileMeta: FileMeta{RemotePath: "/records/b.txt"}}, true},
+		{"copy locked file", OperationRequest{OperationType: constants.FileOperationCopy, RemotePath: "/drafts/a.txt", DestPath: "/"}, true},
+		{"delete unlocked sibling", OperationRequest{OperationType: constants.FileOperationDelete, RemotePath: "/drafts/b.txt"}, true},
+		{"delete similar name", OperationRequest{OperationType: constants.FileOperationDelete, RemotePath: "/records2"}, true},
+		{"delete released", OperationRequest{OperationType: constants.FileOperationDelete, RemotePath: "/released"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.check(&tt.op)
+			if tt.allowed {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.True(t, errors.Is(err, ErrRetentionLocked))
+		})
+	}
+
+	t.Run("policy document rewound", func(t *testing.T) {
+		op := policyUpdate(&extended)
+		require.NoError(t, policy.check(&op))
+		buf, err := ioutil.ReadAll(op.FileReader)
+		require.NoError(t, err)
+		next := &AllocationPolicy{}
+		require.NoError(t, json.Unmarshal(buf, next))
+		require.Equal(t, extended.RetainUntil, next.Locks[0].RetainUntil)
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		compliance := policy.Locks[0]
+		require.NoError(t, checkLockReplace(compliance, &RetentionLock{Mode: RetentionCompliance, RetainUntil: now + 7200}, now))
+		require.Error(t, checkLockReplace(compliance, &RetentionLock{Mode: RetentionCompliance, RetainUntil: now + 60}, now))
+		require.Error(t, checkLockReplace(compliance, &RetentionLock{Mode: RetentionGovernance, RetainUntil: now + 7200}, now))
+		require.Error(t, checkLockReplace(compliance, nil, now))
+
+		governance := policy.Locks[1]
+		require.NoError(t, checkLockReplace(governance, &RetentionLock{Mode: RetentionGovernance, RetainUntil: now + 60}, now))
+		require.NoError(t, checkLockReplace(governance, nil, now))
+
+		released := policy.Locks[2]
+		require.NoError(t, checkLockReplace(released, nil, now))
+	})
+
+	t.Run("file meta", func(t *testing.T) {
+		a := &Allocation{ID: "retention allocation"}
+		// the policy is loaded if it isn't cached
+		loads := stubPolicy(t, policy)
+		lock, err := a.retentionLockOf("/records/a.txt")
+		require.NoError(t, err)
+		require.NotNil(t, lock)
+		require.Equal(t, "/records", lock.Path)
+		lock, err = a.retentionLockOf("/drafts/b.txt")
+		require.NoError(t, err)
+		require.Nil(t, lock)
+		require.Equal(t, 1, *loads)
+	})
+
+	t.Run("owner", func(t *testing.T) {
+		a := &Allocation{ID: "owner retention allocation", Owner: client.GetClientID()}
+		stubPolicy(t, policy)
+		err := a.checkPolicyOperations([]OperationRequest{{OperationType: constants.FileOperationDelete, RemotePath: "/records/a.txt"}})
+		require.True(t, errors.Is(err, ErrRetentionLocked))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		p := &AllocationPolicy{Locks: []*RetentionLock{{Path: "/a", Mode: "legal"}}}
+		require.Error(t, p.Validate())
+	})
+}
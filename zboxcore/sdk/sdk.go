@@ -2,7 +2,6 @@ package sdk
 
 import (
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -26,7 +25,6 @@ import (
 	"github.com/0chain/gosdk/core/version"
 	"github.com/0chain/gosdk/zboxcore/blockchain"
 	"github.com/0chain/gosdk/zboxcore/client"
-	"github.com/0chain/gosdk/zboxcore/encryption"
 	l "github.com/0chain/gosdk/zboxcore/logger"
 	"github.com/0chain/gosdk/zboxcore/marker"
 	"github.com/0chain/gosdk/zboxcore/zboxutil"
@@ -869,8 +867,7 @@ func GetClientEncryptedPublicKey() (string, error) {
 	if !sdkInitialized {
 		return "", sdkNotInitialized
 	}
-	encScheme := encryption.NewEncryptionScheme()
-	_, err := encScheme.Initialize(client.GetClient().Mnemonic)
+	encScheme, err := clientEncryptionScheme()
 	if err != nil {
 		return "", err
 	}
@@ -881,16 +878,20 @@ func GetAllocationFromAuthTicket(authTicket string) (*Allocation, error) {
 	if !sdkInitialized {
 		return nil, sdkNotInitialized
 	}
-	sEnc, err := base64.StdEncoding.DecodeString(authTicket)
+	at, err := marker.ParseAuthTicket(authTicket)
 	if err != nil {
-		return nil, errors.New("auth_ticket_decode_error", "Error decoding the auth ticket."+err.Error())
+		return nil, err
 	}
-	at := &marker.AuthTicket{}
-	err = json.Unmarshal(sEnc, at)
+	a, err := GetAllocation(at.AllocationID)
 	if err != nil {
-		return nil, errors.New("auth_ticket_decode_error", "Error unmarshaling the auth ticket."+err.Error())
+		return nil, err
+	}
+	if a.OwnerPublicKey != "" {
+		if err := at.VerifySignature(a.OwnerPublicKey); err != nil {
+			return nil, err
+		}
 	}
-	return GetAllocation(at.AllocationID)
+	return a, nil
 }
 
 func GetAllocation(allocationID string) (*Allocation, error) {
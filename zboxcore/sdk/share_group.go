@@ -0,0 +1,626 @@
+package sdk
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/0chain/errors"
+
+	"github.com/0chain/gosdk/core/common"
+	"github.com/0chain/gosdk/core/sys"
+	"github.com/0chain/gosdk/zboxcore/encryption"
+	"github.com/0chain/gosdk/zboxcore/fileref"
+	"github.com/0chain/gosdk/zboxcore/zboxutil"
+)
+
+var (
+	ErrShareGroupNotFound = errors.New("share_group_not_found", "share group not found")
+	ErrNotGroupMember     = errors.New("not_group_member", "client is not a member of the share group")
+	// ErrGCMGroupShare the data keys of EncryptionModeGCM files are not
+	// rotated with the group key, so removed members could still decrypt them
+	ErrGCMGroupShare = errors.New("gcm_group_share", "files encrypted with EncryptionModeGCM can't be shared with a group")
+	// ErrGroupSharePublicLink is returned for group shares of paths with a public link
+	ErrGroupSharePublicLink = errors.New("group_share_public_link", "path has a public link")
+)
+
+// GroupKey is the encryption key of a share group. Members download the files
+// shared with the group with it, see WithGroupKey.
+type GroupKey struct {
+	GroupID    string `json:"group_id"`
+	Version    int    `json:"version"`
+	PrivateKey string `json:"private_key,omitempty"` // base64 private key of the proxy re-encryption scheme
+	// SealedPrivateKey is the private key sealed by the store key of the
+	// owner's key ring, as the groups are stored
+	SealedPrivateKey []byte `json:"sealed_private_key,omitempty"`
+}
+
+func newGroupKey(groupID string, version int) (*GroupKey, error) {
+	seed := make([]byte, 32)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, err
+	}
+	encScheme := encryption.NewEncryptionScheme()
+	if _, err := encScheme.Initialize(hex.EncodeToString(seed)); err != nil {
+		return nil, err
+	}
+	privateKey, err := encScheme.GetPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	return &GroupKey{GroupID: groupID, Version: version, PrivateKey: privateKey}, nil
+}
+
+func (k *GroupKey) encryptionScheme() (encryption.EncryptionScheme, error) {
+	key, err := base64.StdEncoding.DecodeString(k.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	encScheme := encryption.NewEncryptionScheme()
+	if err := encScheme.InitializeWithPrivateKey(key); err != nil {
+		return nil, err
+	}
+	return encScheme, nil
+}
+
+// PublicKey returns the encryption public key files are shared to.
+func (k *GroupKey) PublicKey() (string, error) {
+	encScheme, err := k.encryptionScheme()
+	if err != nil {
+		return "", err
+	}
+	return encScheme.GetPublicKey()
+}
+
+// groupKeyEnvelope is a group key encrypted to a member
+type groupKeyEnvelope struct {
+	GroupID string `json:"group_id"`
+	Version int    `json:"version"`
+	Key     []byte `json:"key"` // re-encrypted private key
+}
+
+// sealGroupKey encrypts the key with the owner's scheme and re-encrypts it to
+// the member's encryption public key.
+func sealGroupKey(owner encryption.EncryptionScheme, key *GroupKey, memberPublicKey string) (string, error) {
+	buf, err := sealToPublicKey(owner, []byte(key.PrivateKey), memberPublicKey)
+	if err != nil {
+		return "", err
+	}
+	envelope, err := json.Marshal(&groupKeyEnvelope{GroupID: key.GroupID, Version: key.Version, Key: buf})
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(envelope), nil
+}
+
+// openGroupKey decrypts the envelope of a group key with the member's scheme.
+func openGroupKey(member encryption.EncryptionScheme, envelope string) (*GroupKey, error) {
+	buf, err := base64.StdEncoding.DecodeString(envelope)
+	if err != nil {
+		return nil, errors.New("invalid_group_key", err.Error())
+	}
+	env := &groupKeyEnvelope{}
+	if err := json.Unmarshal(buf, env); err != nil {
+		return nil, errors.New("invalid_group_key", err.Error())
+	}
+
+	reEncMsg, err := unmarshalReEncryptedMessage(env.Key)
+	if err != nil {
+		return nil, errors.New("invalid_group_key", err.Error())
+	}
+	privateKey, err := member.ReDecrypt(reEncMsg)
+	if err != nil {
+		return nil, errors.New("invalid_group_key", err.Error())
+	}
+	return &GroupKey{GroupID: env.GroupID, Version: env.Version, PrivateKey: string(privateKey)}, nil
+}
+
+// OpenGroupKey decrypts the group key envelope of a member with the client's keys.
+func OpenGroupKey(envelope string) (*GroupKey, error) {
+	encScheme, err := clientEncryptionScheme()
+	if err != nil {
+		return nil, err
+	}
+	return openGroupKey(encScheme, envelope)
+}
+
+// GroupMember is a member of a share group.
+type GroupMember struct {
+	ClientID            string `json:"client_id"`
+	EncryptionPublicKey string `json:"encryption_public_key"`
+	// Envelope is the group key encrypted to the member, to be sent to it and opened with OpenGroupKey
+	Envelope string `json:"envelope"`
+}
+
+// ShareGroup shares files and directories of an allocation with its members
+// at once. Shares are re-encrypted to the group key, which the owner gives
+// to every member encrypted to the member's key, and the key is rotated when
+// members are removed.
+//
+// Group shares are bearer tickets without a referee carrying the
+// re-encryption key to the group key: anyone holding a ticket can download
+// the shared files from the blobbers, but only holders of the group key can
+// decrypt them, so a path can't have both a group and a public share.
+// Rotating the key stops removed members from decrypting files shared after
+// the rotation, not from downloading the ciphertext with an old ticket.
+//
+// Files encrypted with EncryptionModeGCM can't be shared with groups, see
+// ErrGCMGroupShare.
+type ShareGroup struct {
+	ID           string                  `json:"id"`
+	Name         string                  `json:"name"`
+	AllocationID string                  `json:"allocation_id"`
+	Key          *GroupKey               `json:"key"`
+	Members      map[string]*GroupMember `json:"members"`
+	Paths        []string                `json:"paths"` // shared paths
+}
+
+// ShareGroupStorer loads and saves the share groups of allocations.
+type ShareGroupStorer interface {
+	// Load loads the share groups of the allocation
+	Load(allocationID string) ([]*ShareGroup, error)
+	// Save saves the share groups of the allocation
+	Save(allocationID string, groups []*ShareGroup) error
+}
+
+// fsShareGroupStorer saves the groups of an allocation as json in the groups directory of the work dir
+type fsShareGroupStorer struct {
+	dir string
+}
+
+func (fs *fsShareGroupStorer) path(allocationID string) string {
+	dir := fs.dir
+	if dir == "" {
+		dir = filepath.Join(defaultWorkdir(), "groups")
+	}
+	return filepath.Join(dir, allocationID+".json")
+}
+
+func (fs *fsShareGroupStorer) Load(allocationID string) ([]*ShareGroup, error) {
+	buf, err := sys.Files.ReadFile(fs.path(allocationID))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var groups []*ShareGroup
+	if err := json.Unmarshal(buf, &groups); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+func (fs *fsShareGroupStorer) Save(allocationID string, groups []*ShareGroup) error {
+	buf, err := json.Marshal(groups)
+	if err != nil {
+		return err
+	}
+	p := fs.path(allocationID)
+	if err := sys.Files.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return err
+	}
+	return sys.Files.WriteFile(p, buf, 0600)
+}
+
+var (
+	shareGroupStorer ShareGroupStorer = &fsShareGroupStorer{}
+	shareGroupMux    sync.Mutex
+)
+
+// SetShareGroupStorer sets the storer of the share groups, the groups
+// directory of the work dir by default. The storer gets the group keys
+// sealed by the store key of the owner's key ring.
+func SetShareGroupStorer(s ShareGroupStorer) {
+	shareGroupMux.Lock()
+	defer shareGroupMux.Unlock()
+	shareGroupStorer = s
+}
+
+// updateShareGroups applies f to the groups of the allocation and saves them
+func (a *Allocation) updateShareGroups(f func(groups []*ShareGroup) ([]*ShareGroup, error)) error {
+	shareGroupMux.Lock()
+	defer shareGroupMux.Unlock()
+
+	storeKey, err := shareGroupStoreKey()
+	if err != nil {
+		return err
+	}
+	groups, err := loadShareGroups(a.ID, storeKey)
+	if err != nil {
+		return err
+	}
+	if groups, err = f(groups); err != nil {
+		return err
+	}
+	sealed, err := sealShareGroups(groups, storeKey)
+	if err != nil {
+		return err
+	}
+	return shareGroupStorer.Save(a.ID, sealed)
+}
+
+// shareGroupStoreKey returns the store key of the client's key ring, which
+// seals the stored group keys.
+func shareGroupStoreKey() ([]byte, error) {
+	r, err := GetEncryptionKeyRing()
+	if err != nil {
+		return nil, errors.New("share_group_store", err.Error())
+	}
+	return r.storeKey()
+}
+
+// loadShareGroups loads the groups of the allocation and opens their keys.
+func loadShareGroups(allocationID string, storeKey []byte) ([]*ShareGroup, error) {
+	groups, err := shareGroupStorer.Load(allocationID)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range groups {
+		if g.Key == nil || len(g.Key.SealedPrivateKey) == 0 {
+			// groups saved before the keys were sealed
+			continue
+		}
+		privateKey, err := encryption.OpenWithKey(storeKey, g.Key.SealedPrivateKey)
+		if err != nil {
+			return nil, errors.New("invalid_group_key", err.Error())
+		}
+		g.Key.PrivateKey = string(privateKey)
+		g.Key.SealedPrivateKey = nil
+	}
+	return groups, nil
+}
+
+// sealShareGroups returns copies of the groups with the keys sealed.
+func sealShareGroups(groups []*ShareGroup, storeKey []byte) ([]*ShareGroup, error) {
+	sealed := make([]*ShareGroup, 0, len(groups))
+	for _, g := range groups {
+		c := *g
+		if g.Key != nil && g.Key.PrivateKey != "" {
+			key := *g.Key
+			var err error
+			if key.SealedPrivateKey, err = encryption.SealWithKey(storeKey, []byte(key.PrivateKey)); err != nil {
+				return nil, err
+			}
+			key.PrivateKey = ""
+			c.Key = &key
+		}
+		sealed = append(sealed, &c)
+	}
+	return sealed, nil
+}
+
+func findShareGroup(groups []*ShareGroup, groupID string) (*ShareGroup, error) {
+	for _, g := range groups {
+		if g.ID == groupID {
+			return g, nil
+		}
+	}
+	return nil, ErrShareGroupNotFound
+}
+
+// ListShareGroups returns the share groups of the allocation.
+func (a *Allocation) ListShareGroups() ([]*ShareGroup, error) {
+	shareGroupMux.Lock()
+	defer shareGroupMux.Unlock()
+
+	storeKey, err := shareGroupStoreKey()
+	if err != nil {
+		return nil, err
+	}
+	return loadShareGroups(a.ID, storeKey)
+}
+
+// GetShareGroup returns the share group.
+func (a *Allocation) GetShareGroup(groupID string) (*ShareGroup, error) {
+	groups, err := a.ListShareGroups()
+	if err != nil {
+		return nil, err
+	}
+	return findShareGroup(groups, groupID)
+}
+
+// CreateShareGroup creates a share group with the members, by their client
+// ids and encryption public keys.
+func (a *Allocation) CreateShareGroup(name string, members map[string]string) (*ShareGroup, error) {
+	owner, err := clientEncryptionScheme()
+	if err != nil {
+		return nil, err
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	group := &ShareGroup{
+		ID:           hex.EncodeToString(id),
+		Name:         name,
+		AllocationID: a.ID,
+		Members:      make(map[string]*GroupMember),
+	}
+	if group.Key, err = newGroupKey(group.ID, 1); err != nil {
+		return nil, err
+	}
+	for clientID, encPublicKey := range members {
+		if err := group.addMember(owner, clientID, encPublicKey); err != nil {
+			return nil, err
+		}
+	}
+
+	err = a.updateShareGroups(func(groups []*ShareGroup) ([]*ShareGroup, error) {
+		return append(groups, group), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+func (g *ShareGroup) addMember(owner encryption.EncryptionScheme, clientID, encPublicKey string) error {
+	envelope, err := sealGroupKey(owner, g.Key, encPublicKey)
+	if err != nil {
+		return err
+	}
+	g.Members[clientID] = &GroupMember{ClientID: clientID, EncryptionPublicKey: encPublicKey, Envelope: envelope}
+	return nil
+}
+
+// AddGroupMember gives the group key to a new member and returns the
+// member with its key envelope.
+func (a *Allocation) AddGroupMember(groupID, clientID, encPublicKey string) (*GroupMember, error) {
+	owner, err := clientEncryptionScheme()
+	if err != nil {
+		return nil, err
+	}
+
+	var member *GroupMember
+	err = a.updateShareGroups(func(groups []*ShareGroup) ([]*ShareGroup, error) {
+		g, err := findShareGroup(groups, groupID)
+		if err != nil {
+			return nil, err
+		}
+		if err := g.addMember(owner, clientID, encPublicKey); err != nil {
+			return nil, err
+		}
+		member = g.Members[clientID]
+		return groups, nil
+	})
+	return member, err
+}
+
+// RemoveGroupMember removes the member and rotates the group key so that
+// the removed member can't decrypt the shares anymore. The remaining members
+// need their new envelopes.
+func (a *Allocation) RemoveGroupMember(groupID, clientID string) (*ShareGroup, error) {
+	g, err := a.GetShareGroup(groupID)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := g.Members[clientID]; !ok {
+		return nil, ErrNotGroupMember
+	}
+	return a.rotateGroupKey(groupID, clientID)
+}
+
+// RotateGroupKey replaces the group key, seals it for the members and
+// re-shares the group paths to it. It fails with ErrGroupSharePublicLink if
+// a group path has an active public link.
+func (a *Allocation) RotateGroupKey(groupID string) (*ShareGroup, error) {
+	return a.rotateGroupKey(groupID, "")
+}
+
+func (a *Allocation) rotateGroupKey(groupID, removedClientID string) (*ShareGroup, error) {
+	owner, err := clientEncryptionScheme()
+	if err != nil {
+		return nil, err
+	}
+	g, err := a.GetShareGroup(groupID)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range g.Paths {
+		if err := a.checkNoPublicLink(p); err != nil {
+			return nil, err
+		}
+	}
+
+	next := *g
+	if next.Key, err = newGroupKey(g.ID, g.Key.Version+1); err != nil {
+		return nil, err
+	}
+	next.Members = make(map[string]*GroupMember, len(g.Members))
+	for _, m := range g.Members {
+		if m.ClientID == removedClientID {
+			continue
+		}
+		if err := next.addMember(owner, m.ClientID, m.EncryptionPublicKey); err != nil {
+			return nil, err
+		}
+	}
+
+	// overwrite the shares on the blobbers with re-encryption keys to the new
+	// key before saving it, and back to the saved key if any share fails
+	var reshared []ShareInfo
+	rollback := func(err error) (*ShareGroup, error) {
+		for _, s := range reshared {
+			if rerr := a.shareWithGroup(g, s.Path, s.FileName, s.RefType, s.Expiration); rerr != nil {
+				return nil, errors.Wrap(err, errors.New("group_key_rollback", s.Path+": "+rerr.Error()))
+			}
+		}
+		return nil, err
+	}
+	for _, p := range g.Paths {
+		s := a.GetShare(p, "")
+		if s == nil || !s.Active() {
+			continue
+		}
+		if err := a.shareWithGroup(&next, s.Path, s.FileName, s.RefType, s.Expiration); err != nil {
+			return rollback(err)
+		}
+		reshared = append(reshared, *s)
+	}
+
+	err = a.updateShareGroups(func(groups []*ShareGroup) ([]*ShareGroup, error) {
+		stored, err := findShareGroup(groups, groupID)
+		if err != nil {
+			return nil, err
+		}
+		stored.Key = next.Key
+		stored.Members = next.Members
+		next = *stored
+		return groups, nil
+	})
+	if err != nil {
+		return rollback(err)
+	}
+	return &next, nil
+}
+
+// ShareWithGroup shares the encrypted file or directory with the group and
+// returns the auth ticket for the members. expiration is in seconds from
+// now, 0 never. Paths with an active public link fail with
+// ErrGroupSharePublicLink.
+func (a *Allocation) ShareWithGroup(groupID, path, filename, referenceType string, expiration int64) (string, error) {
+	g, err := a.GetShareGroup(groupID)
+	if err != nil {
+		return "", err
+	}
+
+	path = zboxutil.RemoteClean(path)
+	if referenceType == fileref.FILE {
+		fileMeta, err := a.GetFileMeta(path)
+		if err != nil {
+			return "", err
+		}
+		if !fileMeta.isEncrypted() {
+			return "", ErrInvalidPrivateShare
+		}
+	}
+
+	if expiration > 0 {
+		expiration += int64(common.Now())
+	}
+	if err := a.shareWithGroup(g, path, filename, referenceType, expiration); err != nil {
+		return "", err
+	}
+
+	err = a.updateShareGroups(func(groups []*ShareGroup) ([]*ShareGroup, error) {
+		g, err := findShareGroup(groups, groupID)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range g.Paths {
+			if p == path {
+				return groups, nil
+			}
+		}
+		g.Paths = append(g.Paths, path)
+		return groups, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return a.GetShare(path, "").AuthTicket, nil
+}
+
+// shareWithGroup shares the path to the current group key until expiresAt, 0 never.
+func (a *Allocation) shareWithGroup(g *ShareGroup, path, filename, referenceType string, expiresAt int64) error {
+	if err := a.checkNoPublicLink(path); err != nil {
+		return err
+	}
+	if err := a.checkNoGCMFiles(path, referenceType); err != nil {
+		return err
+	}
+	encPublicKey, err := g.Key.PublicKey()
+	if err != nil {
+		return err
+	}
+
+	var expiration int64
+	if expiresAt > 0 {
+		if expiration = expiresAt - int64(common.Now()); expiration <= 0 {
+			return errors.New("share_expired", "group share is expired")
+		}
+	}
+	if _, err := a.GetAuthTicket(path, filename, referenceType, "", encPublicKey, expiration, nil); err != nil {
+		return err
+	}
+	getShareRegistry(a.ID).update(path, "", func(s *ShareInfo) {
+		s.GroupID = g.ID
+	})
+	return nil
+}
+
+// checkNoPublicLink fails with ErrGroupSharePublicLink if the path has an
+// active public link. Links and group shares both take the share of the
+// path without a referee.
+func (a *Allocation) checkNoPublicLink(path string) error {
+	if s := a.GetShare(path, ""); s != nil && s.Link != nil && s.Active() {
+		return ErrGroupSharePublicLink
+	}
+	return nil
+}
+
+// checkNoGCMFiles fails with ErrGCMGroupShare if the file, or a file of the
+// directory, is encrypted with EncryptionModeGCM.
+func (a *Allocation) checkNoGCMFiles(path, referenceType string) error {
+	if referenceType == fileref.FILE {
+		fileMeta, err := a.GetFileMeta(path)
+		if err != nil {
+			return err
+		}
+		if isGCMKeyPoint(fileMeta.EncryptedKeyPoint) {
+			return ErrGCMGroupShare
+		}
+		return nil
+	}
+
+	remotePath, err := a.remotePath(path)
+	if err != nil {
+		return err
+	}
+	points, err := a.gcmKeyPoints(remotePath)
+	if err != nil {
+		return err
+	}
+	if len(points) > 0 {
+		return ErrGCMGroupShare
+	}
+	return nil
+}
+
+// DeleteShareGroup revokes the shares of the group and deletes it.
+func (a *Allocation) DeleteShareGroup(groupID string) error {
+	g, err := a.GetShareGroup(groupID)
+	if err != nil {
+		return err
+	}
+	for _, p := range g.Paths {
+		if s := a.GetShare(p, ""); s != nil && s.GroupID == g.ID && s.Active() {
+			if err := a.RevokeShare(p, ""); err != nil {
+				return err
+			}
+		}
+	}
+	return a.updateShareGroups(func(groups []*ShareGroup) ([]*ShareGroup, error) {
+		for i, it := range groups {
+			if it.ID == groupID {
+				return append(groups[:i], groups[i+1:]...), nil
+			}
+		}
+		return groups, nil
+	})
+}
+
+// MemberEnvelope returns the group key envelope of the client.
+func (g *ShareGroup) MemberEnvelope(clientID string) (string, error) {
+	m, ok := g.Members[clientID]
+	if !ok {
+		return "", ErrNotGroupMember
+	}
+	return m.Envelope, nil
+}
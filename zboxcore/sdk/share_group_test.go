@@ -0,0 +1,135 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/0chain/gosdk/zboxcore/encryption"
+	"github.com/stretchr/testify/require"
+)
+
+// groupKeyTag is the tag of the re-encryption keys of the shares
+const groupKeyTag = "filetype:audio"
+
+func newTestEncryptionScheme(t *testing.T, mnemonic string) encryption.EncryptionScheme {
+	encScheme := encryption.NewEncryptionScheme()
+	_, err := encScheme.Initialize(mnemonic)
+	require.NoError(t, err)
+	return encScheme
+}
+
+func TestGroupKey(t *testing.T) {
+	owner := newTestEncryptionScheme(t, "owner")
+	member := newTestEncryptionScheme(t, "member")
+	memberPublicKey, err := member.GetPublicKey()
+	require.NoError(t, err)
+
+	key, err := newGroupKey("group", 1)
+	require.NoError(t, err)
+
+	t.Run("envelope", func(t *testing.T) {
+		envelope, err := sealGroupKey(newTestEncryptionScheme(t, "owner"), key, memberPublicKey)
+		require.NoError(t, err)
+
+		opened, err := openGroupKey(member, envelope)
+		require.NoError(t, err)
+		require.Equal(t, key, opened)
+
+		_, err = openGroupKey(newTestEncryptionScheme(t, "other"), envelope)
+		require.Error(t, err)
+	})
+
+	t.Run("decrypt group share", func(t *testing.T) {
+		groupPublicKey, err := key.PublicKey()
+		require.NoError(t, err)
+
+		// the owner encrypts a block and re-keys the share to the group
+		owner.InitForEncryption(groupKeyTag)
+		encMsg, err := owner.Encrypt([]byte("block"))
+		require.NoError(t, err)
+		reKey, err := owner.GetReGenKey(groupPublicKey, groupKeyTag)
+		require.NoError(t, err)
+
+		// the blobber re-encrypts it to the group key
+		reEncMsg, err := owner.ReEncrypt(encMsg, reKey, groupPublicKey)
+		require.NoError(t, err)
+
+		groupScheme, err := key.encryptionScheme()
+		require.NoError(t, err)
+		data, err := groupScheme.ReDecrypt(reEncMsg)
+		require.NoError(t, err)
+		require.Equal(t, []byte("block"), data)
+
+		rotated, err := newGroupKey("group", 2)
+		require.NoError(t, err)
+		rotatedScheme, err := rotated.encryptionScheme()
+		require.NoError(t, err)
+		_, err = rotatedScheme.ReDecrypt(reEncMsg)
+		require.Error(t, err)
+	})
+}
+
+func TestFsShareGroupStorer(t *testing.T) {
+	fs := &fsShareGroupStorer{dir: t.TempDir()}
+
+	groups, err := fs.Load("alloc")
+	require.NoError(t, err)
+	require.Empty(t, groups)
+
+	want := []*ShareGroup{{
+		ID:      "group",
+		Key:     &GroupKey{GroupID: "group", Version: 1, PrivateKey: "key"},
+		Members: map[string]*GroupMember{"bob": {ClientID: "bob", Envelope: "envelope"}},
+		Paths:   []string{"/team"},
+	}}
+	require.NoError(t, fs.Save("alloc", want))
+
+	groups, err = fs.Load("alloc")
+	require.NoError(t, err)
+	require.Equal(t, want, groups)
+}
+
+func TestSealShareGroups(t *testing.T) {
+	storer := &memoryShareGroupStorer{groups: make(map[string][]*ShareGroup)}
+	SetShareGroupStorer(storer)
+	defer SetShareGroupStorer(&fsShareGroupStorer{})
+
+	storeKey := make([]byte, 32)
+	groups := []*ShareGroup{{ID: "group", Key: &GroupKey{GroupID: "group", Version: 1, PrivateKey: "key"}}}
+	sealed, err := sealShareGroups(groups, storeKey)
+	require.NoError(t, err)
+	require.Empty(t, sealed[0].Key.PrivateKey)
+	require.NotContains(t, string(sealed[0].Key.SealedPrivateKey), "key")
+	require.Equal(t, "key", groups[0].Key.PrivateKey)
+	require.NoError(t, storer.Save("alloc", sealed))
+
+	loaded, err := loadShareGroups("alloc", storeKey)
+	require.NoError(t, err)
+	require.Equal(t, groups, loaded)
+
+	// sealed by another key ring
+	otherKey := make([]byte, 32)
+	otherKey[0] = 1
+	_, err = loadShareGroups("alloc", otherKey)
+	require.Error(t, err)
+}
+
+type memoryShareGroupStorer struct {
+	groups map[string][]*ShareGroup
+}
+
+func (m *memoryShareGroupStorer) Load(allocationID string) ([]*ShareGroup, error) {
+	// copy as a storer reading json would
+	var groups []*ShareGroup
+	for _, g := range m.groups[allocationID] {
+		c := *g
+		key := *g.Key
+		c.Key = &key
+		groups = append(groups, &c)
+	}
+	return groups, nil
+}
+
+func (m *memoryShareGroupStorer) Save(allocationID string, groups []*ShareGroup) error {
+	m.groups[allocationID] = groups
+	return nil
+}
@@ -0,0 +1,418 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0chain/errors"
+
+	"github.com/0chain/gosdk/core/common"
+	"github.com/0chain/gosdk/core/sys"
+	"github.com/0chain/gosdk/zboxcore/blockchain"
+	"github.com/0chain/gosdk/zboxcore/logger"
+	"github.com/0chain/gosdk/zboxcore/marker"
+	"github.com/mitchellh/go-homedir"
+)
+
+// ShareInfo is a share of a file or directory of an allocation.
+type ShareInfo struct {
+	AllocationID               string `json:"allocation_id"`
+	Path                       string `json:"path"`
+	FileName                   string `json:"file_name"`
+	RefType                    string `json:"reference_type"`
+	RefereeClientID            string `json:"referee_client_id,omitempty"` // empty for public shares
+	RefereeEncryptionPublicKey string `json:"referee_encryption_public_key,omitempty"`
+	AuthTicket                 string `json:"auth_ticket"`
+	CreatedAt                  int64  `json:"created_at"`
+	Expiration                 int64  `json:"expiration"`      // unix seconds, 0 never expires
+	AvailableAfter             int64  `json:"available_after"` // unix seconds, 0 available at once
+	Revoked                    bool   `json:"revoked"`
+	GroupID                    string `json:"group_id,omitempty"`     // set for shares with a share group
+	Collaborator               bool   `json:"collaborator,omitempty"` // set for the read grants of AddCollaborator
+	// Link is the public link of the share, see CreatePublicLink
+	Link *PublicLinkInfo `json:"link,omitempty"`
+
+	// PendingBlobbers are the blobbers missing the last change of the share
+	PendingBlobbers []string `json:"pending_blobbers,omitempty"`
+	// UploadedTicket is the ticket uploaded to the pending blobbers. It has
+	// no re-encryption key, a new one is generated by ReconcileShares.
+	UploadedTicket string `json:"uploaded_ticket,omitempty"`
+}
+
+// Active reports whether the share is neither revoked nor expired.
+func (s *ShareInfo) Active() bool {
+	return !s.Revoked && (s.Expiration == 0 || s.Expiration > int64(common.Now()))
+}
+
+func (s *ShareInfo) clone() *ShareInfo {
+	c := *s
+	if s.Link != nil {
+		link := *s.Link
+		c.Link = &link
+	}
+	return &c
+}
+
+func (s *ShareInfo) key() string {
+	return s.Path + "\x00" + s.RefereeClientID
+}
+
+// ShareFilter selects shares of the registry.
+type ShareFilter struct {
+	// PathPrefix selects the shares of the path and below
+	PathPrefix string
+	// RefereeClientID selects the shares of a referee
+	RefereeClientID string
+	// IncludeInactive includes revoked and expired shares
+	IncludeInactive bool
+}
+
+func (f *ShareFilter) match(s *ShareInfo) bool {
+	if f == nil {
+		return s.Active()
+	}
+	if !f.IncludeInactive && !s.Active() {
+		return false
+	}
+	if f.RefereeClientID != "" && f.RefereeClientID != s.RefereeClientID {
+		return false
+	}
+	if f.PathPrefix != "" && f.PathPrefix != "/" {
+		prefix := strings.TrimSuffix(f.PathPrefix, "/")
+		if s.Path != prefix && !strings.HasPrefix(s.Path, prefix+"/") {
+			return false
+		}
+	}
+	return true
+}
+
+// ShareStorer loads and saves the shares of allocations
+type ShareStorer interface {
+	// Load loads the shares of the allocation
+	Load(allocationID string) ([]*ShareInfo, error)
+	// Save saves the shares of the allocation
+	Save(allocationID string, shares []*ShareInfo) error
+}
+
+// fsShareStorer saves the shares of an allocation as json in the shares
+// directory of Workdir. The shares are kept in memory only if neither is set.
+type fsShareStorer struct {
+	dir string
+}
+
+func (fs *fsShareStorer) path(allocationID string) string {
+	dir := fs.dir
+	if dir == "" {
+		if Workdir == "" {
+			return ""
+		}
+		dir = filepath.Join(Workdir, "shares")
+	}
+	return filepath.Join(dir, allocationID+".json")
+}
+
+// defaultWorkdir returns Workdir, or the home dir if unset
+func defaultWorkdir() string {
+	if Workdir != "" {
+		return Workdir
+	}
+	dir, _ := homedir.Dir()
+	return dir
+}
+
+func (fs *fsShareStorer) Load(allocationID string) ([]*ShareInfo, error) {
+	p := fs.path(allocationID)
+	if p == "" {
+		return nil, nil
+	}
+	buf, err := sys.Files.ReadFile(p)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var shares []*ShareInfo
+	if err := json.Unmarshal(buf, &shares); err != nil {
+		return nil, err
+	}
+	return shares, nil
+}
+
+func (fs *fsShareStorer) Save(allocationID string, shares []*ShareInfo) error {
+	buf, err := json.Marshal(shares)
+	if err != nil {
+		return err
+	}
+	p := fs.path(allocationID)
+	if p == "" {
+		return nil
+	}
+	if err := sys.Files.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return err
+	}
+	return sys.Files.WriteFile(p, buf, 0600)
+}
+
+var (
+	shareStorer      ShareStorer = &fsShareStorer{}
+	shareRegistries              = make(map[string]*shareRegistry)
+	shareRegistryMux sync.Mutex
+)
+
+// SetShareStorer sets the storer of the share registries, the shares
+// directory of Workdir by default. Without Workdir nor storer the shares
+// are not persisted.
+func SetShareStorer(s ShareStorer) {
+	shareRegistryMux.Lock()
+	defer shareRegistryMux.Unlock()
+	shareStorer = s
+	shareRegistries = make(map[string]*shareRegistry)
+}
+
+// shareRegistry records the shares of an allocation
+type shareRegistry struct {
+	sync.Mutex
+	allocationID string
+	storer       ShareStorer
+	shares       map[string]*ShareInfo
+}
+
+func getShareRegistry(allocationID string) *shareRegistry {
+	shareRegistryMux.Lock()
+	defer shareRegistryMux.Unlock()
+
+	r, ok := shareRegistries[allocationID]
+	if !ok {
+		r = &shareRegistry{allocationID: allocationID, storer: shareStorer}
+		shareRegistries[allocationID] = r
+	}
+	return r
+}
+
+// load loads the shares once, the registry must be locked
+func (r *shareRegistry) load() {
+	if r.shares != nil {
+		return
+	}
+	r.shares = make(map[string]*ShareInfo)
+	shares, err := r.storer.Load(r.allocationID)
+	if err != nil {
+		logger.Logger.Error("[shares] load ", r.allocationID, err)
+	}
+	for _, s := range shares {
+		r.shares[s.key()] = s
+	}
+}
+
+// save saves the shares, the registry must be locked
+func (r *shareRegistry) save() {
+	shares := make([]*ShareInfo, 0, len(r.shares))
+	for _, s := range r.shares {
+		shares = append(shares, s)
+	}
+	if err := r.storer.Save(r.allocationID, shares); err != nil {
+		logger.Logger.Error("[shares] save ", r.allocationID, err)
+	}
+}
+
+func (r *shareRegistry) put(s *ShareInfo) {
+	r.Lock()
+	defer r.Unlock()
+	r.load()
+	r.shares[s.key()] = s
+	r.save()
+}
+
+func (r *shareRegistry) get(path, refereeClientID string) *ShareInfo {
+	r.Lock()
+	defer r.Unlock()
+	r.load()
+	s, ok := r.shares[path+"\x00"+refereeClientID]
+	if !ok {
+		return nil
+	}
+	return s.clone()
+}
+
+func (r *shareRegistry) list(filter *ShareFilter) []*ShareInfo {
+	r.Lock()
+	defer r.Unlock()
+	r.load()
+	var shares []*ShareInfo
+	for _, s := range r.shares {
+		if filter.match(s) {
+			shares = append(shares, s.clone())
+		}
+	}
+	return shares
+}
+
+// update applies f to the share of the path and referee if recorded
+func (r *shareRegistry) update(path, refereeClientID string, f func(*ShareInfo)) {
+	r.Lock()
+	defer r.Unlock()
+	r.load()
+	if s, ok := r.shares[path+"\x00"+refereeClientID]; ok {
+		f(s)
+		r.save()
+	}
+}
+
+// ListShares returns the shares of the allocation recorded by GetAuthTicket
+// matching the filter, the active shares if nil. Only the shares created with
+// this registry are listed, not the shares created by other SDK instances of
+// the owner, which the blobbers do not list.
+func (a *Allocation) ListShares(filter *ShareFilter) []*ShareInfo {
+	return getShareRegistry(a.ID).list(filter)
+}
+
+// GetShare returns the recorded share of the path to the referee, nil if none.
+func (a *Allocation) GetShare(path, refereeClientID string) *ShareInfo {
+	return getShareRegistry(a.ID).get(path, refereeClientID)
+}
+
+// UpdateShareExpiration issues a new auth ticket of an active share
+// expiring expirationSeconds from now, 0 never.
+func (a *Allocation) UpdateShareExpiration(path, refereeClientID string, expirationSeconds int64) (*ShareInfo, error) {
+	s := a.GetShare(path, refereeClientID)
+	if s == nil || !s.Active() {
+		return nil, errors.New("share_not_found", "share not found")
+	}
+
+	var availableAfter *time.Time
+	if s.AvailableAfter > 0 {
+		t := time.Unix(s.AvailableAfter, 0)
+		availableAfter = &t
+	}
+	if _, err := a.GetAuthTicket(s.Path, s.FileName, s.RefType, s.RefereeClientID,
+		s.RefereeEncryptionPublicKey, expirationSeconds, availableAfter); err != nil {
+		return nil, err
+	}
+	return a.GetShare(path, refereeClientID), nil
+}
+
+// RevokeShares revokes the active shares matching the filter and returns
+// them. It continues on errors and returns the last one.
+func (a *Allocation) RevokeShares(filter *ShareFilter) ([]*ShareInfo, error) {
+	if filter == nil || (filter.PathPrefix == "" && filter.RefereeClientID == "") {
+		return nil, errors.New("invalid_share_filter", "share filter requires a path prefix or referee")
+	}
+	active := *filter
+	active.IncludeInactive = false
+
+	var revoked []*ShareInfo
+	var lastErr error
+	for _, s := range a.ListShares(&active) {
+		if err := a.RevokeShare(s.Path, s.RefereeClientID); err != nil {
+			lastErr = err
+			continue
+		}
+		revoked = append(revoked, a.GetShare(s.Path, s.RefereeClientID))
+	}
+	return revoked, lastErr
+}
+
+// ReconcileShares retries the share changes on the blobbers which missed them.
+func (a *Allocation) ReconcileShares() error {
+	var lastErr error
+	for _, s := range a.ListShares(&ShareFilter{IncludeInactive: true}) {
+		if len(s.PendingBlobbers) == 0 {
+			continue
+		}
+		blobbers := a.blobbersByID(s.PendingBlobbers)
+
+		var failed []string
+		var err error
+		if s.Revoked {
+			failed, _, err = a.revokeShareOnBlobbers(blobbers, s.Path, s.RefereeClientID)
+		} else if s.UploadedTicket != "" {
+			var availableAfter *time.Time
+			if s.AvailableAfter > 0 {
+				t := time.Unix(s.AvailableAfter, 0)
+				availableAfter = &t
+			}
+			var ticket string
+			ticket, err = withReEncryptionKey(s.UploadedTicket, s.RefereeEncryptionPublicKey)
+			if err == nil {
+				failed, err = a.uploadAuthTicketToBlobbers(blobbers, ticket, s.RefereeEncryptionPublicKey, availableAfter)
+			}
+		}
+		if err != nil {
+			// keep the share pending to retry
+			lastErr = err
+			continue
+		}
+
+		getShareRegistry(a.ID).update(s.Path, s.RefereeClientID, func(share *ShareInfo) {
+			share.PendingBlobbers = failed
+			if len(failed) == 0 {
+				share.UploadedTicket = ""
+			}
+		})
+	}
+	return lastErr
+}
+
+// withReEncryptionKey adds a new re-encryption key of the referee to the
+// auth ticket json, so that the key is never persisted.
+func withReEncryptionKey(ticket, refereeEncryptionPublicKey string) (string, error) {
+	if refereeEncryptionPublicKey == "" {
+		return ticket, nil
+	}
+	at := &marker.AuthTicket{}
+	if err := json.Unmarshal([]byte(ticket), at); err != nil {
+		return "", errors.Wrap(err, "invalid uploaded auth ticket")
+	}
+	encScheme, err := clientEncryptionScheme()
+	if err != nil {
+		return "", err
+	}
+	at.ReEncryptionKey, err = encScheme.GetReGenKey(refereeEncryptionPublicKey, "filetype:audio")
+	if err != nil {
+		return "", err
+	}
+	if err := at.Sign(); err != nil {
+		return "", err
+	}
+	buf, err := json.Marshal(at)
+	if err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// StartShareReconciler reconciles the shares every interval until ctx is done.
+func (a *Allocation) StartShareReconciler(ctx context.Context, interval time.Duration) {
+	go func() {
+		tc := time.NewTicker(interval)
+		defer tc.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tc.C:
+				if err := a.ReconcileShares(); err != nil {
+					logger.Logger.Error("[shares] reconcile ", a.ID, err)
+				}
+			}
+		}
+	}()
+}
+
+func (a *Allocation) blobbersByID(ids []string) []*blockchain.StorageNode {
+	var blobbers []*blockchain.StorageNode
+	for _, b := range a.Blobbers {
+		for _, id := range ids {
+			if b.ID == id {
+				blobbers = append(blobbers, b)
+			}
+		}
+	}
+	return blobbers
+}
@@ -0,0 +1,139 @@
+package sdk
+
+import (
+	"os"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/0chain/gosdk/core/common"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryShareStorer struct {
+	sync.Mutex
+	shares map[string][]*ShareInfo
+}
+
+func (m *memoryShareStorer) Load(allocationID string) ([]*ShareInfo, error) {
+	m.Lock()
+	defer m.Unlock()
+	return m.shares[allocationID], nil
+}
+
+func (m *memoryShareStorer) Save(allocationID string, shares []*ShareInfo) error {
+	m.Lock()
+	defer m.Unlock()
+	m.shares[allocationID] = shares
+	return nil
+}
+
+func TestMain(m *testing.M) {
+	// keep the shares recorded by the tests out of the home dir
+	SetShareStorer(&memoryShareStorer{shares: make(map[string][]*ShareInfo)})
+	// the mocked blobbers have no policy document
+	loadPolicy = func(a *Allocation) (*AllocationPolicy, error) {
+		return &AllocationPolicy{}, nil
+	}
+	// nor the path encryption marker
+	loadPathEncryption = func(a *Allocation) (bool, error) {
+		return false, nil
+	}
+	os.Exit(m.Run())
+}
+
+func TestShareFilter(t *testing.T) {
+	now := int64(common.Now())
+	share := &ShareInfo{Path: "/docs/a.txt", RefereeClientID: "bob"}
+
+	tests := []struct {
+		name   string
+		filter *ShareFilter
+		share  *ShareInfo
+		want   bool
+	}{
+		{"nil", nil, share, true},
+		{"root prefix", &ShareFilter{PathPrefix: "/"}, share, true},
+		{"dir prefix", &ShareFilter{PathPrefix: "/docs/"}, share, true},
+		{"exact path", &ShareFilter{PathPrefix: "/docs/a.txt"}, share, true},
+		{"partial name", &ShareFilter{PathPrefix: "/doc"}, share, false},
+		{"referee", &ShareFilter{RefereeClientID: "bob"}, share, true},
+		{"other referee", &ShareFilter{RefereeClientID: "alice"}, share, false},
+		{"revoked", nil, &ShareInfo{Path: "/a", Revoked: true}, false},
+		{"expired", nil, &ShareInfo{Path: "/a", Expiration: now - 1}, false},
+		{"include inactive", &ShareFilter{IncludeInactive: true}, &ShareInfo{Path: "/a", Revoked: true}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.filter.match(tt.share))
+		})
+	}
+}
+
+func TestShareRegistry(t *testing.T) {
+	storer := &memoryShareStorer{shares: make(map[string][]*ShareInfo)}
+	r := &shareRegistry{allocationID: "alloc", storer: storer}
+
+	r.put(&ShareInfo{AllocationID: "alloc", Path: "/a", RefereeClientID: "bob"})
+	r.put(&ShareInfo{AllocationID: "alloc", Path: "/b"})
+	r.put(&ShareInfo{AllocationID: "alloc", Path: "/a", RefereeClientID: "bob", AuthTicket: "new"})
+
+	shares := r.list(nil)
+	sort.Slice(shares, func(i, j int) bool { return shares[i].Path < shares[j].Path })
+	require.Len(t, shares, 2)
+	require.Equal(t, "new", shares[0].AuthTicket)
+
+	r.update("/a", "bob", func(s *ShareInfo) {
+		s.Revoked = true
+		s.PendingBlobbers = []string{"blobber"}
+	})
+	require.Len(t, r.list(nil), 1)
+	require.Equal(t, []string{"blobber"}, r.get("/a", "bob").PendingBlobbers)
+	require.Nil(t, r.get("/a", ""))
+
+	t.Run("reload", func(t *testing.T) {
+		r := &shareRegistry{allocationID: "alloc", storer: storer}
+		require.True(t, r.get("/a", "bob").Revoked)
+		require.Len(t, r.list(&ShareFilter{IncludeInactive: true}), 2)
+	})
+}
+
+func TestFsShareStorer(t *testing.T) {
+	fs := &fsShareStorer{dir: t.TempDir()}
+
+	shares, err := fs.Load("alloc")
+	require.NoError(t, err)
+	require.Empty(t, shares)
+
+	want := []*ShareInfo{{AllocationID: "alloc", Path: "/a", Expiration: 10, PendingBlobbers: []string{"b1"}}}
+	require.NoError(t, fs.Save("alloc", want))
+
+	shares, err = fs.Load("alloc")
+	require.NoError(t, err)
+	require.Equal(t, want, shares)
+
+	t.Run("no workdir", func(t *testing.T) {
+		fs := &fsShareStorer{}
+		require.Empty(t, fs.path("alloc"))
+		require.NoError(t, fs.Save("alloc", want))
+		shares, err := fs.Load("alloc")
+		require.NoError(t, err)
+		require.Empty(t, shares)
+	})
+}
+
+func TestReconcileSharesError(t *testing.T) {
+	a := &Allocation{ID: "reconcile"}
+	share := &ShareInfo{
+		AllocationID:               a.ID,
+		Path:                       "/a",
+		RefereeClientID:            "bob",
+		RefereeEncryptionPublicKey: "key",
+		PendingBlobbers:            []string{"b1"},
+		UploadedTicket:             "invalid",
+	}
+	getShareRegistry(a.ID).put(share.clone())
+
+	require.Error(t, a.ReconcileShares())
+	require.Equal(t, share, a.GetShare("/a", "bob"))
+}
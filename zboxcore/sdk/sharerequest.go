@@ -9,7 +9,6 @@ import (
 	"github.com/0chain/gosdk/core/common"
 	"github.com/0chain/gosdk/zboxcore/blockchain"
 	"github.com/0chain/gosdk/zboxcore/client"
-	"github.com/0chain/gosdk/zboxcore/encryption"
 	"github.com/0chain/gosdk/zboxcore/fileref"
 	"github.com/0chain/gosdk/zboxcore/marker"
 )
@@ -23,6 +22,9 @@ type ShareRequest struct {
 	expirationSeconds int64
 	blobbers          []*blockchain.StorageNode
 	ctx               context.Context
+	// gcmKeyPoints are the encrypted key points of the EncryptionModeGCM
+	// files of a shared directory, by lookup hash
+	gcmKeyPoints map[string]string
 }
 
 func (req *ShareRequest) GetFileRef() (*fileref.FileRef, error) {
@@ -67,8 +69,8 @@ func (req *ShareRequest) getAuthTicket(clientID, encPublicKey string) (*marker.A
 	}
 
 	if encPublicKey != "" { // file is encrypted
-		encScheme := encryption.NewEncryptionScheme()
-		if _, err := encScheme.Initialize((client.GetClient().Mnemonic)); err != nil {
+		encScheme, err := clientEncryptionScheme()
+		if err != nil {
 			return nil, err
 		}
 
@@ -79,6 +81,24 @@ func (req *ShareRequest) getAuthTicket(clientID, encPublicKey string) (*marker.A
 
 		at.ReEncryptionKey = reKey
 		at.Encrypted = true
+
+		// blobbers don't re-encrypt AES-GCM files, the referee gets the data keys instead
+		if req.refType == fileref.FILE && isGCMKeyPoint(fRef.EncryptedKeyPoint) {
+			at.FileKey, err = reEncryptDataKey(at.FilePathHash, fRef.EncryptedKeyPoint, encPublicKey)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for lookupHash, point := range req.gcmKeyPoints {
+			fileKey, err := reEncryptDataKey(lookupHash, point, encPublicKey)
+			if err != nil {
+				return nil, err
+			}
+			if at.FileKeys == nil {
+				at.FileKeys = make(map[string]string, len(req.gcmKeyPoints))
+			}
+			at.FileKeys[lookupHash] = fileKey
+		}
 	}
 
 	if err := at.Sign(); err != nil {
@@ -87,3 +107,13 @@ func (req *ShareRequest) getAuthTicket(clientID, encPublicKey string) (*marker.A
 
 	return at, nil
 }
+
+// reEncryptDataKey re-encrypts the data key of the client's EncryptionModeGCM
+// file to the encryption public key.
+func reEncryptDataKey(lookupHash, point, encPublicKey string) (string, error) {
+	_, fileKey, owner, err := ownerDataKey(lookupHash, point)
+	if err != nil {
+		return "", err
+	}
+	return fileKey.reEncrypt(owner, encPublicKey)
+}
@@ -45,7 +45,8 @@ var (
 	client *http.Client
 )
 
-// QueryEthereumMintPayload gets burn ticket and creates mint payload to be minted in the Ethereum chain
+// QueryEthereumMintPayload gets burn ticket and creates mint payload to be minted in the Ethereum chain.
+// Only tickets signed by registered Ethereum authorizers and agreeing on the burn count for the quorum.
 // zchainBurnHash - Ethereum burn transaction hash
 func (b *BridgeClient) QueryEthereumMintPayload(zchainBurnHash string) (*ethereum.MintPayload, error) {
 	client = h.CleanClient()
@@ -72,20 +73,22 @@ func (b *BridgeClient) QueryEthereumMintPayload(zchainBurnHash string) (*ethereu
 		},
 	}
 
+	registry, err := b.ethereumAuthorizerRegistry()
+	if err != nil {
+		return nil, err
+	}
+
 	thresh := b.ConsensusThreshold
 	results := queryAllAuthorizers(authorizers, handler)
-	numSuccess := len(results)
+	proofs := verifyZCNBurnProofs(results, zchainBurnHash, b.EthereumAddress, registry)
+	numSuccess := len(proofs)
 	quorum := math.Ceil((float64(numSuccess) * 100) / float64(totalWorkers))
 
 	if numSuccess > 0 && quorum >= thresh {
-		burnTicket, ok := results[0].(*ProofZCNBurn)
-		if !ok {
-			return nil, errors.Wrap("type_cast", "failed to convert to *proofEthereumBurn", err)
-		}
+		burnTicket := proofs[0]
 
 		var sigs []*ethereum.AuthorizerSignature
-		for _, result := range results {
-			ticket := result.(*ProofZCNBurn)
+		for _, ticket := range proofs {
 			sig := &ethereum.AuthorizerSignature{
 				ID:        ticket.GetAuthorizerID(),
 				Signature: ticket.Signature,
@@ -163,7 +166,8 @@ func (b *BridgeClient) QueryEthereumBurnEvents(startNonce string) ([]*ethereum.B
 	return nil, errors.New("get_burn_events", text)
 }
 
-// QueryZChainMintPayload gets burn ticket and creates mint payload to be minted in the ZChain
+// QueryZChainMintPayload gets burn ticket and creates mint payload to be minted in the ZChain.
+// Only tickets signed by the registered authorizer keys and agreeing on the burn count for the quorum.
 // ethBurnHash - Ethereum burn transaction hash
 func (b *BridgeClient) QueryZChainMintPayload(ethBurnHash string) (*zcnsc.MintPayload, error) {
 	client = h.CleanClient()
@@ -194,21 +198,18 @@ func (b *BridgeClient) QueryZChainMintPayload(ethBurnHash string) (*zcnsc.MintPa
 
 	thresh := b.ConsensusThreshold
 	results := queryAllAuthorizers(authorizers, handler)
-	numSuccess := len(results)
+	events := verifyEthereumBurnProofs(results, ethBurnHash, values["clientid"])
+	numSuccess := len(events)
 	quorum := math.Ceil((float64(numSuccess) * 100) / float64(totalWorkers))
 
 	if numSuccess > 0 && quorum >= thresh {
-		burnTicket, ok := results[0].Data().(*ProofEthereumBurn)
-		if !ok {
-			return nil, errors.Wrap("type_cast", "failed to convert to *proofEthereumBurn", err)
-		}
+		burnTicket := events[0].BurnTicket
 
 		var sigs []*zcnsc.AuthorizerSignature
-		for _, result := range results {
-			ticket := result.Data().(*ProofEthereumBurn)
+		for _, event := range events {
 			sig := &zcnsc.AuthorizerSignature{
-				ID:        result.GetAuthorizerID(),
-				Signature: ticket.Signature,
+				ID:        event.GetAuthorizerID(),
+				Signature: event.BurnTicket.Signature,
 			}
 			sigs = append(sigs, sig)
 		}
@@ -0,0 +1,203 @@
+package zcnbridge
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/0chain/gosdk/core/encryption"
+	"github.com/0chain/gosdk/core/zcncrypto"
+	"github.com/0chain/gosdk/zcnbridge/errors"
+	"github.com/0chain/gosdk/zcnbridge/ethereum/authorizers"
+	"github.com/0chain/gosdk/zcncore"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"go.uber.org/zap"
+)
+
+// ethereumAuthorizerRegistry is the part of the Ethereum authorizers contract
+// used to check authorizer signatures.
+type ethereumAuthorizerRegistry interface {
+	MessageHash(opts *bind.CallOpts, to_ common.Address, amount_ *big.Int, txid_ []byte, nonce_ *big.Int) ([32]byte, error)
+	Authorizers(opts *bind.CallOpts, arg0 common.Address) (struct {
+		Index        *big.Int
+		IsAuthorizer bool
+	}, error)
+}
+
+// AuthorizerPublicKey returns the registered 0chain public key of the authorizer.
+// The key is checked to derive the authorizer id.
+var AuthorizerPublicKey = func(authorizerID string) (string, error) {
+	details, err := zcncore.GetClientDetails(authorizerID)
+	if err != nil {
+		return "", err
+	}
+	return details.PublicKey, nil
+}
+
+func (b *BridgeClient) ethereumAuthorizerRegistry() (ethereumAuthorizerRegistry, error) {
+	caller, err := authorizers.NewAuthorizersCaller(common.HexToAddress(b.AuthorizersAddress), b.ethereumClient)
+	if err != nil {
+		return nil, errors.Wrap("authorizers_contract", "failed to create authorizers instance", err)
+	}
+	return caller, nil
+}
+
+// verifyZCNBurnProofs returns the largest group of proofs with a valid signature
+// of a registered Ethereum authorizer agreeing on the burn. Proofs of another
+// burn or receiver, and proofs disagreeing with the group, are dropped.
+func verifyZCNBurnProofs(results []JobResult, burnHash, to string, registry ethereumAuthorizerRegistry) []*ProofZCNBurn {
+	var (
+		verified     = make(map[string][]*ProofZCNBurn)
+		messages     = make(map[string][]byte)
+		seenSigners  = make(map[common.Address]bool)
+		isAuthorizer = make(map[common.Address]bool)
+	)
+
+	for _, result := range results {
+		proof, ok := result.Data().(*ProofZCNBurn)
+		if !ok || proof == nil {
+			continue
+		}
+		authorizerID := result.GetAuthorizerID()
+		if proof.TxnID != burnHash || !strings.EqualFold(proof.To, to) {
+			Logger.Error("authorizer proof of another burn", zap.String("authorizer", authorizerID))
+			continue
+		}
+
+		key := fmt.Sprintf("%v:%v:%v:%v", proof.TxnID, proof.Amount, proof.Nonce, strings.ToLower(proof.To))
+		message, ok := messages[key]
+		if !ok {
+			hash, err := registry.MessageHash(nil, common.HexToAddress(proof.To), big.NewInt(proof.Amount),
+				DefaultClientIDEncoder(proof.TxnID), big.NewInt(proof.Nonce))
+			if err != nil {
+				Logger.Error("failed to compute mint message hash", zap.Error(err))
+				continue
+			}
+			message = accounts.TextHash(hash[:])
+			messages[key] = message
+		}
+
+		signer, err := recoverEthereumSigner(message, proof.Signature)
+		if err != nil {
+			Logger.Error("invalid authorizer signature", zap.String("authorizer", authorizerID), zap.Error(err))
+			continue
+		}
+		if _, ok := isAuthorizer[signer]; !ok {
+			a, err := registry.Authorizers(nil, signer)
+			if err != nil {
+				Logger.Error("failed to check authorizer", zap.String("address", signer.Hex()), zap.Error(err))
+				continue
+			}
+			isAuthorizer[signer] = a.IsAuthorizer
+		}
+		if !isAuthorizer[signer] {
+			Logger.Error("signature of unregistered authorizer", zap.String("authorizer", authorizerID),
+				zap.String("address", signer.Hex()))
+			continue
+		}
+		if seenSigners[signer] {
+			continue
+		}
+		seenSigners[signer] = true
+
+		proof.AuthorizerID = authorizerID
+		verified[key] = append(verified[key], proof)
+	}
+
+	var largest []*ProofZCNBurn
+	for _, proofs := range verified {
+		if len(proofs) > len(largest) {
+			largest = proofs
+		}
+	}
+	if len(largest) < len(seenSigners) {
+		Logger.Error("authorizers disagree on the burn ticket", zap.String("hash", burnHash),
+			zap.Int("agreeing", len(largest)), zap.Int("verified", len(seenSigners)))
+	}
+	return largest
+}
+
+// verifyEthereumBurnProofs returns the largest group of burn events signed by
+// the registered 0chain key of their authorizer and agreeing on the burn.
+func verifyEthereumBurnProofs(results []JobResult, burnHash, clientID string) []*WZCNBurnEvent {
+	verified := make(map[string][]*WZCNBurnEvent)
+	var count int
+
+	for _, result := range results {
+		event, ok := result.(*WZCNBurnEvent)
+		if !ok || event.BurnTicket == nil {
+			continue
+		}
+		ticket := event.BurnTicket
+		authorizerID := event.GetAuthorizerID()
+		if !strings.EqualFold(ticket.TxnID, burnHash) || ticket.ReceivingClientID != clientID {
+			Logger.Error("authorizer ticket of another burn", zap.String("authorizer", authorizerID))
+			continue
+		}
+
+		publicKey, err := AuthorizerPublicKey(authorizerID)
+		if err != nil {
+			Logger.Error("failed to get authorizer public key", zap.String("authorizer", authorizerID), zap.Error(err))
+			continue
+		}
+		if err := checkPublicKeyID(publicKey, authorizerID); err != nil {
+			Logger.Error("invalid authorizer public key", zap.String("authorizer", authorizerID), zap.Error(err))
+			continue
+		}
+
+		key := fmt.Sprintf("%v:%v:%v:%v", ticket.TxnID, ticket.Amount, ticket.Nonce, ticket.ReceivingClientID)
+		ok, err = zcncore.VerifyWithKey(publicKey, ticket.Signature, zcncrypto.Sha3Sum256(key))
+		if err != nil || !ok {
+			Logger.Error("invalid authorizer signature", zap.String("authorizer", authorizerID))
+			continue
+		}
+
+		verified[key] = append(verified[key], event)
+		count++
+	}
+
+	var largest []*WZCNBurnEvent
+	for _, events := range verified {
+		if len(events) > len(largest) {
+			largest = events
+		}
+	}
+	if len(largest) < count {
+		Logger.Error("authorizers disagree on the burn ticket", zap.String("hash", burnHash),
+			zap.Int("agreeing", len(largest)), zap.Int("verified", count))
+	}
+	return largest
+}
+
+func recoverEthereumSigner(message, signature []byte) (common.Address, error) {
+	if len(signature) != crypto.SignatureLength {
+		return common.Address{}, errors.New("invalid_signature", "invalid signature length")
+	}
+	sig := make([]byte, crypto.SignatureLength)
+	copy(sig, signature)
+	// accept signatures with the Ethereum recovery id
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	pub, err := crypto.SigToPub(message, sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+func checkPublicKeyID(publicKey, id string) error {
+	pk, err := hex.DecodeString(publicKey)
+	if err != nil {
+		return err
+	}
+	if encryption.Hash(pk) != id {
+		return errors.New("invalid_public_key", "public key does not match authorizer id")
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package zcnbridge
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/0chain/gosdk/core/encryption"
+	"github.com/0chain/gosdk/core/zcncrypto"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAuthorizerRegistry struct {
+	authorizers map[common.Address]bool
+}
+
+func (r *fakeAuthorizerRegistry) MessageHash(opts *bind.CallOpts, to_ common.Address, amount_ *big.Int, txid_ []byte, nonce_ *big.Int) ([32]byte, error) {
+	return crypto.Keccak256Hash(to_.Bytes(), amount_.Bytes(), txid_, nonce_.Bytes()), nil
+}
+
+func (r *fakeAuthorizerRegistry) Authorizers(opts *bind.CallOpts, arg0 common.Address) (struct {
+	Index        *big.Int
+	IsAuthorizer bool
+}, error) {
+	return struct {
+		Index        *big.Int
+		IsAuthorizer bool
+	}{Index: big.NewInt(0), IsAuthorizer: r.authorizers[arg0]}, nil
+}
+
+func signZCNBurnProof(t *testing.T, r *fakeAuthorizerRegistry, key *ecdsa.PrivateKey, proof *ProofZCNBurn) *ProofZCNBurn {
+	hash, err := r.MessageHash(nil, common.HexToAddress(proof.To), big.NewInt(proof.Amount),
+		DefaultClientIDEncoder(proof.TxnID), big.NewInt(proof.Nonce))
+	require.NoError(t, err)
+	sig, err := crypto.Sign(accounts.TextHash(hash[:]), key)
+	require.NoError(t, err)
+	sig[crypto.RecoveryIDOffset] += 27
+	proof.Signature = sig
+	return proof
+}
+
+func TestVerifyZCNBurnProofs(t *testing.T) {
+	const (
+		burnHash = "a1b2c3d4"
+		to       = "0x860FA46F170a87dF44D7bB867AA4a5D2813127c1"
+	)
+
+	registry := &fakeAuthorizerRegistry{authorizers: make(map[common.Address]bool)}
+	var keys []*ecdsa.PrivateKey
+	for i := 0; i < 4; i++ {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		registry.authorizers[crypto.PubkeyToAddress(key.PublicKey)] = true
+		keys = append(keys, key)
+	}
+	outsider, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	proof := func(amount int64) *ProofZCNBurn {
+		return &ProofZCNBurn{TxnID: burnHash, To: to, Nonce: 1, Amount: amount}
+	}
+	results := []JobResult{
+		signZCNBurnProof(t, registry, keys[0], proof(100)),
+		signZCNBurnProof(t, registry, keys[1], proof(100)),
+		// disagrees on the amount
+		signZCNBurnProof(t, registry, keys[2], proof(1000)),
+		// not a registered authorizer
+		signZCNBurnProof(t, registry, outsider, proof(100)),
+		// signature of another ticket
+		&ProofZCNBurn{TxnID: burnHash, To: to, Nonce: 1, Amount: 100,
+			Signature: signZCNBurnProof(t, registry, keys[3], proof(5)).Signature},
+	}
+	for i, r := range results {
+		r.SetAuthorizerID(fmt.Sprint(i))
+	}
+
+	proofs := verifyZCNBurnProofs(results, burnHash, to, registry)
+	require.Len(t, proofs, 2)
+	for _, p := range proofs {
+		require.EqualValues(t, 100, p.Amount)
+	}
+
+	require.Empty(t, verifyZCNBurnProofs(results, "ff", to, registry))
+}
+
+func TestVerifyEthereumBurnProofs(t *testing.T) {
+	const (
+		burnHash = "0xburn"
+		clientID = "client"
+	)
+
+	publicKeys := make(map[string]string)
+	var results []JobResult
+	for i := 0; i < 3; i++ {
+		ss := zcncrypto.NewSignatureScheme("bls0chain")
+		w, err := ss.GenerateKeys()
+		require.NoError(t, err)
+		pk, err := hex.DecodeString(w.ClientKey)
+		require.NoError(t, err)
+		id := encryption.Hash(pk)
+		publicKeys[id] = w.ClientKey
+
+		ticket := &ProofEthereumBurn{TxnID: burnHash, Nonce: 2, Amount: 100, ReceivingClientID: clientID}
+		sig, err := ss.Sign(zcncrypto.Sha3Sum256(fmt.Sprintf("%v:%v:%v:%v", ticket.TxnID, ticket.Amount, ticket.Nonce, ticket.ReceivingClientID)))
+		require.NoError(t, err)
+		ticket.Signature = sig
+		if i == 2 {
+			// the ticket was changed after signing
+			ticket.Amount = 1000
+		}
+		results = append(results, &WZCNBurnEvent{AuthorizerID: id, BurnTicket: ticket})
+	}
+	// an authorizer claiming another authorizer's ticket
+	results = append(results, &WZCNBurnEvent{AuthorizerID: "unknown", BurnTicket: results[0].Data().(*ProofEthereumBurn)})
+
+	defer func(f func(string) (string, error)) { AuthorizerPublicKey = f }(AuthorizerPublicKey)
+	AuthorizerPublicKey = func(id string) (string, error) {
+		if pk, ok := publicKeys[id]; ok {
+			return pk, nil
+		}
+		return publicKeys[results[0].GetAuthorizerID()], nil
+	}
+
+	events := verifyEthereumBurnProofs(results, burnHash, clientID)
+	require.Len(t, events, 2)
+	require.Empty(t, verifyEthereumBurnProofs(results, burnHash, "other"))
+}
@@ -15,7 +15,6 @@ import (
 
 	"github.com/0chain/gosdk/zcnbridge/ethereum/bancortoken"
 
-	"github.com/0chain/common/core/currency"
 	"github.com/0chain/gosdk/zcnbridge/ethereum/bancornetwork"
 	"github.com/0chain/gosdk/zcnbridge/ethereum/zcntoken"
 	h "github.com/0chain/gosdk/zcnbridge/http"
@@ -25,10 +24,13 @@ import (
 	"gopkg.in/natefinch/lumberjack.v2"
 
 	"github.com/0chain/gosdk/core/logger"
+	coreTransaction "github.com/0chain/gosdk/core/transaction"
 	"github.com/0chain/gosdk/zcnbridge/ethereum"
 	"github.com/0chain/gosdk/zcnbridge/ethereum/authorizers"
 	"github.com/0chain/gosdk/zcnbridge/ethereum/bridge"
 	"github.com/0chain/gosdk/zcnbridge/ethereum/nftconfig"
+	"github.com/0chain/gosdk/zcnbridge/ethsigner"
+	"github.com/0chain/gosdk/zcnbridge/gas"
 	"github.com/0chain/gosdk/zcnbridge/log"
 	"github.com/0chain/gosdk/zcncore"
 
@@ -36,7 +38,6 @@ import (
 	"github.com/0chain/gosdk/zcnbridge/wallet"
 	"github.com/0chain/gosdk/zcnbridge/zcnsc"
 	eth "github.com/ethereum/go-ethereum"
-	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -73,51 +74,85 @@ var (
 	}
 )
 
+// CreateSignedTransactionFromKeyStore creates transact options signed by the client signer,
+// with the fees of the client gas strategy.
 func (b *BridgeClient) CreateSignedTransactionFromKeyStore(client EthereumClient, gasLimitUnits uint64) *bind.TransactOpts {
-	var (
-		signerAddress = common.HexToAddress(b.EthereumAddress)
-		password      = b.Password
-	)
-
-	signer := accounts.Account{
-		Address: signerAddress,
-	}
-
-	signerAcc, err := b.keyStore.Find(signer)
-	if err != nil {
-		Logger.Fatal(errors.Wrapf(err, "signer: %s", signerAddress.Hex()))
-	}
+	s := b.ethereumSigner()
 
 	chainID, err := client.ChainID(context.Background())
 	if err != nil {
 		Logger.Fatal(errors.Wrap(err, "failed to get chain ID"))
 	}
 
-	nonce, err := client.PendingNonceAt(context.Background(), signerAddress)
+	nonce, err := client.PendingNonceAt(context.Background(), s.Address())
 	if err != nil {
 		Logger.Fatal(err)
 	}
 
-	gasPriceWei, err := client.SuggestGasPrice(context.Background())
+	fees, err := b.gasStrategy().Fees(context.Background(), client)
 	if err != nil {
 		Logger.Fatal(err)
 	}
 
-	err = b.keyStore.TimedUnlock(signer, password, time.Second*2)
-	if err != nil {
-		Logger.Fatal(err)
+	opts := ethsigner.NewTransactOpts(context.Background(), s, chainID)
+	opts.Nonce = big.NewInt(int64(nonce))
+	opts.GasLimit = gasLimitUnits // in units
+	fees.Apply(opts)              // wei
+
+	return opts
+}
+
+// EstimateFees returns the fees the gas strategy sets on new transactions.
+func (b *BridgeClient) EstimateFees(ctx context.Context) (*gas.Fees, error) {
+	return b.gasStrategy().Fees(ctx, b.ethereumClient)
+}
+
+// errNoReceipts the Ethereum client can't tell when transactions are mined
+var errNoReceipts = errors.New("ethereum client does not support transaction receipts")
+
+// WaitMined waits until the transaction is mined, replacing it with higher
+// fees while it stays pending, and returns its receipt.
+func (b *BridgeClient) WaitMined(ctx context.Context, tx *types.Transaction) (*types.Receipt, error) {
+	return b.waitMined(ctx, tx, nil)
+}
+
+// waitMined is WaitMined calling onReplace with each replacement before it is sent.
+func (b *BridgeClient) waitMined(ctx context.Context, tx *types.Transaction, onReplace func(tx *types.Transaction) error) (*types.Receipt, error) {
+	backend, ok := b.ethereumClient.(gas.ReplaceBackend)
+	if !ok {
+		return nil, errNoReceipts
 	}
 
-	opts, err := bind.NewKeyStoreTransactorWithChainID(b.keyStore.GetEthereumKeyStore(), signerAcc, chainID)
+	chainID, err := b.ethereumClient.ChainID(ctx)
 	if err != nil {
-		Logger.Fatal(err)
+		return nil, errors.Wrap(err, "failed to get chain ID")
 	}
+	opts := ethsigner.NewTransactOpts(ctx, b.ethereumSigner(), chainID)
 
-	opts.Nonce = big.NewInt(int64(nonce))
-	opts.GasLimit = gasLimitUnits // in units
-	opts.GasPrice = gasPriceWei   // wei
+	replacer := &gas.Replacer{
+		Backend:   backend,
+		Strategy:  b.gasStrategy(),
+		From:      opts.From,
+		Signer:    opts.Signer,
+		OnReplace: onReplace,
+	}
+	return replacer.WaitMined(ctx, tx)
+}
 
-	return opts
+// ethereumSigner returns the configured signer, the key store signer of the
+// client Ethereum address if none.
+func (b *BridgeClient) ethereumSigner() ethsigner.Signer {
+	if b.Signer != nil {
+		return b.Signer
+	}
+	return ethsigner.NewKeyStoreSigner(signerKeyStore{b.keyStore}, common.HexToAddress(b.EthereumAddress), b.Password)
+}
+
+func (b *BridgeClient) gasStrategy() gas.Strategy {
+	if b.GasStrategy != nil {
+		return b.GasStrategy
+	}
+	return gas.Default()
 }
 
 // AddEthereumAuthorizer Adds authorizer to Ethereum bridge. Only contract deployer can call this method
@@ -421,26 +456,13 @@ func (b *BridgeClient) VerifyZCNTransaction(ctx context.Context, hash string) (t
 	return transaction.Verify(ctx, hash)
 }
 
-// SignWithEthereumChain signs the digest with Ethereum chain signer taking key from the current user key storage
+// SignWithEthereumChain signs the digest with the client Ethereum signer
 func (b *BridgeClient) SignWithEthereumChain(message string) ([]byte, error) {
 	hash := crypto.Keccak256Hash([]byte(message))
 
-	signer := accounts.Account{
-		Address: common.HexToAddress(b.EthereumAddress),
-	}
-
-	signerAcc, err := b.keyStore.Find(signer)
-	if err != nil {
-		Logger.Fatal(err)
-	}
-
-	signature, err := b.keyStore.SignHash(signerAcc, hash.Bytes())
-	if err != nil {
-		return nil, err
-	}
-
+	signature, err := b.ethereumSigner().SignHash(hash.Bytes())
 	if err != nil {
-		return []byte{}, errors.Wrap(err, "failed to sign the message")
+		return nil, errors.Wrap(err, "failed to sign the message")
 	}
 
 	return signature, nil
@@ -492,6 +514,8 @@ func (b *BridgeClient) ResetUserNonceMinted(ctx context.Context) (*types.Transac
 
 // MintWZCN Mint ZCN tokens on behalf of the 0ZCN client
 // payload: received from authorizers
+// It returns once the mint is sent. WaitMined waits for it, replacing it with
+// higher fees while it stays pending.
 func (b *BridgeClient) MintWZCN(ctx context.Context, payload *ethereum.MintPayload) (*types.Transaction, error) {
 	if DefaultClientIDEncoder == nil {
 		return nil, errors.New("DefaultClientIDEncoder must be setup")
@@ -553,7 +577,30 @@ func (b *BridgeClient) MintWZCN(ctx context.Context, payload *ethereum.MintPaylo
 // amountTokens - ZCN tokens
 // clientID - 0ZCN client
 // ERC20 signature: "burn(uint256,bytes)"
+// It returns once the burn is sent. WaitMined waits for it, replacing it with
+// higher fees while it stays pending.
 func (b *BridgeClient) BurnWZCN(ctx context.Context, amountTokens uint64) (*types.Transaction, error) {
+	tran, err := b.SignWZCNBurn(ctx, amountTokens)
+	if err != nil {
+		return nil, err
+	}
+	if err = b.SendEthereumTransaction(ctx, tran); err != nil {
+		msg := "failed to execute Burn WZCN transaction to ClientID = %s with amount = %d"
+		return nil, errors.Wrapf(err, msg, zcncore.GetClientWalletID(), amountTokens)
+	}
+
+	Logger.Info(
+		"Posted Burn WZCN",
+		zap.String("clientID", zcncore.GetClientWalletID()),
+		zap.Uint64("amount", amountTokens),
+	)
+
+	return tran, nil
+}
+
+// SignWZCNBurn creates and signs the WZCN burn transaction without sending it,
+// so it can be persisted first. Send it with SendEthereumTransaction.
+func (b *BridgeClient) SignWZCNBurn(ctx context.Context, amountTokens uint64) (*types.Transaction, error) {
 	if DefaultClientIDEncoder == nil {
 		return nil, errors.New("DefaultClientIDEncoder must be setup")
 	}
@@ -569,6 +616,7 @@ func (b *BridgeClient) BurnWZCN(ctx context.Context, amountTokens uint64) (*type
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to prepare bridge")
 	}
+	transactOpts.NoSend = true
 
 	Logger.Info(
 		"Staring Burn WZCN",
@@ -577,17 +625,20 @@ func (b *BridgeClient) BurnWZCN(ctx context.Context, amountTokens uint64) (*type
 
 	tran, err := bridgeInstance.Burn(transactOpts, amount, clientID)
 	if err != nil {
-		msg := "failed to execute Burn WZCN transaction to ClientID = %s with amount = %s"
+		msg := "failed to sign Burn WZCN transaction to ClientID = %s with amount = %s"
 		return nil, errors.Wrapf(err, msg, zcncore.GetClientWalletID(), amount)
 	}
+	return tran, nil
+}
 
-	Logger.Info(
-		"Posted Burn WZCN",
-		zap.String("clientID", zcncore.GetClientWalletID()),
-		zap.Int64("amount", amount.Int64()),
-	)
-
-	return tran, err
+// SendEthereumTransaction sends the signed transaction. A transaction the node
+// already knows, e.g. sent before a restart, is not an error.
+func (b *BridgeClient) SendEthereumTransaction(ctx context.Context, tx *types.Transaction) error {
+	err := b.ethereumClient.SendTransaction(ctx, tx)
+	if err != nil && strings.Contains(strings.ToLower(err.Error()), "already known") {
+		return nil
+	}
+	return err
 }
 
 // MintZCN mints ZCN tokens after receiving proof-of-burn of WZCN tokens
@@ -669,6 +720,65 @@ func (b *BridgeClient) BurnZCN(ctx context.Context, amount, txnfee uint64) (tran
 	return trx, nil
 }
 
+// zcnSignedSubmitter is implemented by the zcncore transactions which can be
+// signed before they are submitted.
+type zcnSignedSubmitter interface {
+	SignSmartContract(address, methodName string, input interface{}, val uint64, opts ...zcncore.FeeOption) (*coreTransaction.Transaction, error)
+	SubmitSigned(txn *coreTransaction.Transaction) error
+}
+
+// SignZCNBurn creates and signs the ZCN burn transaction without submitting
+// it, so it can be persisted first. Submit it with SubmitZCNBurn.
+func (b *BridgeClient) SignZCNBurn(ctx context.Context, amount, txnfee uint64) (*coreTransaction.Transaction, error) {
+	txn, err := zcncore.NewTransaction(transaction.NewStatus(), txnfee, 0)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create new transaction")
+	}
+	submitter, ok := txn.(zcnSignedSubmitter)
+	if !ok {
+		return nil, errors.New("the wallet can't sign transactions before submitting them")
+	}
+
+	payload := zcnsc.BurnPayload{
+		EthereumAddress: b.EthereumAddress,
+	}
+	signed, err := submitter.SignSmartContract(wallet.ZCNSCSmartContractAddress, wallet.BurnFunc, payload, amount)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign burn transaction")
+	}
+	return signed, nil
+}
+
+// SubmitZCNBurn submits the burn transaction signed by SignZCNBurn and waits
+// until it is verified.
+func (b *BridgeClient) SubmitZCNBurn(ctx context.Context, signed *coreTransaction.Transaction) error {
+	cb := transaction.NewStatus()
+	txn, err := zcncore.NewTransaction(cb, signed.TransactionFee, signed.TransactionNonce)
+	if err != nil {
+		return errors.Wrap(err, "failed to create new transaction")
+	}
+	submitter, ok := txn.(zcnSignedSubmitter)
+	if !ok {
+		return errors.New("the wallet can't submit signed transactions")
+	}
+
+	Logger.Info(
+		"Submitting BURN smart contract",
+		zap.String("hash", signed.Hash),
+		zap.Uint64("burn amount", signed.Value),
+	)
+	if err = submitter.SubmitSigned(signed); err != nil {
+		return err
+	}
+	if err = cb.WaitCompleteCall(ctx); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("failed to execute smart contract, hash = %s", signed.Hash))
+	}
+	if _, err = b.VerifyZCNTransaction(ctx, signed.Hash); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("failed to verify smart contract, hash = %s", signed.Hash))
+	}
+	return nil
+}
+
 // FetchZCNToETHRate retrieves latest ZCN to ETH rate using Bancor API
 func (b *BridgeClient) FetchZCNToSourceTokenRate(sourceTokenAddress string) (*big.Float, error) {
 	client = h.CleanClient()
@@ -692,14 +802,14 @@ func (b *BridgeClient) FetchZCNToSourceTokenRate(sourceTokenAddress string) (*bi
 
 	var zcnSourceTokenRateFloat float64
 
-	switch sourceTokenAddress {
-	case SourceTokenETHAddress:
+	switch {
+	case strings.EqualFold(sourceTokenAddress, SourceTokenETHAddress):
 		zcnSourceTokenRateFloat, err = strconv.ParseFloat(bancorTokenDetails.Data.Rate.ETH, 64)
-	case SourceTokenBNTAddress:
+	case strings.EqualFold(sourceTokenAddress, SourceTokenBNTAddress):
 		zcnSourceTokenRateFloat, err = strconv.ParseFloat(bancorTokenDetails.Data.Rate.BNT, 64)
-	case SourceTokenUSDCAddress:
+	case strings.EqualFold(sourceTokenAddress, SourceTokenUSDCAddress):
 		zcnSourceTokenRateFloat, err = strconv.ParseFloat(bancorTokenDetails.Data.Rate.USDC, 64)
-	case SourceTokenEURCAddress:
+	case strings.EqualFold(sourceTokenAddress, SourceTokenEURCAddress):
 		zcnSourceTokenRateFloat, err = strconv.ParseFloat(bancorTokenDetails.Data.Rate.EURC, 64)
 	}
 
@@ -712,42 +822,43 @@ func (b *BridgeClient) FetchZCNToSourceTokenRate(sourceTokenAddress string) (*bi
 
 // GetMaxBancorTargetAmount retrieves max amount of a given source token for Bancor swap
 func (b *BridgeClient) GetMaxBancorTargetAmount(sourceTokenAddress string, amountSwap uint64) (*big.Int, error) {
-	amountSwapZCN, err := currency.Coin(amountSwap).ToZCN()
+	zcnEthRate, err := b.FetchZCNToSourceTokenRate(sourceTokenAddress)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to convert current zcntoken balance to ZCN")
+		return nil, errors.Wrap(err, "failed to retrieve ZCN to source zcntoken rate using Bancor API")
 	}
 
-	var zcnEthRate *big.Float
-	zcnEthRate, err = b.FetchZCNToSourceTokenRate(sourceTokenAddress)
+	decimals, err := b.tokenDecimals(context.Background(), common.HexToAddress(sourceTokenAddress))
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to retrieve ZCN to source zcntoken rate using Bancor API")
+		return nil, err
+	}
+	amountIn, err := sourceAmount(amountSwap, zcnEthRate, decimals)
+	if err != nil {
+		return nil, err
 	}
 
-	zcnEthRateFloat, _ := zcnEthRate.Float64()
-
-	return big.NewInt(int64(amountSwapZCN * zcnEthRateFloat * 1.5 * 1e18)), nil
+	// 50% slippage
+	return amountIn.Add(amountIn, new(big.Int).Div(amountIn, big.NewInt(2))), nil
 }
 
 // ApproveSwap provides opportunity to approve swap operation for ERC20 tokens
 func (b *BridgeClient) ApproveSwap(ctx context.Context, sourceTokenAddress string, maxAmountSwap *big.Int) (*types.Transaction, error) {
-	// 1. Token source token address parameter
-	tokenAddress := common.HexToAddress(sourceTokenAddress)
-
-	// 2. Spender source token address parameter
-	spender := common.HexToAddress(BancorNetworkAddress)
+	return b.approve(ctx, common.HexToAddress(sourceTokenAddress), common.HexToAddress(BancorNetworkAddress), maxAmountSwap)
+}
 
-	bancorTokenInstance, transactOpts, err := b.prepareBancorToken(ctx, "approve", tokenAddress, spender, maxAmountSwap)
+// approve allows spender to spend amount of the given ERC20 token
+func (b *BridgeClient) approve(ctx context.Context, tokenAddress, spender common.Address, amount *big.Int) (*types.Transaction, error) {
+	bancorTokenInstance, transactOpts, err := b.prepareBancorToken(ctx, "approve", tokenAddress, spender, amount)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to prepare bancor token")
 	}
 
 	Logger.Info(
 		"Starting ApproveSwap",
-		zap.Int64("amount", maxAmountSwap.Int64()),
+		zap.String("amount", amount.String()),
 		zap.String("spender", spender.String()),
 	)
 
-	tran, err := bancorTokenInstance.Approve(transactOpts, spender, maxAmountSwap)
+	tran, err := bancorTokenInstance.Approve(transactOpts, spender, amount)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to execute Approve transaction")
 	}
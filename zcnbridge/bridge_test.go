@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"math/big"
 	"net/http"
@@ -33,6 +34,7 @@ import (
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -86,6 +88,13 @@ var (
 	}
 )
 
+// TestMain keeps the package loggers from writing bridge.log into the source tree.
+func TestMain(m *testing.M) {
+	Logger.SetLogFile(io.Discard, false)
+	wallet.Logger.SetLogFile(io.Discard, false)
+	os.Exit(m.Run())
+}
+
 type ethereumClientMock struct {
 	mock.TestingT
 }
@@ -268,6 +277,7 @@ func prepareEthereumClientGeneralMockCalls(ethereumClient *mock.Mock) {
 	ethereumClient.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(400000), nil)
 	ethereumClient.On("ChainID", mock.Anything).Return(big.NewInt(400000), nil)
 	ethereumClient.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(nonce), nil)
+	ethereumClient.On("HeaderByNumber", mock.Anything, mock.Anything).Return(&types.Header{}, nil)
 	ethereumClient.On("SuggestGasPrice", mock.Anything).Return(big.NewInt(400000), nil)
 	ethereumClient.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)
 }
@@ -8,7 +8,10 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 
+	"github.com/0chain/gosdk/zcnbridge/ethsigner"
+	"github.com/0chain/gosdk/zcnbridge/gas"
 	"github.com/0chain/gosdk/zcnbridge/log"
+	"github.com/0chain/gosdk/zcnbridge/swap"
 	"github.com/0chain/gosdk/zcnbridge/transaction"
 	"github.com/ethereum/go-ethereum/ethclient"
 
@@ -28,11 +31,12 @@ const (
 )
 
 const (
-	BancorNetworkAddress   = "0xeEF417e1D5CC832e619ae18D2F140De2999dD4fB"
-	SourceTokenETHAddress  = "0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE"
-	SourceTokenUSDCAddress = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
-	SourceTokenEURCAddress = "0x1aBaEA1f7C830bD89Acc67eC4af516284b1bC33c"
-	SourceTokenBNTAddress  = "0x1f573d6fb3f13d689ff844b4ce37794d79a7ff1c"
+	BancorNetworkAddress     = "0xeEF417e1D5CC832e619ae18D2F140De2999dD4fB"
+	BancorNetworkInfoAddress = "0x8E303D296851B320e6a697bAcB979d13c9D6E760"
+	SourceTokenETHAddress    = "0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE"
+	SourceTokenUSDCAddress   = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+	SourceTokenEURCAddress   = "0x1aBaEA1f7C830bD89Acc67eC4af516284b1bC33c"
+	SourceTokenBNTAddress    = "0x1f573d6fb3f13d689ff844b4ce37794d79a7ff1c"
 )
 
 const BancorAPIURL = "https://api-v3.bancor.network"
@@ -43,6 +47,8 @@ type BridgeSDKConfig struct {
 	ConfigChainFile *string
 	ConfigDir       *string
 	Development     *bool
+	// Signer selects the Ethereum signer, read from the bridge.signer chain config if nil
+	Signer *ethsigner.Config
 }
 
 // EthereumClient describes Ethereum JSON-RPC client generealized interface
@@ -69,6 +75,18 @@ type BridgeClient struct {
 
 	ConsensusThreshold float64
 	GasLimit           uint64
+
+	// GasStrategy sets the fees of Ethereum transactions, gas.Default() if nil
+	GasStrategy gas.Strategy
+	// Signer signs Ethereum transactions, the key store account EthereumAddress if nil
+	Signer ethsigner.Signer
+
+	// Uniswap contracts quoted by SwapToWZCN besides Bancor, skipped if empty
+	UniswapV2RouterAddress,
+	UniswapV3RouterAddress,
+	UniswapV3QuoterAddress string
+	// SwapProviders replaces the Bancor and Uniswap providers of SwapToWZCN if set
+	SwapProviders []swap.Provider
 }
 
 // NewBridgeClient creates BridgeClient with the given parameters.
@@ -144,20 +162,57 @@ func SetupBridgeClientSDK(cfg *BridgeSDKConfig) *BridgeClient {
 		log.Logger.Fatal("err happened during home directory retrieval")
 	}
 
-	keyStore := NewKeyStore(path.Join(homedir, EthereumWalletStorageDir))
+	signerCfg := cfg.Signer
+	if signerCfg == nil {
+		signerCfg = &ethsigner.Config{}
+		if err := chainCfg.UnmarshalKey("bridge.signer", signerCfg); err != nil {
+			log.Logger.Fatal(fmt.Errorf("%w: can't read signer config", err).Error())
+		}
+	}
 
-	return NewBridgeClient(
+	ethereumAddress := chainCfg.GetString("bridge.ethereum_address")
+	password := chainCfg.GetString("bridge.password")
+	keyStoreDir := path.Join(homedir, EthereumWalletStorageDir)
+
+	var ethereumSigner ethsigner.Signer
+	switch signerCfg.Type {
+	case "", ethsigner.TypeKeyStore:
+		// the key store signer of the bridge account settings
+		if signerCfg.Address != "" {
+			ethereumAddress = signerCfg.Address
+		}
+		if signerCfg.Password != "" {
+			password = signerCfg.Password
+		}
+		if signerCfg.KeyStoreDir != "" {
+			keyStoreDir = signerCfg.KeyStoreDir
+		}
+	default:
+		ethereumSigner, err = ethsigner.New(signerCfg)
+		if err != nil {
+			log.Logger.Fatal(fmt.Errorf("%w: can't create signer", err).Error())
+		}
+		ethereumAddress = ethereumSigner.Address().Hex()
+	}
+
+	bridgeClient := NewBridgeClient(
 		chainCfg.GetString("bridge.bridge_address"),
 		chainCfg.GetString("bridge.token_address"),
 		chainCfg.GetString("bridge.authorizers_address"),
-		chainCfg.GetString("bridge.ethereum_address"),
+		ethereumAddress,
 		ethereumNodeURL,
-		chainCfg.GetString("bridge.password"),
+		password,
 		chainCfg.GetUint64("bridge.gas_limit"),
 		chainCfg.GetFloat64("bridge.consensus_threshold"),
 		BancorAPIURL,
 		ethereumClient,
 		transactionProvider,
-		keyStore,
+		NewKeyStore(keyStoreDir),
 	)
+	bridgeClient.Signer = ethereumSigner
+	bridgeClient.UniswapV2RouterAddress = chainCfg.GetString("bridge.uniswap_v2_router_address")
+	bridgeClient.UniswapV3RouterAddress = chainCfg.GetString("bridge.uniswap_v3_router_address")
+	bridgeClient.UniswapV3QuoterAddress = chainCfg.GetString("bridge.uniswap_v3_quoter_address")
+
+	return bridgeClient
 }
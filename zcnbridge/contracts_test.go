@@ -0,0 +1,243 @@
+package zcnbridge
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/0chain/gosdk/zcnbridge/ethereum/nftconfig"
+	"github.com/0chain/gosdk/zcnbridge/ethereum/simulated"
+	"github.com/0chain/gosdk/zcnbridge/ethereum/zcntoken"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/stretchr/testify/require"
+)
+
+// The token and nftconfig bindings were generated from their ABI only, so
+// the tests deploy stand-ins assembled after the Solidity sources of the
+// bindings. They only back the tests of the SDK calls going through the
+// unmodified bindings.
+
+// tokenName, tokenSymbol and tokenDecimals describe the test token.
+const (
+	tokenName     = "0chain"
+	tokenSymbol   = "ZCN"
+	tokenDecimals = 10
+)
+
+var (
+	ownershipTransferred = eventID("OwnershipTransferred(address,address)")
+	transferEvent        = eventID("Transfer(address,address,uint256)")
+	approvalEvent        = eventID("Approval(address,address,uint256)")
+)
+
+// ownable adds the methods of OpenZeppelin's Ownable, with the owner at slot.
+func ownable(p *program, handlers map[string]func(), slot int, renounce bool) {
+	handlers["owner"] = func() {
+		p.sload(slot).returnWord()
+	}
+	handlers["transferOwnership"] = func() {
+		p.onlyOwner(slot)
+		p.argAddress(0).op(vm.DUP1).require("Ownable: new owner is the zero address")
+		p.argAddress(0).sload(slot).push(ownershipTransferred).push(0).push(0).op(vm.LOG3)
+		p.argAddress(0).sstore(slot).op(vm.STOP)
+	}
+	if renounce {
+		handlers["renounceOwnership"] = func() {
+			p.onlyOwner(slot)
+			p.push(0).sload(slot).push(ownershipTransferred).push(0).push(0).op(vm.LOG3)
+			p.push(0).sstore(slot).op(vm.STOP)
+		}
+	}
+}
+
+// setOwner makes the deployer the owner at slot.
+func setOwner(p *program, slot int) {
+	p.op(vm.CALLER).sstore(slot)
+	p.op(vm.CALLER).push(0).push(ownershipTransferred).push(0).push(0).op(vm.LOG3)
+}
+
+// storage slots of the token
+const (
+	tokenOwner = iota
+	tokenTotalSupply
+	tokenMintingFinished
+	tokenBalances
+	tokenAllowed
+)
+
+// tokenBin is the ZCN token: a mintable ERC-20 with increaseApproval and
+// decreaseApproval.
+var tokenBin = func() []byte {
+	parsed := mustABI(zcntoken.TokenMetaData)
+	p := newProgram()
+	h := make(map[string]func())
+	ownable(p, h, tokenOwner, false)
+
+	mint := eventID("Mint(address,uint256)")
+	mintFinished := eventID("MintFinished()")
+
+	// move transfers the value from the account to the account.
+	move := func(from, to func(), value func()) {
+		to()
+		p.op(vm.DUP1).require("transfer to the zero address").op(vm.POP)
+		from()
+		p.mapping(tokenBalances).op(vm.DUP1, vm.SLOAD) // [balance, slot]
+		p.op(vm.DUP1)
+		value()
+		p.op(vm.GT, vm.ISZERO).require("transfer amount exceeds balance")
+		value()
+		p.op(vm.SWAP1, vm.SUB, vm.SWAP1, vm.SSTORE)
+		to()
+		p.mapping(tokenBalances).op(vm.DUP1, vm.SLOAD)
+		value()
+		p.op(vm.ADD, vm.SWAP1, vm.SSTORE)
+		value()
+		p.push(0).op(vm.MSTORE)
+		to()
+		from()
+		p.push(transferEvent).push(32).push(0).op(vm.LOG3)
+	}
+	// approval emits Approval with the allowance of the caller stored at 0.
+	approval := func() {
+		p.argAddress(0).op(vm.CALLER).push(approvalEvent).push(32).push(0).op(vm.LOG3)
+		p.push(1).returnWord()
+	}
+	caller := func() { p.op(vm.CALLER) }
+
+	h["name"] = func() { p.returnConst(abiString(tokenName)) }
+	h["symbol"] = func() { p.returnConst(abiString(tokenSymbol)) }
+	h["decimals"] = func() { p.push(tokenDecimals).returnWord() }
+	h["totalSupply"] = func() { p.sload(tokenTotalSupply).returnWord() }
+	h["mintingFinished"] = func() { p.sload(tokenMintingFinished).returnWord() }
+	h["balanceOf"] = func() {
+		p.argAddress(0).mapping(tokenBalances).op(vm.SLOAD).returnWord()
+	}
+	h["allowance"] = func() {
+		p.argAddress(1).argAddress(0).mapping2(tokenAllowed).op(vm.SLOAD).returnWord()
+	}
+	h["transfer"] = func() {
+		move(caller, func() { p.argAddress(0) }, func() { p.arg(1) })
+		p.push(1).returnWord()
+	}
+	h["transferFrom"] = func() {
+		p.op(vm.CALLER).argAddress(0).mapping2(tokenAllowed).op(vm.DUP1, vm.SLOAD) // [allowance, slot]
+		p.op(vm.DUP1).arg(2).op(vm.GT, vm.ISZERO).require("transfer amount exceeds allowance")
+		p.arg(2).op(vm.SWAP1, vm.SUB, vm.SWAP1, vm.SSTORE)
+		move(func() { p.argAddress(0) }, func() { p.argAddress(1) }, func() { p.arg(2) })
+		p.push(1).returnWord()
+	}
+	h["approve"] = func() {
+		p.arg(1).argAddress(0).op(vm.CALLER).mapping2(tokenAllowed).op(vm.SSTORE)
+		p.arg(1).push(0).op(vm.MSTORE)
+		approval()
+	}
+	h["increaseApproval"] = func() {
+		p.argAddress(0).op(vm.CALLER).mapping2(tokenAllowed).op(vm.DUP1, vm.SLOAD) // [allowance, slot]
+		p.arg(1).op(vm.ADD, vm.DUP1).push(0).op(vm.MSTORE, vm.SWAP1, vm.SSTORE)
+		approval()
+	}
+	h["decreaseApproval"] = func() {
+		sub, set := p.newLabel(), p.newLabel()
+		p.argAddress(0).op(vm.CALLER).mapping2(tokenAllowed).op(vm.DUP1, vm.SLOAD) // [allowance, slot]
+		p.op(vm.DUP1).arg(1).op(vm.LT).jumpi(sub)
+		p.op(vm.POP).push(0).jump(set)
+		p.label(sub)
+		p.arg(1).op(vm.SWAP1, vm.SUB)
+		p.label(set)
+		p.op(vm.DUP1).push(0).op(vm.MSTORE, vm.SWAP1, vm.SSTORE)
+		approval()
+	}
+	h["mint"] = func() {
+		p.onlyOwner(tokenOwner)
+		p.sload(tokenMintingFinished).op(vm.ISZERO).require("minting finished")
+		p.sload(tokenTotalSupply).arg(1).op(vm.ADD).sstore(tokenTotalSupply)
+		p.argAddress(0).mapping(tokenBalances).op(vm.DUP1, vm.SLOAD).arg(1).op(vm.ADD, vm.SWAP1, vm.SSTORE)
+		p.arg(1).push(0).op(vm.MSTORE)
+		p.argAddress(0).push(mint).push(32).push(0).op(vm.LOG2)
+		p.argAddress(0).push(0).push(transferEvent).push(32).push(0).op(vm.LOG3)
+		p.push(1).returnWord()
+	}
+	h["finishMinting"] = func() {
+		p.onlyOwner(tokenOwner)
+		p.sload(tokenMintingFinished).op(vm.ISZERO).require("minting finished")
+		p.push(1).sstore(tokenMintingFinished)
+		p.push(mintFinished).push(0).push(0).op(vm.LOG1)
+		p.push(1).returnWord()
+	}
+
+	p.dispatch(parsed, h)
+	return deployCode(p.assemble(), func(p *program) { setOwner(p, tokenOwner) })
+}()
+
+// storage slots of the nftconfig
+const (
+	configOwner = iota
+	configValues
+)
+
+// nftConfigBin is the NFTConfig key value store. Addresses are stored as
+// uint256 values.
+var nftConfigBin = func() []byte {
+	parsed := mustABI(nftconfig.NFTConfigMetaData)
+	p := newProgram()
+	h := make(map[string]func())
+	ownable(p, h, configOwner, true)
+
+	configUpdated := eventID("ConfigUpdated(bytes32,uint256,uint256)")
+	set := func(value func()) {
+		p.onlyOwner(configOwner)
+		p.arg(0).mapping(configValues).op(vm.DUP1, vm.SLOAD).push(0).op(vm.MSTORE) // [slot]
+		value()
+		p.op(vm.DUP1).push(32).op(vm.MSTORE, vm.SWAP1, vm.SSTORE)
+		p.arg(0).push(configUpdated).push(64).push(0).op(vm.LOG2, vm.STOP)
+	}
+	h["setUint256"] = func() { set(func() { p.arg(1) }) }
+	h["setAddress"] = func() { set(func() { p.argAddress(1) }) }
+	h["getUint256"] = func() {
+		p.arg(0).mapping(configValues).op(vm.SLOAD).returnWord()
+	}
+	h["getAddress"] = func() {
+		p.arg(0).mapping(configValues).op(vm.SLOAD).push(addressMask).op(vm.AND).returnWord()
+	}
+
+	p.dispatch(parsed, h)
+	return deployCode(p.assemble(), func(p *program) { setOwner(p, configOwner) })
+}()
+
+func mustABI(metadata *bind.MetaData) abi.ABI {
+	parsed, err := metadata.GetAbi()
+	if err != nil {
+		panic(err)
+	}
+	return *parsed
+}
+
+// deployTestToken deploys the test token owned by the owner of the backend.
+func deployTestToken(t *testing.T, backend *simulated.Backend) common.Address {
+	return deployTestContract(t, backend, zcntoken.TokenMetaData, tokenBin)
+}
+
+// deployTestNFTConfig deploys the test nftconfig owned by the owner of the backend.
+func deployTestNFTConfig(t *testing.T, backend *simulated.Backend) common.Address {
+	return deployTestContract(t, backend, nftconfig.NFTConfigMetaData, nftConfigBin)
+}
+
+// mintTestTokens mints amount tokens of the test token to the address.
+func mintTestTokens(t *testing.T, backend *simulated.Backend, token, to common.Address, amount *big.Int) {
+	instance, err := zcntoken.NewToken(token, backend)
+	require.NoError(t, err)
+	opts, err := backend.TransactOpts(backend.Owner)
+	require.NoError(t, err)
+	_, err = instance.Mint(opts, to, amount)
+	require.NoError(t, err)
+}
+
+func deployTestContract(t *testing.T, backend *simulated.Backend, metadata *bind.MetaData, bin []byte, params ...interface{}) common.Address {
+	opts, err := backend.TransactOpts(backend.Owner)
+	require.NoError(t, err)
+	address, _, _, err := bind.DeployContract(opts, mustABI(metadata), bin, backend, params...)
+	require.NoError(t, err)
+	return address
+}
@@ -0,0 +1,174 @@
+// Package simulated runs an in-memory Ethereum chain, so the SDK can be
+// tested offline.
+//
+// DeployBridge deploys the bridge and authorizers contracts from the compiled
+// bytecode of their bindings. Other contracts are deployed with DeployContract
+// from their ABI and compiled bytecode.
+package simulated
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/0chain/gosdk/zcnbridge/ethereum/authorizers"
+	"github.com/0chain/gosdk/zcnbridge/ethereum/bridge"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ChainID is the chain id of the simulated chain.
+	ChainID = 1337
+	// Password unlocks the accounts of the backend key store.
+	Password = "password"
+	// GasLimit is the block gas limit of the simulated chain.
+	GasLimit = 30_000_000
+)
+
+// DefaultBalance is the balance of the accounts created by the backend, 1000 ETH.
+var DefaultBalance = new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))
+
+// Backend is a simulated Ethereum chain mining every transaction right away.
+// It implements the EthereumClient interfaces of zcnbridge and znft.
+type Backend struct {
+	*backends.SimulatedBackend
+
+	// KeyStore holds the keys of the accounts, unlocked with Password
+	KeyStore *keystore.KeyStore
+	// KeyStoreDir is the directory of KeyStore
+	KeyStoreDir string
+	// Owner deploys the contracts and owns them
+	Owner accounts.Account
+
+	// Addresses of the contracts deployed by DeployBridge
+	AuthorizersAddress common.Address
+	BridgeAddress      common.Address
+
+	mu sync.Mutex
+}
+
+// New creates a simulated chain with a funded owner account stored in keyStoreDir.
+func New(keyStoreDir string) (*Backend, error) {
+	// light scrypt parameters keep unlocking fast in tests
+	ks := keystore.NewKeyStore(keyStoreDir, keystore.LightScryptN, keystore.LightScryptP)
+	owner, err := ks.NewAccount(Password)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create owner account")
+	}
+
+	alloc := core.GenesisAlloc{
+		owner.Address: {Balance: new(big.Int).Mul(DefaultBalance, big.NewInt(1000))},
+	}
+	return &Backend{
+		SimulatedBackend: backends.NewSimulatedBackend(alloc, GasLimit),
+		KeyStore:         ks,
+		KeyStoreDir:      keyStoreDir,
+		Owner:            owner,
+	}, nil
+}
+
+// ChainID returns the chain id of the simulated chain.
+func (b *Backend) ChainID(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(ChainID), nil
+}
+
+// SendTransaction sends tx and mines it in a new block.
+func (b *Backend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.SimulatedBackend.SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+	b.Commit()
+	return nil
+}
+
+// TransactOpts returns transact options signing with the key of account.
+func (b *Backend) TransactOpts(account accounts.Account) (*bind.TransactOpts, error) {
+	if err := b.KeyStore.Unlock(account, Password); err != nil {
+		return nil, errors.Wrapf(err, "failed to unlock %s", account.Address.Hex())
+	}
+	return bind.NewKeyStoreTransactorWithChainID(b.KeyStore, account, big.NewInt(ChainID))
+}
+
+// NewAccount creates an account in the key store funded with DefaultBalance.
+func (b *Backend) NewAccount() (accounts.Account, error) {
+	account, err := b.KeyStore.NewAccount(Password)
+	if err != nil {
+		return accounts.Account{}, errors.Wrap(err, "failed to create account")
+	}
+	if err := b.Fund(account.Address, DefaultBalance); err != nil {
+		return accounts.Account{}, err
+	}
+	return account, nil
+}
+
+// Fund transfers amount wei from the owner to address.
+func (b *Backend) Fund(address common.Address, amount *big.Int) error {
+	ctx := context.Background()
+	nonce, err := b.PendingNonceAt(ctx, b.Owner.Address)
+	if err != nil {
+		return errors.Wrap(err, "failed to get owner nonce")
+	}
+	price, err := b.SuggestGasPrice(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to suggest gas price")
+	}
+	opts, err := b.TransactOpts(b.Owner)
+	if err != nil {
+		return err
+	}
+	tx, err := opts.Signer(b.Owner.Address, types.NewTransaction(nonce, address, amount, params.TxGas, price, nil))
+	if err != nil {
+		return errors.Wrap(err, "failed to sign transfer")
+	}
+	return b.SendTransaction(ctx, tx)
+}
+
+// DeployBridge deploys the authorizers and the bridge contracts owned by the
+// owner account. The bridge mints and burns tokens of the token contract.
+func (b *Backend) DeployBridge(token common.Address) error {
+	opts, err := b.TransactOpts(b.Owner)
+	if err != nil {
+		return err
+	}
+
+	b.AuthorizersAddress, _, _, err = authorizers.DeployAuthorizers(opts, b)
+	if err != nil {
+		return errors.Wrap(err, "failed to deploy authorizers")
+	}
+	b.BridgeAddress, _, _, err = bridge.DeployBridge(opts, b, token, b.AuthorizersAddress)
+	if err != nil {
+		return errors.Wrap(err, "failed to deploy bridge")
+	}
+	return nil
+}
+
+// DeployContract deploys a contract from its ABI and hex encoded bytecode
+// with the owner account.
+func (b *Backend) DeployContract(abiJSON, bin string, params ...interface{}) (common.Address, error) {
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "failed to parse ABI")
+	}
+	opts, err := b.TransactOpts(b.Owner)
+	if err != nil {
+		return common.Address{}, err
+	}
+	address, _, _, err := bind.DeployContract(opts, parsed, common.FromHex(bin), b, params...)
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "failed to deploy contract")
+	}
+	return address, nil
+}
@@ -0,0 +1,71 @@
+package ethsigner
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/external"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+// ExternalSigner signs with an external signer speaking the clef JSON-RPC API.
+type ExternalSigner struct {
+	api     *external.ExternalSigner
+	account accounts.Account
+}
+
+// NewExternalSigner connects to the signer at url. The account with address
+// signs, the first account of the signer if address is empty.
+func NewExternalSigner(url, address string) (*ExternalSigner, error) {
+	api, err := external.NewExternalSigner(url)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to external signer %s", url)
+	}
+
+	account := accounts.Account{Address: common.HexToAddress(address)}
+	if address == "" {
+		list := api.Accounts()
+		if len(list) == 0 {
+			return nil, errors.Errorf("external signer %s has no accounts", url)
+		}
+		account = list[0]
+	}
+	return &ExternalSigner{api: api, account: account}, nil
+}
+
+// Address implements Signer.
+func (s *ExternalSigner) Address() common.Address {
+	return s.account.Address
+}
+
+// SignTx implements Signer.
+func (s *ExternalSigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return s.api.SignTx(s.account, tx, chainID)
+}
+
+// SignHash implements Signer. Clef signs data with account_signData, which
+// always signs a digest of the data, so it can not sign raw hashes. Use
+// SignText for messages signed by clef.
+func (s *ExternalSigner) SignHash(hash []byte) ([]byte, error) {
+	return nil, errors.Wrap(ErrUnsupported, "external signers can not sign hashes")
+}
+
+// SignText implements Signer. Clef signs the text with account_signData of
+// the text/plain content type, the EIP-191 hash of the text.
+func (s *ExternalSigner) SignText(text []byte) ([]byte, error) {
+	sig, err := s.api.SignData(s.account, accounts.MimetypeTextPlain, text)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign text with external signer")
+	}
+	if len(sig) != crypto.SignatureLength {
+		return nil, errors.Errorf("external signer returned a signature of %d bytes", len(sig))
+	}
+	// clef returns V in the 27/28 form of personal_sign
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	return sig, nil
+}
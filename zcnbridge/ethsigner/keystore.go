@@ -0,0 +1,74 @@
+package ethsigner
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// unlockTimeout is the time an account stays unlocked to sign one transaction.
+const unlockTimeout = 2 * time.Second
+
+// KeyStore is the part of a key store used by KeyStoreSigner, implemented by
+// keystore.KeyStore.
+type KeyStore interface {
+	Find(a accounts.Account) (accounts.Account, error)
+	TimedUnlock(a accounts.Account, passphrase string, timeout time.Duration) error
+	SignTx(a accounts.Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+	SignHash(a accounts.Account, hash []byte) ([]byte, error)
+}
+
+// KeyStoreSigner signs with an account of a key store unlocked with a password.
+type KeyStoreSigner struct {
+	ks       KeyStore
+	address  common.Address
+	password string
+}
+
+// NewKeyStoreSigner creates a signer of the account with address stored in ks.
+func NewKeyStoreSigner(ks KeyStore, address common.Address, password string) *KeyStoreSigner {
+	return &KeyStoreSigner{ks: ks, address: address, password: password}
+}
+
+// Address implements Signer.
+func (s *KeyStoreSigner) Address() common.Address {
+	return s.address
+}
+
+// SignTx implements Signer.
+func (s *KeyStoreSigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	account, err := s.unlock()
+	if err != nil {
+		return nil, err
+	}
+	return s.ks.SignTx(account, tx, chainID)
+}
+
+// SignHash implements Signer.
+func (s *KeyStoreSigner) SignHash(hash []byte) ([]byte, error) {
+	account, err := s.unlock()
+	if err != nil {
+		return nil, err
+	}
+	return s.ks.SignHash(account, hash)
+}
+
+// SignText implements Signer.
+func (s *KeyStoreSigner) SignText(text []byte) ([]byte, error) {
+	return s.SignHash(accounts.TextHash(text))
+}
+
+func (s *KeyStoreSigner) unlock() (accounts.Account, error) {
+	account, err := s.ks.Find(accounts.Account{Address: s.address})
+	if err != nil {
+		return accounts.Account{}, errors.Wrapf(err, "ethsigner: %s", s.address.Hex())
+	}
+	if err := s.ks.TimedUnlock(account, s.password, unlockTimeout); err != nil {
+		return accounts.Account{}, errors.Wrapf(err, "failed to unlock %s", s.address.Hex())
+	}
+	return account, nil
+}
@@ -0,0 +1,83 @@
+package ethsigner
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"strings"
+
+	hdw "github.com/0chain/gosdk/zcncore/ethhdwallet"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+// DefaultDerivationPath is the path of the first Ethereum account of an HD wallet.
+const DefaultDerivationPath = "m/44'/60'/0'/0/0"
+
+// PrivateKeySigner signs with a private key held in memory.
+type PrivateKeySigner struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+// NewPrivateKeySigner creates a signer of key.
+func NewPrivateKeySigner(key *ecdsa.PrivateKey) *PrivateKeySigner {
+	return &PrivateKeySigner{key: key, address: crypto.PubkeyToAddress(key.PublicKey)}
+}
+
+// NewPrivateKeySignerFromHex creates a signer of the hex encoded key.
+func NewPrivateKeySignerFromHex(hexKey string) (*PrivateKeySigner, error) {
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(hexKey, "0x"))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid private key")
+	}
+	return NewPrivateKeySigner(key), nil
+}
+
+// NewHDWalletSigner creates a signer of the account derived from mnemonic at
+// path, DefaultDerivationPath if empty.
+func NewHDWalletSigner(mnemonic, path string) (*PrivateKeySigner, error) {
+	if path == "" {
+		path = DefaultDerivationPath
+	}
+	derivationPath, err := hdw.ParseDerivationPath(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid derivation path %s", path)
+	}
+	wallet, err := hdw.NewFromMnemonic(mnemonic)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid mnemonic")
+	}
+	account, err := wallet.Derive(derivationPath, true)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to derive account")
+	}
+	// the wallet signs with the homestead signer, sign with the derived key instead
+	key, err := wallet.PrivateKey(account)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get account key")
+	}
+	return NewPrivateKeySigner(key), nil
+}
+
+// Address implements Signer.
+func (s *PrivateKeySigner) Address() common.Address {
+	return s.address
+}
+
+// SignTx implements Signer.
+func (s *PrivateKeySigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), s.key)
+}
+
+// SignHash implements Signer.
+func (s *PrivateKeySigner) SignHash(hash []byte) ([]byte, error) {
+	return crypto.Sign(hash, s.key)
+}
+
+// SignText implements Signer.
+func (s *PrivateKeySigner) SignText(text []byte) ([]byte, error) {
+	return crypto.Sign(accounts.TextHash(text), s.key)
+}
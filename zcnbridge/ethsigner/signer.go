@@ -0,0 +1,97 @@
+// Package ethsigner signs the Ethereum transactions of the bridge and NFT
+// clients with a key store, a private key in memory, an HD wallet account or
+// an external signer like clef.
+package ethsigner
+
+import (
+	"context"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// Types of signers selectable in Config.
+const (
+	TypeKeyStore   = "keystore"
+	TypePrivateKey = "private_key"
+	TypeHDWallet   = "hd_wallet"
+	TypeExternal   = "external"
+)
+
+var (
+	// ErrUnknownType the signer type of the configuration is not supported
+	ErrUnknownType = errors.New("ethsigner: unknown signer type")
+	// ErrUnsupported the signer can not perform the operation
+	ErrUnsupported = errors.New("ethsigner: operation not supported")
+)
+
+// Signer signs Ethereum transactions and hashes with the key of one account.
+type Signer interface {
+	// Address returns the address of the signing account.
+	Address() common.Address
+	// SignTx signs tx for the chain with chainID.
+	SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+	// SignHash signs a 32 byte hash.
+	SignHash(hash []byte) ([]byte, error)
+	// SignText signs the EIP-191 hash of text, like personal_sign.
+	SignText(text []byte) ([]byte, error)
+}
+
+// NewTransactOpts creates transact options sending from and signed by s.
+func NewTransactOpts(ctx context.Context, s Signer, chainID *big.Int) *bind.TransactOpts {
+	from := s.Address()
+	return &bind.TransactOpts{
+		From: from,
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != from {
+				return nil, bind.ErrNotAuthorized
+			}
+			return s.SignTx(tx, chainID)
+		},
+		Context: ctx,
+	}
+}
+
+// Config selects and configures a signer.
+type Config struct {
+	// Type is one of TypeKeyStore (default), TypePrivateKey, TypeHDWallet and TypeExternal
+	Type string `json:"type" yaml:"type" mapstructure:"type"`
+	// Address of the signing account, required by key store and external signers
+	Address string `json:"address" yaml:"address" mapstructure:"address"`
+
+	// KeyStoreDir and Password of key store signers
+	KeyStoreDir string `json:"keystore_dir" yaml:"keystore_dir" mapstructure:"keystore_dir"`
+	Password    string `json:"password" yaml:"password" mapstructure:"password"`
+
+	// PrivateKey is the hex encoded key of private key signers
+	PrivateKey string `json:"private_key" yaml:"private_key" mapstructure:"private_key"`
+
+	// Mnemonic and DerivationPath of HD wallet signers, DefaultDerivationPath if empty
+	Mnemonic       string `json:"mnemonic" yaml:"mnemonic" mapstructure:"mnemonic"`
+	DerivationPath string `json:"derivation_path" yaml:"derivation_path" mapstructure:"derivation_path"`
+
+	// ExternalURL is the JSON-RPC endpoint of external signers
+	ExternalURL string `json:"external_url" yaml:"external_url" mapstructure:"external_url"`
+}
+
+// New creates the signer selected by cfg.
+func New(cfg *Config) (Signer, error) {
+	switch strings.ToLower(cfg.Type) {
+	case "", TypeKeyStore:
+		ks := keystore.NewKeyStore(cfg.KeyStoreDir, keystore.StandardScryptN, keystore.StandardScryptP)
+		return NewKeyStoreSigner(ks, common.HexToAddress(cfg.Address), cfg.Password), nil
+	case TypePrivateKey:
+		return NewPrivateKeySignerFromHex(cfg.PrivateKey)
+	case TypeHDWallet:
+		return NewHDWalletSigner(cfg.Mnemonic, cfg.DerivationPath)
+	case TypeExternal:
+		return NewExternalSigner(cfg.ExternalURL, cfg.Address)
+	default:
+		return nil, errors.Wrap(ErrUnknownType, cfg.Type)
+	}
+}
@@ -0,0 +1,172 @@
+package ethsigner
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/require"
+)
+
+const testMnemonic = "tag volcano eight thank tide danger coast health above argue embrace heavy"
+
+// fakeClef serves the account API of clef.
+type fakeClef struct {
+	key *ecdsa.PrivateKey
+}
+
+func (c *fakeClef) Version() string {
+	return "6.0.0"
+}
+
+func (c *fakeClef) List() []common.Address {
+	return []common.Address{crypto.PubkeyToAddress(c.key.PublicKey)}
+}
+
+func (c *fakeClef) SignTransaction(args apitypes.SendTxArgs, methodSelector *string) (map[string]interface{}, error) {
+	tx, err := types.SignTx(args.ToTransaction(), types.LatestSignerForChainID((*big.Int)(args.ChainID)), c.key)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"raw": hexutil.Bytes(raw), "tx": tx}, nil
+}
+
+func (c *fakeClef) SignData(contentType string, addr common.MixedcaseAddress, data hexutil.Bytes) (hexutil.Bytes, error) {
+	if contentType != accounts.MimetypeTextPlain {
+		return nil, errors.New("unsupported content type")
+	}
+	sig, err := crypto.Sign(accounts.TextHash(data), c.key)
+	if err != nil {
+		return nil, err
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	return sig, nil
+}
+
+func newTx() *types.Transaction {
+	to := common.HexToAddress("0x01")
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID: big.NewInt(5), Nonce: 1, GasFeeCap: big.NewInt(100), GasTipCap: big.NewInt(2), Gas: 21000, To: &to,
+	})
+}
+
+func requireSigned(t *testing.T, s Signer) {
+	chainID := big.NewInt(5)
+	tx, err := s.SignTx(newTx(), chainID)
+	require.NoError(t, err)
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), tx)
+	require.NoError(t, err)
+	require.Equal(t, s.Address(), sender)
+}
+
+func requireSignedHash(t *testing.T, s Signer) {
+	hash := crypto.Keccak256([]byte("message"))
+	sig, err := s.SignHash(hash)
+	require.NoError(t, err)
+	pub, err := crypto.SigToPub(hash, sig)
+	require.NoError(t, err)
+	require.Equal(t, s.Address(), crypto.PubkeyToAddress(*pub))
+}
+
+func requireSignedText(t *testing.T, s Signer) {
+	text := []byte("message")
+	sig, err := s.SignText(text)
+	require.NoError(t, err)
+	pub, err := crypto.SigToPub(accounts.TextHash(text), sig)
+	require.NoError(t, err)
+	require.Equal(t, s.Address(), crypto.PubkeyToAddress(*pub))
+}
+
+func TestSigners(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	t.Run("private key", func(t *testing.T) {
+		s, err := New(&Config{Type: TypePrivateKey, PrivateKey: hexutil.Encode(crypto.FromECDSA(key))})
+		require.NoError(t, err)
+		require.Equal(t, crypto.PubkeyToAddress(key.PublicKey), s.Address())
+		requireSigned(t, s)
+		requireSignedHash(t, s)
+		requireSignedText(t, s)
+	})
+
+	t.Run("hd wallet", func(t *testing.T) {
+		s, err := New(&Config{Type: TypeHDWallet, Mnemonic: testMnemonic})
+		require.NoError(t, err)
+		requireSigned(t, s)
+		requireSignedHash(t, s)
+		requireSignedText(t, s)
+
+		other, err := NewHDWalletSigner(testMnemonic, "m/44'/60'/0'/0/1")
+		require.NoError(t, err)
+		require.NotEqual(t, s.Address(), other.Address())
+	})
+
+	t.Run("key store", func(t *testing.T) {
+		dir := t.TempDir()
+		ks := keystore.NewKeyStore(dir, keystore.LightScryptN, keystore.LightScryptP)
+		account, err := ks.NewAccount("password")
+		require.NoError(t, err)
+
+		s, err := New(&Config{KeyStoreDir: dir, Address: account.Address.Hex(), Password: "password"})
+		require.NoError(t, err)
+		requireSigned(t, s)
+		requireSignedHash(t, s)
+		requireSignedText(t, s)
+
+		s = NewKeyStoreSigner(ks, account.Address, "wrong")
+		_, err = s.SignTx(newTx(), big.NewInt(5))
+		require.Error(t, err)
+	})
+
+	t.Run("external", func(t *testing.T) {
+		server := rpc.NewServer()
+		require.NoError(t, server.RegisterName("account", &fakeClef{key: key}))
+		ts := httptest.NewServer(server)
+		defer ts.Close()
+
+		s, err := New(&Config{Type: TypeExternal, ExternalURL: ts.URL})
+		require.NoError(t, err)
+		require.Equal(t, crypto.PubkeyToAddress(key.PublicKey), s.Address())
+		requireSigned(t, s)
+		requireSignedText(t, s)
+
+		_, err = s.SignHash(crypto.Keccak256([]byte("message")))
+		require.ErrorIs(t, err, ErrUnsupported)
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		_, err := New(&Config{Type: "ledger"})
+		require.ErrorIs(t, err, ErrUnknownType)
+	})
+}
+
+func TestNewTransactOpts(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	s := NewPrivateKeySigner(key)
+
+	opts := NewTransactOpts(context.Background(), s, big.NewInt(5))
+	require.Equal(t, s.Address(), opts.From)
+
+	_, err = opts.Signer(s.Address(), newTx())
+	require.NoError(t, err)
+	_, err = opts.Signer(common.HexToAddress("0x02"), newTx())
+	require.ErrorIs(t, err, bind.ErrNotAuthorized)
+}
@@ -0,0 +1,338 @@
+package zcnbridge
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// errorSelector is the selector of the Error(string) revert reason.
+var errorSelector = crypto.Keccak256([]byte("Error(string)"))[:4]
+
+// addressMask keeps the low 20 bytes of a word.
+var addressMask = common.LeftPadBytes(bytes.Repeat([]byte{0xff}, common.AddressLength), 32)
+
+// program assembles EVM bytecode. Jump targets are labels resolved by
+// assemble, revert reasons are emitted once at the end of the code.
+//
+// Stack effects are noted as [top, ...] in the comments of the macros.
+type program struct {
+	code    []byte
+	labels  map[string]int
+	refs    map[int]string
+	reverts map[string]string
+	n       int
+}
+
+func newProgram() *program {
+	return &program{
+		labels:  make(map[string]int),
+		refs:    make(map[int]string),
+		reverts: make(map[string]string),
+	}
+}
+
+func (p *program) op(ops ...vm.OpCode) *program {
+	for _, o := range ops {
+		p.code = append(p.code, byte(o))
+	}
+	return p
+}
+
+// push pushes an unsigned integer, or up to 32 big endian bytes as they are.
+func (p *program) push(v interface{}) *program {
+	var b []byte
+	switch v := v.(type) {
+	case int:
+		b = big.NewInt(int64(v)).Bytes()
+	case *big.Int:
+		b = v.Bytes()
+	case []byte:
+		b = v
+	case common.Hash:
+		b = v.Bytes()
+	default:
+		panic(fmt.Sprintf("zcnbridge: can not push %T", v))
+	}
+	if len(b) == 0 {
+		b = []byte{0}
+	}
+	if len(b) > 32 {
+		panic("zcnbridge: push of more than 32 bytes")
+	}
+	p.code = append(p.code, byte(vm.PUSH1)+byte(len(b)-1))
+	p.code = append(p.code, b...)
+	return p
+}
+
+// pushLabel pushes the offset of the label.
+func (p *program) pushLabel(name string) *program {
+	p.refs[len(p.code)+1] = name
+	p.code = append(p.code, byte(vm.PUSH2), 0, 0)
+	return p
+}
+
+// label marks a jump destination.
+func (p *program) label(name string) *program {
+	p.mark(name)
+	return p.op(vm.JUMPDEST)
+}
+
+// mark names the current offset without a jump destination.
+func (p *program) mark(name string) {
+	if _, ok := p.labels[name]; ok {
+		panic("zcnbridge: duplicate label " + name)
+	}
+	p.labels[name] = len(p.code)
+}
+
+func (p *program) newLabel() string {
+	p.n++
+	return fmt.Sprintf("L%d", p.n)
+}
+
+func (p *program) jump(name string) *program {
+	return p.pushLabel(name).op(vm.JUMP)
+}
+
+// jumpi jumps to the label if the top of the stack is not zero. [cond]
+func (p *program) jumpi(name string) *program {
+	return p.pushLabel(name).op(vm.JUMPI)
+}
+
+// require reverts with msg if the top of the stack is zero. [cond]
+func (p *program) require(msg string) *program {
+	l, ok := p.reverts[msg]
+	if !ok {
+		l = "revert:" + msg
+		p.reverts[msg] = l
+	}
+	return p.op(vm.ISZERO).jumpi(l)
+}
+
+// emitReverts emits the revert reasons used by require.
+func (p *program) emitReverts() {
+	msgs := make([]string, 0, len(p.reverts))
+	for msg := range p.reverts {
+		msgs = append(msgs, msg)
+	}
+	sort.Strings(msgs)
+	for _, msg := range msgs {
+		p.label(p.reverts[msg])
+		data := append(append([]byte{}, errorSelector...), abiString(msg)...)
+		p.mstoreBytes(0, data)
+		p.push(len(data)).push(0).op(vm.REVERT)
+	}
+	p.reverts = make(map[string]string)
+}
+
+// assemble emits the reverts and resolves the labels.
+func (p *program) assemble() []byte {
+	p.emitReverts()
+	for at, name := range p.refs {
+		offset, ok := p.labels[name]
+		if !ok {
+			panic("zcnbridge: undefined label " + name)
+		}
+		p.code[at], p.code[at+1] = byte(offset>>8), byte(offset)
+	}
+	return p.code
+}
+
+// mstoreBytes stores data in memory at offset, padded to words.
+func (p *program) mstoreBytes(offset int, data []byte) *program {
+	for i := 0; i < len(data); i += 32 {
+		word := make([]byte, 32)
+		copy(word, data[i:])
+		p.push(word).push(offset + i).op(vm.MSTORE)
+	}
+	return p
+}
+
+// arg loads the i-th word of the call arguments. [arg]
+func (p *program) arg(i int) *program {
+	return p.push(4 + 32*i).op(vm.CALLDATALOAD)
+}
+
+// argAddress loads the i-th call argument as an address. [address]
+func (p *program) argAddress(i int) *program {
+	return p.arg(i).push(addressMask).op(vm.AND)
+}
+
+// argBool loads the i-th call argument as 0 or 1. [bool]
+func (p *program) argBool(i int) *program {
+	return p.arg(i).op(vm.ISZERO, vm.ISZERO)
+}
+
+func (p *program) sload(slot int) *program {
+	return p.push(slot).op(vm.SLOAD)
+}
+
+// sstore stores the top of the stack in slot. [value]
+func (p *program) sstore(slot int) *program {
+	return p.push(slot).op(vm.SSTORE)
+}
+
+// mapping computes the storage slot of key in the mapping at slot. [key] -> [slot]
+func (p *program) mapping(slot int) *program {
+	p.push(0).op(vm.MSTORE)
+	p.push(slot).push(32).op(vm.MSTORE)
+	return p.push(64).push(0).op(vm.KECCAK256)
+}
+
+// mapping2 computes the slot of m[k1][k2] for the nested mapping m at
+// slot. [k1, k2] -> [slot]
+func (p *program) mapping2(slot int) *program {
+	p.mapping(slot)
+	p.push(32).op(vm.MSTORE)
+	p.push(0).op(vm.MSTORE)
+	return p.push(64).push(0).op(vm.KECCAK256)
+}
+
+// pad32 rounds up to a multiple of 32. [n] -> [padded]
+func (p *program) pad32() *program {
+	return p.push(31).op(vm.ADD).push(32).op(vm.SWAP1, vm.DIV).push(32).op(vm.MUL)
+}
+
+// returnWord returns the top of the stack. [value]
+func (p *program) returnWord() *program {
+	p.push(0).op(vm.MSTORE)
+	return p.push(32).push(0).op(vm.RETURN)
+}
+
+// returnConst returns the ABI encoded data.
+func (p *program) returnConst(data []byte) *program {
+	p.mstoreBytes(0x80, data)
+	return p.push(len(data)).push(0x80).op(vm.RETURN)
+}
+
+// onlyOwner reverts unless the caller is the address stored in slot.
+func (p *program) onlyOwner(slot int) *program {
+	return p.op(vm.CALLER).sload(slot).op(vm.EQ).require("Ownable: caller is not the owner")
+}
+
+// storeString copies the string of the i-th argument to the string at
+// slot. Arguments are read with load, MLOAD or CALLDATALOAD, from base.
+//
+// Strings are stored as their length in slot and their words from
+// keccak256(slot).
+func (p *program) storeString(slot, i int, load vm.OpCode, base int) *program {
+	loop, end := p.newLabel(), p.newLabel()
+	p.push(base + 32*i).op(load).push(base).op(vm.ADD)  // [off]
+	p.op(vm.DUP1, load)                                 // [len, off]
+	p.op(vm.DUP1).sstore(slot)                          // [len, off]
+	p.push(31).op(vm.ADD).push(32).op(vm.SWAP1, vm.DIV) // [words, off]
+	p.op(vm.SWAP1).push(32).op(vm.ADD)                  // [src, words]
+	p.push(slot).push(0).op(vm.MSTORE)
+	p.push(32).push(0).op(vm.KECCAK256) // [dst, src, words]
+	p.op(vm.SWAP2)                      // [words, src, dst]
+
+	p.label(loop)
+	p.op(vm.DUP1, vm.ISZERO).jumpi(end)
+	p.op(vm.DUP2, load, vm.DUP4, vm.SSTORE)
+	p.push(1).op(vm.SWAP1, vm.SUB)
+	p.op(vm.SWAP1).push(32).op(vm.ADD, vm.SWAP1)
+	p.op(vm.SWAP2).push(1).op(vm.ADD, vm.SWAP2)
+	p.jump(loop)
+	p.label(end)
+	return p.op(vm.POP, vm.POP, vm.POP)
+}
+
+// loadString copies the string at slot to memory as its length and
+// words. [ptr] -> [end]
+func (p *program) loadString(slot int) *program {
+	loop, end := p.newLabel(), p.newLabel()
+	p.sload(slot)                                       // [len, ptr]
+	p.op(vm.DUP1, vm.DUP3, vm.MSTORE)                   // [len, ptr]
+	p.push(31).op(vm.ADD).push(32).op(vm.SWAP1, vm.DIV) // [words, ptr]
+	p.op(vm.SWAP1).push(32).op(vm.ADD)                  // [dst, words]
+	p.push(slot).push(0).op(vm.MSTORE)
+	p.push(32).push(0).op(vm.KECCAK256) // [src, dst, words]
+	p.op(vm.SWAP2)                      // [words, dst, src]
+
+	p.label(loop)
+	p.op(vm.DUP1, vm.ISZERO).jumpi(end)
+	p.op(vm.DUP3, vm.SLOAD, vm.DUP3, vm.MSTORE)
+	p.push(1).op(vm.SWAP1, vm.SUB)
+	p.op(vm.SWAP1).push(32).op(vm.ADD, vm.SWAP1)
+	p.op(vm.SWAP2).push(1).op(vm.ADD, vm.SWAP2)
+	p.jump(loop)
+	p.label(end)
+	return p.op(vm.POP, vm.SWAP1, vm.POP)
+}
+
+// returnString returns the string whose length is stored at 0xa0 and
+// words follow.
+func (p *program) returnString() *program {
+	p.push(32).push(0x80).op(vm.MSTORE)
+	p.push(0xa0).op(vm.MLOAD).pad32().push(64).op(vm.ADD)
+	return p.push(0x80).op(vm.RETURN)
+}
+
+// dispatch jumps to the handler of the called method, reverting on
+// unknown selectors and on value sent to non payable methods. Every
+// method of the ABI must have a handler, keyed by its Go name.
+func (p *program) dispatch(parsed abi.ABI, handlers map[string]func()) {
+	names := make([]string, 0, len(parsed.Methods))
+	for name := range parsed.Methods {
+		if handlers[name] == nil {
+			panic("zcnbridge: no handler for " + name)
+		}
+		names = append(names, name)
+	}
+	if len(names) != len(handlers) {
+		panic("zcnbridge: handlers of methods not in the ABI")
+	}
+	sort.Strings(names)
+
+	p.push(0).op(vm.CALLDATALOAD).push(0xe0).op(vm.SHR)
+	for _, name := range names {
+		p.op(vm.DUP1).push(parsed.Methods[name].ID).op(vm.EQ).jumpi("method:" + name)
+	}
+	p.push(0).op(vm.DUP1, vm.REVERT)
+
+	for _, name := range names {
+		p.label("method:" + name)
+		if !parsed.Methods[name].IsPayable() {
+			p.op(vm.CALLVALUE, vm.ISZERO).require("non-payable method")
+		}
+		handlers[name]()
+	}
+}
+
+// deployCode returns the init code running ctor and deploying runtime.
+// The constructor arguments follow the init code, at the "args" label.
+func deployCode(runtime []byte, ctor func(p *program)) []byte {
+	p := newProgram()
+	if ctor != nil {
+		ctor(p)
+	}
+	p.push(len(runtime)).op(vm.DUP1).pushLabel("runtime").push(0).op(vm.CODECOPY)
+	p.push(0).op(vm.RETURN)
+	p.emitReverts()
+	p.mark("runtime")
+	p.code = append(p.code, runtime...)
+	p.mark("args")
+	return p.assemble()
+}
+
+// abiString returns the ABI encoding of s as a single return value.
+func abiString(s string) []byte {
+	t, _ := abi.NewType("string", "", nil)
+	data, err := abi.Arguments{{Type: t}}.Pack(s)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// eventID returns the topic of the event signature.
+func eventID(signature string) common.Hash {
+	return crypto.Keccak256Hash([]byte(signature))
+}
@@ -0,0 +1,154 @@
+// Package gas provides fee strategies for Ethereum transactions sent by the
+// bridge and NFT clients: EIP-1559 fees from the fee history, a legacy gas
+// price fallback, user caps and fee bumping of stuck transactions.
+package gas
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// DefaultBumpPercent is the fee increase of a replacement transaction. Nodes
+// accept a replacement only if it pays at least 10% more.
+const DefaultBumpPercent = 15
+
+var (
+	// ErrFeeCapReached the fees can not be bumped without exceeding the caps
+	ErrFeeCapReached = errors.New("gas: fee cap reached")
+)
+
+// Backend is the part of an Ethereum client used to suggest fees.
+type Backend interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+}
+
+// FeeHistoryReader is implemented by clients supporting eth_feeHistory, like ethclient.Client.
+type FeeHistoryReader interface {
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
+}
+
+// Fees are the fees of a transaction. Legacy transactions only set GasPrice,
+// EIP-1559 transactions set GasFeeCap and GasTipCap.
+type Fees struct {
+	GasPrice  *big.Int `json:"gas_price,omitempty"`
+	GasFeeCap *big.Int `json:"max_fee_per_gas,omitempty"`
+	GasTipCap *big.Int `json:"max_priority_fee_per_gas,omitempty"`
+}
+
+// IsDynamic reports whether the fees are EIP-1559 fees.
+func (f *Fees) IsDynamic() bool {
+	return f.GasFeeCap != nil
+}
+
+// Max returns the highest price per gas unit the transaction may pay.
+func (f *Fees) Max() *big.Int {
+	if f.IsDynamic() {
+		return f.GasFeeCap
+	}
+	return f.GasPrice
+}
+
+// Apply sets the fees on the transact options.
+func (f *Fees) Apply(opts *bind.TransactOpts) {
+	opts.GasPrice = f.GasPrice
+	opts.GasFeeCap = f.GasFeeCap
+	opts.GasTipCap = f.GasTipCap
+}
+
+// Caps limit the fees suggested by a strategy. Nil caps are not applied.
+type Caps struct {
+	// MaxGasPrice caps the gas price of legacy transactions
+	MaxGasPrice *big.Int `json:"max_gas_price,omitempty"`
+	// MaxFeePerGas caps the fee cap of EIP-1559 transactions
+	MaxFeePerGas *big.Int `json:"max_fee_per_gas,omitempty"`
+	// MaxPriorityFeePerGas caps the tip of EIP-1559 transactions
+	MaxPriorityFeePerGas *big.Int `json:"max_priority_fee_per_gas,omitempty"`
+}
+
+// apply lowers the fees to the caps.
+func (c *Caps) apply(f *Fees) *Fees {
+	if f.GasPrice != nil {
+		f.GasPrice = capped(f.GasPrice, c.MaxGasPrice)
+	}
+	if f.GasTipCap != nil {
+		f.GasTipCap = capped(f.GasTipCap, c.MaxPriorityFeePerGas)
+	}
+	if f.GasFeeCap != nil {
+		f.GasFeeCap = capped(f.GasFeeCap, c.MaxFeePerGas)
+		// the tip can not be higher than the fee cap
+		f.GasTipCap = capped(f.GasTipCap, f.GasFeeCap)
+	}
+	return f
+}
+
+// Strategy suggests fees of new transactions and of replacements of stuck ones.
+type Strategy interface {
+	// Fees returns the fees of a new transaction.
+	Fees(ctx context.Context, backend Backend) (*Fees, error)
+
+	// Bump returns the fees of a transaction replacing one with prev fees.
+	// It returns ErrFeeCapReached if the caps do not allow higher fees.
+	Bump(ctx context.Context, backend Backend, prev *Fees) (*Fees, error)
+}
+
+// Default is the strategy used when none is configured: EIP-1559 fees with
+// a legacy fallback on chains without base fee.
+func Default() Strategy {
+	return &EIP1559Strategy{}
+}
+
+// bump returns the higher of the prev fees raised by percent and the
+// suggested fees, within caps.
+func bump(prev, suggested *Fees, percent int64, caps Caps) (*Fees, error) {
+	if percent <= 0 {
+		percent = DefaultBumpPercent
+	}
+	next := &Fees{}
+	if prev.IsDynamic() {
+		if !suggested.IsDynamic() {
+			suggested = &Fees{GasFeeCap: suggested.GasPrice, GasTipCap: suggested.GasPrice}
+		}
+		next.GasFeeCap = maxBig(raise(prev.GasFeeCap, percent), suggested.GasFeeCap)
+		next.GasTipCap = maxBig(raise(prev.GasTipCap, percent), suggested.GasTipCap)
+	} else {
+		next.GasPrice = maxBig(raise(prev.GasPrice, percent), suggested.Max())
+	}
+
+	caps.apply(next)
+	if next.Max().Cmp(raise(prev.Max(), 10)) < 0 ||
+		(next.IsDynamic() && next.GasTipCap.Cmp(raise(prev.GasTipCap, 10)) < 0) {
+		return nil, ErrFeeCapReached
+	}
+	return next, nil
+}
+
+// raise returns v increased by percent, at least by one wei.
+func raise(v *big.Int, percent int64) *big.Int {
+	delta := new(big.Int).Mul(v, big.NewInt(percent))
+	delta.Div(delta, big.NewInt(100))
+	if delta.Sign() == 0 {
+		delta.SetInt64(1)
+	}
+	return delta.Add(delta, v)
+}
+
+func capped(v, limit *big.Int) *big.Int {
+	if limit != nil && v.Cmp(limit) > 0 {
+		return new(big.Int).Set(limit)
+	}
+	return v
+}
+
+func maxBig(a, b *big.Int) *big.Int {
+	if b != nil && b.Cmp(a) > 0 {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,217 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBackend struct {
+	baseFee  *big.Int
+	gasPrice *big.Int
+	tip      *big.Int
+	history  *ethereum.FeeHistory
+
+	sent  []*types.Transaction
+	mined map[common.Hash]bool
+	// onSend, if set, is called instead of accepting a sent transaction
+	onSend func(tx *types.Transaction) error
+}
+
+func (b *fakeBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{BaseFee: b.baseFee}, nil
+}
+
+func (b *fakeBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return b.gasPrice, nil
+}
+
+func (b *fakeBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return b.tip, nil
+}
+
+func (b *fakeBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if b.onSend != nil {
+		return b.onSend(tx)
+	}
+	b.sent = append(b.sent, tx)
+	return nil
+}
+
+func (b *fakeBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	if b.mined[txHash] {
+		return &types.Receipt{TxHash: txHash, Status: types.ReceiptStatusSuccessful}, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+type fakeHistoryBackend struct {
+	*fakeBackend
+}
+
+func (b fakeHistoryBackend) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	return b.history, nil
+}
+
+func TestStrategies(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("legacy fallback", func(t *testing.T) {
+		b := &fakeBackend{gasPrice: big.NewInt(100)}
+		fees, err := Default().Fees(ctx, b)
+		require.NoError(t, err)
+		require.False(t, fees.IsDynamic())
+		require.EqualValues(t, 100, fees.GasPrice.Int64())
+	})
+
+	t.Run("fee history", func(t *testing.T) {
+		b := fakeHistoryBackend{&fakeBackend{
+			baseFee: big.NewInt(90),
+			history: &ethereum.FeeHistory{
+				BaseFee: []*big.Int{big.NewInt(90), big.NewInt(100)},
+				Reward:  [][]*big.Int{{big.NewInt(1)}, {big.NewInt(5)}, {big.NewInt(3)}},
+			},
+		}}
+		fees, err := Default().Fees(ctx, b)
+		require.NoError(t, err)
+		require.True(t, fees.IsDynamic())
+		require.EqualValues(t, 3, fees.GasTipCap.Int64())
+		require.EqualValues(t, 203, fees.GasFeeCap.Int64())
+	})
+
+	t.Run("caps", func(t *testing.T) {
+		b := &fakeBackend{baseFee: big.NewInt(100), tip: big.NewInt(50)}
+		s := &EIP1559Strategy{Caps: Caps{MaxFeePerGas: big.NewInt(150), MaxPriorityFeePerGas: big.NewInt(20)}}
+		fees, err := s.Fees(ctx, b)
+		require.NoError(t, err)
+		require.EqualValues(t, 150, fees.GasFeeCap.Int64())
+		require.EqualValues(t, 20, fees.GasTipCap.Int64())
+
+		_, err = s.Bump(ctx, b, fees)
+		require.ErrorIs(t, err, ErrFeeCapReached)
+	})
+
+	t.Run("bump", func(t *testing.T) {
+		b := &fakeBackend{baseFee: big.NewInt(10), tip: big.NewInt(1)}
+		prev := &Fees{GasFeeCap: big.NewInt(1000), GasTipCap: big.NewInt(100)}
+		fees, err := Default().Bump(ctx, b, prev)
+		require.NoError(t, err)
+		require.EqualValues(t, 1150, fees.GasFeeCap.Int64())
+		require.EqualValues(t, 115, fees.GasTipCap.Int64())
+	})
+}
+
+func TestReplacer(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+
+	b := &fakeBackend{baseFee: big.NewInt(10), tip: big.NewInt(1), mined: make(map[common.Hash]bool)}
+	to := common.HexToAddress("0x01")
+	tx, err := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+		ChainID: big.NewInt(1), Nonce: 7, GasFeeCap: big.NewInt(21), GasTipCap: big.NewInt(1), Gas: 21000, To: &to,
+	})
+	require.NoError(t, err)
+
+	r := &Replacer{
+		Backend: b,
+		From:    from,
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			next, err := types.SignTx(tx, signer, key)
+			if err == nil {
+				// the replacement gets mined
+				b.mined[next.Hash()] = true
+			}
+			return next, err
+		},
+		ReplaceAfter: time.Millisecond,
+		PollInterval: time.Millisecond,
+	}
+	var replaced []*types.Transaction
+	r.OnReplace = func(next *types.Transaction) error {
+		// the replacement is known before it is sent
+		require.Len(t, b.sent, 0)
+		replaced = append(replaced, next)
+		return nil
+	}
+
+	receipt, err := r.WaitMined(context.Background(), tx)
+	require.NoError(t, err)
+	require.Len(t, b.sent, 1)
+	require.Equal(t, b.sent[0].Hash(), receipt.TxHash)
+	require.Len(t, replaced, 1)
+	require.Equal(t, replaced[0].Hash(), receipt.TxHash)
+	require.EqualValues(t, 7, b.sent[0].Nonce())
+	require.True(t, b.sent[0].GasFeeCap().Cmp(tx.GasFeeCap()) > 0)
+}
+
+func TestReplacerSendErrors(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	to := common.HexToAddress("0x01")
+	tx, err := types.SignNewTx(key, signer, &types.LegacyTx{Nonce: 7, GasPrice: big.NewInt(10), Gas: 21000, To: &to})
+	require.NoError(t, err)
+
+	newReplacer := func(b *fakeBackend) *Replacer {
+		return &Replacer{
+			Backend: b,
+			From:    crypto.PubkeyToAddress(key.PublicKey),
+			Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+				return types.SignTx(tx, signer, key)
+			},
+			ReplaceAfter: time.Millisecond,
+			PollInterval: time.Millisecond,
+		}
+	}
+
+	t.Run("original mined before the replacement", func(t *testing.T) {
+		b := &fakeBackend{gasPrice: big.NewInt(10), mined: make(map[common.Hash]bool)}
+		b.onSend = func(*types.Transaction) error {
+			b.mined[tx.Hash()] = true
+			return errors.New("nonce too low")
+		}
+		receipt, err := newReplacer(b).WaitMined(context.Background(), tx)
+		require.NoError(t, err)
+		require.Equal(t, tx.Hash(), receipt.TxHash)
+	})
+
+	t.Run("replacement already known", func(t *testing.T) {
+		b := &fakeBackend{gasPrice: big.NewInt(10), mined: make(map[common.Hash]bool)}
+		var known common.Hash
+		b.onSend = func(next *types.Transaction) error {
+			known = next.Hash()
+			b.mined[known] = true
+			return errors.New("already known")
+		}
+		receipt, err := newReplacer(b).WaitMined(context.Background(), tx)
+		require.NoError(t, err)
+		require.Equal(t, known, receipt.TxHash)
+	})
+
+	t.Run("nonce used by another transaction", func(t *testing.T) {
+		b := &fakeBackend{gasPrice: big.NewInt(10), mined: make(map[common.Hash]bool)}
+		b.onSend = func(*types.Transaction) error {
+			return errors.New("nonce too low")
+		}
+		_, err := newReplacer(b).WaitMined(context.Background(), tx)
+		require.Error(t, err)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		b := &fakeBackend{gasPrice: big.NewInt(10), mined: make(map[common.Hash]bool)}
+		r := newReplacer(b)
+		r.Timeout = 20 * time.Millisecond
+		_, err := r.WaitMined(context.Background(), tx)
+		require.ErrorIs(t, err, ErrNotMined)
+	})
+}
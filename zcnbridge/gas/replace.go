@@ -0,0 +1,209 @@
+package gas
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultReplaceAfter is the time a transaction may stay pending before
+	// it is replaced with higher fees.
+	DefaultReplaceAfter = 3 * time.Minute
+	// DefaultMaxReplacements is the number of fee bumps of a transaction.
+	DefaultMaxReplacements = 5
+	// DefaultPollInterval is the interval receipts are checked.
+	DefaultPollInterval = 5 * time.Second
+	// DefaultWaitTimeout bounds WaitMined.
+	DefaultWaitTimeout = 30 * time.Minute
+)
+
+// ErrNotMined the transaction and its replacements were not mined in time.
+var ErrNotMined = errors.New("transaction is not mined")
+
+// ReplaceBackend is the part of an Ethereum client used to replace stuck transactions.
+type ReplaceBackend interface {
+	Backend
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// Replacer waits for transactions to be mined and replaces the ones pending
+// for too long with the same transaction paying higher fees.
+type Replacer struct {
+	Backend  ReplaceBackend
+	Strategy Strategy
+	From     common.Address
+	Signer   bind.SignerFn
+
+	// ReplaceAfter is DefaultReplaceAfter if zero
+	ReplaceAfter time.Duration
+	// MaxReplacements is DefaultMaxReplacements if zero
+	MaxReplacements int
+	// PollInterval is DefaultPollInterval if zero
+	PollInterval time.Duration
+	// Timeout bounds WaitMined, DefaultWaitTimeout if zero
+	Timeout time.Duration
+	// OnReplace, if set, is called with each replacement before it is sent,
+	// e.g. to persist it. An error stops the replacement.
+	OnReplace func(tx *types.Transaction) error
+}
+
+// WaitMined waits until tx or one of its replacements is mined and returns
+// its receipt. It fails with ErrNotMined after Timeout.
+func (r *Replacer) WaitMined(ctx context.Context, tx *types.Transaction) (*types.Receipt, error) {
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = DefaultWaitTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	replaceAfter := r.ReplaceAfter
+	if replaceAfter <= 0 {
+		replaceAfter = DefaultReplaceAfter
+	}
+	maxReplacements := r.MaxReplacements
+	if maxReplacements <= 0 {
+		maxReplacements = DefaultMaxReplacements
+	}
+	pollInterval := r.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = DefaultPollInterval
+	}
+	strategy := r.Strategy
+	if strategy == nil {
+		strategy = Default()
+	}
+
+	// any of the sent transactions may be mined
+	sent := []*types.Transaction{tx}
+	current := tx
+	replacements := 0
+	deadline := time.Now().Add(replaceAfter)
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if receipt, err := r.receipt(ctx, sent); receipt != nil || err != nil {
+			return receipt, err
+		}
+
+		if time.Now().After(deadline) && replacements < maxReplacements {
+			next, err := r.replace(ctx, strategy, current)
+			switch {
+			case err == nil, isAlreadyKnown(err):
+				sent = append(sent, next)
+				current = next
+				replacements++
+			case errors.Is(err, ErrFeeCapReached):
+				// keep waiting for the last transaction
+				replacements = maxReplacements
+			case isNonceTooLow(err):
+				// one of the sent transactions was mined meanwhile
+				receipt, rerr := r.receipt(ctx, sent)
+				if receipt != nil || rerr != nil {
+					return receipt, rerr
+				}
+				return nil, errors.Wrap(err, "nonce is used by another transaction")
+			default:
+				return nil, err
+			}
+			deadline = time.Now().Add(replaceAfter)
+		}
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, errors.Wrapf(ErrNotMined, "%s after %s", tx.Hash().Hex(), timeout)
+			}
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// receipt returns the receipt of the first mined transaction of sent, nil if none is mined.
+func (r *Replacer) receipt(ctx context.Context, sent []*types.Transaction) (*types.Receipt, error) {
+	for _, t := range sent {
+		receipt, err := r.Backend.TransactionReceipt(ctx, t.Hash())
+		if err == nil {
+			return receipt, nil
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			return nil, errors.Wrap(err, "failed to get transaction receipt")
+		}
+	}
+	return nil, nil
+}
+
+// isNonceTooLow reports whether a transaction with the same nonce is already mined.
+func isNonceTooLow(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "nonce too low")
+}
+
+// isAlreadyKnown reports whether the node already has the transaction in its pool.
+func isAlreadyKnown(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "already known")
+}
+
+// replace sends tx again with bumped fees. The replacement is returned even if
+// sending it fails, as it may be known to the node already.
+func (r *Replacer) replace(ctx context.Context, strategy Strategy, tx *types.Transaction) (*types.Transaction, error) {
+	prev := &Fees{GasPrice: tx.GasPrice()}
+	if tx.Type() == types.DynamicFeeTxType {
+		prev = &Fees{GasFeeCap: tx.GasFeeCap(), GasTipCap: tx.GasTipCap()}
+	}
+
+	fees, err := strategy.Bump(ctx, r.Backend, prev)
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := r.Signer(r.From, rebuild(tx, fees))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign replacement transaction")
+	}
+	if r.OnReplace != nil {
+		if err := r.OnReplace(next); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.Backend.SendTransaction(ctx, next); err != nil {
+		return next, errors.Wrap(err, "failed to send replacement transaction")
+	}
+	return next, nil
+}
+
+// rebuild returns tx with the given fees.
+func rebuild(tx *types.Transaction, fees *Fees) *types.Transaction {
+	if fees.IsDynamic() {
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:    tx.ChainId(),
+			Nonce:      tx.Nonce(),
+			GasTipCap:  fees.GasTipCap,
+			GasFeeCap:  fees.GasFeeCap,
+			Gas:        tx.Gas(),
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: tx.AccessList(),
+		})
+	}
+	return types.NewTx(&types.LegacyTx{
+		Nonce:    tx.Nonce(),
+		GasPrice: new(big.Int).Set(fees.GasPrice),
+		Gas:      tx.Gas(),
+		To:       tx.To(),
+		Value:    tx.Value(),
+		Data:     tx.Data(),
+	})
+}
@@ -0,0 +1,145 @@
+package gas
+
+import (
+	"context"
+	"math/big"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultFeeHistoryBlocks is the number of blocks the tip is sampled from.
+	DefaultFeeHistoryBlocks = 10
+	// DefaultRewardPercentile is the percentile of the tips paid in a block.
+	DefaultRewardPercentile = 50
+	// DefaultBaseFeeMultiplier keeps a transaction valid while the base fee
+	// rises for several full blocks.
+	DefaultBaseFeeMultiplier = 2
+)
+
+// LegacyStrategy uses the gas price suggested by the node.
+type LegacyStrategy struct {
+	Caps Caps
+	// BumpPercent is the fee increase of replacements, DefaultBumpPercent if zero
+	BumpPercent int64
+}
+
+// Fees implements Strategy.
+func (s *LegacyStrategy) Fees(ctx context.Context, backend Backend) (*Fees, error) {
+	price, err := backend.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to suggest gas price")
+	}
+	return s.Caps.apply(&Fees{GasPrice: price}), nil
+}
+
+// Bump implements Strategy.
+func (s *LegacyStrategy) Bump(ctx context.Context, backend Backend, prev *Fees) (*Fees, error) {
+	suggested, err := s.Fees(ctx, backend)
+	if err != nil {
+		return nil, err
+	}
+	return bump(prev, suggested, s.BumpPercent, s.Caps)
+}
+
+// EIP1559Strategy suggests a max fee of the next block base fee times
+// BaseFeeMultiplier plus a priority fee sampled from eth_feeHistory. Chains
+// without base fee get legacy fees.
+type EIP1559Strategy struct {
+	Caps Caps
+	// BumpPercent is the fee increase of replacements, DefaultBumpPercent if zero
+	BumpPercent int64
+	// FeeHistoryBlocks is the number of blocks sampled, DefaultFeeHistoryBlocks if zero
+	FeeHistoryBlocks uint64
+	// RewardPercentile is the tip percentile sampled, DefaultRewardPercentile if zero
+	RewardPercentile float64
+	// BaseFeeMultiplier is DefaultBaseFeeMultiplier if zero
+	BaseFeeMultiplier int64
+}
+
+// Fees implements Strategy.
+func (s *EIP1559Strategy) Fees(ctx context.Context, backend Backend) (*Fees, error) {
+	head, err := backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get latest block header")
+	}
+	if head.BaseFee == nil {
+		legacy := &LegacyStrategy{Caps: s.Caps}
+		return legacy.Fees(ctx, backend)
+	}
+
+	baseFee, tip, err := s.feeHistory(ctx, backend)
+	if err != nil {
+		return nil, err
+	}
+	if baseFee == nil {
+		baseFee = head.BaseFee
+	}
+	if tip == nil {
+		if tip, err = backend.SuggestGasTipCap(ctx); err != nil {
+			return nil, errors.Wrap(err, "failed to suggest gas tip cap")
+		}
+	}
+
+	multiplier := s.BaseFeeMultiplier
+	if multiplier <= 0 {
+		multiplier = DefaultBaseFeeMultiplier
+	}
+	feeCap := new(big.Int).Mul(baseFee, big.NewInt(multiplier))
+	feeCap.Add(feeCap, tip)
+
+	return s.Caps.apply(&Fees{GasFeeCap: feeCap, GasTipCap: tip}), nil
+}
+
+// Bump implements Strategy.
+func (s *EIP1559Strategy) Bump(ctx context.Context, backend Backend, prev *Fees) (*Fees, error) {
+	suggested, err := s.Fees(ctx, backend)
+	if err != nil {
+		return nil, err
+	}
+	return bump(prev, suggested, s.BumpPercent, s.Caps)
+}
+
+// feeHistory returns the base fee of the next block and the median tip paid in
+// the last blocks. Both are nil if the backend does not support eth_feeHistory.
+func (s *EIP1559Strategy) feeHistory(ctx context.Context, backend Backend) (*big.Int, *big.Int, error) {
+	reader, ok := backend.(FeeHistoryReader)
+	if !ok {
+		return nil, nil, nil
+	}
+
+	blocks := s.FeeHistoryBlocks
+	if blocks == 0 {
+		blocks = DefaultFeeHistoryBlocks
+	}
+	percentile := s.RewardPercentile
+	if percentile <= 0 {
+		percentile = DefaultRewardPercentile
+	}
+
+	history, err := reader.FeeHistory(ctx, blocks, nil, []float64{percentile})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get fee history")
+	}
+
+	var baseFee *big.Int
+	if n := len(history.BaseFee); n > 0 {
+		// the last base fee is the one of the next block
+		baseFee = history.BaseFee[n-1]
+	}
+
+	var rewards []*big.Int
+	for _, reward := range history.Reward {
+		if len(reward) > 0 && reward[0] != nil {
+			rewards = append(rewards, reward[0])
+		}
+	}
+	if len(rewards) == 0 {
+		return baseFee, nil, nil
+	}
+	sort.Slice(rewards, func(i, j int) bool {
+		return rewards[i].Cmp(rewards[j]) < 0
+	})
+	return baseFee, rewards[len(rewards)/2], nil
+}
@@ -2,6 +2,7 @@ package zcnbridge
 
 import (
 	"fmt"
+	"math/big"
 	"path"
 	"time"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/pkg/errors"
 )
 
@@ -58,6 +60,16 @@ func (k *keyStore) GetEthereumKeyStore() *keystore.KeyStore {
 	return k.ks
 }
 
+// signerKeyStore adapts KeyStore to the key store signer.
+type signerKeyStore struct {
+	KeyStore
+}
+
+// SignTx forwards request to Ethereum KeyStore SignTx method
+func (k signerKeyStore) SignTx(account accounts.Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return k.GetEthereumKeyStore().SignTx(account, tx, chainID)
+}
+
 // ListStorageAccounts List available accounts
 func ListStorageAccounts(homedir string) []common.Address {
 	keyDir := path.Join(homedir, EthereumWalletStorageDir)
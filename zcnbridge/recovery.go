@@ -0,0 +1,208 @@
+package zcnbridge
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/0chain/gosdk/zcnbridge/errors"
+	"github.com/0chain/gosdk/zcnbridge/ethereum"
+	"github.com/0chain/gosdk/zcnbridge/wallet"
+	"github.com/0chain/gosdk/zcncore"
+	"go.uber.org/zap"
+)
+
+// StuckBurn is a burn which was never minted on the other chain.
+type StuckBurn struct {
+	Direction TransferDirection `json:"direction"`
+	BurnHash  string            `json:"burn_hash"`
+	Nonce     int64             `json:"nonce"`
+	Amount    int64             `json:"amount"`
+
+	// EstimatedGas is the gas amount estimated for minting WZCN
+	EstimatedGas float64 `json:"estimated_gas,omitempty"`
+	// MintHash is set once the burn was minted by the recovery
+	MintHash string `json:"mint_hash,omitempty"`
+	// Error tells why the burn could not be recovered
+	Error string `json:"error,omitempty"`
+}
+
+// recoveryOperations are the bridge calls needed to recover burns.
+type recoveryOperations interface {
+	bridgeOperations
+	QueryEthereumBurnEvents(startNonce string) ([]*ethereum.BurnEvent, error)
+	EstimateMintWZCNGasAmount(ctx context.Context, from, to, zcnTransactionRaw string, amountToken, nonceRaw int64, signaturesRaw [][]byte) (float64, error)
+}
+
+type burnRecovery struct {
+	bridge          recoveryOperations
+	ethereumAddress string
+	bridgeAddress   string
+
+	zcnBurnTickets func(ethereumAddress, startNonce string) ([]zcncore.BurnTicket, error)
+	zcnMintNonce   func() (int64, error)
+}
+
+// RecoverBurns scans both chains for burns of the wallet which were never
+// minted and rebuilds their mint payloads from the authorizers. With complete
+// set, the burns are minted in nonce order, otherwise they are only reported.
+func (b *BridgeClient) RecoverBurns(ctx context.Context, complete bool) ([]*StuckBurn, error) {
+	r := &burnRecovery{
+		bridge:          b,
+		ethereumAddress: b.EthereumAddress,
+		bridgeAddress:   b.BridgeAddress,
+		zcnBurnTickets:  getNotProcessedZCNBurnTickets,
+		zcnMintNonce:    getZCNMintNonce,
+	}
+	return r.recover(ctx, complete)
+}
+
+func (r *burnRecovery) recover(ctx context.Context, complete bool) ([]*StuckBurn, error) {
+	toEthereum, err := r.recoverZCNBurns(ctx, complete)
+	if err != nil {
+		return nil, err
+	}
+	toZCN, err := r.recoverEthereumBurns(ctx, complete)
+	if err != nil {
+		return toEthereum, err
+	}
+	return append(toEthereum, toZCN...), nil
+}
+
+// recoverZCNBurns recovers ZCN burns never minted as WZCN.
+func (r *burnRecovery) recoverZCNBurns(ctx context.Context, complete bool) ([]*StuckBurn, error) {
+	userNonce, err := r.bridge.GetUserNonceMinted(ctx, r.ethereumAddress)
+	if err != nil {
+		return nil, errors.Wrap("recover_burns", "failed to retrieve user nonce", err)
+	}
+
+	tickets, err := r.zcnBurnTickets(r.ethereumAddress, userNonce.String())
+	if err != nil {
+		return nil, errors.Wrap("recover_burns", "failed to retrieve ZCN burn tickets", err)
+	}
+	sort.Slice(tickets, func(i, j int) bool {
+		return tickets[i].Nonce < tickets[j].Nonce
+	})
+
+	var (
+		burns   []*StuckBurn
+		blocked bool
+	)
+	for _, ticket := range tickets {
+		if ticket.Nonce <= userNonce.Int64() {
+			continue
+		}
+		burn := &StuckBurn{
+			Direction: ZCNToEthereum,
+			BurnHash:  ticket.Hash,
+			Nonce:     ticket.Nonce,
+			Amount:    ticket.Amount,
+		}
+		burns = append(burns, burn)
+
+		payload, err := r.bridge.QueryEthereumMintPayload(ticket.Hash)
+		if err != nil {
+			burn.Error = err.Error()
+			blocked = true
+			continue
+		}
+
+		var sigs [][]byte
+		for _, sig := range payload.Signatures {
+			sigs = append(sigs, sig.Signature)
+		}
+		burn.EstimatedGas, err = r.bridge.EstimateMintWZCNGasAmount(ctx, r.ethereumAddress, r.bridgeAddress,
+			payload.ZCNTxnID, payload.Amount, payload.Nonce, sigs)
+		if err != nil {
+			Logger.Error("failed to estimate mint gas amount", zap.String("hash", ticket.Hash), zap.Error(err))
+		}
+
+		if !complete {
+			continue
+		}
+		// the bridge contract mints nonces in order
+		if blocked {
+			burn.Error = "previous burn is not minted"
+			continue
+		}
+		tx, err := r.bridge.MintWZCN(ctx, payload)
+		if err != nil {
+			burn.Error = err.Error()
+			blocked = true
+			continue
+		}
+		burn.MintHash = tx.Hash().Hex()
+	}
+	return burns, nil
+}
+
+// recoverEthereumBurns recovers WZCN burns never minted as ZCN.
+func (r *burnRecovery) recoverEthereumBurns(ctx context.Context, complete bool) ([]*StuckBurn, error) {
+	mintNonce, err := r.zcnMintNonce()
+	if err != nil {
+		return nil, errors.Wrap("recover_burns", "failed to retrieve last ZCN processed mint nonce", err)
+	}
+
+	events, err := r.bridge.QueryEthereumBurnEvents(strconv.FormatInt(mintNonce, 10))
+	if err != nil {
+		return nil, errors.Wrap("recover_burns", "failed to retrieve WZCN burn events", err)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Nonce < events[j].Nonce
+	})
+
+	var (
+		burns   []*StuckBurn
+		blocked bool
+	)
+	for _, event := range events {
+		if event.Nonce <= mintNonce {
+			continue
+		}
+		burn := &StuckBurn{
+			Direction: EthereumToZCN,
+			BurnHash:  event.TransactionHash,
+			Nonce:     event.Nonce,
+		}
+		burns = append(burns, burn)
+
+		payload, err := r.bridge.QueryZChainMintPayload(event.TransactionHash)
+		if err != nil {
+			burn.Error = err.Error()
+			blocked = true
+			continue
+		}
+		burn.Amount = int64(payload.Amount)
+
+		if !complete {
+			continue
+		}
+		if blocked {
+			burn.Error = "previous burn is not minted"
+			continue
+		}
+		burn.MintHash, err = r.bridge.MintZCN(ctx, payload)
+		if err != nil {
+			burn.Error = err.Error()
+			blocked = true
+		}
+	}
+	return burns, nil
+}
+
+func getNotProcessedZCNBurnTickets(ethereumAddress, startNonce string) ([]zcncore.BurnTicket, error) {
+	var burnTickets []zcncore.BurnTicket
+	cb := wallet.NewZCNStatus(&burnTickets)
+	cb.Begin()
+
+	if err := zcncore.GetNotProcessedZCNBurnTickets(ethereumAddress, startNonce, cb); err != nil {
+		return nil, err
+	}
+	if err := cb.Wait(); err != nil {
+		return nil, err
+	}
+	if !cb.Success {
+		return nil, errors.New("get_burn_tickets", "failed to retrieve ZCN burn tickets")
+	}
+	return burnTickets, nil
+}
@@ -0,0 +1,86 @@
+package zcnbridge
+
+import (
+	"context"
+	"testing"
+
+	"github.com/0chain/gosdk/zcnbridge/errors"
+	"github.com/0chain/gosdk/zcnbridge/ethereum"
+	"github.com/0chain/gosdk/zcnbridge/zcnsc"
+	"github.com/0chain/gosdk/zcncore"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRecoveryBridge struct {
+	fakeBridge
+	burnEvents []*ethereum.BurnEvent
+	noQuorum   map[string]bool
+}
+
+func (b *fakeRecoveryBridge) QueryEthereumMintPayload(zchainBurnHash string) (*ethereum.MintPayload, error) {
+	if b.noQuorum[zchainBurnHash] {
+		return nil, errors.New("get_burn_ticket", "failed to reach the quorum")
+	}
+	return &ethereum.MintPayload{ZCNTxnID: zchainBurnHash, Amount: 10, To: "0xabc"}, nil
+}
+
+func (b *fakeRecoveryBridge) QueryZChainMintPayload(ethBurnHash string) (*zcnsc.MintPayload, error) {
+	return &zcnsc.MintPayload{EthereumTxnID: ethBurnHash, Amount: 20}, nil
+}
+
+func (b *fakeRecoveryBridge) QueryEthereumBurnEvents(startNonce string) ([]*ethereum.BurnEvent, error) {
+	return b.burnEvents, nil
+}
+
+func (b *fakeRecoveryBridge) EstimateMintWZCNGasAmount(ctx context.Context, from, to, zcnTransactionRaw string, amountToken, nonceRaw int64, signaturesRaw [][]byte) (float64, error) {
+	return 21000, nil
+}
+
+func TestRecoverBurns(t *testing.T) {
+	b := &fakeRecoveryBridge{
+		fakeBridge: fakeBridge{nonceMinted: 1},
+		burnEvents: []*ethereum.BurnEvent{
+			{Nonce: 4, TransactionHash: "0xeth4"},
+			{Nonce: 3, TransactionHash: "0xeth3"},
+		},
+		noQuorum: map[string]bool{"zcn3": true},
+	}
+	r := &burnRecovery{
+		bridge:          b,
+		ethereumAddress: "0xabc",
+		zcnBurnTickets: func(ethereumAddress, startNonce string) ([]zcncore.BurnTicket, error) {
+			require.Equal(t, "1", startNonce)
+			return []zcncore.BurnTicket{
+				{Hash: "zcn4", Nonce: 4, Amount: 10},
+				{Hash: "zcn2", Nonce: 2, Amount: 10},
+				{Hash: "zcn3", Nonce: 3, Amount: 10},
+			}, nil
+		},
+		zcnMintNonce: func() (int64, error) { return 2, nil },
+	}
+
+	t.Run("report", func(t *testing.T) {
+		burns, err := r.recover(context.Background(), false)
+		require.NoError(t, err)
+		require.Len(t, burns, 5)
+		require.Zero(t, b.mints)
+		require.Equal(t, "zcn2", burns[0].BurnHash)
+		require.EqualValues(t, 21000, burns[0].EstimatedGas)
+		require.NotEmpty(t, burns[1].Error)
+		require.Equal(t, "0xeth3", burns[3].BurnHash)
+		require.EqualValues(t, 20, burns[3].Amount)
+	})
+
+	t.Run("complete", func(t *testing.T) {
+		burns, err := r.recover(context.Background(), true)
+		require.NoError(t, err)
+		require.NotEmpty(t, burns[0].MintHash)
+		// nonce 3 has no quorum, nonce 4 must wait for it
+		require.Empty(t, burns[1].MintHash)
+		require.Empty(t, burns[2].MintHash)
+		require.NotEmpty(t, burns[2].Error)
+		require.Equal(t, "zcn_mint", burns[3].MintHash)
+		require.Equal(t, "zcn_mint", burns[4].MintHash)
+		require.Equal(t, 3, b.mints)
+	})
+}
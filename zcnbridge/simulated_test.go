@@ -0,0 +1,350 @@
+package zcnbridge
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/0chain/gosdk/zcnbridge/ethereum"
+	"github.com/0chain/gosdk/zcnbridge/ethereum/nftconfig"
+	"github.com/0chain/gosdk/zcnbridge/ethereum/simulated"
+	"github.com/0chain/gosdk/zcnbridge/ethereum/zcntoken"
+	"github.com/0chain/gosdk/zcnbridge/ethsigner"
+	"github.com/0chain/gosdk/zcnbridge/swap"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func newSimulatedBridgeClient(t *testing.T) (*BridgeClient, *simulated.Backend) {
+	dir := t.TempDir()
+	backend, err := simulated.New(dir)
+	require.NoError(t, err)
+	t.Cleanup(func() { backend.Close() })
+	token := deployTestToken(t, backend)
+	require.NoError(t, backend.DeployBridge(token))
+	nftConfig := deployTestNFTConfig(t, backend)
+	// the bridge mints from its pool
+	mintTestTokens(t, backend, token, backend.BridgeAddress, big.NewInt(1000))
+
+	bridgeClient := NewBridgeClient(
+		backend.BridgeAddress.Hex(),
+		token.Hex(),
+		backend.AuthorizersAddress.Hex(),
+		backend.Owner.Address.Hex(),
+		"",
+		simulated.Password,
+		0,
+		0.75,
+		"",
+		backend,
+		nil,
+		NewKeyStore(dir),
+	)
+	bridgeClient.NFTConfigAddress = nftConfig.Hex()
+	return bridgeClient, backend
+}
+
+func requireMined(t *testing.T, backend *simulated.Backend, tx *types.Transaction) {
+	receipt, err := backend.TransactionReceipt(context.Background(), tx.Hash())
+	require.NoError(t, err)
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+}
+
+func TestSimulatedBridge(t *testing.T) {
+	ctx := context.Background()
+	bridgeClient, backend := newSimulatedBridgeClient(t)
+
+	var keys []*ecdsa.PrivateKey
+	for i := 0; i < 3; i++ {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		keys = append(keys, key)
+	}
+	outsider, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	t.Run("authorizers", func(t *testing.T) {
+		for _, key := range keys {
+			tx, err := bridgeClient.AddEthereumAuthorizer(ctx, crypto.PubkeyToAddress(key.PublicKey))
+			require.NoError(t, err)
+			requireMined(t, backend, tx)
+		}
+
+		registry, err := bridgeClient.ethereumAuthorizerRegistry()
+		require.NoError(t, err)
+		a, err := registry.Authorizers(nil, crypto.PubkeyToAddress(keys[0].PublicKey))
+		require.NoError(t, err)
+		require.True(t, a.IsAuthorizer)
+
+		tx, err := bridgeClient.AddEthereumAuthorizer(ctx, crypto.PubkeyToAddress(outsider.PublicKey))
+		require.NoError(t, err)
+		requireMined(t, backend, tx)
+		tx, err = bridgeClient.RemoveEthereumAuthorizer(ctx, crypto.PubkeyToAddress(outsider.PublicKey))
+		require.NoError(t, err)
+		requireMined(t, backend, tx)
+
+		a, err = registry.Authorizers(nil, crypto.PubkeyToAddress(outsider.PublicKey))
+		require.NoError(t, err)
+		require.False(t, a.IsAuthorizer)
+	})
+
+	t.Run("user nonce", func(t *testing.T) {
+		nonce, err := bridgeClient.GetUserNonceMinted(ctx, backend.Owner.Address.Hex())
+		require.NoError(t, err)
+		require.Zero(t, nonce.Int64())
+	})
+
+	const burnHash = "a1b2c3d4"
+	to := backend.Owner.Address.Hex()
+	sign := func(key *ecdsa.PrivateKey, amount int64) *ProofZCNBurn {
+		registry, err := bridgeClient.ethereumAuthorizerRegistry()
+		require.NoError(t, err)
+		hash, err := registry.MessageHash(nil, common.HexToAddress(to), big.NewInt(amount),
+			DefaultClientIDEncoder(burnHash), big.NewInt(1))
+		require.NoError(t, err)
+		sig, err := crypto.Sign(accounts.TextHash(hash[:]), key)
+		require.NoError(t, err)
+		sig[crypto.RecoveryIDOffset] += 27
+		return &ProofZCNBurn{TxnID: burnHash, To: to, Nonce: 1, Amount: amount, Signature: sig}
+	}
+
+	t.Run("verify burn proofs", func(t *testing.T) {
+		registry, err := bridgeClient.ethereumAuthorizerRegistry()
+		require.NoError(t, err)
+
+		results := []JobResult{sign(keys[0], 100), sign(keys[1], 100), sign(keys[2], 100), sign(outsider, 100)}
+		for i, r := range results {
+			r.SetAuthorizerID(string(rune('a' + i)))
+		}
+		proofs := verifyZCNBurnProofs(results, burnHash, to, registry)
+		require.Len(t, proofs, 3)
+	})
+
+	t.Run("mint", func(t *testing.T) {
+		payload := &ethereum.MintPayload{ZCNTxnID: burnHash, Amount: 100, To: to, Nonce: 1}
+		for _, key := range []*ecdsa.PrivateKey{keys[0], outsider} {
+			payload.Signatures = append(payload.Signatures, &ethereum.AuthorizerSignature{Signature: sign(key, 100).Signature})
+		}
+		_, err := bridgeClient.MintWZCN(ctx, payload)
+		require.Error(t, err)
+
+		payload.Signatures = nil
+		for _, key := range keys {
+			payload.Signatures = append(payload.Signatures, &ethereum.AuthorizerSignature{Signature: sign(key, 100).Signature})
+		}
+		tx, err := bridgeClient.MintWZCN(ctx, payload)
+		require.NoError(t, err)
+		requireMined(t, backend, tx)
+
+		nonce, err := bridgeClient.GetUserNonceMinted(ctx, to)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, nonce.Int64())
+
+		balance, err := bridgeClient.GetTokenBalance()
+		require.NoError(t, err)
+		require.EqualValues(t, 100, balance.Int64())
+
+		// the nonce can not be minted twice
+		_, err = bridgeClient.MintWZCN(ctx, payload)
+		require.Error(t, err)
+	})
+
+	t.Run("burn", func(t *testing.T) {
+		// the bridge can not transfer the tokens without allowance
+		_, err := bridgeClient.BurnWZCN(ctx, 60)
+		require.Error(t, err)
+
+		tx, err := bridgeClient.IncreaseBurnerAllowance(ctx, 60)
+		require.NoError(t, err)
+		requireMined(t, backend, tx)
+
+		tx, err = bridgeClient.BurnWZCN(ctx, 60)
+		require.NoError(t, err)
+		receipt, err := bridgeClient.WaitMined(ctx, tx)
+		require.NoError(t, err)
+		require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+
+		balance, err := bridgeClient.GetTokenBalance()
+		require.NoError(t, err)
+		require.EqualValues(t, 40, balance.Int64())
+
+		// the allowance is spent
+		_, err = bridgeClient.BurnWZCN(ctx, 10)
+		require.Error(t, err)
+	})
+
+	t.Run("private key signer", func(t *testing.T) {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		s := ethsigner.NewPrivateKeySigner(key)
+		require.NoError(t, backend.Fund(s.Address(), simulated.DefaultBalance))
+
+		mintTestTokens(t, backend, common.HexToAddress(bridgeClient.TokenAddress), s.Address(), big.NewInt(100))
+
+		client := *bridgeClient
+		client.Signer = s
+		client.EthereumAddress = s.Address().Hex()
+		tx, err := client.IncreaseBurnerAllowance(ctx, 100)
+		require.NoError(t, err)
+		requireMined(t, backend, tx)
+		tx, err = client.BurnWZCN(ctx, 100)
+		require.NoError(t, err)
+		requireMined(t, backend, tx)
+
+		sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(simulated.ChainID)), tx)
+		require.NoError(t, err)
+		require.Equal(t, s.Address(), sender)
+	})
+}
+
+func TestSimulatedToken(t *testing.T) {
+	bridgeClient, backend := newSimulatedBridgeClient(t)
+	token, err := zcntoken.NewToken(common.HexToAddress(bridgeClient.TokenAddress), backend)
+	require.NoError(t, err)
+	owner, err := backend.TransactOpts(backend.Owner)
+	require.NoError(t, err)
+	holder, err := backend.NewAccount()
+	require.NoError(t, err)
+	opts, err := backend.TransactOpts(holder)
+	require.NoError(t, err)
+
+	symbol, err := token.Symbol(nil)
+	require.NoError(t, err)
+	require.Equal(t, tokenSymbol, symbol)
+	decimals, err := token.Decimals(nil)
+	require.NoError(t, err)
+	require.EqualValues(t, tokenDecimals, decimals)
+
+	t.Run("source amount", func(t *testing.T) {
+		ctx := context.Background()
+		decimals, err := bridgeClient.tokenDecimals(ctx, common.HexToAddress(bridgeClient.TokenAddress))
+		require.NoError(t, err)
+		require.EqualValues(t, tokenDecimals, decimals)
+		decimals, err = bridgeClient.tokenDecimals(ctx, swap.ETHAddress)
+		require.NoError(t, err)
+		require.EqualValues(t, 18, decimals)
+
+		// 2 ZCN at 0.5 source tokens per ZCN
+		amount, err := sourceAmount(2e10, big.NewFloat(0.5), 6)
+		require.NoError(t, err)
+		require.EqualValues(t, 1e6, amount.Int64())
+		amount, err = sourceAmount(2e10, big.NewFloat(0.5), 18)
+		require.NoError(t, err)
+		require.Equal(t, big.NewInt(1e18), amount)
+	})
+
+	balanceOf := func(address common.Address) int64 {
+		balance, err := token.BalanceOf(nil, address)
+		require.NoError(t, err)
+		return balance.Int64()
+	}
+
+	t.Run("mint", func(t *testing.T) {
+		_, err := token.Mint(opts, holder.Address, big.NewInt(10))
+		require.Error(t, err)
+
+		_, err = token.Mint(owner, holder.Address, big.NewInt(50))
+		require.NoError(t, err)
+		require.EqualValues(t, 50, balanceOf(holder.Address))
+		supply, err := token.TotalSupply(nil)
+		require.NoError(t, err)
+		require.EqualValues(t, 1050, supply.Int64())
+	})
+
+	t.Run("transfer", func(t *testing.T) {
+		_, err := token.Transfer(opts, backend.Owner.Address, big.NewInt(51))
+		require.Error(t, err)
+		_, err = token.Transfer(opts, backend.Owner.Address, big.NewInt(20))
+		require.NoError(t, err)
+		require.EqualValues(t, 30, balanceOf(holder.Address))
+		require.EqualValues(t, 20, balanceOf(backend.Owner.Address))
+	})
+
+	t.Run("allowance", func(t *testing.T) {
+		_, err := token.IncreaseApproval(opts, backend.Owner.Address, big.NewInt(15))
+		require.NoError(t, err)
+		_, err = token.DecreaseApproval(opts, backend.Owner.Address, big.NewInt(5))
+		require.NoError(t, err)
+		allowance, err := token.Allowance(nil, holder.Address, backend.Owner.Address)
+		require.NoError(t, err)
+		require.EqualValues(t, 10, allowance.Int64())
+
+		_, err = token.TransferFrom(owner, holder.Address, backend.Owner.Address, big.NewInt(11))
+		require.Error(t, err)
+		_, err = token.TransferFrom(owner, holder.Address, backend.Owner.Address, big.NewInt(10))
+		require.NoError(t, err)
+		require.EqualValues(t, 20, balanceOf(holder.Address))
+
+		_, err = token.DecreaseApproval(opts, backend.Owner.Address, big.NewInt(5))
+		require.NoError(t, err)
+		allowance, err = token.Allowance(nil, holder.Address, backend.Owner.Address)
+		require.NoError(t, err)
+		require.Zero(t, allowance.Int64())
+	})
+
+	t.Run("finish minting", func(t *testing.T) {
+		_, err := token.FinishMinting(owner)
+		require.NoError(t, err)
+		_, err = token.Mint(owner, holder.Address, big.NewInt(1))
+		require.Error(t, err)
+
+		balance, err := bridgeClient.GetTokenBalance()
+		require.NoError(t, err)
+		require.EqualValues(t, 30, balance.Int64())
+	})
+}
+
+func TestSimulatedNFTConfig(t *testing.T) {
+	ctx := context.Background()
+	bridgeClient, backend := newSimulatedBridgeClient(t)
+
+	t.Run("uint256", func(t *testing.T) {
+		tx, err := bridgeClient.NFTConfigSetUint256(ctx, "royalty", 250)
+		require.NoError(t, err)
+		requireMined(t, backend, tx)
+
+		key, value, err := bridgeClient.NFTConfigGetUint256(ctx, "royalty")
+		require.NoError(t, err)
+		require.Equal(t, crypto.Keccak256Hash([]byte("royalty")).String(), key)
+		require.EqualValues(t, 250, value)
+
+		_, value, err = bridgeClient.NFTConfigGetUint256(ctx, "unset")
+		require.NoError(t, err)
+		require.Zero(t, value)
+	})
+
+	t.Run("address", func(t *testing.T) {
+		address := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+		tx, err := bridgeClient.NFTConfigSetAddress(ctx, "receiver", address.Hex())
+		require.NoError(t, err)
+		requireMined(t, backend, tx)
+
+		_, value, err := bridgeClient.NFTConfigGetAddress(ctx, "receiver")
+		require.NoError(t, err)
+		require.Equal(t, address.Hex(), value)
+	})
+
+	t.Run("owner only", func(t *testing.T) {
+		cfg, err := nftconfig.NewNFTConfig(common.HexToAddress(bridgeClient.NFTConfigAddress), backend)
+		require.NoError(t, err)
+		account, err := backend.NewAccount()
+		require.NoError(t, err)
+		opts, err := backend.TransactOpts(account)
+		require.NoError(t, err)
+		opts.GasLimit = 100_000
+
+		tx, err := cfg.SetUint256(opts, crypto.Keccak256Hash([]byte("royalty")), big.NewInt(1))
+		require.NoError(t, err)
+		receipt, err := backend.TransactionReceipt(ctx, tx.Hash())
+		require.NoError(t, err)
+		require.Equal(t, types.ReceiptStatusFailed, receipt.Status)
+
+		_, value, err := bridgeClient.NFTConfigGetUint256(ctx, "royalty")
+		require.NoError(t, err)
+		require.EqualValues(t, 250, value)
+	})
+}
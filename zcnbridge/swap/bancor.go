@@ -0,0 +1,79 @@
+package swap
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// The parts of the Bancor network and network info ABIs used to swap.
+const (
+	bancorNetworkABI = `[
+{"name":"tradeByTargetAmount","type":"function","stateMutability":"payable","inputs":[{"name":"sourceToken","type":"address"},{"name":"targetToken","type":"address"},{"name":"targetAmount","type":"uint256"},{"name":"maxSourceAmount","type":"uint256"},{"name":"deadline","type":"uint256"},{"name":"beneficiary","type":"address"}],"outputs":[{"name":"","type":"uint256"}]}
+]`
+
+	bancorNetworkInfoABI = `[
+{"name":"tradeInputByTargetAmount","type":"function","stateMutability":"view","inputs":[{"name":"sourceToken","type":"address"},{"name":"targetToken","type":"address"},{"name":"targetAmount","type":"uint256"}],"outputs":[{"name":"","type":"uint256"}]}
+]`
+)
+
+var (
+	bancorNetwork     = mustParseABI(bancorNetworkABI)
+	bancorNetworkInfo = mustParseABI(bancorNetworkInfoABI)
+)
+
+// Bancor swaps on the Bancor V3 network, quoting with its network info
+// contract. Bancor trades ETH as ETHAddress itself.
+type Bancor struct {
+	network common.Address
+	info    common.Address
+	caller  Caller
+}
+
+// NewBancor creates a provider of the Bancor network, quoting with the
+// BancorNetworkInfo contract.
+func NewBancor(network, info common.Address, caller Caller) *Bancor {
+	return &Bancor{network: network, info: info, caller: caller}
+}
+
+// Name implements Provider.
+func (b *Bancor) Name() string {
+	return "bancor"
+}
+
+// QuoteExactOutput implements Provider.
+func (b *Bancor) QuoteExactOutput(ctx context.Context, source, target common.Address, amountOut *big.Int) (*Quote, error) {
+	out, err := call(ctx, b.caller, b.info, bancorNetworkInfo, "tradeInputByTargetAmount", source, target, amountOut)
+	if err != nil {
+		// no pool of the tokens
+		return nil, errors.Wrap(ErrNoQuote, err.Error())
+	}
+	amountIn, ok := out[0].(*big.Int)
+	if !ok || amountIn.Sign() <= 0 {
+		return nil, ErrNoQuote
+	}
+
+	quote := &Quote{Source: source, Target: target, AmountIn: amountIn, AmountOut: amountOut}
+	if source != ETHAddress {
+		quote.Spender = b.network
+	}
+	return quote, nil
+}
+
+// SwapExactOutput implements Provider.
+func (b *Bancor) SwapExactOutput(quote *Quote, maxAmountIn *big.Int, recipient common.Address, deadline time.Time) (*Call, error) {
+	data, err := bancorNetwork.Pack("tradeByTargetAmount", quote.Source, quote.Target, quote.AmountOut, maxAmountIn,
+		big.NewInt(deadline.Unix()), recipient)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to pack tradeByTargetAmount")
+	}
+
+	value := new(big.Int)
+	if quote.Source == ETHAddress {
+		value = maxAmountIn
+	}
+	return &Call{To: b.network, Value: value, Data: data}, nil
+}
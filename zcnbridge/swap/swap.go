@@ -0,0 +1,157 @@
+// Package swap quotes and builds swaps of ERC-20 tokens or ETH into another
+// token across exchanges, like Bancor and Uniswap.
+package swap
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// ETHAddress stands for ETH as the source token of a swap.
+var ETHAddress = common.HexToAddress("0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE")
+
+const (
+	// DefaultDeadline is the time a swap may wait to be mined when no deadline is given.
+	DefaultDeadline = 20 * time.Minute
+	// DefaultSlippage is the default accepted price move, in percent.
+	DefaultSlippage = 0.5
+	// MaxSlippage is the highest accepted slippage, in percent.
+	MaxSlippage = 50
+)
+
+var (
+	// ErrNoQuote no provider can quote the swap
+	ErrNoQuote = errors.New("swap: no quote")
+	// ErrUnknownProvider the quote was made by a provider the router does not know
+	ErrUnknownProvider = errors.New("swap: unknown provider")
+	// ErrInvalidSlippage the slippage is negative or higher than MaxSlippage
+	ErrInvalidSlippage = errors.New("swap: invalid slippage")
+	// ErrDeadlineExpired the swap deadline is in the past
+	ErrDeadlineExpired = errors.New("swap: deadline expired")
+)
+
+// Call is an Ethereum transaction performing a swap.
+type Call struct {
+	To    common.Address
+	Value *big.Int
+	Data  []byte
+}
+
+// Quote is the price of buying AmountOut target tokens with source tokens.
+type Quote struct {
+	Provider  string         `json:"provider"`
+	Source    common.Address `json:"source"`
+	Target    common.Address `json:"target"`
+	AmountIn  *big.Int       `json:"amount_in"`
+	AmountOut *big.Int       `json:"amount_out"`
+	// Spender must be approved to spend the ERC-20 source tokens, zero for ETH
+	Spender common.Address `json:"spender"`
+
+	// Path is the token route of providers trading along a path
+	Path []common.Address `json:"path,omitempty"`
+	// Fee is the pool fee tier of providers with fee tiers
+	Fee *big.Int `json:"fee,omitempty"`
+}
+
+// Provider quotes and builds swaps on one exchange.
+type Provider interface {
+	// Name identifies the provider of a quote.
+	Name() string
+	// QuoteExactOutput returns the source amount needed to buy amountOut target tokens.
+	QuoteExactOutput(ctx context.Context, source, target common.Address, amountOut *big.Int) (*Quote, error)
+	// SwapExactOutput returns the call buying quote.AmountOut target tokens for
+	// recipient, spending at most maxAmountIn source tokens before deadline.
+	SwapExactOutput(quote *Quote, maxAmountIn *big.Int, recipient common.Address, deadline time.Time) (*Call, error)
+}
+
+// Router quotes swaps across providers.
+type Router struct {
+	Providers []Provider
+}
+
+// NewRouter creates a router of providers.
+func NewRouter(providers ...Provider) *Router {
+	return &Router{Providers: providers}
+}
+
+// Quotes returns the quotes of all providers able to quote the swap, the
+// cheapest first. It returns ErrNoQuote if none can.
+func (r *Router) Quotes(ctx context.Context, source, target common.Address, amountOut *big.Int) ([]*Quote, error) {
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		quotes []*Quote
+		errs   []string
+	)
+	for _, p := range r.Providers {
+		wg.Add(1)
+		go func(p Provider) {
+			defer wg.Done()
+			q, err := p.QuoteExactOutput(ctx, source, target, amountOut)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, p.Name()+": "+err.Error())
+				return
+			}
+			q.Provider = p.Name()
+			quotes = append(quotes, q)
+		}(p)
+	}
+	wg.Wait()
+
+	if len(quotes) == 0 {
+		return nil, errors.Wrap(ErrNoQuote, strings.Join(errs, "; "))
+	}
+	sort.Slice(quotes, func(i, j int) bool {
+		return quotes[i].AmountIn.Cmp(quotes[j].AmountIn) < 0
+	})
+	return quotes, nil
+}
+
+// Swap returns the call executing quote, spending at most the quoted amount
+// plus slippage percent. A zero deadline is DefaultDeadline from now.
+func (r *Router) Swap(quote *Quote, slippage float64, recipient common.Address, deadline time.Time) (*Call, error) {
+	var provider Provider
+	for _, p := range r.Providers {
+		if p.Name() == quote.Provider {
+			provider = p
+			break
+		}
+	}
+	if provider == nil {
+		return nil, errors.Wrap(ErrUnknownProvider, quote.Provider)
+	}
+
+	maxAmountIn, err := MaxAmountIn(quote.AmountIn, slippage)
+	if err != nil {
+		return nil, err
+	}
+	if deadline.IsZero() {
+		deadline = time.Now().Add(DefaultDeadline)
+	} else if !deadline.After(time.Now()) {
+		return nil, ErrDeadlineExpired
+	}
+	return provider.SwapExactOutput(quote, maxAmountIn, recipient, deadline)
+}
+
+// MaxAmountIn returns amountIn raised by slippage percent.
+func MaxAmountIn(amountIn *big.Int, slippage float64) (*big.Int, error) {
+	if slippage < 0 || slippage > MaxSlippage {
+		return nil, ErrInvalidSlippage
+	}
+	// slippage in basis points keeps the math in integers
+	bps := big.NewInt(int64(slippage*100 + 0.5))
+	max := new(big.Int).Mul(amountIn, bps.Add(bps, big.NewInt(10000)))
+	// round up, so the limit never drops below the quote
+	max.Add(max, big.NewInt(9999))
+	return max.Div(max, big.NewInt(10000)), nil
+}
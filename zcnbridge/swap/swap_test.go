@@ -0,0 +1,306 @@
+package swap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	weth   = common.HexToAddress("0x0a")
+	usdc   = common.HexToAddress("0x0b")
+	wzcn   = common.HexToAddress("0x0c")
+	router = common.HexToAddress("0x0d")
+	quoter = common.HexToAddress("0x0e")
+	user   = common.HexToAddress("0x0f")
+)
+
+// fakeCaller answers contract calls by method, reverting unknown calls.
+type fakeCaller struct {
+	contract abi.ABI
+	methods  map[string]func(args []interface{}) ([]interface{}, error)
+}
+
+func (c *fakeCaller) CallContract(_ context.Context, call eth.CallMsg, _ *big.Int) ([]byte, error) {
+	method, err := c.contract.MethodById(call.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+	handler, ok := c.methods[method.Name]
+	if !ok {
+		return nil, errors.New("execution reverted")
+	}
+	args, err := method.Inputs.Unpack(call.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	out, err := handler(args)
+	if err != nil {
+		return nil, err
+	}
+	return method.Outputs.Pack(out...)
+}
+
+func newUniswapV2Caller() *fakeCaller {
+	return &fakeCaller{
+		contract: uniswapV2Router,
+		methods: map[string]func([]interface{}) ([]interface{}, error){
+			"WETH": func([]interface{}) ([]interface{}, error) {
+				return []interface{}{weth}, nil
+			},
+			"getAmountsIn": func(args []interface{}) ([]interface{}, error) {
+				path := args[1].([]common.Address)
+				amountOut := args[0].(*big.Int)
+				// the direct USDC pair is more expensive than the route through WETH
+				var amountIn int64
+				switch {
+				case len(path) == 2 && path[0] == usdc:
+					amountIn = 300
+				case len(path) == 3 && path[0] == usdc:
+					amountIn = 200
+				case len(path) == 2 && path[0] == weth:
+					amountIn = 2
+				default:
+					return nil, errors.New("execution reverted")
+				}
+				return []interface{}{[]*big.Int{big.NewInt(amountIn), amountOut}}, nil
+			},
+		},
+	}
+}
+
+func newUniswapV3Caller() *fakeCaller {
+	contract := abi.ABI{Methods: map[string]abi.Method{}}
+	for _, parsed := range []abi.ABI{uniswapV3Router, uniswapV3Quoter} {
+		for name, method := range parsed.Methods {
+			contract.Methods[name] = method
+		}
+	}
+	return &fakeCaller{
+		contract: contract,
+		methods: map[string]func([]interface{}) ([]interface{}, error){
+			"WETH9": func([]interface{}) ([]interface{}, error) {
+				return []interface{}{weth}, nil
+			},
+			"quoteExactOutputSingle": func(args []interface{}) ([]interface{}, error) {
+				params := args[0].(struct {
+					TokenIn           common.Address `json:"tokenIn"`
+					TokenOut          common.Address `json:"tokenOut"`
+					Amount            *big.Int       `json:"amount"`
+					Fee               *big.Int       `json:"fee"`
+					SqrtPriceLimitX96 *big.Int       `json:"sqrtPriceLimitX96"`
+				})
+				amountIn := map[int64]int64{3000: 150, 10000: 180}[params.Fee.Int64()]
+				if amountIn == 0 {
+					return nil, errors.New("execution reverted")
+				}
+				return []interface{}{big.NewInt(amountIn), new(big.Int), uint32(1), new(big.Int)}, nil
+			},
+		},
+	}
+}
+
+func newBancorCaller() *fakeCaller {
+	return &fakeCaller{
+		contract: bancorNetworkInfo,
+		methods: map[string]func([]interface{}) ([]interface{}, error){
+			"tradeInputByTargetAmount": func(args []interface{}) ([]interface{}, error) {
+				if args[0].(common.Address) != ETHAddress && args[0].(common.Address) != usdc {
+					return nil, errors.New("execution reverted")
+				}
+				// 2 source tokens per target token
+				return []interface{}{new(big.Int).Mul(args[2].(*big.Int), big.NewInt(2))}, nil
+			},
+		},
+	}
+}
+
+// fixedProvider quotes a fixed amount.
+type fixedProvider struct {
+	name     string
+	amountIn int64
+}
+
+func (p *fixedProvider) Name() string {
+	return p.name
+}
+
+func (p *fixedProvider) QuoteExactOutput(_ context.Context, source, target common.Address, amountOut *big.Int) (*Quote, error) {
+	if p.amountIn == 0 {
+		return nil, errors.New("no pool")
+	}
+	return &Quote{Source: source, Target: target, AmountIn: big.NewInt(p.amountIn), AmountOut: amountOut}, nil
+}
+
+func (p *fixedProvider) SwapExactOutput(quote *Quote, maxAmountIn *big.Int, _ common.Address, _ time.Time) (*Call, error) {
+	return &Call{Value: maxAmountIn}, nil
+}
+
+func TestUniswapV2(t *testing.T) {
+	ctx := context.Background()
+	u := NewUniswapV2(router, newUniswapV2Caller())
+
+	t.Run("token", func(t *testing.T) {
+		q, err := u.QuoteExactOutput(ctx, usdc, wzcn, big.NewInt(1000))
+		require.NoError(t, err)
+		require.EqualValues(t, 200, q.AmountIn.Int64())
+		require.Equal(t, []common.Address{usdc, weth, wzcn}, q.Path)
+		require.Equal(t, router, q.Spender)
+
+		c, err := u.SwapExactOutput(q, big.NewInt(201), user, time.Now())
+		require.NoError(t, err)
+		require.Equal(t, router, c.To)
+		require.Zero(t, c.Value.Sign())
+		require.Equal(t, uniswapV2Router.Methods["swapTokensForExactTokens"].ID, c.Data[:4])
+	})
+
+	t.Run("eth", func(t *testing.T) {
+		q, err := u.QuoteExactOutput(ctx, ETHAddress, wzcn, big.NewInt(1000))
+		require.NoError(t, err)
+		require.EqualValues(t, 2, q.AmountIn.Int64())
+		require.Equal(t, []common.Address{weth, wzcn}, q.Path)
+		require.Equal(t, common.Address{}, q.Spender)
+
+		c, err := u.SwapExactOutput(q, big.NewInt(3), user, time.Now())
+		require.NoError(t, err)
+		require.EqualValues(t, 3, c.Value.Int64())
+
+		args, err := uniswapV2Router.Methods["swapETHForExactTokens"].Inputs.Unpack(c.Data[4:])
+		require.NoError(t, err)
+		require.Equal(t, []common.Address{weth, wzcn}, args[1])
+		require.Equal(t, user, args[2])
+	})
+
+	t.Run("no pair", func(t *testing.T) {
+		_, err := u.QuoteExactOutput(ctx, common.HexToAddress("0x01"), wzcn, big.NewInt(1000))
+		require.ErrorIs(t, err, ErrNoQuote)
+	})
+}
+
+func TestUniswapV3(t *testing.T) {
+	ctx := context.Background()
+	u := NewUniswapV3(router, quoter, newUniswapV3Caller())
+
+	q, err := u.QuoteExactOutput(ctx, ETHAddress, wzcn, big.NewInt(1000))
+	require.NoError(t, err)
+	require.EqualValues(t, 150, q.AmountIn.Int64())
+	require.EqualValues(t, 3000, q.Fee.Int64())
+
+	c, err := u.SwapExactOutput(q, big.NewInt(160), user, time.Now())
+	require.NoError(t, err)
+	require.EqualValues(t, 160, c.Value.Int64())
+	require.Equal(t, uniswapV3Router.Methods["multicall"].ID, c.Data[:4])
+
+	t.Run("serialized quote", func(t *testing.T) {
+		data, err := json.Marshal(q)
+		require.NoError(t, err)
+		var decoded Quote
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		require.Equal(t, q.Path, decoded.Path)
+		require.EqualValues(t, 3000, decoded.Fee.Int64())
+
+		sc, err := u.SwapExactOutput(&decoded, big.NewInt(160), user, time.Now())
+		require.NoError(t, err)
+		require.Equal(t, c.To, sc.To)
+	})
+
+	u.Fees = []int64{500}
+	_, err = u.QuoteExactOutput(ctx, usdc, wzcn, big.NewInt(1000))
+	require.ErrorIs(t, err, ErrNoQuote)
+}
+
+func TestBancor(t *testing.T) {
+	ctx := context.Background()
+	network := common.HexToAddress("0x10")
+	b := NewBancor(network, quoter, newBancorCaller())
+
+	t.Run("token", func(t *testing.T) {
+		q, err := b.QuoteExactOutput(ctx, usdc, wzcn, big.NewInt(1000))
+		require.NoError(t, err)
+		require.EqualValues(t, 2000, q.AmountIn.Int64())
+		require.Equal(t, network, q.Spender)
+
+		c, err := b.SwapExactOutput(q, big.NewInt(2010), user, time.Now())
+		require.NoError(t, err)
+		require.Equal(t, network, c.To)
+		require.Zero(t, c.Value.Sign())
+
+		args, err := bancorNetwork.Methods["tradeByTargetAmount"].Inputs.Unpack(c.Data[4:])
+		require.NoError(t, err)
+		require.Equal(t, usdc, args[0])
+		require.EqualValues(t, 1000, args[2].(*big.Int).Int64())
+		require.EqualValues(t, 2010, args[3].(*big.Int).Int64())
+		require.Equal(t, user, args[5])
+	})
+
+	t.Run("eth", func(t *testing.T) {
+		q, err := b.QuoteExactOutput(ctx, ETHAddress, wzcn, big.NewInt(1000))
+		require.NoError(t, err)
+		require.Equal(t, common.Address{}, q.Spender)
+
+		c, err := b.SwapExactOutput(q, big.NewInt(2010), user, time.Now())
+		require.NoError(t, err)
+		require.EqualValues(t, 2010, c.Value.Int64())
+	})
+
+	t.Run("no pool", func(t *testing.T) {
+		_, err := b.QuoteExactOutput(ctx, common.HexToAddress("0x01"), wzcn, big.NewInt(1000))
+		require.ErrorIs(t, err, ErrNoQuote)
+	})
+}
+
+func TestRouter(t *testing.T) {
+	ctx := context.Background()
+	r := NewRouter(&fixedProvider{name: "a", amountIn: 300}, &fixedProvider{name: "b", amountIn: 100},
+		&fixedProvider{name: "c"})
+
+	quotes, err := r.Quotes(ctx, usdc, wzcn, big.NewInt(1000))
+	require.NoError(t, err)
+	require.Len(t, quotes, 2)
+	require.Equal(t, "b", quotes[0].Provider)
+	require.Equal(t, "a", quotes[1].Provider)
+
+	t.Run("swap", func(t *testing.T) {
+		c, err := r.Swap(quotes[0], 1, user, time.Time{})
+		require.NoError(t, err)
+		require.EqualValues(t, 101, c.Value.Int64())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := r.Swap(quotes[0], -1, user, time.Time{})
+		require.ErrorIs(t, err, ErrInvalidSlippage)
+
+		_, err = r.Swap(quotes[0], 1, user, time.Now().Add(-time.Minute))
+		require.ErrorIs(t, err, ErrDeadlineExpired)
+
+		_, err = r.Swap(&Quote{Provider: "d", AmountIn: big.NewInt(1)}, 1, user, time.Time{})
+		require.ErrorIs(t, err, ErrUnknownProvider)
+	})
+
+	t.Run("no quote", func(t *testing.T) {
+		_, err := NewRouter(&fixedProvider{name: "c"}).Quotes(ctx, usdc, wzcn, big.NewInt(1000))
+		require.ErrorIs(t, err, ErrNoQuote)
+	})
+}
+
+func TestMaxAmountIn(t *testing.T) {
+	max, err := MaxAmountIn(big.NewInt(1000), 0.5)
+	require.NoError(t, err)
+	require.EqualValues(t, 1005, max.Int64())
+
+	// rounds up
+	max, err = MaxAmountIn(big.NewInt(1), 0.5)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, max.Int64())
+
+	_, err = MaxAmountIn(big.NewInt(1), MaxSlippage+1)
+	require.ErrorIs(t, err, ErrInvalidSlippage)
+}
@@ -0,0 +1,319 @@
+package swap
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// The parts of the Uniswap router and quoter ABIs used to swap.
+const (
+	uniswapV2RouterABI = `[
+{"name":"WETH","type":"function","stateMutability":"pure","inputs":[],"outputs":[{"name":"","type":"address"}]},
+{"name":"getAmountsIn","type":"function","stateMutability":"view","inputs":[{"name":"amountOut","type":"uint256"},{"name":"path","type":"address[]"}],"outputs":[{"name":"amounts","type":"uint256[]"}]},
+{"name":"swapTokensForExactTokens","type":"function","stateMutability":"nonpayable","inputs":[{"name":"amountOut","type":"uint256"},{"name":"amountInMax","type":"uint256"},{"name":"path","type":"address[]"},{"name":"to","type":"address"},{"name":"deadline","type":"uint256"}],"outputs":[{"name":"amounts","type":"uint256[]"}]},
+{"name":"swapETHForExactTokens","type":"function","stateMutability":"payable","inputs":[{"name":"amountOut","type":"uint256"},{"name":"path","type":"address[]"},{"name":"to","type":"address"},{"name":"deadline","type":"uint256"}],"outputs":[{"name":"amounts","type":"uint256[]"}]}
+]`
+
+	uniswapV3RouterABI = `[
+{"name":"WETH9","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"address"}]},
+{"name":"exactOutputSingle","type":"function","stateMutability":"payable","inputs":[{"name":"params","type":"tuple","components":[{"name":"tokenIn","type":"address"},{"name":"tokenOut","type":"address"},{"name":"fee","type":"uint24"},{"name":"recipient","type":"address"},{"name":"deadline","type":"uint256"},{"name":"amountOut","type":"uint256"},{"name":"amountInMaximum","type":"uint256"},{"name":"sqrtPriceLimitX96","type":"uint160"}]}],"outputs":[{"name":"amountIn","type":"uint256"}]},
+{"name":"refundETH","type":"function","stateMutability":"payable","inputs":[],"outputs":[]},
+{"name":"multicall","type":"function","stateMutability":"payable","inputs":[{"name":"data","type":"bytes[]"}],"outputs":[{"name":"results","type":"bytes[]"}]}
+]`
+
+	uniswapV3QuoterABI = `[
+{"name":"quoteExactOutputSingle","type":"function","stateMutability":"nonpayable","inputs":[{"name":"params","type":"tuple","components":[{"name":"tokenIn","type":"address"},{"name":"tokenOut","type":"address"},{"name":"amount","type":"uint256"},{"name":"fee","type":"uint24"},{"name":"sqrtPriceLimitX96","type":"uint160"}]}],"outputs":[{"name":"amountIn","type":"uint256"},{"name":"sqrtPriceX96After","type":"uint160"},{"name":"initializedTicksCrossed","type":"uint32"},{"name":"gasEstimate","type":"uint256"}]}
+]`
+)
+
+var (
+	uniswapV2Router = mustParseABI(uniswapV2RouterABI)
+	uniswapV3Router = mustParseABI(uniswapV3RouterABI)
+	uniswapV3Quoter = mustParseABI(uniswapV3QuoterABI)
+)
+
+// DefaultUniswapV3Fees are the pool fee tiers quoted, in hundredths of a bip.
+var DefaultUniswapV3Fees = []int64{500, 3000, 10000}
+
+// Caller calls contracts, implemented by Ethereum clients.
+type Caller interface {
+	CallContract(ctx context.Context, call eth.CallMsg, blockNumber *big.Int) ([]byte, error)
+}
+
+// UniswapV2 swaps with a Uniswap V2 router, directly or through WETH.
+type UniswapV2 struct {
+	router common.Address
+	caller Caller
+
+	mu   sync.Mutex
+	weth common.Address
+}
+
+// NewUniswapV2 creates a provider of the Uniswap V2 router.
+func NewUniswapV2(router common.Address, caller Caller) *UniswapV2 {
+	return &UniswapV2{router: router, caller: caller}
+}
+
+// Name implements Provider.
+func (u *UniswapV2) Name() string {
+	return "uniswap_v2"
+}
+
+// QuoteExactOutput implements Provider.
+func (u *UniswapV2) QuoteExactOutput(ctx context.Context, source, target common.Address, amountOut *big.Int) (*Quote, error) {
+	weth, err := u.wethAddress(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenIn := source
+	if source == ETHAddress {
+		tokenIn = weth
+	}
+	paths := [][]common.Address{{tokenIn, target}}
+	if tokenIn != weth && target != weth {
+		paths = append(paths, []common.Address{tokenIn, weth, target})
+	}
+
+	var best *Quote
+	for _, path := range paths {
+		out, err := call(ctx, u.caller, u.router, uniswapV2Router, "getAmountsIn", amountOut, path)
+		if err != nil {
+			// no pair on the path
+			continue
+		}
+		amounts, ok := out[0].([]*big.Int)
+		if !ok || len(amounts) == 0 {
+			continue
+		}
+		if best == nil || amounts[0].Cmp(best.AmountIn) < 0 {
+			best = &Quote{Source: source, Target: target, AmountIn: amounts[0], AmountOut: amountOut, Path: path}
+		}
+	}
+	if best == nil {
+		return nil, ErrNoQuote
+	}
+	if source != ETHAddress {
+		best.Spender = u.router
+	}
+	return best, nil
+}
+
+// SwapExactOutput implements Provider.
+func (u *UniswapV2) SwapExactOutput(quote *Quote, maxAmountIn *big.Int, recipient common.Address, deadline time.Time) (*Call, error) {
+	unix := big.NewInt(deadline.Unix())
+	if quote.Source == ETHAddress {
+		data, err := uniswapV2Router.Pack("swapETHForExactTokens", quote.AmountOut, quote.Path, recipient, unix)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to pack swapETHForExactTokens")
+		}
+		return &Call{To: u.router, Value: maxAmountIn, Data: data}, nil
+	}
+
+	data, err := uniswapV2Router.Pack("swapTokensForExactTokens", quote.AmountOut, maxAmountIn, quote.Path, recipient, unix)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to pack swapTokensForExactTokens")
+	}
+	return &Call{To: u.router, Value: new(big.Int), Data: data}, nil
+}
+
+func (u *UniswapV2) wethAddress(ctx context.Context) (common.Address, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.weth == (common.Address{}) {
+		weth, err := callAddress(ctx, u.caller, u.router, uniswapV2Router, "WETH")
+		if err != nil {
+			return common.Address{}, err
+		}
+		u.weth = weth
+	}
+	return u.weth, nil
+}
+
+// UniswapV3 swaps in the single Uniswap V3 pool with the best price among
+// the fee tiers.
+type UniswapV3 struct {
+	router common.Address
+	quoter common.Address
+	caller Caller
+	// Fees are the quoted fee tiers, DefaultUniswapV3Fees if empty
+	Fees []int64
+
+	mu   sync.Mutex
+	weth common.Address
+}
+
+// NewUniswapV3 creates a provider of the Uniswap V3 swap router, quoting
+// with the QuoterV2 contract.
+func NewUniswapV3(router, quoter common.Address, caller Caller) *UniswapV3 {
+	return &UniswapV3{router: router, quoter: quoter, caller: caller}
+}
+
+// Name implements Provider.
+func (u *UniswapV3) Name() string {
+	return "uniswap_v3"
+}
+
+type uniswapV3QuoteParams struct {
+	TokenIn           common.Address
+	TokenOut          common.Address
+	Amount            *big.Int
+	Fee               *big.Int
+	SqrtPriceLimitX96 *big.Int
+}
+
+type uniswapV3SwapParams struct {
+	TokenIn           common.Address
+	TokenOut          common.Address
+	Fee               *big.Int
+	Recipient         common.Address
+	Deadline          *big.Int
+	AmountOut         *big.Int
+	AmountInMaximum   *big.Int
+	SqrtPriceLimitX96 *big.Int
+}
+
+// QuoteExactOutput implements Provider.
+func (u *UniswapV3) QuoteExactOutput(ctx context.Context, source, target common.Address, amountOut *big.Int) (*Quote, error) {
+	tokenIn := source
+	if source == ETHAddress {
+		weth, err := u.wethAddress(ctx)
+		if err != nil {
+			return nil, err
+		}
+		tokenIn = weth
+	}
+
+	fees := u.Fees
+	if len(fees) == 0 {
+		fees = DefaultUniswapV3Fees
+	}
+
+	var best *Quote
+	for _, fee := range fees {
+		params := uniswapV3QuoteParams{
+			TokenIn:           tokenIn,
+			TokenOut:          target,
+			Amount:            amountOut,
+			Fee:               big.NewInt(fee),
+			SqrtPriceLimitX96: new(big.Int),
+		}
+		out, err := call(ctx, u.caller, u.quoter, uniswapV3Quoter, "quoteExactOutputSingle", params)
+		if err != nil {
+			// no pool of the fee tier
+			continue
+		}
+		amountIn, ok := out[0].(*big.Int)
+		if !ok {
+			continue
+		}
+		if best == nil || amountIn.Cmp(best.AmountIn) < 0 {
+			best = &Quote{Source: source, Target: target, AmountIn: amountIn, AmountOut: amountOut,
+				Path: []common.Address{tokenIn, target}, Fee: big.NewInt(fee)}
+		}
+	}
+	if best == nil {
+		return nil, ErrNoQuote
+	}
+	if source != ETHAddress {
+		best.Spender = u.router
+	}
+	return best, nil
+}
+
+// SwapExactOutput implements Provider.
+func (u *UniswapV3) SwapExactOutput(quote *Quote, maxAmountIn *big.Int, recipient common.Address, deadline time.Time) (*Call, error) {
+	if len(quote.Path) != 2 || quote.Fee == nil {
+		return nil, errors.New("swap: not a uniswap v3 quote")
+	}
+	params := uniswapV3SwapParams{
+		TokenIn:           quote.Path[0],
+		TokenOut:          quote.Path[1],
+		Fee:               quote.Fee,
+		Recipient:         recipient,
+		Deadline:          big.NewInt(deadline.Unix()),
+		AmountOut:         quote.AmountOut,
+		AmountInMaximum:   maxAmountIn,
+		SqrtPriceLimitX96: new(big.Int),
+	}
+	swap, err := uniswapV3Router.Pack("exactOutputSingle", params)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to pack exactOutputSingle")
+	}
+	if quote.Source != ETHAddress {
+		return &Call{To: u.router, Value: new(big.Int), Data: swap}, nil
+	}
+
+	// the router keeps the ETH left over, refund it in the same transaction
+	refund, err := uniswapV3Router.Pack("refundETH")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to pack refundETH")
+	}
+	data, err := uniswapV3Router.Pack("multicall", [][]byte{swap, refund})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to pack multicall")
+	}
+	return &Call{To: u.router, Value: maxAmountIn, Data: data}, nil
+}
+
+func (u *UniswapV3) wethAddress(ctx context.Context) (common.Address, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.weth == (common.Address{}) {
+		weth, err := callAddress(ctx, u.caller, u.router, uniswapV3Router, "WETH9")
+		if err != nil {
+			return common.Address{}, err
+		}
+		u.weth = weth
+	}
+	return u.weth, nil
+}
+
+func call(ctx context.Context, caller Caller, to common.Address, contract abi.ABI, method string, params ...interface{}) ([]interface{}, error) {
+	data, err := contract.Pack(method, params...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to pack %s", method)
+	}
+	out, err := caller.CallContract(ctx, eth.CallMsg{To: &to, Data: data}, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to call %s", method)
+	}
+	res, err := contract.Unpack(method, out)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unpack %s", method)
+	}
+	if len(res) == 0 {
+		return nil, errors.Errorf("empty %s result", method)
+	}
+	return res, nil
+}
+
+func callAddress(ctx context.Context, caller Caller, to common.Address, contract abi.ABI, method string) (common.Address, error) {
+	out, err := call(ctx, caller, to, contract, method)
+	if err != nil {
+		return common.Address{}, err
+	}
+	address, ok := out[0].(common.Address)
+	if !ok {
+		return common.Address{}, errors.Errorf("invalid %s result", method)
+	}
+	return address, nil
+}
+
+func mustParseABI(s string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(s))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
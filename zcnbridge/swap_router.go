@@ -0,0 +1,169 @@
+package zcnbridge
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/0chain/common/core/currency"
+	"github.com/0chain/gosdk/zcnbridge/ethereum/bancortoken"
+	"github.com/0chain/gosdk/zcnbridge/swap"
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// sourceAmount converts amount WZCN to the smallest units of a source token
+// with decimals, at rate source tokens per ZCN.
+func sourceAmount(amount uint64, rate *big.Float, decimals uint8) (*big.Int, error) {
+	amountZCN, err := currency.Coin(amount).ToZCN()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to convert amount to ZCN")
+	}
+	unit := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	amountIn, _ := new(big.Float).Mul(new(big.Float).Mul(big.NewFloat(amountZCN), unit), rate).Int(nil)
+	return amountIn, nil
+}
+
+// tokenDecimals returns the decimals of an ERC-20 token, 18 for ETH.
+func (b *BridgeClient) tokenDecimals(ctx context.Context, token common.Address) (uint8, error) {
+	if token == swap.ETHAddress {
+		return 18, nil
+	}
+	tokenInstance, err := bancortoken.NewBancortoken(token, b.ethereumClient)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to initialize token instance")
+	}
+	decimals, err := tokenInstance.Decimals(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to get decimals of %s", token.Hex())
+	}
+	return decimals, nil
+}
+
+// swapRouter returns the router of the configured swap providers, the Bancor
+// network and the Uniswap routers with configured addresses if none.
+func (b *BridgeClient) swapRouter() *swap.Router {
+	if len(b.SwapProviders) > 0 {
+		return swap.NewRouter(b.SwapProviders...)
+	}
+
+	providers := []swap.Provider{swap.NewBancor(common.HexToAddress(BancorNetworkAddress),
+		common.HexToAddress(BancorNetworkInfoAddress), b.ethereumClient)}
+	if b.UniswapV2RouterAddress != "" {
+		providers = append(providers, swap.NewUniswapV2(common.HexToAddress(b.UniswapV2RouterAddress), b.ethereumClient))
+	}
+	if b.UniswapV3RouterAddress != "" && b.UniswapV3QuoterAddress != "" {
+		providers = append(providers, swap.NewUniswapV3(common.HexToAddress(b.UniswapV3RouterAddress),
+			common.HexToAddress(b.UniswapV3QuoterAddress), b.ethereumClient))
+	}
+	return swap.NewRouter(providers...)
+}
+
+// QuoteSwap returns the quotes of buying amountOut WZCN with the source token
+// across swap providers, the cheapest first. Use SourceTokenETHAddress to pay with ETH.
+func (b *BridgeClient) QuoteSwap(ctx context.Context, sourceTokenAddress string, amountOut uint64) ([]*swap.Quote, error) {
+	return b.swapRouter().Quotes(ctx, common.HexToAddress(sourceTokenAddress), common.HexToAddress(b.TokenAddress),
+		new(big.Int).SetUint64(amountOut))
+}
+
+// SwapToWZCN buys amountOut WZCN with the source token from the cheapest swap
+// provider, paying at most the quote plus slippage percent. ERC-20 source tokens
+// are approved to the provider first. A zero deadline is swap.DefaultDeadline from now.
+func (b *BridgeClient) SwapToWZCN(ctx context.Context, sourceTokenAddress string, amountOut uint64, slippage float64, deadline time.Time) (*types.Transaction, error) {
+	router := b.swapRouter()
+
+	quotes, err := router.Quotes(ctx, common.HexToAddress(sourceTokenAddress), common.HexToAddress(b.TokenAddress),
+		new(big.Int).SetUint64(amountOut))
+	if err != nil {
+		return nil, err
+	}
+	quote := quotes[0]
+
+	beneficiary := common.HexToAddress(b.EthereumAddress)
+	call, err := router.Swap(quote, slippage, beneficiary, deadline)
+	if err != nil {
+		return nil, err
+	}
+
+	if quote.Spender != (common.Address{}) {
+		maxAmountIn, err := swap.MaxAmountIn(quote.AmountIn, slippage)
+		if err != nil {
+			return nil, err
+		}
+		if err := b.ensureAllowance(ctx, quote.Source, quote.Spender, maxAmountIn); err != nil {
+			return nil, err
+		}
+	}
+
+	Logger.Info(
+		"Starting Swap",
+		zap.String("provider", quote.Provider),
+		zap.String("sourceToken", sourceTokenAddress),
+		zap.String("amountIn", quote.AmountIn.String()),
+		zap.Uint64("amountOut", amountOut),
+	)
+
+	tran, err := b.sendCall(ctx, call)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to execute %s swap transaction", quote.Provider)
+	}
+	return tran, nil
+}
+
+// ensureAllowance approves spender to spend amount tokens if the allowance is
+// lower, and waits for the approval to be mined.
+func (b *BridgeClient) ensureAllowance(ctx context.Context, token, spender common.Address, amount *big.Int) error {
+	tokenInstance, err := bancortoken.NewBancortoken(token, b.ethereumClient)
+	if err != nil {
+		return errors.Wrap(err, "failed to initialize token instance")
+	}
+	allowance, err := tokenInstance.Allowance(&bind.CallOpts{Context: ctx}, common.HexToAddress(b.EthereumAddress), spender)
+	if err != nil {
+		return errors.Wrap(err, "failed to get allowance")
+	}
+	if allowance.Cmp(amount) >= 0 {
+		return nil
+	}
+
+	tran, err := b.approve(ctx, token, spender, amount)
+	if err != nil {
+		return err
+	}
+	receipt, err := b.WaitMined(ctx, tran)
+	if err != nil {
+		return errors.Wrap(err, "failed to wait for approval")
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return errors.Errorf("approval %s failed", tran.Hash().Hex())
+	}
+	return nil
+}
+
+// sendCall signs and sends call with the client signer and gas strategy.
+func (b *BridgeClient) sendCall(ctx context.Context, call *swap.Call) (*types.Transaction, error) {
+	msg := eth.CallMsg{
+		To:   &call.To,
+		From: common.HexToAddress(b.EthereumAddress),
+		Data: call.Data,
+	}
+	if call.Value != nil && call.Value.Sign() > 0 {
+		msg.Value = call.Value
+	}
+
+	gasLimitUnits, err := b.ethereumClient.EstimateGas(ctx, msg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to estimate gas limit")
+	}
+	gasLimitUnits = addPercents(gasLimitUnits, 10).Uint64()
+
+	transactOpts := b.CreateSignedTransactionFromKeyStore(b.ethereumClient, gasLimitUnits)
+	transactOpts.Value = msg.Value
+
+	contract := bind.NewBoundContract(call.To, abi.ABI{}, b.ethereumClient, b.ethereumClient, b.ethereumClient)
+	return contract.RawTransact(transactOpts, call.Data)
+}
@@ -0,0 +1,633 @@
+package zcnbridge
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"sync"
+	"time"
+
+	coreTransaction "github.com/0chain/gosdk/core/transaction"
+	"github.com/0chain/gosdk/core/util"
+	"github.com/0chain/gosdk/zcnbridge/errors"
+	"github.com/0chain/gosdk/zcnbridge/ethereum"
+	ctime "github.com/0chain/gosdk/zcnbridge/time"
+	"github.com/0chain/gosdk/zcnbridge/transaction"
+	"github.com/0chain/gosdk/zcnbridge/wallet"
+	"github.com/0chain/gosdk/zcnbridge/zcnsc"
+	"github.com/0chain/gosdk/zcncore"
+	"github.com/ethereum/go-ethereum/core/types"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrTransferNotFound no transfer with the given id is stored
+	ErrTransferNotFound = errors.New("transfer_not_found", "bridge transfer not found")
+)
+
+// TransferDirection is the direction tokens move across the bridge.
+type TransferDirection string
+
+const (
+	// ZCNToEthereum burns ZCN and mints WZCN.
+	ZCNToEthereum TransferDirection = "zcn_to_eth"
+	// EthereumToZCN burns WZCN and mints ZCN.
+	EthereumToZCN TransferDirection = "eth_to_zcn"
+)
+
+// TransferState is the state of a bridge transfer.
+//
+//	created -> burning -> burned -> quorum_reached -> minted -> confirmed
+//
+// The burn is signed and saved with its hash before it is broadcast, so a
+// transfer found in burning state after a restart is reconciled from the burn
+// hash or the burn tickets, and the same signed burn is broadcast again if it
+// never made it to the chain. A burn is never signed twice.
+type TransferState string
+
+const (
+	TransferCreated       TransferState = "created"
+	TransferBurning       TransferState = "burning"
+	TransferBurned        TransferState = "burned"
+	TransferQuorumReached TransferState = "quorum_reached"
+	TransferMinted        TransferState = "minted"
+	TransferConfirmed     TransferState = "confirmed"
+	TransferFailed        TransferState = "failed"
+)
+
+// Transfer is the persisted state of a bridge transfer.
+type Transfer struct {
+	ID        string            `json:"id"`
+	Direction TransferDirection `json:"direction"`
+	State     TransferState     `json:"state"`
+	Amount    uint64            `json:"amount"`
+	TxnFee    uint64            `json:"txn_fee,omitempty"`
+	// To is the Ethereum address receiving WZCN or the client receiving ZCN
+	To string `json:"to"`
+
+	BurnHash string `json:"burn_hash,omitempty"`
+	// BurnTxn is the signed burn transaction, kept to broadcast it again
+	BurnTxn  json.RawMessage `json:"burn_txn,omitempty"`
+	MintHash string          `json:"mint_hash,omitempty"`
+	// ReplacedBurnHashes are the Ethereum burns replaced with higher fees
+	ReplacedBurnHashes []string `json:"replaced_burn_hashes,omitempty"`
+	// MintTxn is the mined WZCN mint
+	MintTxn json.RawMessage `json:"mint_txn,omitempty"`
+	// Nonce is the bridge nonce of the burn, known once the quorum is reached
+	Nonce int64 `json:"nonce,omitempty"`
+
+	EthereumMintPayload *ethereum.MintPayload `json:"ethereum_mint_payload,omitempty"`
+	ZCNMintPayload      *zcnsc.MintPayload    `json:"zcn_mint_payload,omitempty"`
+
+	Attempts  int             `json:"attempts"`
+	LastError string          `json:"last_error,omitempty"`
+	CreatedAt ctime.Timestamp `json:"created_at"`
+	UpdatedAt ctime.Timestamp `json:"updated_at"`
+}
+
+// Done reports whether the transfer reached a final state.
+func (t *Transfer) Done() bool {
+	return t.State == TransferConfirmed || t.State == TransferFailed
+}
+
+// TransferConfig controls retries of the transfer orchestrator.
+type TransferConfig struct {
+	// MaxAttempts is the number of attempts of a step before the transfer is
+	// left for a later Resume
+	MaxAttempts int
+	// RetryDelay is the delay between attempts
+	RetryDelay time.Duration
+	// ConfirmationAttempts is the number of Ethereum confirmation checks per attempt
+	ConfirmationAttempts int
+	// ConfirmationDelay is the delay between Ethereum confirmation checks
+	ConfirmationDelay time.Duration
+}
+
+// DefaultTransferConfig is used by NewTransferOrchestrator.
+var DefaultTransferConfig = TransferConfig{
+	MaxAttempts:          5,
+	RetryDelay:           10 * time.Second,
+	ConfirmationAttempts: 20,
+	ConfirmationDelay:    15 * time.Second,
+}
+
+// bridgeOperations are the bridge calls a transfer is made of.
+type bridgeOperations interface {
+	SignZCNBurn(ctx context.Context, amount, txnfee uint64) (*coreTransaction.Transaction, error)
+	SubmitZCNBurn(ctx context.Context, signed *coreTransaction.Transaction) error
+	QueryEthereumMintPayload(zchainBurnHash string) (*ethereum.MintPayload, error)
+	GetUserNonceMinted(ctx context.Context, rawEthereumAddress string) (*big.Int, error)
+	MintWZCN(ctx context.Context, payload *ethereum.MintPayload) (*types.Transaction, error)
+	waitMined(ctx context.Context, tx *types.Transaction, onReplace func(tx *types.Transaction) error) (*types.Receipt, error)
+
+	SignWZCNBurn(ctx context.Context, amountTokens uint64) (*types.Transaction, error)
+	SendEthereumTransaction(ctx context.Context, tx *types.Transaction) error
+	QueryZChainMintPayload(ethBurnHash string) (*zcnsc.MintPayload, error)
+	MintZCN(ctx context.Context, payload *zcnsc.MintPayload) (string, error)
+	VerifyZCNTransaction(ctx context.Context, hash string) (transaction.Transaction, error)
+}
+
+// TransferOrchestrator runs bridge transfers as persisted state machines.
+// Every state change is saved before the next step, so a transfer interrupted
+// by a restart is resumed from its last state. Mints are guarded by the bridge
+// nonces, so retrying a step never mints twice.
+type TransferOrchestrator struct {
+	bridge bridgeOperations
+	store  *TransferStore
+	config TransferConfig
+
+	ethereumAddress string
+	clientID        func() string
+
+	// confirmEthereumTxn returns 1 once the transaction succeeded, 0 if it failed
+	confirmEthereumTxn func(hash string, times int, duration time.Duration) (int, error)
+	// zcnMintNonce returns the last nonce minted on 0chain for the client
+	zcnMintNonce func() (int64, error)
+
+	locks sync.Map
+}
+
+// NewTransferOrchestrator creates an orchestrator running transfers of the
+// bridge client and persisting them in dir.
+func NewTransferOrchestrator(b *BridgeClient, dir string, config TransferConfig) (*TransferOrchestrator, error) {
+	store, err := NewTransferStore(dir)
+	if err != nil {
+		return nil, err
+	}
+	if config.MaxAttempts < 1 {
+		config.MaxAttempts = 1
+	}
+	if config.ConfirmationAttempts < 1 {
+		config.ConfirmationAttempts = 1
+	}
+
+	return &TransferOrchestrator{
+		bridge:             b,
+		store:              store,
+		config:             config,
+		ethereumAddress:    b.EthereumAddress,
+		clientID:           zcncore.GetClientWalletID,
+		confirmEthereumTxn: ConfirmEthereumTransaction,
+		zcnMintNonce:       getZCNMintNonce,
+	}, nil
+}
+
+// StartZCNToEthereum burns amount ZCN and mints WZCN to the bridge client
+// Ethereum address.
+func (o *TransferOrchestrator) StartZCNToEthereum(ctx context.Context, amount, txnFee uint64) (*Transfer, error) {
+	t := o.newTransfer(ZCNToEthereum, amount, o.ethereumAddress)
+	t.TxnFee = txnFee
+	return o.start(ctx, t)
+}
+
+// StartEthereumToZCN burns amount WZCN and mints ZCN to the wallet client.
+func (o *TransferOrchestrator) StartEthereumToZCN(ctx context.Context, amount uint64) (*Transfer, error) {
+	t := o.newTransfer(EthereumToZCN, amount, o.clientID())
+	return o.start(ctx, t)
+}
+
+// Resume continues the transfer with the given id from its persisted state.
+func (o *TransferOrchestrator) Resume(ctx context.Context, id string) (*Transfer, error) {
+	unlock := o.lock(id)
+	defer unlock()
+
+	t, err := o.store.Load(id)
+	if err != nil {
+		return nil, err
+	}
+	return t, o.run(ctx, t)
+}
+
+// ResumeAll resumes all unfinished transfers, e.g. after a restart.
+func (o *TransferOrchestrator) ResumeAll(ctx context.Context) ([]*Transfer, error) {
+	transfers, err := o.store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var resumed []*Transfer
+	var lastErr error
+	for _, t := range transfers {
+		if t.Done() {
+			continue
+		}
+		r, err := o.Resume(ctx, t.ID)
+		if err != nil {
+			lastErr = err
+			Logger.Error("failed to resume bridge transfer", zap.String("id", t.ID), zap.Error(err))
+		}
+		if r != nil {
+			resumed = append(resumed, r)
+		}
+	}
+	return resumed, lastErr
+}
+
+// Status returns the persisted state of the transfer with the given id.
+func (o *TransferOrchestrator) Status(id string) (*Transfer, error) {
+	return o.store.Load(id)
+}
+
+// List returns all transfers, oldest first.
+func (o *TransferOrchestrator) List() ([]*Transfer, error) {
+	return o.store.List()
+}
+
+func (o *TransferOrchestrator) newTransfer(direction TransferDirection, amount uint64, to string) *Transfer {
+	now := ctime.Now()
+	return &Transfer{
+		ID:        util.GetNewUUID().String(),
+		Direction: direction,
+		State:     TransferCreated,
+		Amount:    amount,
+		To:        to,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func (o *TransferOrchestrator) start(ctx context.Context, t *Transfer) (*Transfer, error) {
+	unlock := o.lock(t.ID)
+	defer unlock()
+
+	if err := o.save(t); err != nil {
+		return nil, err
+	}
+	return t, o.run(ctx, t)
+}
+
+// run advances the transfer until it is done or a step keeps failing.
+func (o *TransferOrchestrator) run(ctx context.Context, t *Transfer) error {
+	for !t.Done() {
+		err := o.retry(ctx, t)
+		if err != nil {
+			t.LastError = err.Error()
+			if saveErr := o.save(t); saveErr != nil {
+				return saveErr
+			}
+			return err
+		}
+		t.Attempts = 0
+		t.LastError = ""
+		if err := o.save(t); err != nil {
+			return err
+		}
+		Logger.Info("bridge transfer advanced", zap.String("id", t.ID), zap.String("state", string(t.State)))
+	}
+	return nil
+}
+
+func (o *TransferOrchestrator) retry(ctx context.Context, t *Transfer) error {
+	var err error
+	for i := 0; i < o.config.MaxAttempts; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(o.config.RetryDelay):
+			}
+		}
+
+		t.Attempts++
+		err = o.step(ctx, t)
+		if err == nil || t.State == TransferFailed {
+			return err
+		}
+		Logger.Error("bridge transfer step failed", zap.String("id", t.ID),
+			zap.String("state", string(t.State)), zap.Int("attempt", t.Attempts), zap.Error(err))
+	}
+	return err
+}
+
+// step moves the transfer to its next state.
+func (o *TransferOrchestrator) step(ctx context.Context, t *Transfer) error {
+	switch t.State {
+	case TransferCreated:
+		// persist the signed burn before broadcasting it, a restart must not burn twice
+		if err := o.signBurn(ctx, t); err != nil {
+			return err
+		}
+		if err := o.save(t); err != nil {
+			t.State, t.BurnHash, t.BurnTxn = TransferCreated, "", nil
+			return err
+		}
+		return o.broadcastBurn(ctx, t)
+	case TransferBurning:
+		return o.reconcileBurn(ctx, t)
+	case TransferBurned:
+		return o.queryMintPayload(t)
+	case TransferQuorumReached:
+		return o.mint(ctx, t)
+	case TransferMinted:
+		return o.confirmMint(ctx, t)
+	}
+	return errors.New("bridge_transfer", "unknown transfer state "+string(t.State))
+}
+
+// signBurn signs the burn and moves the transfer to burning with its hash.
+func (o *TransferOrchestrator) signBurn(ctx context.Context, t *Transfer) error {
+	var (
+		hash string
+		raw  []byte
+		err  error
+	)
+	switch t.Direction {
+	case ZCNToEthereum:
+		var txn *coreTransaction.Transaction
+		if txn, err = o.bridge.SignZCNBurn(ctx, t.Amount, t.TxnFee); err != nil {
+			return errors.Wrap("bridge_transfer", "failed to sign ZCN burn", err)
+		}
+		hash = txn.Hash
+		raw, err = json.Marshal(txn)
+	case EthereumToZCN:
+		var tx *types.Transaction
+		if tx, err = o.bridge.SignWZCNBurn(ctx, t.Amount); err != nil {
+			return errors.Wrap("bridge_transfer", "failed to sign WZCN burn", err)
+		}
+		hash = tx.Hash().Hex()
+		raw, err = tx.MarshalJSON()
+	default:
+		t.State = TransferFailed
+		return errors.New("bridge_transfer", "invalid transfer direction "+string(t.Direction))
+	}
+	if err != nil {
+		return errors.Wrap("bridge_transfer", "failed to encode the burn", err)
+	}
+
+	t.BurnHash = hash
+	t.BurnTxn = raw
+	t.State = TransferBurning
+	return nil
+}
+
+// broadcastBurn broadcasts the saved burn. The transfer stays burning on
+// error, so the next attempt reconciles it before broadcasting again.
+func (o *TransferOrchestrator) broadcastBurn(ctx context.Context, t *Transfer) error {
+	switch t.Direction {
+	case ZCNToEthereum:
+		txn := &coreTransaction.Transaction{}
+		if err := json.Unmarshal(t.BurnTxn, txn); err != nil {
+			t.State = TransferFailed
+			return errors.Wrap("bridge_transfer", "invalid saved ZCN burn", err)
+		}
+		if err := o.bridge.SubmitZCNBurn(ctx, txn); err != nil {
+			return errors.Wrap("bridge_transfer", "failed to burn ZCN", err)
+		}
+	case EthereumToZCN:
+		tx := &types.Transaction{}
+		if err := tx.UnmarshalJSON(t.BurnTxn); err != nil {
+			t.State = TransferFailed
+			return errors.Wrap("bridge_transfer", "invalid saved WZCN burn", err)
+		}
+		if err := o.bridge.SendEthereumTransaction(ctx, tx); err != nil {
+			return errors.Wrap("bridge_transfer", "failed to burn WZCN", err)
+		}
+		return o.waitBurnMined(ctx, t, tx)
+	}
+	t.State = TransferBurned
+	return nil
+}
+
+// waitBurnMined waits until the WZCN burn is mined, replacing it with higher
+// fees while it stays pending. Every replacement is saved before it is sent.
+func (o *TransferOrchestrator) waitBurnMined(ctx context.Context, t *Transfer, tx *types.Transaction) error {
+	ctx, cancel := o.confirmationContext(ctx)
+	defer cancel()
+
+	receipt, err := o.bridge.waitMined(ctx, tx, func(next *types.Transaction) error {
+		raw, err := next.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		t.ReplacedBurnHashes = append(t.ReplacedBurnHashes, t.BurnHash)
+		t.BurnHash, t.BurnTxn = next.Hash().Hex(), raw
+		return o.save(t)
+	})
+	if errors.Is(err, errNoReceipts) {
+		// the burn is sent, a failed burn is checked by the quorum query
+		t.State = TransferBurned
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap("bridge_transfer", "WZCN burn transaction is pending "+t.BurnHash, err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		// the burn was reverted, nothing was burned
+		t.State, t.BurnHash, t.BurnTxn, t.ReplacedBurnHashes = TransferCreated, "", nil, nil
+		return errors.New("bridge_transfer", "WZCN burn transaction failed "+receipt.TxHash.Hex())
+	}
+	t.BurnHash = receipt.TxHash.Hex()
+	t.State = TransferBurned
+	return nil
+}
+
+// confirmationContext bounds the wait for an Ethereum transaction by the
+// confirmation attempts of the config.
+func (o *TransferOrchestrator) confirmationContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := time.Duration(o.config.ConfirmationAttempts) * o.config.ConfirmationDelay
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// reconcileBurn finds out whether the saved burn reached the chain, from its
+// hash or its burn tickets, and broadcasts it again if it did not.
+func (o *TransferOrchestrator) reconcileBurn(ctx context.Context, t *Transfer) error {
+	switch t.Direction {
+	case ZCNToEthereum:
+		if _, err := o.bridge.VerifyZCNTransaction(ctx, t.BurnHash); err == nil {
+			t.State = TransferBurned
+			return nil
+		}
+		if payload, err := o.bridge.QueryEthereumMintPayload(t.BurnHash); err == nil {
+			t.EthereumMintPayload = payload
+			t.Nonce = payload.Nonce
+			t.State = TransferQuorumReached
+			return nil
+		}
+	case EthereumToZCN:
+		// any of the burn and its replacements may have been mined
+		for _, hash := range append([]string{t.BurnHash}, t.ReplacedBurnHashes...) {
+			status, err := o.confirmEthereumTxn(hash, 1, 0)
+			if err == nil && status == 1 {
+				t.BurnHash = hash
+				t.State = TransferBurned
+				return nil
+			}
+			if err == nil && status == 0 {
+				// the burn was reverted, nothing was burned
+				t.State, t.BurnHash, t.BurnTxn, t.ReplacedBurnHashes = TransferCreated, "", nil, nil
+				return errors.New("bridge_transfer", "WZCN burn transaction failed "+hash)
+			}
+			if payload, err := o.bridge.QueryZChainMintPayload(hash); err == nil {
+				t.BurnHash = hash
+				t.ZCNMintPayload = payload
+				t.Nonce = payload.Nonce
+				t.State = TransferQuorumReached
+				return nil
+			}
+		}
+	}
+	return o.broadcastBurn(ctx, t)
+}
+
+func (o *TransferOrchestrator) queryMintPayload(t *Transfer) error {
+	switch t.Direction {
+	case ZCNToEthereum:
+		payload, err := o.bridge.QueryEthereumMintPayload(t.BurnHash)
+		if err != nil {
+			return errors.Wrap("bridge_transfer", "failed to reach the authorizers quorum", err)
+		}
+		t.EthereumMintPayload = payload
+		t.Nonce = payload.Nonce
+	case EthereumToZCN:
+		payload, err := o.bridge.QueryZChainMintPayload(t.BurnHash)
+		if err != nil {
+			return errors.Wrap("bridge_transfer", "failed to reach the authorizers quorum", err)
+		}
+		t.ZCNMintPayload = payload
+		t.Nonce = payload.Nonce
+	}
+	t.State = TransferQuorumReached
+	return nil
+}
+
+func (o *TransferOrchestrator) mint(ctx context.Context, t *Transfer) error {
+	minted, err := o.isMinted(ctx, t)
+	if err != nil {
+		return err
+	}
+	if minted {
+		t.State = TransferConfirmed
+		return nil
+	}
+
+	switch t.Direction {
+	case ZCNToEthereum:
+		tx, err := o.bridge.MintWZCN(ctx, t.EthereumMintPayload)
+		if err != nil {
+			return errors.Wrap("bridge_transfer", "failed to mint WZCN", err)
+		}
+		if t.MintTxn, err = tx.MarshalJSON(); err != nil {
+			return errors.Wrap("bridge_transfer", "failed to encode WZCN mint", err)
+		}
+		t.MintHash = tx.Hash().Hex()
+	case EthereumToZCN:
+		hash, err := o.bridge.MintZCN(ctx, t.ZCNMintPayload)
+		if err != nil {
+			return errors.Wrap("bridge_transfer", "failed to mint ZCN", err)
+		}
+		t.MintHash = hash
+	}
+	t.State = TransferMinted
+	return nil
+}
+
+func (o *TransferOrchestrator) confirmMint(ctx context.Context, t *Transfer) error {
+	switch t.Direction {
+	case ZCNToEthereum:
+		status, err := o.confirmWZCNMint(ctx, t)
+		if err != nil {
+			return errors.Wrap("bridge_transfer", "failed to confirm WZCN mint", err)
+		}
+		switch status {
+		case 1:
+			t.State = TransferConfirmed
+			return nil
+		case 0:
+			// the mint was reverted, mint again
+			t.State = TransferQuorumReached
+			return errors.New("bridge_transfer", "WZCN mint transaction failed "+t.MintHash)
+		}
+		return errors.New("bridge_transfer", "WZCN mint transaction is pending "+t.MintHash)
+	case EthereumToZCN:
+		if _, err := o.bridge.VerifyZCNTransaction(ctx, t.MintHash); err != nil {
+			minted, nonceErr := o.isMinted(ctx, t)
+			if nonceErr == nil && !minted {
+				t.State = TransferQuorumReached
+			}
+			return errors.Wrap("bridge_transfer", "failed to confirm ZCN mint", err)
+		}
+		t.State = TransferConfirmed
+	}
+	return nil
+}
+
+// confirmWZCNMint waits for the mint like MintWZCN, replacing it with higher
+// fees while it stays pending, and returns 1 once it succeeded, 0 if it failed
+// and -1 while it is pending.
+func (o *TransferOrchestrator) confirmWZCNMint(ctx context.Context, t *Transfer) (int, error) {
+	if len(t.MintTxn) == 0 {
+		// saved before the mint transaction was kept
+		return o.confirmEthereumTxn(t.MintHash, o.config.ConfirmationAttempts, o.config.ConfirmationDelay)
+	}
+	tx := &types.Transaction{}
+	if err := tx.UnmarshalJSON(t.MintTxn); err != nil {
+		return -1, err
+	}
+
+	ctx, cancel := o.confirmationContext(ctx)
+	defer cancel()
+	receipt, err := o.bridge.waitMined(ctx, tx, nil)
+	switch {
+	case errors.Is(err, errNoReceipts):
+		return o.confirmEthereumTxn(t.MintHash, o.config.ConfirmationAttempts, o.config.ConfirmationDelay)
+	case errors.Is(err, context.DeadlineExceeded):
+		return -1, nil
+	case err != nil:
+		return -1, err
+	}
+	t.MintHash = receipt.TxHash.Hex()
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return 0, nil
+	}
+	return 1, nil
+}
+
+// isMinted reports whether the bridge already processed the burn nonce.
+func (o *TransferOrchestrator) isMinted(ctx context.Context, t *Transfer) (bool, error) {
+	switch t.Direction {
+	case ZCNToEthereum:
+		nonce, err := o.bridge.GetUserNonceMinted(ctx, t.EthereumMintPayload.To)
+		if err != nil {
+			return false, errors.Wrap("bridge_transfer", "failed to get user nonce minted", err)
+		}
+		return nonce.Cmp(big.NewInt(t.Nonce)) >= 0, nil
+	case EthereumToZCN:
+		nonce, err := o.zcnMintNonce()
+		if err != nil {
+			return false, errors.Wrap("bridge_transfer", "failed to get mint nonce", err)
+		}
+		return nonce >= t.Nonce, nil
+	}
+	return false, nil
+}
+
+func (o *TransferOrchestrator) save(t *Transfer) error {
+	t.UpdatedAt = ctime.Now()
+	return o.store.Save(t)
+}
+
+func (o *TransferOrchestrator) lock(id string) func() {
+	mu, _ := o.locks.LoadOrStore(id, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	return mu.(*sync.Mutex).Unlock
+}
+
+func getZCNMintNonce() (int64, error) {
+	var mintNonce int64
+	cb := wallet.NewZCNStatus(&mintNonce)
+	cb.Begin()
+
+	if err := zcncore.GetMintNonce(cb); err != nil {
+		return 0, err
+	}
+	if err := cb.Wait(); err != nil {
+		return 0, err
+	}
+	if !cb.Success {
+		return 0, errors.New("get_mint_nonce", "failed to retrieve last ZCN processed mint nonce")
+	}
+	return mintNonce, nil
+}
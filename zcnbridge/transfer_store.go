@@ -0,0 +1,100 @@
+package zcnbridge
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/0chain/gosdk/zcnbridge/errors"
+)
+
+const transferFileExt = ".json"
+
+// TransferStore persists bridge transfers as one json file per transfer, so
+// transfers survive a restart of the process driving them.
+type TransferStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewTransferStore creates a store keeping transfers in dir.
+func NewTransferStore(dir string) (*TransferStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.Wrap("transfer_store", "failed to create transfer directory", err)
+	}
+	return &TransferStore{dir: dir}, nil
+}
+
+// Save writes the transfer atomically, replacing the previous state.
+func (s *TransferStore) Save(t *Transfer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	buf, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return errors.Wrap("transfer_store", "failed to encode transfer", err)
+	}
+
+	path := s.path(t.ID)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf, 0600); err != nil {
+		return errors.Wrap("transfer_store", "failed to write transfer "+t.ID, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return errors.Wrap("transfer_store", "failed to write transfer "+t.ID, err)
+	}
+	return nil
+}
+
+// Load reads the transfer with the given id.
+func (s *TransferStore) Load(id string) (*Transfer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	buf, err := os.ReadFile(s.path(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrTransferNotFound
+		}
+		return nil, errors.Wrap("transfer_store", "failed to read transfer "+id, err)
+	}
+
+	t := &Transfer{}
+	if err := json.Unmarshal(buf, t); err != nil {
+		return nil, errors.Wrap("transfer_store", "failed to decode transfer "+id, err)
+	}
+	return t, nil
+}
+
+// List returns all transfers, oldest first.
+func (s *TransferStore) List() ([]*Transfer, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, errors.Wrap("transfer_store", "failed to list transfers", err)
+	}
+
+	var transfers []*Transfer
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, transferFileExt) {
+			continue
+		}
+		t, err := s.Load(strings.TrimSuffix(name, transferFileExt))
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, t)
+	}
+
+	sort.Slice(transfers, func(i, j int) bool {
+		return transfers[i].CreatedAt < transfers[j].CreatedAt
+	})
+	return transfers, nil
+}
+
+func (s *TransferStore) path(id string) string {
+	return filepath.Join(s.dir, id+transferFileExt)
+}
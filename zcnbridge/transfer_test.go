@@ -0,0 +1,299 @@
+package zcnbridge
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	coreTransaction "github.com/0chain/gosdk/core/transaction"
+	"github.com/0chain/gosdk/zcnbridge/errors"
+	"github.com/0chain/gosdk/zcnbridge/ethereum"
+	"github.com/0chain/gosdk/zcnbridge/transaction"
+	"github.com/0chain/gosdk/zcnbridge/zcnsc"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBridgeTransaction struct {
+	transaction.Transaction
+	hash string
+}
+
+func (t *fakeBridgeTransaction) GetHash() string {
+	return t.hash
+}
+
+type fakeBridge struct {
+	signs       int
+	burns       int
+	mints       int
+	nonceMinted int64
+	quorumFails int
+	// onChain are the hashes of the transactions on the chains
+	onChain map[string]bool
+	// broadcastFails makes the burn broadcasts fail
+	broadcastFails bool
+	// replaceBurn makes the WZCN burn stay pending until it is replaced
+	replaceBurn bool
+	waits       int
+}
+
+func (b *fakeBridge) SignZCNBurn(ctx context.Context, amount, txnfee uint64) (*coreTransaction.Transaction, error) {
+	b.signs++
+	return &coreTransaction.Transaction{Hash: "zcn_burn", Signature: "signature", Value: amount}, nil
+}
+
+func (b *fakeBridge) SubmitZCNBurn(ctx context.Context, signed *coreTransaction.Transaction) error {
+	return b.broadcast(signed.Hash)
+}
+
+func (b *fakeBridge) broadcast(hash string) error {
+	if b.broadcastFails {
+		return errors.New("broadcast", "node is not reachable")
+	}
+	if b.onChain == nil {
+		b.onChain = make(map[string]bool)
+	}
+	if !b.onChain[hash] {
+		b.burns++
+		b.onChain[hash] = true
+	}
+	return nil
+}
+
+func (b *fakeBridge) QueryEthereumMintPayload(zchainBurnHash string) (*ethereum.MintPayload, error) {
+	if !b.onChain[zchainBurnHash] {
+		return nil, errors.New("get_burn_ticket", "burn not found")
+	}
+	if b.quorumFails > 0 {
+		b.quorumFails--
+		return nil, errors.New("get_burn_ticket", "failed to reach the quorum")
+	}
+	return &ethereum.MintPayload{ZCNTxnID: zchainBurnHash, Amount: 10, To: "0xabc", Nonce: 1}, nil
+}
+
+func (b *fakeBridge) GetUserNonceMinted(ctx context.Context, rawEthereumAddress string) (*big.Int, error) {
+	return big.NewInt(b.nonceMinted), nil
+}
+
+func (b *fakeBridge) MintWZCN(ctx context.Context, payload *ethereum.MintPayload) (*types.Transaction, error) {
+	b.mints++
+	b.nonceMinted = payload.Nonce
+	return types.NewTx(&types.LegacyTx{Nonce: uint64(payload.Nonce)}), nil
+}
+
+func (b *fakeBridge) SignWZCNBurn(ctx context.Context, amountTokens uint64) (*types.Transaction, error) {
+	b.signs++
+	return types.NewTx(&types.LegacyTx{Value: new(big.Int).SetUint64(amountTokens)}), nil
+}
+
+func (b *fakeBridge) SendEthereumTransaction(ctx context.Context, tx *types.Transaction) error {
+	return b.broadcast(tx.Hash().Hex())
+}
+
+func (b *fakeBridge) waitMined(ctx context.Context, tx *types.Transaction, onReplace func(tx *types.Transaction) error) (*types.Receipt, error) {
+	b.waits++
+	if b.replaceBurn && tx.Nonce() == 0 {
+		b.replaceBurn = false
+		next := types.NewTx(&types.LegacyTx{Value: tx.Value(), GasPrice: big.NewInt(2)})
+		if err := onReplace(next); err != nil {
+			return nil, err
+		}
+		b.onChain[tx.Hash().Hex()] = false
+		b.onChain[next.Hash().Hex()] = true
+		tx = next
+	}
+	return &types.Receipt{TxHash: tx.Hash(), Status: types.ReceiptStatusSuccessful}, nil
+}
+
+func (b *fakeBridge) QueryZChainMintPayload(ethBurnHash string) (*zcnsc.MintPayload, error) {
+	if !b.onChain[ethBurnHash] {
+		return nil, errors.New("get_burn_ticket", "burn not found")
+	}
+	return &zcnsc.MintPayload{EthereumTxnID: ethBurnHash, Amount: 10, Nonce: 1}, nil
+}
+
+func (b *fakeBridge) MintZCN(ctx context.Context, payload *zcnsc.MintPayload) (string, error) {
+	b.mints++
+	b.nonceMinted = payload.Nonce
+	return "zcn_mint", nil
+}
+
+func (b *fakeBridge) VerifyZCNTransaction(ctx context.Context, hash string) (transaction.Transaction, error) {
+	if hash == "zcn_mint" || b.onChain[hash] {
+		return &fakeBridgeTransaction{hash: hash}, nil
+	}
+	return nil, errors.New("transaction_verify", "transaction not found")
+}
+
+func newTestOrchestrator(t *testing.T, b *fakeBridge, dir string) *TransferOrchestrator {
+	store, err := NewTransferStore(dir)
+	require.NoError(t, err)
+	return &TransferOrchestrator{
+		bridge:          b,
+		store:           store,
+		config:          TransferConfig{MaxAttempts: 3, RetryDelay: time.Millisecond, ConfirmationAttempts: 1},
+		ethereumAddress: "0xabc",
+		clientID:        func() string { return "client" },
+		confirmEthereumTxn: func(hash string, times int, duration time.Duration) (int, error) {
+			if b.onChain[hash] {
+				return 1, nil
+			}
+			return -1, nil
+		},
+		zcnMintNonce: func() (int64, error) { return b.nonceMinted, nil },
+	}
+}
+
+func TestTransferOrchestrator(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("zcn to ethereum", func(t *testing.T) {
+		b := &fakeBridge{quorumFails: 2}
+		o := newTestOrchestrator(t, b, t.TempDir())
+
+		tr, err := o.StartZCNToEthereum(ctx, 10, 1)
+		require.NoError(t, err)
+		require.Equal(t, TransferConfirmed, tr.State)
+		require.Equal(t, "zcn_burn", tr.BurnHash)
+		require.NotEmpty(t, tr.MintHash)
+
+		status, err := o.Status(tr.ID)
+		require.NoError(t, err)
+		require.Equal(t, TransferConfirmed, status.State)
+		require.Equal(t, 1, b.burns)
+		require.Equal(t, 1, b.mints)
+	})
+
+	t.Run("ethereum to zcn", func(t *testing.T) {
+		b := &fakeBridge{}
+		o := newTestOrchestrator(t, b, t.TempDir())
+
+		tr, err := o.StartEthereumToZCN(ctx, 10)
+		require.NoError(t, err)
+		require.Equal(t, TransferConfirmed, tr.State)
+		require.Equal(t, "zcn_mint", tr.MintHash)
+		require.Equal(t, "client", tr.To)
+	})
+
+	t.Run("resume after restart", func(t *testing.T) {
+		dir := t.TempDir()
+		b := &fakeBridge{quorumFails: 10}
+		o := newTestOrchestrator(t, b, dir)
+
+		tr, err := o.StartZCNToEthereum(ctx, 10, 1)
+		require.Error(t, err)
+		require.Equal(t, TransferBurned, tr.State)
+
+		// minted by an earlier run which died before saving it
+		b.quorumFails = 0
+		b.nonceMinted = 1
+		o = newTestOrchestrator(t, b, dir)
+		resumed, err := o.ResumeAll(ctx)
+		require.NoError(t, err)
+		require.Len(t, resumed, 1)
+		require.Equal(t, TransferConfirmed, resumed[0].State)
+		require.Equal(t, 1, b.burns)
+		require.Equal(t, 0, b.mints)
+	})
+
+	t.Run("burn broadcast after restart", func(t *testing.T) {
+		for _, direction := range []TransferDirection{ZCNToEthereum, EthereumToZCN} {
+			t.Run(string(direction), func(t *testing.T) {
+				dir := t.TempDir()
+				b := &fakeBridge{broadcastFails: true}
+				o := newTestOrchestrator(t, b, dir)
+
+				// the signed burn is saved but never reaches the chain
+				start := o.StartZCNToEthereum
+				if direction == EthereumToZCN {
+					start = func(ctx context.Context, amount, _ uint64) (*Transfer, error) {
+						return o.StartEthereumToZCN(ctx, amount)
+					}
+				}
+				tr, err := start(ctx, 10, 1)
+				require.Error(t, err)
+				require.Equal(t, TransferBurning, tr.State)
+				require.NotEmpty(t, tr.BurnHash)
+				require.NotEmpty(t, tr.BurnTxn)
+				require.Equal(t, 0, b.burns)
+
+				// the same signed burn is broadcast on resume
+				b.broadcastFails = false
+				o = newTestOrchestrator(t, b, dir)
+				tr, err = o.Resume(ctx, tr.ID)
+				require.NoError(t, err)
+				require.Equal(t, TransferConfirmed, tr.State)
+				require.Equal(t, 1, b.signs)
+				require.Equal(t, 1, b.burns)
+				require.Equal(t, 1, b.mints)
+			})
+		}
+	})
+
+	t.Run("fee bumping", func(t *testing.T) {
+		dir := t.TempDir()
+		b := &fakeBridge{replaceBurn: true}
+		o := newTestOrchestrator(t, b, dir)
+
+		tr, err := o.StartEthereumToZCN(ctx, 10)
+		require.NoError(t, err)
+		require.Equal(t, TransferConfirmed, tr.State)
+		require.Len(t, tr.ReplacedBurnHashes, 1)
+		require.NotEqual(t, tr.ReplacedBurnHashes[0], tr.BurnHash)
+		require.True(t, b.onChain[tr.BurnHash])
+		require.Equal(t, 1, b.signs)
+
+		b = &fakeBridge{}
+		o = newTestOrchestrator(t, b, dir)
+		zcnToEth, err := o.StartZCNToEthereum(ctx, 10, 1)
+		require.NoError(t, err)
+		require.Equal(t, TransferConfirmed, zcnToEth.State)
+		require.NotEmpty(t, zcnToEth.MintTxn)
+		// the mint is confirmed from its receipt
+		require.Equal(t, 1, b.waits)
+	})
+
+	t.Run("burn replaced before restart", func(t *testing.T) {
+		dir := t.TempDir()
+		b := &fakeBridge{}
+		o := newTestOrchestrator(t, b, dir)
+
+		// the replacement was saved and mined, the process died before it was burned
+		tr := o.newTransfer(EthereumToZCN, 10, "client")
+		require.NoError(t, o.signBurn(ctx, tr))
+		replacement := types.NewTx(&types.LegacyTx{Value: big.NewInt(10), GasPrice: big.NewInt(2)})
+		tr.ReplacedBurnHashes = []string{tr.BurnHash}
+		tr.BurnHash = replacement.Hash().Hex()
+		require.NoError(t, o.store.Save(tr))
+		require.NoError(t, b.SendEthereumTransaction(ctx, types.NewTx(&types.LegacyTx{Value: big.NewInt(10)})))
+
+		tr, err := o.Resume(ctx, tr.ID)
+		require.NoError(t, err)
+		require.Equal(t, TransferConfirmed, tr.State)
+		require.Equal(t, tr.ReplacedBurnHashes[0], tr.BurnHash)
+		require.Equal(t, 1, b.burns)
+	})
+
+	t.Run("burn on chain before restart", func(t *testing.T) {
+		dir := t.TempDir()
+		b := &fakeBridge{}
+		o := newTestOrchestrator(t, b, dir)
+
+		// the process died after the broadcast, before saving the burned state
+		tr := o.newTransfer(ZCNToEthereum, 10, "0xabc")
+		require.NoError(t, o.signBurn(ctx, tr))
+		require.NoError(t, o.store.Save(tr))
+		require.NoError(t, b.SubmitZCNBurn(ctx, &coreTransaction.Transaction{Hash: tr.BurnHash}))
+
+		tr, err := o.Resume(ctx, tr.ID)
+		require.NoError(t, err)
+		require.Equal(t, TransferConfirmed, tr.State)
+		require.Equal(t, 1, b.signs)
+		require.Equal(t, 1, b.burns)
+
+		_, err = o.Status("unknown")
+		require.ErrorIs(t, err, ErrTransferNotFound)
+	})
+}
@@ -0,0 +1,274 @@
+//go:build !mobile
+// +build !mobile
+
+package zcncore
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/0chain/errors"
+	"github.com/0chain/gosdk/core/common"
+	"github.com/0chain/gosdk/core/keystore"
+	"github.com/0chain/gosdk/core/transaction"
+	"github.com/0chain/gosdk/core/zcncrypto"
+	"github.com/0chain/gosdk/zboxcore/client"
+	"github.com/0chain/gosdk/zboxcore/sdk"
+)
+
+const (
+	// rotationPendingSuffix marks the new wallet of an unfinished rotation in the keystore
+	rotationPendingSuffix = ".rotating"
+	// rotationRetiredSuffix marks the old wallet after a rotation
+	rotationRetiredSuffix = ".retired-"
+
+	rotationTxnTimeout = 2 * time.Minute
+)
+
+// KeyRotation is the result of RotateWallet.
+type KeyRotation struct {
+	OldClientID string `json:"old_client_id"`
+	NewClientID string `json:"new_client_id"`
+	// RetiredName is the keystore name the old wallet is kept under
+	RetiredName string `json:"retired_name"`
+	// AllocationTxns maps allocation id to its ownership transfer transaction
+	AllocationTxns map[string]string `json:"allocation_txns"`
+	// TransferTxn is the balance transfer transaction, empty if there was nothing to move
+	TransferTxn string `json:"transfer_txn"`
+}
+
+// RotateWallet replaces the wallet stored under name with freshly generated keys.
+// The new wallet is saved together with the rotation state before anything is
+// moved, and every step is recorded in the store, so an interrupted rotation is
+// resumed by calling RotateWallet again. The allocations are transferred to
+// the new owner with sdk.TransferAllocation, then the whole balance is sent to
+// the new client id. The old wallet stays in the store under RetiredName.
+//
+// The sdks must be initialized with the wallet stored under name (or, when
+// resuming, with either wallet of the rotation), and are switched to the new
+// wallet on success.
+//   - store: keystore holding the wallet
+//   - name: name of the wallet in the store
+//   - passphrase: passphrase of the wallet, also used for the new one
+//   - allocationIDs: allocations owned by the wallet to transfer
+func RotateWallet(store *keystore.Store, name, passphrase string, allocationIDs []string) (*KeyRotation, error) {
+	if err := CheckConfig(); err != nil {
+		return nil, err
+	}
+
+	r, err := store.Rotation(name)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		if r, err = beginRotation(store, name, passphrase); err != nil {
+			return nil, err
+		}
+	} else if id := client.GetClientID(); id != r.OldClientID && id != r.NewClientID {
+		return nil, errors.New("rotate_wallet", "sdk is not initialized with a wallet of the rotation")
+	}
+
+	newName := r.PendingName
+	if r.Swapped {
+		newName = name
+	}
+	newWallet, err := store.Load(newName, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if newWallet.ClientID != r.NewClientID {
+		return nil, errors.New("rotate_wallet", "stored wallet does not match the rotation")
+	}
+
+	if !r.Swapped {
+		if client.GetClientID() != r.OldClientID {
+			return nil, errors.New("rotate_wallet", "sdk is not initialized with the wallet to rotate")
+		}
+		if err = moveToWallet(store, name, r, newWallet, allocationIDs); err != nil {
+			return nil, err
+		}
+		if err = store.SwapRotation(name); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = SetWallet(*newWallet, false); err != nil {
+		return nil, err
+	}
+	walletJSON, err := newWallet.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	if err = client.PopulateClient(walletJSON, _config.chain.SignatureScheme); err != nil {
+		return nil, err
+	}
+	if err = store.FinishRotation(name); err != nil {
+		return nil, err
+	}
+
+	result := &KeyRotation{
+		OldClientID:    r.OldClientID,
+		NewClientID:    r.NewClientID,
+		RetiredName:    r.RetiredName,
+		AllocationTxns: make(map[string]string),
+		TransferTxn:    r.Txns[rotationTransferStep],
+	}
+	for step, hash := range r.Txns {
+		if step != rotationTransferStep {
+			result.AllocationTxns[step] = hash
+		}
+	}
+	return result, nil
+}
+
+// rotationTransferStep records the balance transfer in Rotation.Txns, the
+// other steps are the ids of the transferred allocations
+const rotationTransferStep = "transfer"
+
+// beginRotation generates the new wallet and stores it with the rotation state.
+func beginRotation(store *keystore.Store, name, passphrase string) (*keystore.Rotation, error) {
+	oldWallet, err := store.Load(name, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if oldWallet.ClientID != _config.wallet.ClientID || oldWallet.ClientID != client.GetClientID() {
+		return nil, errors.New("rotate_wallet", "sdk is not initialized with the wallet to rotate")
+	}
+
+	newWallet, err := zcncrypto.NewSignatureScheme(_config.chain.SignatureScheme).GenerateKeys()
+	if err != nil {
+		return nil, err
+	}
+	r := &keystore.Rotation{
+		OldClientID: oldWallet.ClientID,
+		NewClientID: newWallet.ClientID,
+		PendingName: name + rotationPendingSuffix,
+		RetiredName: fmt.Sprintf("%s%s%d", name, rotationRetiredSuffix, common.Now()),
+		Txns:        make(map[string]string),
+	}
+	if err = store.BeginRotation(name, newWallet, passphrase, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// moveToWallet transfers the allocations and the balance of the old wallet of
+// the rotation to newWallet, saving every completed step to the store.
+func moveToWallet(store *keystore.Store, name string, r *keystore.Rotation, newWallet *zcncrypto.Wallet, allocationIDs []string) error {
+	if r.Txns == nil {
+		r.Txns = make(map[string]string)
+	}
+	for _, allocationID := range allocationIDs {
+		if _, ok := r.Txns[allocationID]; ok {
+			continue
+		}
+		alloc, err := sdk.GetAllocation(allocationID)
+		if err != nil {
+			return errors.Wrap(err, "get allocation "+allocationID)
+		}
+		if alloc.Owner != newWallet.ClientID {
+			hash, _, err := sdk.TransferAllocation(allocationID, newWallet.ClientID, newWallet.ClientKey)
+			if err != nil {
+				return errors.Wrap(err, "transfer allocation "+allocationID)
+			}
+			r.Txns[allocationID] = hash
+		} else {
+			// transferred by an attempt interrupted before it was recorded
+			r.Txns[allocationID] = ""
+		}
+		if err = store.UpdateRotation(name, r); err != nil {
+			return err
+		}
+	}
+
+	if _, ok := r.Txns[rotationTransferStep]; ok {
+		return nil
+	}
+	hash, err := transferWholeBalance(r.OldClientID, r.NewClientID)
+	if err != nil {
+		return err
+	}
+	r.Txns[rotationTransferStep] = hash
+	return store.UpdateRotation(name, r)
+}
+
+// transferWholeBalance sends the balance of clientID minus the fee to toClientID.
+func transferWholeBalance(clientID, toClientID string) (string, error) {
+	balance, _, err := getWalletBalance(clientID)
+	if err != nil {
+		return "", err
+	}
+
+	cb := newRotationTxnCallback()
+	t, err := newTransaction(cb, 0, 0)
+	if err != nil {
+		return "", err
+	}
+
+	txnData, err := json.Marshal(transaction.SmartContractTxnData{Name: "transfer", InputArgs: SendTxnData{Note: "key rotation"}})
+	if err != nil {
+		return "", err
+	}
+	t.txn.TransactionType = transaction.TxnTypeSend
+	t.txn.ToClientID = toClientID
+	t.txn.Value = uint64(balance)
+	t.txn.TransactionData = string(txnData)
+	fee, err := transaction.EstimateFee(t.txn, _config.chain.Miners, 0.2)
+	if err != nil {
+		return "", err
+	}
+	if uint64(balance) <= fee {
+		return "", nil
+	}
+	t.txn.TransactionFee = fee
+
+	if err = t.Send(toClientID, uint64(balance)-fee, "key rotation"); err != nil {
+		return "", err
+	}
+	if err = cb.wait(); err != nil {
+		return "", err
+	}
+	if err = t.Verify(); err != nil {
+		return "", err
+	}
+	if err = cb.wait(); err != nil {
+		return "", err
+	}
+	return t.GetTransactionHash(), nil
+}
+
+type rotationTxnCallback struct {
+	done chan error
+}
+
+func newRotationTxnCallback() *rotationTxnCallback {
+	return &rotationTxnCallback{done: make(chan error, 1)}
+}
+
+func (cb *rotationTxnCallback) OnTransactionComplete(t *Transaction, status int) {
+	if status != StatusSuccess {
+		cb.done <- errors.New("rotate_wallet", "balance transfer failed: "+t.GetTransactionError())
+		return
+	}
+	cb.done <- nil
+}
+
+func (cb *rotationTxnCallback) OnVerifyComplete(t *Transaction, status int) {
+	if status != StatusSuccess {
+		cb.done <- errors.New("rotate_wallet", "balance transfer verification failed: "+t.GetVerifyError())
+		return
+	}
+	cb.done <- nil
+}
+
+func (cb *rotationTxnCallback) OnAuthComplete(t *Transaction, status int) {}
+
+func (cb *rotationTxnCallback) wait() error {
+	select {
+	case err := <-cb.done:
+		return err
+	case <-time.After(rotationTxnTimeout):
+		return errors.New("rotate_wallet", "balance transfer timed out")
+	}
+}
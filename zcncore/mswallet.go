@@ -9,6 +9,7 @@ import (
 
 	"github.com/0chain/errors"
 	"github.com/0chain/gosdk/core/encryption"
+	"github.com/0chain/gosdk/core/multisig"
 	"github.com/0chain/gosdk/core/zcncrypto"
 )
 
@@ -205,3 +206,42 @@ func CreateMSVote(proposal, grpClientID, signerWalletstr, toClientID string, tok
 	}
 	return string(vbytes), nil
 }
+
+// NewMSCoordinator creates a threshold signing coordinator for the multisig wallet
+// given as the MultisigSCWallet payload. Transactions are signed as the group with
+// SignTransaction, which sends them to the signers for review.
+func NewMSCoordinator(msscwstr string, transport multisig.Transport) (*multisig.Coordinator, error) {
+	var msscw MultisigSCWallet
+	if err := json.Unmarshal([]byte(msscwstr), &msscw); err != nil {
+		return nil, errors.Wrap(err, "invalid multisig wallet")
+	}
+	if len(msscw.SignerThresholdIDs) != len(msscw.SignerPublicKeys) {
+		return nil, errors.New("", "signer ids and public keys do not match")
+	}
+
+	group := multisig.Group{
+		ClientID:  msscw.ClientID,
+		PublicKey: msscw.PublicKey,
+		Signers:   make(map[string]string, len(msscw.SignerThresholdIDs)),
+		T:         msscw.NumRequired,
+	}
+	for i, id := range msscw.SignerThresholdIDs {
+		group.Signers[id] = msscw.SignerPublicKeys[i]
+	}
+	return multisig.NewCoordinator(group, transport)
+}
+
+// NewMSSigner creates the signer answering sign requests with the key share at
+// index of the multisig wallet, as created by CreateMSWallet. approve reviews
+// every transaction before it is signed and is required.
+func NewMSSigner(mswstr string, index int, approve multisig.ApproveFunc) (*multisig.Signer, error) {
+	var msw MSWallet
+	if err := json.Unmarshal([]byte(mswstr), &msw); err != nil {
+		return nil, errors.Wrap(err, "invalid multisig wallet")
+	}
+	if index < 0 || index >= len(msw.SignerKeys) {
+		return nil, errors.New("", "invalid signer index")
+	}
+
+	return multisig.NewSigner(msw.SignerKeys[index], approve), nil
+}
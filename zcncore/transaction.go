@@ -16,6 +16,7 @@ import (
 	"github.com/0chain/gosdk/core/block"
 	"github.com/0chain/gosdk/core/common"
 	"github.com/0chain/gosdk/core/encryption"
+	"github.com/0chain/gosdk/core/lightclient"
 	"github.com/0chain/gosdk/core/node"
 	"github.com/0chain/gosdk/core/transaction"
 	"github.com/0chain/gosdk/core/util"
@@ -277,6 +278,32 @@ func (t *Transaction) ExecuteSmartContract(address, methodName string, input int
 	return t.txn, nil
 }
 
+// SignSmartContract creates and signs the smart contract transaction without
+// submitting it, so the caller can persist it first. Submit it with SubmitSigned.
+func (t *Transaction) SignSmartContract(address, methodName string, input interface{}, val uint64, opts ...FeeOption) (*transaction.Transaction, error) {
+	if err := t.createSmartContractTxn(address, methodName, input, val, opts...); err != nil {
+		return nil, err
+	}
+	t.setNonce()
+	if err := t.txn.ComputeHashAndSign(SignFn); err != nil {
+		node.Cache.Evict(t.txn.ClientID)
+		return nil, err
+	}
+	return t.txn, nil
+}
+
+// SubmitSigned submits txn as signed, e.g. by SignSmartContract in an earlier
+// process. Submitting a transaction again never executes it twice, the chain
+// accepts one transaction per hash.
+func (t *Transaction) SubmitSigned(txn *transaction.Transaction) error {
+	if txn == nil || txn.Signature == "" || txn.Hash == "" {
+		return errors.New("", "transaction is not signed")
+	}
+	t.txn = txn
+	go t.submitTxn()
+	return nil
+}
+
 func (t *Transaction) Send(toClientID string, val uint64, desc string) error {
 	txnData, err := json.Marshal(transaction.SmartContractTxnData{Name: "transfer", InputArgs: SendTxnData{Note: desc}})
 	if err != nil {
@@ -1293,6 +1320,90 @@ func GetMagicBlockByNumber(ctx context.Context, numSharders int, number int64) (
 	return
 }
 
+// NewLightClient creates a light client that follows magic block transitions
+// from the trusted genesis, fetching chain data from the healthy sharders.
+// Sharders do not serve state proofs, so it only proves blocks of the genesis
+// magic block and fails to sync past it.
+func NewLightClient(genesis *block.MagicBlock) (*lightclient.LightClient, error) {
+	return lightclient.New(genesis, sharderSource{})
+}
+
+// sharderSource feeds the light client with data queried from all healthy sharders.
+type sharderSource struct{}
+
+// GetMagicBlockByNumber returns the magic block most sharders agree on. It
+// returns lightclient.ErrMagicBlockNotFound only if every sharder answered
+// that it has no such magic block.
+func (sharderSource) GetMagicBlockByNumber(ctx context.Context, number int64) (*block.MagicBlock, error) {
+	var numSharders = len(Sharders.Healthy())
+	var result = make(chan *util.GetResponse, numSharders)
+	defer close(result)
+
+	Sharders.QueryFromShardersContext(ctx, numSharders,
+		fmt.Sprintf("%smagic_block_number=%d", GET_MAGIC_BLOCK_INFO, number),
+		result)
+
+	var (
+		maxConsensus   int
+		notFound       int
+		failed         int
+		magicBlock     *block.MagicBlock
+		roundConsensus = make(map[string]int)
+	)
+
+	type respObj struct {
+		MagicBlock *block.MagicBlock `json:"magic_block"`
+	}
+
+	for i := 0; i < numSharders; i++ {
+		var rsp = <-result
+		if rsp == nil {
+			failed++
+			continue
+		}
+
+		if rsp.StatusCode == http.StatusNotFound {
+			notFound++
+			continue
+		}
+		if rsp.StatusCode != http.StatusOK {
+			logging.Error(rsp.Body)
+			failed++
+			continue
+		}
+
+		var respo respObj
+		if err := json.Unmarshal([]byte(rsp.Body), &respo); err != nil {
+			logging.Error(" magic block parse error: ", err)
+			failed++
+			continue
+		}
+		if respo.MagicBlock == nil || respo.MagicBlock.Hash == "" {
+			notFound++
+			continue
+		}
+
+		var h = encryption.FastHash([]byte(respo.MagicBlock.Hash))
+		if roundConsensus[h]++; roundConsensus[h] > maxConsensus {
+			maxConsensus = roundConsensus[h]
+			magicBlock = respo.MagicBlock
+		}
+	}
+
+	switch {
+	case maxConsensus > 0:
+		return magicBlock, nil
+	case notFound > 0 && failed == 0:
+		return nil, errors.Throw(lightclient.ErrMagicBlockNotFound, fmt.Sprint(number))
+	default:
+		return nil, errors.New("get_magic_block", "magic block info not found")
+	}
+}
+
+func (sharderSource) GetBlockByRound(ctx context.Context, round int64) (*block.Block, error) {
+	return GetBlockByRound(ctx, len(Sharders.Healthy()), round)
+}
+
 type NonceCache struct {
 	cache map[string]int64
 	guard sync.Mutex
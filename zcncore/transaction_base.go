@@ -12,6 +12,7 @@ import (
 	"github.com/0chain/errors"
 	"github.com/0chain/gosdk/core/common"
 	"github.com/0chain/gosdk/core/node"
+	"github.com/0chain/gosdk/core/signer"
 	"github.com/0chain/gosdk/core/sys"
 	"github.com/0chain/gosdk/zboxcore/logger"
 	"go.uber.org/zap"
@@ -99,6 +100,7 @@ type localConfig struct {
 	isConfigured  bool
 	isValidWallet bool
 	isSplitWallet bool
+	signer        signer.Signer
 }
 
 type ChainConfig struct {
@@ -217,6 +219,9 @@ type SendTxnData struct {
 }
 
 func Sign(hash string) (string, error) {
+	if _config.signer != nil {
+		return _config.signer.Sign(hash)
+	}
 	sigScheme := zcncrypto.NewSignatureScheme(_config.chain.SignatureScheme)
 	err := sigScheme.SetPrivateKey(_config.wallet.Keys[0].PrivateKey)
 	if err != nil {
@@ -244,6 +249,9 @@ func VerifyWithKey(pubKey, signature, hash string) (bool, error) {
 }
 
 var SignFn = func(hash string) (string, error) {
+	if _config.signer != nil {
+		return _config.signer.Sign(hash)
+	}
 	sigScheme := zcncrypto.NewSignatureScheme(_config.chain.SignatureScheme)
 	err := sigScheme.SetPrivateKey(_config.wallet.Keys[0].PrivateKey)
 	if err != nil {
@@ -102,7 +102,15 @@ func verifyFn(signature, msgHash, publicKey string) (bool, error) {
 func (ta *TransactionWithAuth) sign(otherSig string) error {
 	ta.t.txn.ComputeHashData()
 
-	sig, err := AddSignature(_config.wallet.Keys[0].PrivateKey, otherSig, ta.t.txn.Hash)
+	var (
+		sig string
+		err error
+	)
+	if _config.signer != nil {
+		sig, err = addSignerSignature(otherSig, ta.t.txn.Hash)
+	} else {
+		sig, err = AddSignature(_config.wallet.Keys[0].PrivateKey, otherSig, ta.t.txn.Hash)
+	}
 	if err != nil {
 		return err
 	}
@@ -110,6 +118,24 @@ func (ta *TransactionWithAuth) sign(otherSig string) error {
 	return nil
 }
 
+// addSignerSignature aggregates the BLS signature of the configured signer on hash with signature.
+func addSignerSignature(signature, hash string) (string, error) {
+	own, err := _config.signer.Sign(hash)
+	if err != nil {
+		return "", err
+	}
+	sig := zcncrypto.BlsSignerInstance.NewSignature()
+	if err = sig.DeserializeHexStr(signature); err != nil {
+		return "", err
+	}
+	ownSig := zcncrypto.BlsSignerInstance.NewSignature()
+	if err = ownSig.DeserializeHexStr(own); err != nil {
+		return "", err
+	}
+	sig.Add(ownSig)
+	return sig.SerializeToHexStr(), nil
+}
+
 func (ta *TransactionWithAuth) submitTxn() {
 	nonce := ta.t.txn.TransactionNonce
 	if nonce < 1 {
@@ -19,10 +19,12 @@ import (
 	"github.com/0chain/gosdk/core/common"
 	"github.com/0chain/gosdk/core/conf"
 	"github.com/0chain/gosdk/core/logger"
+	"github.com/0chain/gosdk/core/signer"
 	"github.com/0chain/gosdk/core/tokenrate"
 	"github.com/0chain/gosdk/core/util"
 	"github.com/0chain/gosdk/core/version"
 	"github.com/0chain/gosdk/core/zcncrypto"
+	"github.com/0chain/gosdk/zboxcore/client"
 	"github.com/0chain/gosdk/zboxcore/encryption"
 	"github.com/0chain/gosdk/zboxcore/zboxutil"
 	openssl "github.com/Luzifer/go-openssl/v3"
@@ -424,6 +426,56 @@ func RecoverOfflineWallet(mnemonic string) (string, error) {
 	return walletString, nil
 }
 
+// DeriveWalletOffline derives the wallet at index of account from mnemonic, so
+// one mnemonic can back many accounts (e.g. per device or per app).
+//   - mnemonic: the seed phrase
+//   - account: account number of the path m/44'/1337'/account'/0'/index'
+//   - index: index of the wallet in the account
+func DeriveWalletOffline(mnemonic string, account, index int) (string, error) {
+	if !isHardenedIndex(account) || !isHardenedIndex(index) {
+		return "", errors.New("", "invalid account or index")
+	}
+	wallet, err := zcncrypto.DeriveKeys(_config.chain.SignatureScheme, mnemonic,
+		zcncrypto.AccountPath(uint32(account), uint32(index)))
+	if err != nil {
+		return "", err
+	}
+	return wallet.Marshal()
+}
+
+// isHardenedIndex reports whether i fits a hardened path element without wrapping around.
+func isHardenedIndex(i int) bool {
+	return i >= 0 && int64(i) <= int64(zcncrypto.MaxHardenedIndex)
+}
+
+// RestoreDerivedWallets derives the wallets of account from mnemonic and returns
+// the json list of those used on chain, i.e. with a balance or a nonce. The
+// discovery stops after zcncrypto.DefaultAccountGapLimit consecutive unused wallets.
+//   - mnemonic: the seed phrase
+//   - account: account number of the path m/44'/1337'/account'/0'/index'
+func RestoreDerivedWallets(mnemonic string, account int) (string, error) {
+	if !isHardenedIndex(account) {
+		return "", errors.New("", "invalid account")
+	}
+	list, err := zcncrypto.RestoreAccounts(_config.chain.SignatureScheme, mnemonic, uint32(account), 0,
+		func(w *zcncrypto.Wallet) (bool, error) {
+			balance, nonce, err := getWalletBalance(w.ClientID)
+			if err != nil {
+				return false, err
+			}
+			return balance > 0 || nonce > 0, nil
+		})
+	if err != nil {
+		return "", err
+	}
+
+	buf, err := json.Marshal(list)
+	if err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
 // RecoverWallet recovers the previously generated wallet using the mnemonic.
 // It also registers the wallet again to block chain.
 func RecoverWallet(mnemonic string, statusCb WalletCallback) error {
@@ -505,6 +557,7 @@ func IsMnemonicValid(mnemonic string) bool {
 // splitKeyWallet parameter is valid only if SignatureScheme is "BLS0Chain"
 func SetWallet(w zcncrypto.Wallet, splitKeyWallet bool) error {
 	_config.wallet = w
+	_config.signer = nil
 
 	if _config.chain.SignatureScheme == "bls0chain" {
 		_config.isSplitWallet = splitKeyWallet
@@ -514,6 +567,33 @@ func SetWallet(w zcncrypto.Wallet, splitKeyWallet bool) error {
 	return nil
 }
 
+// SetSigner makes transactions and storage requests sign with s instead of the
+// private keys of a wallet, so keys can live outside the SDK process.
+//   - s: signer holding the client key, e.g. signer.NewRemoteSigner
+func SetSigner(s signer.Signer) error {
+	if s == nil {
+		return errors.New("", "invalid signer")
+	}
+	if _config.chain.SignatureScheme != "" && s.Scheme() != _config.chain.SignatureScheme {
+		return errors.New("", "signer scheme does not match chain signature scheme")
+	}
+	clientID, err := signer.ClientID(s)
+	if err != nil {
+		return err
+	}
+
+	_config.wallet = zcncrypto.Wallet{
+		ClientID:  clientID,
+		ClientKey: s.PublicKey(),
+		Version:   zcncrypto.CryptoVersion,
+	}
+	_config.signer = s
+	_config.isSplitWallet = false
+	_config.isValidWallet = true
+
+	return client.SetSigner(s)
+}
+
 func GetWalletRaw() zcncrypto.Wallet {
 	return _config.wallet
 }
@@ -539,6 +619,7 @@ func GetWalletRaw() zcncrypto.Wallet {
 func SetWalletInfo(jsonWallet string, splitKeyWallet bool) error {
 	err := json.Unmarshal([]byte(jsonWallet), &_config.wallet)
 	if err == nil {
+		_config.signer = nil
 		if _config.chain.SignatureScheme == "bls0chain" {
 			_config.isSplitWallet = splitKeyWallet
 		}
@@ -740,6 +821,15 @@ func ConvertTokenToUSD(token float64) (float64, error) {
 	return token * zcnRate, nil
 }
 
+// ConvertTokenToFiat converts ZCN tokens to the fiat currency, like "eur"
+func ConvertTokenToFiat(token float64, currency string) (float64, error) {
+	zcnRate, err := tokenrate.GetPrice(context.TODO(), "zcn", currency)
+	if err != nil {
+		return 0, err
+	}
+	return token * zcnRate, nil
+}
+
 func ConvertUSDToToken(usd float64) (float64, error) {
 	zcnRate, err := getTokenUSDRate()
 	if err != nil {
@@ -0,0 +1,62 @@
+package znft
+
+import (
+	"context"
+	"path"
+
+	"github.com/0chain/gosdk/constants"
+	"github.com/0chain/gosdk/zboxcore/fileref"
+	"github.com/0chain/gosdk/zboxcore/sdk"
+	"github.com/mitchellh/go-homedir"
+)
+
+// AllocationStorage stores collections in a 0chain allocation.
+type AllocationStorage struct {
+	Allocation *sdk.Allocation
+	Workdir    string // Workdir of the uploads (default = sdk.Workdir or home directory)
+}
+
+var _ CollectionStorage = (*AllocationStorage)(nil)
+
+// NewAllocationStorage creates the collection storage of an allocation.
+func NewAllocationStorage(allocation *sdk.Allocation) *AllocationStorage {
+	return &AllocationStorage{Allocation: allocation}
+}
+
+// AllocationID implements CollectionStorage.
+func (s *AllocationStorage) AllocationID() string {
+	return s.Allocation.ID
+}
+
+// Upload implements CollectionStorage, uploading the files in one multi operation.
+func (s *AllocationStorage) Upload(_ context.Context, files []*StorageFile) error {
+	workdir := s.Workdir
+	if workdir == "" {
+		workdir = sdk.Workdir
+	}
+	if workdir == "" {
+		workdir, _ = homedir.Dir()
+	}
+
+	operations := make([]sdk.OperationRequest, 0, len(files))
+	for _, f := range files {
+		operations = append(operations, sdk.OperationRequest{
+			OperationType: constants.FileOperationInsert,
+			RemotePath:    f.RemotePath,
+			Workdir:       workdir,
+			FileReader:    f.Reader,
+			FileMeta: sdk.FileMeta{
+				ActualSize: f.Size,
+				MimeType:   f.MimeType,
+				RemoteName: path.Base(f.RemotePath),
+				RemotePath: f.RemotePath,
+			},
+		})
+	}
+	return s.Allocation.DoMultiOperation(operations)
+}
+
+// ShareDir implements CollectionStorage.
+func (s *AllocationStorage) ShareDir(_ context.Context, remotePath string) (string, error) {
+	return s.Allocation.GetAuthTicketForShare(remotePath, path.Base(remotePath), fileref.DIRECTORY, "")
+}
@@ -0,0 +1,508 @@
+package znft
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	storageerc721 "github.com/0chain/gosdk/znft/contracts/dstorageerc721/binding"
+	storageerc721pack "github.com/0chain/gosdk/znft/contracts/dstorageerc721pack/binding"
+	storageerc721random "github.com/0chain/gosdk/znft/contracts/dstorageerc721random/binding"
+	factory "github.com/0chain/gosdk/znft/contracts/factory/binding"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// CollectionType selects the NFT contract and factory module of a collection.
+type CollectionType string
+
+const (
+	CollectionERC721 CollectionType = "erc721" // DStorageERC721
+	CollectionFixed  CollectionType = "fixed"  // DStorageERC721Fixed, fixed price
+	CollectionPack   CollectionType = "pack"   // DStorageERC721Pack, packs opened into tokens
+	CollectionRandom CollectionType = "random" // DStorageERC721Random, hidden until revealed
+)
+
+const (
+	// HiddenMetadataName is the metadata file of random tokens before reveal and of closed packs.
+	HiddenMetadataName = "hidden"
+	// OpenedMetadataName is the metadata file of opened packs.
+	OpenedMetadataName = "opened"
+
+	collectionMediaDir    = "media"
+	collectionMetadataDir = "metadata"
+)
+
+// StorageFile is a file uploaded to a collection storage.
+type StorageFile struct {
+	RemotePath string
+	MimeType   string
+	Reader     io.Reader
+	Size       int64
+}
+
+// CollectionStorage stores the media and metadata of collections, like a 0chain allocation.
+type CollectionStorage interface {
+	// AllocationID is the allocation recorded by the collection contract.
+	AllocationID() string
+	// Upload uploads files.
+	Upload(ctx context.Context, files []*StorageFile) error
+	// ShareDir returns a public auth ticket of the directory.
+	ShareDir(ctx context.Context, remotePath string) (string, error)
+}
+
+// Attribute is a trait of a token.
+type Attribute struct {
+	TraitType string      `json:"trait_type"`
+	Value     interface{} `json:"value"`
+}
+
+// TokenMetadata is the metadata JSON of a token, following the ERC-721 metadata standard.
+type TokenMetadata struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Image       string      `json:"image,omitempty"`
+	ExternalURL string      `json:"external_url,omitempty"`
+	Attributes  []Attribute `json:"attributes,omitempty"`
+}
+
+// Media is a media file of a token.
+type Media struct {
+	Name     string // Name of the file in the collection media directory
+	MimeType string
+	Reader   io.Reader
+	Size     int64
+}
+
+// CollectionToken is the metadata of a token and its media. Image of the
+// metadata links to the media if set.
+type CollectionToken struct {
+	Metadata TokenMetadata
+	Media    *Media
+}
+
+// CollectionConfig describes a collection to create.
+type CollectionConfig struct {
+	Type   CollectionType
+	Name   string
+	Symbol string
+	Max    *big.Int // Max number of tokens, the number of tokens if nil
+	Price  *big.Int // Price of a token in wei, fixed, pack and random collections
+	Batch  *big.Int // Batch is the max tokens minted at once, fixed, pack and random collections
+	Data   []byte   // Data passed to the factory module
+
+	Royalty  *big.Int // Royalty is set if not nil
+	Receiver string   // Receiver of royalties is set if not empty
+
+	RemoteDir    string // RemoteDir of the collection in the allocation (default = /nft/<symbol>)
+	GatewayURL   string // GatewayURL of the 0nft service serving shared files
+	StartTokenID uint64 // StartTokenID is the id of the first token
+
+	Tokens []*CollectionToken
+	Hidden *CollectionToken // Hidden is the metadata of random tokens before reveal or closed packs
+	Opened *CollectionToken // Opened is the metadata of opened packs
+}
+
+// CollectionAssets are the uploaded files of a collection.
+type CollectionAssets struct {
+	AllocationID       string
+	MediaAuthTicket    string
+	MetadataAuthTicket string
+	BaseURI            string // BaseURI is the token URI prefix, the token id completes it
+	HiddenURI          string
+	OpenedURI          string
+}
+
+// Collection is a deployed collection.
+type Collection struct {
+	Address common.Address
+	Type    CollectionType
+	Assets  *CollectionAssets
+}
+
+// CreateCollection uploads the collection media and metadata, deploys the
+// collection via the factory and sets its allocation, royalty and hidden
+// or pack metadata.
+func (app *Znft) CreateCollection(ctx context.Context, storage CollectionStorage, cfg *CollectionConfig) (*Collection, error) {
+	assets, err := UploadCollection(ctx, storage, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	address, err := app.DeployCollection(ctx, cfg, assets.BaseURI)
+	if err != nil {
+		return nil, err
+	}
+
+	collection := &Collection{Address: address, Type: cfg.Type, Assets: assets}
+	if err := app.ConfigureCollection(ctx, collection, cfg); err != nil {
+		return collection, err
+	}
+
+	return collection, nil
+}
+
+// UploadCollection uploads the media of the tokens, then their metadata
+// named by token id, and shares both directories.
+func UploadCollection(ctx context.Context, storage CollectionStorage, cfg *CollectionConfig) (*CollectionAssets, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	dir := cfg.remoteDir()
+	mediaDir := path.Join(dir, collectionMediaDir)
+	metadataDir := path.Join(dir, collectionMetadataDir)
+	assets := &CollectionAssets{AllocationID: storage.AllocationID()}
+
+	tokens := cfg.allTokens()
+	var media []*StorageFile
+	for _, token := range tokens {
+		if token.Media != nil {
+			media = append(media, &StorageFile{
+				RemotePath: path.Join(mediaDir, token.Media.Name),
+				MimeType:   token.Media.MimeType,
+				Reader:     token.Media.Reader,
+				Size:       token.Media.Size,
+			})
+		}
+	}
+	if len(media) > 0 {
+		if err := storage.Upload(ctx, media); err != nil {
+			return nil, errors.Wrap(err, "failed to upload collection media")
+		}
+		ticket, err := storage.ShareDir(ctx, mediaDir)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to share collection media")
+		}
+		assets.MediaAuthTicket = ticket
+	}
+
+	var metadata []*StorageFile
+	addMetadata := func(name string, token *CollectionToken) error {
+		m := token.Metadata
+		if token.Media != nil {
+			m.Image = cfg.sharedURL(assets.MediaAuthTicket, token.Media.Name)
+		}
+		buf, err := json.Marshal(m)
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal metadata %s", name)
+		}
+		metadata = append(metadata, &StorageFile{
+			RemotePath: path.Join(metadataDir, name),
+			MimeType:   "application/json",
+			Reader:     bytes.NewReader(buf),
+			Size:       int64(len(buf)),
+		})
+		return nil
+	}
+	for i, token := range cfg.Tokens {
+		if err := addMetadata(strconv.FormatUint(cfg.StartTokenID+uint64(i), 10), token); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Hidden != nil {
+		if err := addMetadata(HiddenMetadataName, cfg.Hidden); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Opened != nil {
+		if err := addMetadata(OpenedMetadataName, cfg.Opened); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := storage.Upload(ctx, metadata); err != nil {
+		return nil, errors.Wrap(err, "failed to upload collection metadata")
+	}
+	ticket, err := storage.ShareDir(ctx, metadataDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to share collection metadata")
+	}
+	assets.MetadataAuthTicket = ticket
+	assets.BaseURI = cfg.sharedURL(ticket, "")
+	if cfg.Hidden != nil {
+		assets.HiddenURI = assets.BaseURI + HiddenMetadataName
+	}
+	if cfg.Opened != nil {
+		assets.OpenedURI = assets.BaseURI + OpenedMetadataName
+	}
+
+	return assets, nil
+}
+
+// DeployCollection creates the collection contract via the factory module
+// of the collection type and returns its address.
+func (app *Znft) DeployCollection(ctx context.Context, cfg *CollectionConfig, uri string) (common.Address, error) {
+	module, err := app.factoryModuleAddress(cfg.Type)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	// the factory passes max, price, batch and the module data to the module
+	uint256, _ := abi.NewType("uint256", "", nil)
+	bytesType, _ := abi.NewType("bytes", "", nil)
+	data, err := abi.Arguments{{Type: uint256}, {Type: uint256}, {Type: uint256}, {Type: bytesType}}.
+		Pack(cfg.max(), bigOrZero(cfg.Price), bigOrZero(cfg.Batch), cfg.Data)
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "failed to pack factory data")
+	}
+
+	client, err := CreateEthClient(app.cfg.EthereumNodeURL)
+	if err != nil {
+		return common.Address{}, err
+	}
+	instance, err := factory.NewBinding(common.HexToAddress(app.cfg.FactoryAddress), client)
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "failed to construct Factory")
+	}
+
+	receipt, err := app.transact(ctx, "Create", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return instance.Create(opts, module, cfg.Name, cfg.Symbol, uri, data)
+	})
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	for _, log := range receipt.Logs {
+		created, err := instance.ParseTokenCreated(*log)
+		if err == nil {
+			Logger.Info("Created collection ", cfg.Name, " address: ", created.Token.Hex())
+			return created.Token, nil
+		}
+	}
+	return common.Address{}, errors.Errorf("no TokenCreated event in transaction %s", receipt.TxHash.Hex())
+}
+
+// ConfigureCollection sets the allocation, royalty and receiver of a
+// deployed collection, the hidden metadata of random collections and the
+// closed and opened metadata of packs.
+func (app *Znft) ConfigureCollection(ctx context.Context, collection *Collection, cfg *CollectionConfig) error {
+	client, err := CreateEthClient(app.cfg.EthereumNodeURL)
+	if err != nil {
+		return err
+	}
+	base, err := storageerc721.NewBinding(collection.Address, client)
+	if err != nil {
+		return errors.Wrapf(err, "failed to construct %s", ContractStorageERC721Name)
+	}
+
+	assets := collection.Assets
+	if _, err := app.transact(ctx, SetAllocation, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return base.SetAllocation(opts, assets.AllocationID)
+	}); err != nil {
+		return err
+	}
+	if cfg.Receiver != "" {
+		if _, err := app.transact(ctx, SetReceiver, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return base.SetReceiver(opts, common.HexToAddress(cfg.Receiver))
+		}); err != nil {
+			return err
+		}
+	}
+	if cfg.Royalty != nil {
+		if _, err := app.transact(ctx, SetRoyalty, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return base.SetRoyalty(opts, cfg.Royalty)
+		}); err != nil {
+			return err
+		}
+	}
+
+	switch collection.Type {
+	case CollectionRandom:
+		random, err := storageerc721random.NewBinding(collection.Address, client)
+		if err != nil {
+			return errors.Wrapf(err, "failed to construct %s", ContractStorageERC721RandomName)
+		}
+		if assets.HiddenURI != "" {
+			if _, err := app.transact(ctx, "SetHidden", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+				return random.SetHidden(opts, assets.HiddenURI)
+			}); err != nil {
+				return err
+			}
+		}
+		if _, err := app.transact(ctx, "SetRevealable", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return random.SetRevealable(opts, true)
+		}); err != nil {
+			return err
+		}
+	case CollectionPack:
+		pack, err := storageerc721pack.NewBinding(collection.Address, client)
+		if err != nil {
+			return errors.Wrapf(err, "failed to construct %s", ContractStorageERC721PackName)
+		}
+		if assets.HiddenURI != "" {
+			if _, err := app.transact(ctx, "SetClosed", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+				return pack.SetClosed(opts, assets.HiddenURI)
+			}); err != nil {
+				return err
+			}
+		}
+		if assets.OpenedURI != "" {
+			if _, err := app.transact(ctx, "SetOpened", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+				return pack.SetOpened(opts, assets.OpenedURI)
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// RevealCollection reveals the metadata of tokens of random collections,
+// or opens the packs of pack collections.
+func (app *Znft) RevealCollection(ctx context.Context, collection *Collection, tokens []*big.Int) error {
+	client, err := CreateEthClient(app.cfg.EthereumNodeURL)
+	if err != nil {
+		return err
+	}
+
+	switch collection.Type {
+	case CollectionRandom:
+		random, err := storageerc721random.NewBinding(collection.Address, client)
+		if err != nil {
+			return errors.Wrapf(err, "failed to construct %s", ContractStorageERC721RandomName)
+		}
+		_, err = app.transact(ctx, "Reveal", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return random.Reveal(opts, tokens)
+		})
+		return err
+	case CollectionPack:
+		pack, err := storageerc721pack.NewBinding(collection.Address, client)
+		if err != nil {
+			return errors.Wrapf(err, "failed to construct %s", ContractStorageERC721PackName)
+		}
+		for _, token := range tokens {
+			token := token
+			if _, err := app.transact(ctx, "Reveal", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+				return pack.Reveal(opts, token)
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return errors.Errorf("collection type %s has no reveal", collection.Type)
+	}
+}
+
+// transact sends a transaction and waits for it to be mined successfully.
+func (app *Znft) transact(ctx context.Context, method string, send func(*bind.TransactOpts) (*types.Transaction, error)) (*types.Receipt, error) {
+	opts, err := app.createTransactOpts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := send(opts)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to execute %s", method)
+		Logger.Error(err)
+		return nil, err
+	}
+
+	client, err := CreateEthClient(app.cfg.EthereumNodeURL)
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := bind.WaitMined(ctx, client, tx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to wait for %s", method)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, errors.Errorf("%s transaction %s failed", method, tx.Hash().Hex())
+	}
+
+	Logger.Info("Executed ", method, " hash: ", tx.Hash().Hex())
+
+	return receipt, nil
+}
+
+func (app *Znft) factoryModuleAddress(t CollectionType) (common.Address, error) {
+	var address string
+	switch t {
+	case CollectionERC721:
+		address = app.cfg.FactoryModuleERC721Address
+	case CollectionFixed:
+		address = app.cfg.FactoryModuleERC721FixedAddress
+	case CollectionPack:
+		address = app.cfg.FactoryModuleERC721PackedAddress
+	case CollectionRandom:
+		address = app.cfg.FactoryModuleERC721RandomAddress
+	default:
+		return common.Address{}, errors.Errorf("unknown collection type %q", t)
+	}
+	if address == "" {
+		return common.Address{}, errors.Errorf("no factory module address of %s collections", t)
+	}
+	return common.HexToAddress(address), nil
+}
+
+func (cfg *CollectionConfig) validate() error {
+	if cfg.Name == "" || cfg.Symbol == "" {
+		return errors.New("collection name and symbol are required")
+	}
+	if len(cfg.Tokens) == 0 {
+		return errors.New("collection has no tokens")
+	}
+	names := make(map[string]bool)
+	for _, token := range cfg.allTokens() {
+		if token.Media == nil {
+			continue
+		}
+		if token.Media.Name == "" || token.Media.Reader == nil {
+			return errors.Errorf("media of token %q has no name or content", token.Metadata.Name)
+		}
+		if names[token.Media.Name] {
+			return errors.Errorf("duplicate media name %q", token.Media.Name)
+		}
+		names[token.Media.Name] = true
+	}
+	return nil
+}
+
+func (cfg *CollectionConfig) allTokens() []*CollectionToken {
+	tokens := append([]*CollectionToken{}, cfg.Tokens...)
+	for _, token := range []*CollectionToken{cfg.Hidden, cfg.Opened} {
+		if token != nil {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+func (cfg *CollectionConfig) remoteDir() string {
+	if cfg.RemoteDir != "" {
+		return path.Clean("/" + cfg.RemoteDir)
+	}
+	return path.Join("/nft", cfg.Symbol)
+}
+
+// sharedURL is the 0nft URL of a file in a shared directory.
+func (cfg *CollectionConfig) sharedURL(authTicket, name string) string {
+	return fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(cfg.GatewayURL, "/"), url.PathEscape(authTicket), url.PathEscape(name))
+}
+
+func (cfg *CollectionConfig) max() *big.Int {
+	if cfg.Max != nil {
+		return cfg.Max
+	}
+	return big.NewInt(int64(len(cfg.Tokens)))
+}
+
+func bigOrZero(v *big.Int) *big.Int {
+	if v == nil {
+		return new(big.Int)
+	}
+	return v
+}
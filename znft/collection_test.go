@@ -0,0 +1,100 @@
+package znft
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStorage keeps uploaded files in memory.
+type memoryStorage struct {
+	files  map[string][]byte
+	shared []string
+}
+
+func (s *memoryStorage) AllocationID() string {
+	return "allocation"
+}
+
+func (s *memoryStorage) Upload(_ context.Context, files []*StorageFile) error {
+	for _, f := range files {
+		buf, err := io.ReadAll(f.Reader)
+		if err != nil {
+			return err
+		}
+		s.files[f.RemotePath] = buf
+	}
+	return nil
+}
+
+func (s *memoryStorage) ShareDir(_ context.Context, remotePath string) (string, error) {
+	s.shared = append(s.shared, remotePath)
+	return "ticket" + remotePath, nil
+}
+
+func TestUploadCollection(t *testing.T) {
+	ctx := context.Background()
+	storage := &memoryStorage{files: make(map[string][]byte)}
+	cfg := &CollectionConfig{
+		Type:         CollectionRandom,
+		Name:         "Cats",
+		Symbol:       "CAT",
+		GatewayURL:   "https://0nft.example/",
+		StartTokenID: 1,
+		Tokens: []*CollectionToken{
+			{
+				Metadata: TokenMetadata{Name: "Cat 1", Attributes: []Attribute{{TraitType: "color", Value: "black"}}},
+				Media:    &Media{Name: "cat1.png", MimeType: "image/png", Reader: strings.NewReader("cat1"), Size: 4},
+			},
+			{
+				Metadata: TokenMetadata{Name: "Cat 2", Image: "https://example.com/cat2.png"},
+			},
+		},
+		Hidden: &CollectionToken{
+			Metadata: TokenMetadata{Name: "Hidden cat"},
+			Media:    &Media{Name: "hidden.png", MimeType: "image/png", Reader: strings.NewReader("hidden"), Size: 6},
+		},
+	}
+
+	assets, err := UploadCollection(ctx, storage, cfg)
+	require.NoError(t, err)
+	require.Equal(t, "allocation", assets.AllocationID)
+	require.Equal(t, []string{"/nft/CAT/media", "/nft/CAT/metadata"}, storage.shared)
+	require.Equal(t, "https://0nft.example/ticket%2Fnft%2FCAT%2Fmetadata/", assets.BaseURI)
+	require.Equal(t, assets.BaseURI+HiddenMetadataName, assets.HiddenURI)
+	require.Empty(t, assets.OpenedURI)
+
+	require.Equal(t, "cat1", string(storage.files["/nft/CAT/media/cat1.png"]))
+	require.Equal(t, "hidden", string(storage.files["/nft/CAT/media/hidden.png"]))
+
+	var m TokenMetadata
+	require.NoError(t, json.Unmarshal(storage.files["/nft/CAT/metadata/1"], &m))
+	require.Equal(t, "Cat 1", m.Name)
+	require.Equal(t, "https://0nft.example/ticket%2Fnft%2FCAT%2Fmedia/cat1.png", m.Image)
+	require.Equal(t, "color", m.Attributes[0].TraitType)
+
+	require.NoError(t, json.Unmarshal(storage.files["/nft/CAT/metadata/2"], &m))
+	require.Equal(t, "https://example.com/cat2.png", m.Image)
+
+	require.Contains(t, storage.files, "/nft/CAT/metadata/"+HiddenMetadataName)
+	require.EqualValues(t, 2, cfg.max().Int64())
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := UploadCollection(ctx, storage, &CollectionConfig{Name: "Cats", Symbol: "CAT"})
+		require.Error(t, err)
+
+		media := &Media{Name: "cat.png", Reader: strings.NewReader("cat")}
+		_, err = UploadCollection(ctx, storage, &CollectionConfig{
+			Name:   "Cats",
+			Symbol: "CAT",
+			Max:    big.NewInt(10),
+			Tokens: []*CollectionToken{{Media: media}, {Media: media}},
+		})
+		require.Error(t, err)
+	})
+}
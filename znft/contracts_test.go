@@ -0,0 +1,384 @@
+package znft
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/0chain/gosdk/zcnbridge/ethereum/simulated"
+	storageerc721 "github.com/0chain/gosdk/znft/contracts/dstorageerc721/binding"
+	storageerc721fixed "github.com/0chain/gosdk/znft/contracts/dstorageerc721fixed/binding"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/stretchr/testify/require"
+)
+
+// The dStorage ERC-721 bindings were generated from their ABI only, so the
+// tests deploy a stand-in assembled after the Solidity sources of the
+// bindings. It only backs the tests of the SDK calls going through the
+// unmodified bindings.
+
+// royaltyDenominator is the denominator of the royalty of the test collection.
+const royaltyDenominator = 10000
+
+var (
+	ownershipTransferred = eventID("OwnershipTransferred(address,address)")
+	transferEvent        = eventID("Transfer(address,address,uint256)")
+	approvalEvent        = eventID("Approval(address,address,uint256)")
+)
+
+// ownable adds the methods of OpenZeppelin's Ownable, with the owner at slot.
+func ownable(p *program, handlers map[string]func(), slot int, renounce bool) {
+	handlers["owner"] = func() {
+		p.sload(slot).returnWord()
+	}
+	handlers["transferOwnership"] = func() {
+		p.onlyOwner(slot)
+		p.argAddress(0).op(vm.DUP1).require("Ownable: new owner is the zero address")
+		p.argAddress(0).sload(slot).push(ownershipTransferred).push(0).push(0).op(vm.LOG3)
+		p.argAddress(0).sstore(slot).op(vm.STOP)
+	}
+	if renounce {
+		handlers["renounceOwnership"] = func() {
+			p.onlyOwner(slot)
+			p.push(0).sload(slot).push(ownershipTransferred).push(0).push(0).op(vm.LOG3)
+			p.push(0).sstore(slot).op(vm.STOP)
+		}
+	}
+}
+
+// setOwner makes the deployer the owner at slot.
+func setOwner(p *program, slot int) {
+	p.op(vm.CALLER).sstore(slot)
+	p.op(vm.CALLER).push(0).push(ownershipTransferred).push(0).push(0).op(vm.LOG3)
+}
+
+// storage slots of the dStorage ERC-721
+const (
+	erc721Owner = iota
+	erc721Name
+	erc721Symbol
+	erc721Total
+	erc721Max
+	erc721Batch
+	erc721Price
+	erc721Mintable
+	erc721Allocation
+	erc721URI
+	erc721URIFallback
+	erc721Royalty
+	erc721Receiver
+	erc721Frozen
+	erc721Owners
+	erc721Balances
+	erc721TokenApprovals
+	erc721OperatorApprovals
+)
+
+// interfaces of ERC-165, ERC-721, ERC-721 metadata and ERC-2981
+var erc721Interfaces = []int{0x01ffc9a7, 0x80ac58cd, 0x5b5e139f, 0x2a55205a}
+
+// onERC721Received is the selector returned by ERC-721 receivers.
+const onERC721Received = 0x150b7a02
+
+// storageERC721Runtime is the DStorageERC721 collection. Its tokens are
+// numbered from 1 in mint order. A batch of zero does not limit mints.
+var storageERC721Runtime = func() []byte {
+	parsed := mustABI(storageerc721.BindingMetaData)
+	p := newProgram()
+	h := make(map[string]func())
+	ownable(p, h, erc721Owner, true)
+
+	approvalForAll := eventID("ApprovalForAll(address,address,bool)")
+	metadataFrozen := eventID("MetadataFrozen(string)")
+
+	word := func(name string, slot int) {
+		h[name] = func() { p.sload(slot).returnWord() }
+	}
+	word("total", erc721Total)
+	word("max", erc721Max)
+	word("batch", erc721Batch)
+	word("price", erc721Price)
+	word("mintable", erc721Mintable)
+	word("royalty", erc721Royalty)
+	word("receiver", erc721Receiver)
+	word("frozen", erc721Frozen)
+
+	str := func(name string, slot int) {
+		h[name] = func() { p.push(0xa0).loadString(slot).op(vm.POP).returnString() }
+	}
+	str("name", erc721Name)
+	str("symbol", erc721Symbol)
+	str("allocation", erc721Allocation)
+	str("uri", erc721URI)
+	str("uriFallback", erc721URIFallback)
+
+	// exists reverts unless the token of the i-th argument is minted.
+	exists := func(i int) {
+		p.arg(i).mapping(erc721Owners).op(vm.SLOAD).require("ERC721: invalid token ID")
+	}
+	notFrozen := func() {
+		p.sload(erc721Frozen).op(vm.ISZERO).require("metadata frozen")
+	}
+	// update sets the word at slot from the first argument and emits
+	// the event with the previous and the updated values.
+	update := func(name, event string, slot int, value func()) {
+		topic := eventID(event)
+		h[name] = func() {
+			p.onlyOwner(erc721Owner)
+			p.sload(slot).push(0x80).op(vm.MSTORE)
+			value()
+			p.op(vm.DUP1).push(0xa0).op(vm.MSTORE).sstore(slot)
+			p.push(topic).push(0x40).push(0x80).op(vm.LOG1, vm.STOP)
+		}
+	}
+	update("setMintable", "MintableUpdated(bool,bool)", erc721Mintable, func() { p.argBool(0) })
+	update("setReceiver", "ReceiverUpdated(address,address)", erc721Receiver, func() { p.argAddress(0) })
+	update("setRoyalty", "RoyaltyUpdated(uint256,uint256)", erc721Royalty, func() {
+		p.push(royaltyDenominator).arg(0).op(vm.GT, vm.ISZERO).require("royalty above 100%")
+		p.arg(0)
+	})
+	// updateString sets the string at slot from the first argument and
+	// emits the event with the previous and the updated strings.
+	updateString := func(name, event string, slot int) {
+		topic := eventID(event)
+		h[name] = func() {
+			p.onlyOwner(erc721Owner)
+			notFrozen()
+			p.push(0x40).push(0x80).op(vm.MSTORE)
+			p.push(0xc0).loadString(slot)                                          // [end]
+			p.op(vm.DUP1).push(0x80).op(vm.SWAP1, vm.SUB).push(0xa0).op(vm.MSTORE) // [end]
+			p.push(4).arg(0).op(vm.ADD)                                            // [off, end]
+			p.op(vm.DUP1, vm.CALLDATALOAD).pad32().push(32).op(vm.ADD)             // [size, off, end]
+			p.op(vm.DUP1, vm.SWAP2, vm.DUP4, vm.CALLDATACOPY, vm.ADD)              // [end]
+			p.push(0x80).op(vm.SWAP1, vm.SUB).push(topic).op(vm.SWAP1).push(0x80).op(vm.LOG1)
+			p.storeString(slot, 0, vm.CALLDATALOAD, 4).op(vm.STOP)
+		}
+	}
+	updateString("setURI", "UriUpdated(string,string)", erc721URI)
+	updateString("setURIFallback", "UriFallbackUpdated(string,string)", erc721URIFallback)
+	h["setAllocation"] = func() {
+		p.onlyOwner(erc721Owner)
+		p.storeString(erc721Allocation, 0, vm.CALLDATALOAD, 4).op(vm.STOP)
+	}
+	h["freeze"] = func() {
+		p.onlyOwner(erc721Owner)
+		notFrozen()
+		p.push(1).sstore(erc721Frozen)
+		p.push(32).push(0x80).op(vm.MSTORE)
+		p.push(0xa0).loadString(erc721URI).push(0x80).op(vm.SWAP1, vm.SUB)
+		p.push(metadataFrozen).op(vm.SWAP1).push(0x80).op(vm.LOG1, vm.STOP)
+	}
+
+	// tokenURI returns the string at slot followed by the token id.
+	tokenURI := func(slot int) {
+		count, write := p.newLabel(), p.newLabel()
+		exists(0)
+		p.push(0xa0).loadString(slot).op(vm.POP)
+		p.arg(0).push(0xa0).op(vm.MLOAD).push(0xc0).op(vm.ADD) // [start, id]
+		p.push(1).op(vm.DUP3).push(10).op(vm.SWAP1, vm.DIV)    // [id/10, digits, start, id]
+		p.label(count)
+		p.op(vm.DUP1, vm.ISZERO).jumpi(write)
+		p.push(10).op(vm.SWAP1, vm.DIV, vm.SWAP1).push(1).op(vm.ADD, vm.SWAP1)
+		p.jump(count)
+		p.label(write)
+		p.op(vm.POP)                                                           // [digits, start, id]
+		p.op(vm.DUP1).push(0xa0).op(vm.MLOAD, vm.ADD).push(0xa0).op(vm.MSTORE) // [digits, start, id]
+		p.op(vm.ADD)                                                           // [end, id]
+		digit := p.newLabel()
+		p.label(digit)
+		p.push(1).op(vm.SWAP1, vm.SUB)
+		p.push(10).op(vm.DUP3, vm.MOD).push(int('0')).op(vm.ADD, vm.DUP2, vm.MSTORE8)
+		p.op(vm.SWAP1).push(10).op(vm.SWAP1, vm.DIV, vm.SWAP1)
+		p.op(vm.DUP2).jumpi(digit)
+		p.op(vm.POP, vm.POP).returnString()
+	}
+	h["tokenURI"] = func() { tokenURI(erc721URI) }
+	h["tokenURIFallback"] = func() { tokenURI(erc721URIFallback) }
+
+	h["royaltyInfo"] = func() {
+		p.arg(1).sload(erc721Royalty).op(vm.MUL).push(royaltyDenominator).op(vm.SWAP1, vm.DIV)
+		p.push(32).op(vm.MSTORE)
+		p.sload(erc721Receiver).push(0).op(vm.MSTORE)
+		p.push(64).push(0).op(vm.RETURN)
+	}
+	h["supportsInterface"] = func() {
+		yes := p.newLabel()
+		p.arg(0).push(0xe0).op(vm.SHR)
+		for _, id := range erc721Interfaces {
+			p.op(vm.DUP1).push(id).op(vm.EQ).jumpi(yes)
+		}
+		p.push(0).returnWord()
+		p.label(yes)
+		p.push(1).returnWord()
+	}
+
+	h["balanceOf"] = func() {
+		p.argAddress(0).op(vm.DUP1).require("ERC721: address zero is not a valid owner")
+		p.mapping(erc721Balances).op(vm.SLOAD).returnWord()
+	}
+	h["ownerOf"] = func() {
+		p.arg(0).mapping(erc721Owners).op(vm.SLOAD)
+		p.op(vm.DUP1).require("ERC721: invalid token ID").returnWord()
+	}
+	h["getApproved"] = func() {
+		exists(0)
+		p.arg(0).mapping(erc721TokenApprovals).op(vm.SLOAD).returnWord()
+	}
+	h["isApprovedForAll"] = func() {
+		p.argAddress(1).argAddress(0).mapping2(erc721OperatorApprovals).op(vm.SLOAD).returnWord()
+	}
+	h["approve"] = func() {
+		p.arg(1).mapping(erc721Owners).op(vm.SLOAD) // [owner]
+		p.op(vm.DUP1).require("ERC721: invalid token ID")
+		p.op(vm.DUP1).argAddress(0).op(vm.EQ, vm.ISZERO).require("ERC721: approval to current owner")
+		p.op(vm.DUP1, vm.CALLER, vm.EQ)
+		p.op(vm.CALLER, vm.DUP3).mapping2(erc721OperatorApprovals).op(vm.SLOAD, vm.OR)
+		p.require("ERC721: approve caller is not token owner or approved for all")
+		p.argAddress(0).arg(1).mapping(erc721TokenApprovals).op(vm.SSTORE)
+		p.arg(1).argAddress(0).op(vm.DUP3).push(approvalEvent).push(0).push(0).op(vm.LOG4, vm.STOP)
+	}
+	h["setApprovalForAll"] = func() {
+		p.argAddress(0).op(vm.CALLER, vm.EQ, vm.ISZERO).require("ERC721: approve to caller")
+		p.argBool(1).op(vm.DUP1)
+		p.argAddress(0).op(vm.CALLER).mapping2(erc721OperatorApprovals).op(vm.SSTORE)
+		p.push(0).op(vm.MSTORE)
+		p.argAddress(0).op(vm.CALLER).push(approvalForAll).push(32).push(0).op(vm.LOG3, vm.STOP)
+	}
+
+	// transfer moves the token of the arguments (from, to, tokenId) and,
+	// for safe transfers, checks the receiver. [mode] where mode is 0 for
+	// transferFrom, 1 for safeTransferFrom and 2 for safeTransferFrom with data.
+	h["transferFrom"] = func() { p.push(0).jump("transfer") }
+	h["safeTransferFrom"] = func() { p.push(1).jump("transfer") }
+	h["safeTransferFrom0"] = func() { p.push(2).jump("transfer") }
+	transfer := func() {
+		done, call := p.newLabel(), p.newLabel()
+		p.label("transfer")
+		p.arg(2).mapping(erc721Owners).op(vm.SLOAD) // [owner, mode]
+		p.op(vm.DUP1).require("ERC721: invalid token ID")
+		p.op(vm.DUP1).argAddress(0).op(vm.EQ).require("ERC721: transfer from incorrect owner")
+		p.argAddress(1).require("ERC721: transfer to the zero address")
+		p.op(vm.DUP1, vm.CALLER, vm.EQ)
+		p.arg(2).mapping(erc721TokenApprovals).op(vm.SLOAD, vm.CALLER, vm.EQ, vm.OR)
+		p.op(vm.CALLER, vm.DUP3).mapping2(erc721OperatorApprovals).op(vm.SLOAD, vm.OR)
+		p.require("ERC721: caller is not token owner or approved")
+		p.op(vm.POP) // [mode]
+
+		p.push(0).arg(2).mapping(erc721TokenApprovals).op(vm.SSTORE)
+		p.argAddress(0).mapping(erc721Balances).op(vm.DUP1, vm.SLOAD).push(1).op(vm.SWAP1, vm.SUB, vm.SWAP1, vm.SSTORE)
+		p.argAddress(1).mapping(erc721Balances).op(vm.DUP1, vm.SLOAD).push(1).op(vm.ADD, vm.SWAP1, vm.SSTORE)
+		p.argAddress(1).arg(2).mapping(erc721Owners).op(vm.SSTORE)
+		p.arg(2).argAddress(1).argAddress(0).push(transferEvent).push(0).push(0).op(vm.LOG4)
+
+		p.op(vm.DUP1, vm.ISZERO).jumpi(done)
+		p.argAddress(1).op(vm.EXTCODESIZE, vm.ISZERO).jumpi(done)
+		// onERC721Received(operator, from, tokenId, data)
+		p.push(onERC721Received).push(0xe0).op(vm.SHL).push(0x80).op(vm.MSTORE)
+		p.op(vm.CALLER).push(0x84).op(vm.MSTORE)
+		p.argAddress(0).push(0xa4).op(vm.MSTORE)
+		p.arg(2).push(0xc4).op(vm.MSTORE)
+		p.push(0x80).push(0xe4).op(vm.MSTORE)
+		p.push(0).push(0x104).op(vm.MSTORE)
+		p.push(0xa4) // [size, mode]
+		p.op(vm.DUP2).push(2).op(vm.EQ, vm.ISZERO).jumpi(call)
+		p.op(vm.POP).push(4).arg(3).op(vm.ADD)                     // [off, mode]
+		p.op(vm.DUP1, vm.CALLDATALOAD).pad32().push(32).op(vm.ADD) // [n, off, mode]
+		p.op(vm.DUP1, vm.DUP3).push(0x104).op(vm.CALLDATACOPY)     // [n, off, mode]
+		p.op(vm.SWAP1, vm.POP).push(0x84).op(vm.ADD)               // [size, mode]
+		p.label(call)
+		p.push(32).push(0).op(vm.DUP3).push(0x80).push(0).argAddress(1).op(vm.GAS, vm.CALL)
+		p.require("ERC721: transfer to non ERC721Receiver implementer")
+		p.push(0).op(vm.MLOAD).push(0xe0).op(vm.SHR).push(onERC721Received).op(vm.EQ)
+		p.require("ERC721: transfer to non ERC721Receiver implementer")
+		p.label(done)
+		p.op(vm.STOP)
+	}
+
+	// mint mints tokens to the caller. [amount]
+	h["mint"] = func() {
+		p.sload(erc721Mintable).require("minting is disabled")
+		p.sload(erc721Batch).op(vm.ISZERO)
+		p.arg(0).sload(erc721Batch).op(vm.LT, vm.ISZERO, vm.OR).require("amount exceeds batch")
+		p.sload(erc721Price).arg(0).op(vm.MUL, vm.CALLVALUE, vm.LT, vm.ISZERO).require("insufficient payment")
+		p.arg(0).jump("mintTokens")
+	}
+	h["mintOwner"] = func() {
+		p.onlyOwner(erc721Owner)
+		p.arg(0).jump("mintTokens")
+	}
+	mintTokens := func() {
+		loop, end := p.newLabel(), p.newLabel()
+		p.label("mintTokens")
+		p.op(vm.DUP1).sload(erc721Total).op(vm.ADD)
+		p.sload(erc721Max).op(vm.LT, vm.ISZERO).require("amount exceeds max")
+		p.label(loop)
+		p.op(vm.DUP1, vm.ISZERO).jumpi(end)
+		p.sload(erc721Total).push(1).op(vm.ADD, vm.DUP1).sstore(erc721Total) // [id, amount]
+		p.op(vm.CALLER, vm.DUP2).mapping(erc721Owners).op(vm.SSTORE)
+		p.op(vm.CALLER).mapping(erc721Balances).op(vm.DUP1, vm.SLOAD).push(1).op(vm.ADD, vm.SWAP1, vm.SSTORE)
+		p.op(vm.CALLER).push(0).push(transferEvent).push(0).push(0).op(vm.LOG4) // [amount]
+		p.push(1).op(vm.SWAP1, vm.SUB)
+		p.jump(loop)
+		p.label(end)
+		p.op(vm.STOP)
+	}
+	h["withdraw"] = func() {
+		p.onlyOwner(erc721Owner)
+		p.push(0).push(0).push(0).push(0).op(vm.SELFBALANCE).sload(erc721Owner).op(vm.GAS, vm.CALL)
+		p.require("withdraw failed").op(vm.STOP)
+	}
+
+	p.dispatch(parsed, h)
+	transfer()
+	mintTokens()
+	return p.assemble()
+}()
+
+// storageERC721Bin deploys the DStorageERC721 with the constructor
+// arguments name, symbol, uri, max and, if fixed, price and batch.
+func storageERC721Bin(fixed bool) []byte {
+	return deployCode(storageERC721Runtime, func(p *program) {
+		const args = 0x100
+		setOwner(p, erc721Owner)
+		p.op(vm.CALLER).sstore(erc721Receiver)
+		p.op(vm.CODESIZE).pushLabel("args").op(vm.SWAP1, vm.SUB).pushLabel("args").push(args).op(vm.CODECOPY)
+		p.storeString(erc721Name, 0, vm.MLOAD, args)
+		p.storeString(erc721Symbol, 1, vm.MLOAD, args)
+		p.storeString(erc721URI, 2, vm.MLOAD, args)
+		p.push(args + 3*32).op(vm.MLOAD).sstore(erc721Max)
+		if fixed {
+			p.push(args + 4*32).op(vm.MLOAD).sstore(erc721Price)
+			p.push(args + 5*32).op(vm.MLOAD).sstore(erc721Batch)
+		}
+	})
+}
+
+func mustABI(metadata *bind.MetaData) abi.ABI {
+	parsed, err := metadata.GetAbi()
+	if err != nil {
+		panic(err)
+	}
+	return *parsed
+}
+
+// deployTestStorageERC721 deploys a DStorageERC721 collection owned by the
+// owner of the backend, with at most max tokens.
+func deployTestStorageERC721(t *testing.T, backend *simulated.Backend, name, symbol, uri string, max *big.Int) common.Address {
+	return deployTestContract(t, backend, storageerc721.BindingMetaData, storageERC721Bin(false), name, symbol, uri, max)
+}
+
+// deployTestStorageERC721Fixed deploys a DStorageERC721Fixed collection owned
+// by the owner of the backend, selling at most batch tokens at once at price.
+func deployTestStorageERC721Fixed(t *testing.T, backend *simulated.Backend, name, symbol, uri string, max, price, batch *big.Int) common.Address {
+	return deployTestContract(t, backend, storageerc721fixed.BindingMetaData, storageERC721Bin(true), name, symbol, uri, max, price, batch)
+}
+
+func deployTestContract(t *testing.T, backend *simulated.Backend, metadata *bind.MetaData, bin []byte, params ...interface{}) common.Address {
+	opts, err := backend.TransactOpts(backend.Owner)
+	require.NoError(t, err)
+	address, _, _, err := bind.DeployContract(opts, mustABI(metadata), bin, backend, params...)
+	require.NoError(t, err)
+	return address
+}
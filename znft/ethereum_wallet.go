@@ -4,9 +4,9 @@ import (
 	"context"
 	"math/big"
 	"path"
-	"time"
 
-	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/0chain/gosdk/zcnbridge/ethsigner"
+	"github.com/0chain/gosdk/zcnbridge/gas"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
@@ -25,11 +25,7 @@ func CreateEthClient(ethereumNodeURL string) (*ethclient.Client, error) {
 }
 
 func (app *Znft) createSignedTransactionFromKeyStore(ctx context.Context) (*bind.TransactOpts, error) {
-	var (
-		signerAddress = common.HexToAddress(app.cfg.WalletAddress)
-		password      = app.cfg.VaultPassword
-		value         = app.cfg.Value
-	)
+	value := app.cfg.Value
 
 	client, err := CreateEthClient(app.cfg.EthereumNodeURL)
 	if err != nil {
@@ -38,14 +34,9 @@ func (app *Znft) createSignedTransactionFromKeyStore(ctx context.Context) (*bind
 		return nil, err
 	}
 
-	keyDir := path.Join(app.cfg.Homedir, WalletDir)
-	ks := keystore.NewKeyStore(keyDir, keystore.StandardScryptN, keystore.StandardScryptP)
-	signer := accounts.Account{
-		Address: signerAddress,
-	}
-	signerAcc, err := ks.Find(signer)
+	s, err := app.ethereumSigner()
 	if err != nil {
-		err := errors.Wrapf(err, "signer: %s", signerAddress.Hex())
+		err := errors.Wrap(err, "failed to create signer")
 		Logger.Fatal(err)
 		return nil, err
 	}
@@ -57,21 +48,19 @@ func (app *Znft) createSignedTransactionFromKeyStore(ctx context.Context) (*bind
 		return nil, err
 	}
 
-	err = ks.TimedUnlock(signer, password, time.Second*2)
-	if err != nil {
-		Logger.Fatal(err)
-		return nil, err
-	}
+	opts := ethsigner.NewTransactOpts(ctx, s, chainID)
 
-	opts, err := bind.NewKeyStoreTransactorWithChainID(ks, signerAcc, chainID)
+	fees, err := app.gasStrategy().Fees(ctx, client)
 	if err != nil {
-		Logger.Fatal(err)
+		err := errors.Wrap(err, "failed to estimate fees")
+		Logger.Error(err)
 		return nil, err
 	}
 
 	valueWei := new(big.Int).Mul(big.NewInt(value), big.NewInt(params.Wei))
 
 	opts.Value = valueWei // in wei (= no funds)
+	fees.Apply(opts)      // wei
 
 	return opts, nil
 }
@@ -84,19 +73,13 @@ func (app *Znft) createSignedTransactionFromKeyStoreWithGasPrice(ctx context.Con
 		return nil, err
 	}
 
-	nonce, err := client.PendingNonceAt(ctx, common.HexToAddress(app.cfg.WalletAddress))
-	if err != nil {
-		Logger.Fatal(err)
-		return nil, err
-	}
-
-	gasPriceWei, err := client.SuggestGasPrice(ctx)
+	opts, err := app.createSignedTransactionFromKeyStore(ctx)
 	if err != nil {
 		Logger.Fatal(err)
 		return nil, err
 	}
 
-	opts, err := app.createSignedTransactionFromKeyStore(ctx)
+	nonce, err := client.PendingNonceAt(ctx, opts.From)
 	if err != nil {
 		Logger.Fatal(err)
 		return nil, err
@@ -104,7 +87,25 @@ func (app *Znft) createSignedTransactionFromKeyStoreWithGasPrice(ctx context.Con
 
 	opts.Nonce = big.NewInt(int64(nonce)) // (nil = use pending state), look at bind.CallOpts{Pending: true}
 	opts.GasLimit = gasLimitUnits         // in units  (0 = estimate)
-	opts.GasPrice = gasPriceWei           // wei (nil = gas price oracle)
 
 	return opts, nil
 }
+
+func (app *Znft) gasStrategy() gas.Strategy {
+	if app.cfg.GasStrategy != nil {
+		return app.cfg.GasStrategy
+	}
+	return gas.Default()
+}
+
+// ethereumSigner returns the configured signer, the key store signer of the
+// wallet address if none.
+func (app *Znft) ethereumSigner() (ethsigner.Signer, error) {
+	if app.cfg.Signer != nil {
+		return ethsigner.New(app.cfg.Signer)
+	}
+
+	keyDir := path.Join(app.cfg.Homedir, WalletDir)
+	ks := keystore.NewKeyStore(keyDir, keystore.StandardScryptN, keystore.StandardScryptP)
+	return ethsigner.NewKeyStoreSigner(ks, common.HexToAddress(app.cfg.WalletAddress), app.cfg.VaultPassword), nil
+}
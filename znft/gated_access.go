@@ -0,0 +1,444 @@
+package znft
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/0chain/gosdk/zboxcore/fileref"
+	"github.com/0chain/gosdk/zcnbridge/ethsigner"
+	storageerc721 "github.com/0chain/gosdk/znft/contracts/dstorageerc721/binding"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultAccessProofMaxAge is the age after which an access proof is rejected.
+	DefaultAccessProofMaxAge = 5 * time.Minute
+	// accessProofClockSkew is the accepted time of access proofs in the future.
+	accessProofClockSkew = time.Minute
+)
+
+var (
+	// ErrNotTokenOwner the proof signer does not own the token
+	ErrNotTokenOwner = errors.New("znft: signer is not the token owner")
+	// ErrAccessProofExpired the proof is too old or from the future
+	ErrAccessProofExpired = errors.New("znft: access proof expired")
+)
+
+// AccessProof proves that Owner owns a token and asks for an auth ticket of
+// the token files for the 0chain client ClientID.
+type AccessProof struct {
+	Collection          common.Address `json:"collection"`
+	TokenID             *big.Int       `json:"token_id"`
+	Owner               common.Address `json:"owner"`
+	ClientID            string         `json:"client_id"`
+	EncryptionPublicKey string         `json:"encryption_public_key"`
+	Timestamp           int64          `json:"timestamp"`
+	Signature           []byte         `json:"signature"`
+}
+
+// NewAccessProof signs an access proof of the token with the signer owning it.
+func NewAccessProof(s ethsigner.Signer, collection common.Address, tokenID *big.Int, clientID, encryptionPublicKey string) (*AccessProof, error) {
+	proof := &AccessProof{
+		Collection:          collection,
+		TokenID:             tokenID,
+		Owner:               s.Address(),
+		ClientID:            clientID,
+		EncryptionPublicKey: encryptionPublicKey,
+		Timestamp:           time.Now().Unix(),
+	}
+
+	sig, err := s.SignText([]byte(proof.message()))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign access proof")
+	}
+	proof.Signature = sig
+
+	return proof, nil
+}
+
+// Hash is the signed EIP-191 hash of the proof message.
+func (p *AccessProof) Hash() []byte {
+	return accounts.TextHash([]byte(p.message()))
+}
+
+func (p *AccessProof) message() string {
+	return fmt.Sprintf("0chain NFT access\ncollection: %s\ntoken: %s\nowner: %s\nclient: %s\nencryption key: %s\ntimestamp: %d",
+		p.Collection.Hex(), p.TokenID, p.Owner.Hex(), p.ClientID, p.EncryptionPublicKey, p.Timestamp)
+}
+
+// Signer recovers the address signing the proof.
+func (p *AccessProof) Signer() (common.Address, error) {
+	if len(p.Signature) != crypto.SignatureLength {
+		return common.Address{}, errors.New("invalid access proof signature length")
+	}
+	sig := append([]byte{}, p.Signature...)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	pub, err := crypto.SigToPub(p.Hash(), sig)
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "failed to recover access proof signer")
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// ShareAllocation shares files of an allocation, implemented by sdk.Allocation.
+type ShareAllocation interface {
+	GetAuthTicket(path, filename, referenceType, refereeClientID, refereeEncryptionPublicKey string, expiration int64, availableAfter *time.Time) (string, error)
+	RevokeShare(path string, refereeClientID string) error
+}
+
+// TokenOwnerFunc returns the owner of a token.
+type TokenOwnerFunc func(ctx context.Context, collection common.Address, tokenID *big.Int) (common.Address, error)
+
+// TokenFile is an allocation file or directory unlocked by a token.
+type TokenFile struct {
+	Path string
+	Type string // fileref.FILE or fileref.DIRECTORY
+}
+
+// TokenFilesFunc returns the allocation files unlocked by a token.
+type TokenFilesFunc func(collection common.Address, tokenID *big.Int) ([]TokenFile, error)
+
+// GrantStore loads and saves the grants of an AccessIssuer, so that they are
+// revoked after a restart.
+type GrantStore interface {
+	Load() ([]*AccessGrant, error)
+	Save(grants []*AccessGrant) error
+}
+
+// fileGrantStore saves the grants as json in a file.
+type fileGrantStore struct {
+	path string
+}
+
+// NewFileGrantStore creates the grant store saving the grants in the json file.
+func NewFileGrantStore(path string) GrantStore {
+	return &fileGrantStore{path: path}
+}
+
+func (fs *fileGrantStore) Load() ([]*AccessGrant, error) {
+	buf, err := os.ReadFile(fs.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var grants []*AccessGrant
+	if err := json.Unmarshal(buf, &grants); err != nil {
+		return nil, err
+	}
+	return grants, nil
+}
+
+func (fs *fileGrantStore) Save(grants []*AccessGrant) error {
+	buf, err := json.Marshal(grants)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(fs.path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(fs.path, buf, 0600)
+}
+
+// AccessGrant is the auth tickets issued to the owner of a token.
+type AccessGrant struct {
+	Collection common.Address    `json:"collection"`
+	TokenID    *big.Int          `json:"token_id"`
+	Owner      common.Address    `json:"owner"`
+	ClientID   string            `json:"client_id"`
+	Tickets    map[string]string `json:"tickets"` // Tickets by file path
+	IssuedAt   time.Time         `json:"issued_at"`
+}
+
+// AccessIssuer verifies access proofs and issues re-encryption auth tickets
+// of the token files to token owners. The grants are revoked when the token
+// is transferred, see RevokeTransferred.
+type AccessIssuer struct {
+	Allocation ShareAllocation
+	OwnerOf    TokenOwnerFunc
+	Files      TokenFilesFunc
+	// Expiration of the auth tickets in seconds, 0 never expires
+	Expiration int64
+	// MaxAge of the proofs (default = DefaultAccessProofMaxAge)
+	MaxAge time.Duration
+	// Store persists the grants, nil keeps them in memory only
+	Store GrantStore
+
+	mu     sync.Mutex
+	loaded bool
+	grants map[string][]*AccessGrant
+}
+
+// NewAccessIssuer creates an issuer sharing the token files of the allocation,
+// reading token owners with the collection contracts. The grants are saved
+// in store, see NewFileGrantStore.
+func (app *Znft) NewAccessIssuer(allocation ShareAllocation, files TokenFilesFunc, store GrantStore) *AccessIssuer {
+	return &AccessIssuer{Allocation: allocation, OwnerOf: app.TokenOwner, Files: files, Store: store}
+}
+
+// TokenOwner returns the owner of a token of a collection.
+func (app *Znft) TokenOwner(ctx context.Context, collection common.Address, tokenID *big.Int) (common.Address, error) {
+	client, err := CreateEthClient(app.cfg.EthereumNodeURL)
+	if err != nil {
+		return common.Address{}, err
+	}
+	instance, err := storageerc721.NewBinding(collection, client)
+	if err != nil {
+		return common.Address{}, errors.Wrapf(err, "failed to construct %s", ContractStorageERC721Name)
+	}
+	owner, err := instance.OwnerOf(&bind.CallOpts{Context: ctx}, tokenID)
+	if err != nil {
+		return common.Address{}, errors.Wrapf(err, "failed to read %s", "OwnerOf")
+	}
+	return owner, nil
+}
+
+// TokenDirFiles returns the TokenFilesFunc unlocking the directory of each
+// token in dir, named by collection address and token id.
+func TokenDirFiles(dir string) TokenFilesFunc {
+	return func(collection common.Address, tokenID *big.Int) ([]TokenFile, error) {
+		return []TokenFile{{Path: path.Join(dir, collection.Hex(), tokenID.String()), Type: fileref.DIRECTORY}}, nil
+	}
+}
+
+// Verify checks the proof signature, age and that the signer owns the token.
+func (i *AccessIssuer) Verify(ctx context.Context, proof *AccessProof) error {
+	if proof.TokenID == nil || proof.ClientID == "" {
+		return errors.New("access proof has no token or client")
+	}
+
+	maxAge := i.MaxAge
+	if maxAge == 0 {
+		maxAge = DefaultAccessProofMaxAge
+	}
+	signed := time.Unix(proof.Timestamp, 0)
+	if now := time.Now(); now.Sub(signed) > maxAge || signed.Sub(now) > accessProofClockSkew {
+		return ErrAccessProofExpired
+	}
+
+	signerAddress, err := proof.Signer()
+	if err != nil {
+		return err
+	}
+	if signerAddress != proof.Owner {
+		return errors.Wrap(ErrNotTokenOwner, "signature does not match owner")
+	}
+
+	owner, err := i.OwnerOf(ctx, proof.Collection, proof.TokenID)
+	if err != nil {
+		return err
+	}
+	if owner != proof.Owner {
+		return ErrNotTokenOwner
+	}
+	return nil
+}
+
+// Issue verifies the proof and returns auth tickets of the token files
+// re-encrypted for the proof client. If a file can not be shared, the files
+// shared before are revoked, or kept as a partial grant for Revoke to retry
+// if they can not be revoked either.
+func (i *AccessIssuer) Issue(ctx context.Context, proof *AccessProof) (*AccessGrant, error) {
+	if err := i.Verify(ctx, proof); err != nil {
+		return nil, err
+	}
+
+	files, err := i.Files(proof.Collection, proof.TokenID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get token files")
+	}
+
+	grant := &AccessGrant{
+		Collection: proof.Collection,
+		TokenID:    proof.TokenID,
+		Owner:      proof.Owner,
+		ClientID:   proof.ClientID,
+		Tickets:    make(map[string]string, len(files)),
+		IssuedAt:   time.Now(),
+	}
+	for _, file := range files {
+		// GetAuthTicket uploads the re-encryption key of the client to the blobbers
+		ticket, err := i.Allocation.GetAuthTicket(file.Path, path.Base(file.Path), file.Type, proof.ClientID,
+			proof.EncryptionPublicKey, i.Expiration, &grant.IssuedAt)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to get auth ticket of %s", file.Path)
+			if revokeErr := i.revoke(grant); revokeErr != nil {
+				if addErr := i.addGrant(grant); addErr != nil {
+					return nil, errors.Wrap(addErr, revokeErr.Error())
+				}
+				return nil, errors.Wrapf(err, "kept partial grant: %v", revokeErr)
+			}
+			return nil, err
+		}
+		grant.Tickets[file.Path] = ticket
+	}
+
+	if err := i.addGrant(grant); err != nil {
+		return nil, err
+	}
+	return grant, nil
+}
+
+func (i *AccessIssuer) addGrant(grant *AccessGrant) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if err := i.load(); err != nil {
+		return err
+	}
+	key := grantKey(grant.Collection, grant.TokenID)
+	i.grants[key] = append(i.grants[key], grant)
+	return i.save()
+}
+
+// Grants returns the grants issued for a token.
+func (i *AccessIssuer) Grants(collection common.Address, tokenID *big.Int) ([]*AccessGrant, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if err := i.load(); err != nil {
+		return nil, err
+	}
+	return append([]*AccessGrant{}, i.grants[grantKey(collection, tokenID)]...), nil
+}
+
+// Revoke revokes the shares of all grants of a token, to be called on its transfer.
+func (i *AccessIssuer) Revoke(_ context.Context, collection common.Address, tokenID *big.Int) error {
+	key := grantKey(collection, tokenID)
+
+	i.mu.Lock()
+	if err := i.load(); err != nil {
+		i.mu.Unlock()
+		return err
+	}
+	grants := i.grants[key]
+	i.mu.Unlock()
+
+	var lastErr error
+	for _, grant := range grants {
+		if err := i.revoke(grant); err != nil {
+			// keep the grant to retry
+			lastErr = err
+			continue
+		}
+		if err := i.removeGrant(grant); err != nil {
+			return err
+		}
+	}
+	return lastErr
+}
+
+// RevokeTransferred revokes the grants of tokens no longer owned by the
+// grant owner and returns them.
+func (i *AccessIssuer) RevokeTransferred(ctx context.Context) ([]*AccessGrant, error) {
+	i.mu.Lock()
+	if err := i.load(); err != nil {
+		i.mu.Unlock()
+		return nil, err
+	}
+	var grants []*AccessGrant
+	for _, g := range i.grants {
+		grants = append(grants, g...)
+	}
+	i.mu.Unlock()
+
+	var revoked []*AccessGrant
+	for _, grant := range grants {
+		owner, err := i.OwnerOf(ctx, grant.Collection, grant.TokenID)
+		if err != nil {
+			return revoked, err
+		}
+		if owner == grant.Owner {
+			continue
+		}
+		if err := i.revoke(grant); err != nil {
+			return revoked, err
+		}
+		if err := i.removeGrant(grant); err != nil {
+			return revoked, err
+		}
+		revoked = append(revoked, grant)
+	}
+	return revoked, nil
+}
+
+func (i *AccessIssuer) revoke(grant *AccessGrant) error {
+	for file := range grant.Tickets {
+		if err := i.Allocation.RevokeShare(file, grant.ClientID); err != nil {
+			return errors.Wrapf(err, "failed to revoke share of %s", file)
+		}
+	}
+	return nil
+}
+
+func (i *AccessIssuer) removeGrant(grant *AccessGrant) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	key := grantKey(grant.Collection, grant.TokenID)
+	grants := i.grants[key]
+	for n, g := range grants {
+		if g == grant {
+			grants = append(grants[:n], grants[n+1:]...)
+			break
+		}
+	}
+	if len(grants) == 0 {
+		delete(i.grants, key)
+	} else {
+		i.grants[key] = grants
+	}
+	return i.save()
+}
+
+// load loads the stored grants once, called with mu held.
+func (i *AccessIssuer) load() error {
+	if i.loaded {
+		return nil
+	}
+	i.grants = make(map[string][]*AccessGrant)
+	if i.Store != nil {
+		grants, err := i.Store.Load()
+		if err != nil {
+			return errors.Wrap(err, "failed to load access grants")
+		}
+		for _, grant := range grants {
+			key := grantKey(grant.Collection, grant.TokenID)
+			i.grants[key] = append(i.grants[key], grant)
+		}
+	}
+	i.loaded = true
+	return nil
+}
+
+// save saves the grants, called with mu held.
+func (i *AccessIssuer) save() error {
+	if i.Store == nil {
+		return nil
+	}
+	var grants []*AccessGrant
+	for _, g := range i.grants {
+		grants = append(grants, g...)
+	}
+	if err := i.Store.Save(grants); err != nil {
+		return errors.Wrap(err, "failed to save access grants")
+	}
+	return nil
+}
+
+func grantKey(collection common.Address, tokenID *big.Int) string {
+	return collection.Hex() + "/" + tokenID.String()
+}
@@ -0,0 +1,163 @@
+package znft
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/0chain/gosdk/zboxcore/fileref"
+	"github.com/0chain/gosdk/zcnbridge/ethsigner"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeShareAllocation records the reference type of shares by path and client.
+type fakeShareAllocation struct {
+	shares map[string]string
+	// failShare fails the shares of the path, failRevoke all revocations
+	failShare  string
+	failRevoke bool
+}
+
+func (a *fakeShareAllocation) GetAuthTicket(path, _, referenceType, refereeClientID, _ string, _ int64, _ *time.Time) (string, error) {
+	if path == a.failShare {
+		return "", errors.New("blobbers unavailable")
+	}
+	a.shares[path+"|"+refereeClientID] = referenceType
+	return "ticket:" + path, nil
+}
+
+func (a *fakeShareAllocation) RevokeShare(path string, refereeClientID string) error {
+	if a.failRevoke {
+		return errors.New("blobbers unavailable")
+	}
+	delete(a.shares, path+"|"+refereeClientID)
+	return nil
+}
+
+func TestAccessIssuer(t *testing.T) {
+	ctx := context.Background()
+	collection := common.HexToAddress("0x01")
+	tokenID := big.NewInt(7)
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	holder := ethsigner.NewPrivateKeySigner(key)
+
+	owners := map[string]common.Address{tokenID.String(): holder.Address()}
+	allocation := &fakeShareAllocation{shares: make(map[string]string)}
+	store := NewFileGrantStore(filepath.Join(t.TempDir(), "grants.json"))
+	newIssuer := func() *AccessIssuer {
+		return &AccessIssuer{
+			Allocation: allocation,
+			OwnerOf: func(_ context.Context, _ common.Address, tokenID *big.Int) (common.Address, error) {
+				return owners[tokenID.String()], nil
+			},
+			Files: TokenDirFiles("/gated"),
+			Store: store,
+		}
+	}
+	issuer := newIssuer()
+
+	proof, err := NewAccessProof(holder, collection, tokenID, "client", "encryption key")
+	require.NoError(t, err)
+
+	t.Run("issue", func(t *testing.T) {
+		grant, err := issuer.Issue(ctx, proof)
+		require.NoError(t, err)
+		file := "/gated/" + collection.Hex() + "/7"
+		require.Equal(t, map[string]string{file: "ticket:" + file}, grant.Tickets)
+		require.Equal(t, fileref.DIRECTORY, allocation.shares[file+"|client"])
+		grants, err := issuer.Grants(collection, tokenID)
+		require.NoError(t, err)
+		require.Len(t, grants, 1)
+	})
+
+	t.Run("invalid proofs", func(t *testing.T) {
+		_, err := issuer.Issue(ctx, &AccessProof{Collection: collection, TokenID: big.NewInt(8), Owner: holder.Address(),
+			ClientID: "client", Timestamp: time.Now().Unix(), Signature: proof.Signature})
+		require.Error(t, err)
+
+		other, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		forged, err := NewAccessProof(ethsigner.NewPrivateKeySigner(other), collection, tokenID, "client", "")
+		require.NoError(t, err)
+		require.ErrorIs(t, issuer.Verify(ctx, forged), ErrNotTokenOwner)
+
+		expired := *proof
+		expired.Timestamp = time.Now().Add(-time.Hour).Unix()
+		require.ErrorIs(t, issuer.Verify(ctx, &expired), ErrAccessProofExpired)
+	})
+
+	t.Run("revoke on transfer", func(t *testing.T) {
+		revoked, err := issuer.RevokeTransferred(ctx)
+		require.NoError(t, err)
+		require.Empty(t, revoked)
+
+		// the grants are revoked by an issuer started after the transfer
+		issuer = newIssuer()
+		owners[tokenID.String()] = common.HexToAddress("0x02")
+		revoked, err = issuer.RevokeTransferred(ctx)
+		require.NoError(t, err)
+		require.Len(t, revoked, 1)
+		require.Empty(t, allocation.shares)
+		grants, err := issuer.Grants(collection, tokenID)
+		require.NoError(t, err)
+		require.Empty(t, grants)
+
+		grants, err = newIssuer().Grants(collection, tokenID)
+		require.NoError(t, err)
+		require.Empty(t, grants)
+	})
+}
+
+func TestAccessIssuerPartialIssue(t *testing.T) {
+	ctx := context.Background()
+	collection := common.HexToAddress("0x01")
+	tokenID := big.NewInt(7)
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	holder := ethsigner.NewPrivateKeySigner(key)
+	proof, err := NewAccessProof(holder, collection, tokenID, "client", "")
+	require.NoError(t, err)
+
+	allocation := &fakeShareAllocation{shares: make(map[string]string), failShare: "/b"}
+	issuer := &AccessIssuer{
+		Allocation: allocation,
+		OwnerOf: func(context.Context, common.Address, *big.Int) (common.Address, error) {
+			return holder.Address(), nil
+		},
+		Files: func(common.Address, *big.Int) ([]TokenFile, error) {
+			return []TokenFile{{Path: "/a", Type: fileref.FILE}, {Path: "/b", Type: fileref.FILE}}, nil
+		},
+	}
+
+	t.Run("revokes the shared files", func(t *testing.T) {
+		_, err := issuer.Issue(ctx, proof)
+		require.Error(t, err)
+		require.Empty(t, allocation.shares)
+		grants, err := issuer.Grants(collection, tokenID)
+		require.NoError(t, err)
+		require.Empty(t, grants)
+	})
+
+	t.Run("keeps the partial grant", func(t *testing.T) {
+		allocation.failRevoke = true
+		_, err := issuer.Issue(ctx, proof)
+		require.Error(t, err)
+		require.Contains(t, allocation.shares, "/a|client")
+		grants, err := issuer.Grants(collection, tokenID)
+		require.NoError(t, err)
+		require.Len(t, grants, 1)
+		require.Equal(t, map[string]string{"/a": "ticket:/a"}, grants[0].Tickets)
+
+		allocation.failRevoke = false
+		require.NoError(t, issuer.Revoke(ctx, collection, tokenID))
+		require.Empty(t, allocation.shares)
+	})
+}
@@ -0,0 +1,256 @@
+package znft
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/0chain/gosdk/zcnbridge/ethereum/authorizers"
+	"github.com/0chain/gosdk/zcnbridge/ethereum/simulated"
+	"github.com/0chain/gosdk/zcnbridge/ethsigner"
+	storageerc721 "github.com/0chain/gosdk/znft/contracts/dstorageerc721/binding"
+	storageerc721fixed "github.com/0chain/gosdk/znft/contracts/dstorageerc721fixed/binding"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+)
+
+func newSimulatedBackend(t *testing.T) *simulated.Backend {
+	backend, err := simulated.New(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { backend.Close() })
+	return backend
+}
+
+func transactOpts(t *testing.T, backend *simulated.Backend, account accounts.Account) *bind.TransactOpts {
+	opts, err := backend.TransactOpts(account)
+	require.NoError(t, err)
+	opts.Context = context.Background()
+	return opts
+}
+
+func newStorageERC721Session(t *testing.T, backend *simulated.Backend, address common.Address, account accounts.Account) *StorageECR721 {
+	contract, err := storageerc721.NewBinding(address, backend)
+	require.NoError(t, err)
+	opts := transactOpts(t, backend, account)
+	return &StorageECR721{
+		session: &storageerc721.BindingSession{
+			Contract:     contract,
+			CallOpts:     bind.CallOpts{From: opts.From, Context: opts.Context},
+			TransactOpts: *opts,
+		},
+		ctx: opts.Context,
+	}
+}
+
+func TestStorageERC721Session(t *testing.T) {
+	backend := newSimulatedBackend(t)
+	address := deployTestStorageERC721(t, backend, "Cats", "CAT", "https://0nft.example/ticket/", big.NewInt(3))
+
+	owner := newStorageERC721Session(t, backend, address, backend.Owner)
+	holderAccount, err := backend.NewAccount()
+	require.NoError(t, err)
+	holder := newStorageERC721Session(t, backend, address, holderAccount)
+
+	contract, err := storageerc721.NewBinding(address, backend)
+	require.NoError(t, err)
+
+	t.Run("fields", func(t *testing.T) {
+		name, err := contract.Name(nil)
+		require.NoError(t, err)
+		require.Equal(t, "Cats", name)
+		symbol, err := contract.Symbol(nil)
+		require.NoError(t, err)
+		require.Equal(t, "CAT", symbol)
+
+		max, err := owner.Max()
+		require.NoError(t, err)
+		require.EqualValues(t, 3, max.Int64())
+		uri, err := owner.Uri()
+		require.NoError(t, err)
+		require.Equal(t, "https://0nft.example/ticket/", uri)
+		receiver, err := owner.Receiver()
+		require.NoError(t, err)
+		require.Equal(t, backend.Owner.Address.Hex(), receiver)
+		mintable, err := owner.Mintable()
+		require.NoError(t, err)
+		require.False(t, mintable)
+	})
+
+	t.Run("configure", func(t *testing.T) {
+		require.NoError(t, owner.SetAllocation("allocation id"))
+		allocation, err := owner.Allocation()
+		require.NoError(t, err)
+		require.Equal(t, "allocation id", allocation)
+
+		// longer than a word
+		uri := "https://0nft.example/a-metadata-auth-ticket-longer-than-a-word/"
+		require.NoError(t, owner.SetURI(uri))
+		got, err := owner.Uri()
+		require.NoError(t, err)
+		require.Equal(t, uri, got)
+		require.NoError(t, owner.SetURIFallback("https://fallback.example/"))
+
+		require.NoError(t, owner.SetRoyalty(big.NewInt(250)))
+		require.NoError(t, owner.SetReceiver(holderAccount.Address.Hex()))
+		receiver, sum, err := owner.RoyaltyInfo(big.NewInt(1), big.NewInt(10000))
+		require.NoError(t, err)
+		require.Equal(t, holderAccount.Address.Hex(), receiver)
+		require.EqualValues(t, 250, sum.Int64())
+		require.Error(t, owner.SetRoyalty(big.NewInt(royaltyDenominator+1)))
+
+		require.Error(t, holder.SetURI("https://forged.example/"))
+		require.Error(t, holder.SetAllocation("forged"))
+	})
+
+	t.Run("mint", func(t *testing.T) {
+		require.Error(t, holder.Mint(big.NewInt(1)))
+		require.Error(t, holder.MintOwner(big.NewInt(1)))
+
+		require.NoError(t, owner.MintOwner(big.NewInt(1)))
+		require.NoError(t, owner.SetMintable(true))
+		require.NoError(t, holder.Mint(big.NewInt(2)))
+		require.Error(t, holder.Mint(big.NewInt(1)))
+
+		total, err := holder.Total()
+		require.NoError(t, err)
+		require.EqualValues(t, 3, total.Int64())
+		tokenOwner, err := contract.OwnerOf(nil, big.NewInt(3))
+		require.NoError(t, err)
+		require.Equal(t, holderAccount.Address, tokenOwner)
+		balance, err := contract.BalanceOf(nil, holderAccount.Address)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, balance.Int64())
+	})
+
+	t.Run("token uri", func(t *testing.T) {
+		uri, err := contract.TokenURI(nil, big.NewInt(3))
+		require.NoError(t, err)
+		require.Equal(t, "https://0nft.example/a-metadata-auth-ticket-longer-than-a-word/3", uri)
+		uri, err = holder.TokenURIFallback(big.NewInt(1))
+		require.NoError(t, err)
+		require.Equal(t, "https://fallback.example/1", uri)
+
+		_, err = holder.TokenURIFallback(big.NewInt(4))
+		require.Error(t, err)
+	})
+
+	t.Run("transfer", func(t *testing.T) {
+		opts := transactOpts(t, backend, holderAccount)
+		_, err := contract.TransferFrom(opts, backend.Owner.Address, holderAccount.Address, big.NewInt(1))
+		require.Error(t, err)
+
+		_, err = contract.SafeTransferFrom(opts, holderAccount.Address, backend.Owner.Address, big.NewInt(2))
+		require.NoError(t, err)
+		tokenOwner, err := contract.OwnerOf(nil, big.NewInt(2))
+		require.NoError(t, err)
+		require.Equal(t, backend.Owner.Address, tokenOwner)
+
+		// an approved operator moves the token of the owner
+		_, err = contract.SetApprovalForAll(transactOpts(t, backend, backend.Owner), holderAccount.Address, true)
+		require.NoError(t, err)
+		_, err = contract.TransferFrom(opts, backend.Owner.Address, holderAccount.Address, big.NewInt(1))
+		require.NoError(t, err)
+		balance, err := contract.BalanceOf(nil, holderAccount.Address)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, balance.Int64())
+
+		// a contract not implementing onERC721Received can not receive safe transfers
+		authorizers, _, _, err := authorizers.DeployAuthorizers(transactOpts(t, backend, backend.Owner), backend)
+		require.NoError(t, err)
+		_, err = contract.SafeTransferFrom(opts, holderAccount.Address, authorizers, big.NewInt(1))
+		require.Error(t, err)
+	})
+
+	t.Run("gated access", func(t *testing.T) {
+		ctx := context.Background()
+		allocation := &fakeShareAllocation{shares: make(map[string]string)}
+		issuer := &AccessIssuer{
+			Allocation: allocation,
+			OwnerOf: func(ctx context.Context, collection common.Address, tokenID *big.Int) (common.Address, error) {
+				instance, err := storageerc721.NewBinding(collection, backend)
+				if err != nil {
+					return common.Address{}, err
+				}
+				return instance.OwnerOf(&bind.CallOpts{Context: ctx}, tokenID)
+			},
+			Files: TokenDirFiles("/gated"),
+		}
+		holderSigner := ethsigner.NewKeyStoreSigner(backend.KeyStore, holderAccount.Address, simulated.Password)
+
+		proof, err := NewAccessProof(holderSigner, address, big.NewInt(2), "client", "")
+		require.NoError(t, err)
+		require.ErrorIs(t, issuer.Verify(ctx, proof), ErrNotTokenOwner)
+
+		proof, err = NewAccessProof(holderSigner, address, big.NewInt(3), "client", "")
+		require.NoError(t, err)
+		_, err = issuer.Issue(ctx, proof)
+		require.NoError(t, err)
+
+		_, err = contract.TransferFrom(transactOpts(t, backend, holderAccount), holderAccount.Address, backend.Owner.Address, big.NewInt(3))
+		require.NoError(t, err)
+		revoked, err := issuer.RevokeTransferred(ctx)
+		require.NoError(t, err)
+		require.Len(t, revoked, 1)
+		require.Empty(t, allocation.shares)
+	})
+
+	t.Run("freeze", func(t *testing.T) {
+		_, err := contract.Freeze(transactOpts(t, backend, backend.Owner))
+		require.NoError(t, err)
+		require.Error(t, owner.SetURI("https://0nft.example/other/"))
+	})
+}
+
+func TestStorageERC721FixedSession(t *testing.T) {
+	ctx := context.Background()
+	backend := newSimulatedBackend(t)
+	price := big.NewInt(params.GWei)
+	address := deployTestStorageERC721Fixed(t, backend, "Dogs", "DOG", "https://0nft.example/", big.NewInt(10), price, big.NewInt(2))
+
+	contract, err := storageerc721fixed.NewBinding(address, backend)
+	require.NoError(t, err)
+	buyer, err := backend.NewAccount()
+	require.NoError(t, err)
+	opts := transactOpts(t, backend, buyer)
+	session := &StorageECR721Fixed{
+		session: &storageerc721fixed.BindingSession{
+			Contract:     contract,
+			CallOpts:     bind.CallOpts{From: opts.From, Context: ctx},
+			TransactOpts: *opts,
+		},
+		ctx: ctx,
+	}
+
+	got, err := session.Price()
+	require.NoError(t, err)
+	require.Equal(t, price, got)
+	batch, err := session.Batch()
+	require.NoError(t, err)
+	require.EqualValues(t, 2, batch.Int64())
+
+	_, err = contract.SetMintable(transactOpts(t, backend, backend.Owner), true)
+	require.NoError(t, err)
+
+	// unpaid and above the batch
+	require.Error(t, session.Mint(big.NewInt(1)))
+	session.session.TransactOpts.Value = new(big.Int).Mul(price, big.NewInt(3))
+	require.Error(t, session.Mint(big.NewInt(3)))
+
+	session.session.TransactOpts.Value = new(big.Int).Mul(price, big.NewInt(2))
+	require.NoError(t, session.Mint(big.NewInt(2)))
+	balance, err := backend.BalanceAt(ctx, address, nil)
+	require.NoError(t, err)
+	require.Equal(t, session.session.TransactOpts.Value, balance)
+
+	// the proceeds go to the owner
+	session.session.TransactOpts.Value = nil
+	require.Error(t, session.Withdraw())
+	_, err = contract.Withdraw(transactOpts(t, backend, backend.Owner))
+	require.NoError(t, err)
+	balance, err = backend.BalanceAt(ctx, address, nil)
+	require.NoError(t, err)
+	require.Zero(t, balance.Sign())
+}
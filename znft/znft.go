@@ -6,6 +6,8 @@ import (
 	"os"
 
 	"github.com/0chain/gosdk/core/logger"
+	"github.com/0chain/gosdk/zcnbridge/ethsigner"
+	"github.com/0chain/gosdk/zcnbridge/gas"
 
 	storageerc721 "github.com/0chain/gosdk/znft/contracts/dstorageerc721/binding"
 	storageerc721fixed "github.com/0chain/gosdk/znft/contracts/dstorageerc721fixed/binding"
@@ -41,6 +43,9 @@ type Configuration struct {
 	VaultPassword                    string // VaultPassword used to sign transactions on behalf of the client
 	Homedir                          string // Homedir is a client config folder
 	Value                            int64  // Value to execute Ethereum smart contracts (default = 0)
+
+	GasStrategy gas.Strategy      `yaml:"-"`                // GasStrategy sets transaction fees (default = gas.Default())
+	Signer      *ethsigner.Config `yaml:"signer,omitempty"` // Signer selects the transaction signer (default = key store account WalletAddress)
 }
 
 type Znft struct {